@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"tum_inv_backend/internal/domain/models"
@@ -28,7 +29,7 @@ func (c *AccesoRemotoController) CreateAccesoRemoto(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.accesoService.CreateAccesoRemoto(acceso); err != nil {
+	if err := c.accesoService.CreateAccesoRemoto(ctx.Request().Context(), acceso); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -63,7 +64,7 @@ func (c *AccesoRemotoController) UpdateAccesoRemoto(ctx echo.Context) error {
 	}
 
 	acceso.ID = uint(id)
-	if err := c.accesoService.UpdateAccesoRemoto(acceso); err != nil {
+	if err := c.accesoService.UpdateAccesoRemoto(ctx.Request().Context(), acceso); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -77,7 +78,7 @@ func (c *AccesoRemotoController) DeleteAccesoRemoto(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.accesoService.DeleteAccesoRemoto(uint(id)); err != nil {
+	if err := c.accesoService.DeleteAccesoRemoto(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -86,12 +87,13 @@ func (c *AccesoRemotoController) DeleteAccesoRemoto(ctx echo.Context) error {
 
 // GetAllAccesosRemotos obtiene todos los accesos remotos
 func (c *AccesoRemotoController) GetAllAccesosRemotos(ctx echo.Context) error {
-	accesos, err := c.accesoService.GetAllAccesosRemotos()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, accesos)
+	accesos, err := c.accesoService.GetAllAccesosRemotos(consulta)
+	return responderPagina(ctx, accesos, err)
 }
 
 // GetAccesosRemotosByEquipo obtiene todos los accesos remotos asociados a un equipo
@@ -107,4 +109,22 @@ func (c *AccesoRemotoController) GetAccesosRemotosByEquipo(ctx echo.Context) err
 	}
 
 	return ctx.JSON(http.StatusOK, accesos)
-}
\ No newline at end of file
+}
+
+// RevelarContrasena retorna la contraseña descifrada de un acceso remoto y deja registro de la consulta
+func (c *AccesoRemotoController) RevelarContrasena(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	contrasena, err := c.accesoService.RevelarContrasena(ctx.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrRevelarSinAuditoria) {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"contrasena": contrasena})
+}
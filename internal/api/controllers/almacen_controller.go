@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AlmacenController maneja el catálogo del almacén de repuestos, sus movimientos y su kardex
+type AlmacenController struct {
+	service services.AlmacenService
+}
+
+// NewAlmacenController crea una nueva instancia de AlmacenController
+func NewAlmacenController(service services.AlmacenService) *AlmacenController {
+	return &AlmacenController{service: service}
+}
+
+// GetAllArticulos lista el catálogo (filtros: serial, descripcion, marca, tecnologia, bajo_stock)
+// GET /api/almacen/articulos
+func (c *AlmacenController) GetAllArticulos(ctx echo.Context) error {
+	consulta, err := parseConsulta(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	articulos, err := c.service.GetArticulos(consulta)
+	return responderPagina(ctx, articulos, err)
+}
+
+// GetBajoStock lista los artículos con el stock en el mínimo o por debajo
+// GET /api/almacen/articulos/bajo-stock
+func (c *AlmacenController) GetBajoStock(ctx echo.Context) error {
+	articulos, err := c.service.GetBajoStock()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, articulos)
+}
+
+// GetArticulo obtiene un artículo por su ID
+// GET /api/almacen/articulos/:id
+func (c *AlmacenController) GetArticulo(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	articulo, err := c.service.GetArticulo(uint(id))
+	if err != nil {
+		return responderErrorAlmacen(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, articulo)
+}
+
+// CreateArticulo crea un artículo; Stock es el inventario inicial
+// POST /api/almacen/articulos
+func (c *AlmacenController) CreateArticulo(ctx echo.Context) error {
+	articulo := new(models.ArticuloRepuesto)
+	if err := ctx.Bind(articulo); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	articulo.ID = 0
+
+	if err := c.service.CreateArticulo(ctx.Request().Context(), articulo); err != nil {
+		return responderErrorAlmacen(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, articulo)
+}
+
+// UpdateArticulo actualiza los datos y el stock mínimo de un artículo
+// PUT /api/almacen/articulos/:id
+func (c *AlmacenController) UpdateArticulo(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	articulo := new(models.ArticuloRepuesto)
+	if err := ctx.Bind(articulo); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	articulo.ID = uint(id)
+
+	if err := c.service.UpdateArticulo(ctx.Request().Context(), articulo); err != nil {
+		return responderErrorAlmacen(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, articulo)
+}
+
+// DeleteArticulo elimina un artículo sin existencias que ningún repuesto de un reporte usa
+// DELETE /api/almacen/articulos/:id
+func (c *AlmacenController) DeleteArticulo(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	if err := c.service.DeleteArticulo(ctx.Request().Context(), uint(id)); err != nil {
+		return responderErrorAlmacen(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Artículo eliminado correctamente"})
+}
+
+// RegistrarMovimiento registra una entrada, una salida o un ajuste del stock de un artículo
+// POST /api/almacen/articulos/:id/movimientos
+func (c *AlmacenController) RegistrarMovimiento(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	movimiento := new(models.MovimientoRepuesto)
+	if err := ctx.Bind(movimiento); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	movimiento.ArticuloID = uint(id)
+
+	if err := c.service.RegistrarMovimiento(ctx.Request().Context(), movimiento); err != nil {
+		return responderErrorAlmacen(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, movimiento)
+}
+
+// GetKardex lista los movimientos de un artículo, del más antiguo al más reciente, con el
+// saldo después de cada uno (filtros: tipo, reporte, desde, hasta)
+// GET /api/almacen/articulos/:id/kardex
+func (c *AlmacenController) GetKardex(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+	consulta, err := parseConsulta(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	kardex, err := c.service.GetKardex(uint(id), consulta)
+	if errors.Is(err, services.ErrArticuloNoEncontrado) {
+		return responderErrorAlmacen(ctx, err)
+	}
+	return responderPagina(ctx, kardex, err)
+}
+
+// responderErrorAlmacen traduce los errores del servicio del almacén a respuestas HTTP
+func responderErrorAlmacen(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrArticuloNoEncontrado):
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrArticuloDuplicado), errors.Is(err, services.ErrArticuloConStock),
+		errors.Is(err, services.ErrArticuloEnUso), errors.Is(err, services.ErrStockInsuficiente):
+		return ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrArticuloInvalido), errors.Is(err, services.ErrMovimientoInvalido):
+		return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"tum_inv_backend/internal/infrastructure/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ArchivoController sirve las descargas del almacenamiento local con URL firmadas
+type ArchivoController struct {
+	almacenamiento *storage.LocalStorage
+}
+
+// NewArchivoController crea una nueva instancia del controlador
+func NewArchivoController(almacenamiento *storage.LocalStorage) *ArchivoController {
+	return &ArchivoController{almacenamiento: almacenamiento}
+}
+
+// Descargar entrega el archivo si la firma (?vence=&firma=) es válida y no ha vencido.
+// No requiere token: la URL firmada es la autorización.
+func (c *ArchivoController) Descargar(ctx echo.Context) error {
+	nombre, err := url.PathUnescape(ctx.Param("*"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "nombre de archivo inválido"})
+	}
+
+	if err := c.almacenamiento.VerificarFirma(nombre, ctx.QueryParam("vence"), ctx.QueryParam("firma")); err != nil {
+		return ctx.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	archivo, err := c.almacenamiento.Open(nombre)
+	if err != nil {
+		if errors.Is(err, storage.ErrArchivoNoEncontrado) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	defer archivo.Close()
+
+	tipoContenido := mime.TypeByExtension(path.Ext(nombre))
+	if tipoContenido == "" {
+		tipoContenido = echo.MIMEOctetStream
+	}
+	ctx.Response().Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", path.Base(nombre)))
+	ctx.Response().Header().Set("Cache-Control", "private, no-store")
+	return ctx.Stream(http.StatusOK, tipoContenido, archivo)
+}
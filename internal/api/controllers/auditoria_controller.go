@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuditoriaController maneja las solicitudes HTTP relacionadas con la auditoría
+type AuditoriaController struct {
+	auditoriaService services.AuditoriaService
+}
+
+// NewAuditoriaController crea una nueva instancia de AuditoriaController
+func NewAuditoriaController(auditoriaService services.AuditoriaService) *AuditoriaController {
+	return &AuditoriaController{
+		auditoriaService: auditoriaService,
+	}
+}
+
+// GetAuditoria obtiene una página de registros de auditoría filtrados por entidad, usuario, acción y rango de fechas
+func (c *AuditoriaController) GetAuditoria(ctx echo.Context) error {
+	consulta, err := parseConsulta(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	registros, err := c.auditoriaService.GetAuditoria(consulta)
+	return responderPagina(ctx, registros, err)
+}
+
+// GetHistorialEquipo obtiene el historial de cambios de un equipo y sus componentes
+func (c *AuditoriaController) GetHistorialEquipo(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	historial, err := c.auditoriaService.GetHistorialEquipo(uint(id))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, historial)
+}
+
+// parseFechaFiltro acepta fechas YYYY-MM-DD o RFC3339. Una fecha sin hora usada
+// como límite superior incluye todo el día.
+func parseFechaFiltro(valor string, finDelDia bool) (time.Time, error) {
+	if fecha, err := time.Parse(time.RFC3339, valor); err == nil {
+		return fecha, nil
+	}
+	fecha, err := time.ParseInLocation("2006-01-02", valor, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if finDelDia {
+		fecha = fecha.Add(24*time.Hour - time.Nanosecond)
+	}
+	return fecha, nil
+}
@@ -33,7 +33,7 @@ func (c *AuthController) Register(ctx echo.Context) error {
 	}
 
 	// Registrar usuario
-	usuario, err := c.authService.Register(*req)
+	usuario, err := c.authService.Register(ctx.Request().Context(), *req)
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -118,14 +118,12 @@ func (c *AuthController) GetProfile(ctx echo.Context) error {
 
 // GetAllUsers obtiene la lista de todos los usuarios registrados (solo admin)
 func (c *AuthController) GetAllUsers(ctx echo.Context) error {
-	// Obtener usuarios
-	usuarios, err := c.authService.GetAllUsers()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error obteniendo usuarios"})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]interface{}{
-		"usuarios": usuarios,
-		"total":    len(usuarios),
-	})
+	// Obtener usuarios
+	usuarios, err := c.authService.GetAllUsers(consulta)
+	return responderPagina(ctx, usuarios, err)
 }
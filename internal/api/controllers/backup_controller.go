@@ -28,7 +28,7 @@ func (c *BackupController) CreateBackup(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.backupService.CreateBackup(backup); err != nil {
+	if err := c.backupService.CreateBackup(ctx.Request().Context(), backup); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -63,7 +63,7 @@ func (c *BackupController) UpdateBackup(ctx echo.Context) error {
 	}
 
 	backup.ID = uint(id)
-	if err := c.backupService.UpdateBackup(backup); err != nil {
+	if err := c.backupService.UpdateBackup(ctx.Request().Context(), backup); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -77,7 +77,7 @@ func (c *BackupController) DeleteBackup(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.backupService.DeleteBackup(uint(id)); err != nil {
+	if err := c.backupService.DeleteBackup(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -86,12 +86,13 @@ func (c *BackupController) DeleteBackup(ctx echo.Context) error {
 
 // GetAllBackups obtiene todos los backups
 func (c *BackupController) GetAllBackups(ctx echo.Context) error {
-	backups, err := c.backupService.GetAllBackups()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, backups)
+	backups, err := c.backupService.GetAllBackups(consulta)
+	return responderPagina(ctx, backups, err)
 }
 
 // GetBackupsByEquipo obtiene todos los backups asociados a un equipo
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BusquedaController maneja la búsqueda global
+type BusquedaController struct {
+	service services.BusquedaService
+}
+
+// NewBusquedaController crea una nueva instancia del controlador
+func NewBusquedaController(service services.BusquedaService) *BusquedaController {
+	return &BusquedaController{service: service}
+}
+
+// Buscar busca ?q= en todo el inventario; ?limit= limita el número de resultados
+func (c *BusquedaController) Buscar(ctx echo.Context) error {
+	limite := 0
+	if valor := ctx.QueryParam("limit"); valor != "" {
+		var err error
+		if limite, err = strconv.Atoi(valor); err != nil || limite < 1 {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "limit debe ser un número mayor o igual a 1"})
+		}
+	}
+
+	resultados, err := c.service.Buscar(ctx.QueryParam("q"), limite)
+	if err != nil {
+		if errors.Is(err, dto.ErrConsultaInvalida) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, resultados)
+}
@@ -28,7 +28,7 @@ func (c *ConfiguracionRedController) CreateConfiguracionRed(ctx echo.Context) er
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.configuracionService.CreateConfiguracionRed(configuracion); err != nil {
+	if err := c.configuracionService.CreateConfiguracionRed(ctx.Request().Context(), configuracion); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -63,7 +63,7 @@ func (c *ConfiguracionRedController) UpdateConfiguracionRed(ctx echo.Context) er
 	}
 
 	configuracion.ID = uint(id)
-	if err := c.configuracionService.UpdateConfiguracionRed(configuracion); err != nil {
+	if err := c.configuracionService.UpdateConfiguracionRed(ctx.Request().Context(), configuracion); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -77,7 +77,7 @@ func (c *ConfiguracionRedController) DeleteConfiguracionRed(ctx echo.Context) er
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.configuracionService.DeleteConfiguracionRed(uint(id)); err != nil {
+	if err := c.configuracionService.DeleteConfiguracionRed(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -86,12 +86,13 @@ func (c *ConfiguracionRedController) DeleteConfiguracionRed(ctx echo.Context) er
 
 // GetAllConfiguracionesRed obtiene todas las configuraciones de red
 func (c *ConfiguracionRedController) GetAllConfiguracionesRed(ctx echo.Context) error {
-	configuraciones, err := c.configuracionService.GetAllConfiguracionesRed()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, configuraciones)
+	configuraciones, err := c.configuracionService.GetAllConfiguracionesRed(consulta)
+	return responderPagina(ctx, configuraciones, err)
 }
 
 // GetConfiguracionRedByEquipo obtiene la configuración de red asociada a un equipo
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"tum_inv_backend/internal/domain/models/dto"
+
+	"github.com/labstack/echo/v4"
+)
+
+// parametrosConsulta son los parámetros de paginación y orden; el resto se toman como filtros
+var parametrosConsulta = map[string]bool{
+	"page":      true,
+	"page_size": true,
+	"sort":      true,
+	"order":     true,
+	"desde":     true,
+	"hasta":     true,
+}
+
+// parseConsulta lee ?page=&page_size=&sort=&order=&desde=&hasta= y los filtros por campo
+func parseConsulta(ctx echo.Context) (dto.ConsultaDTO, error) {
+	consulta := dto.ConsultaDTO{
+		Pagina:       dto.PaginaPorDefecto,
+		TamanoPagina: dto.TamanoPaginaPorDefecto,
+		Orden:        ctx.QueryParam("sort"),
+		Filtros:      map[string]string{},
+	}
+
+	if valor := ctx.QueryParam("page"); valor != "" {
+		pagina, err := strconv.Atoi(valor)
+		if err != nil || pagina < 1 {
+			return consulta, errors.New("page debe ser un número mayor o igual a 1")
+		}
+		consulta.Pagina = pagina
+	}
+	if valor := ctx.QueryParam("page_size"); valor != "" {
+		tamano, err := strconv.Atoi(valor)
+		if err != nil || tamano < 1 || tamano > dto.TamanoPaginaMaximo {
+			return consulta, fmt.Errorf("page_size debe estar entre 1 y %d", dto.TamanoPaginaMaximo)
+		}
+		consulta.TamanoPagina = tamano
+	}
+
+	switch strings.ToLower(ctx.QueryParam("order")) {
+	case "", "asc":
+	case "desc":
+		consulta.Descendente = true
+	default:
+		return consulta, errors.New("order debe ser asc o desc")
+	}
+
+	if valor := ctx.QueryParam("desde"); valor != "" {
+		desde, err := parseFechaFiltro(valor, false)
+		if err != nil {
+			return consulta, errors.New("fecha desde inválida, use YYYY-MM-DD o RFC3339")
+		}
+		consulta.Desde = &desde
+	}
+	if valor := ctx.QueryParam("hasta"); valor != "" {
+		hasta, err := parseFechaFiltro(valor, true)
+		if err != nil {
+			return consulta, errors.New("fecha hasta inválida, use YYYY-MM-DD o RFC3339")
+		}
+		consulta.Hasta = &hasta
+	}
+	if consulta.Desde != nil && consulta.Hasta != nil && consulta.Hasta.Before(*consulta.Desde) {
+		return consulta, errors.New("la fecha hasta no puede ser anterior a la fecha desde")
+	}
+
+	for nombre, valores := range ctx.QueryParams() {
+		if parametrosConsulta[nombre] || len(valores) == 0 {
+			continue
+		}
+		consulta.Filtros[nombre] = valores[0]
+	}
+
+	return consulta, nil
+}
+
+// responderPagina responde un listado paginado; los errores de orden o filtros son 400
+func responderPagina[T any](ctx echo.Context, pagina dto.PaginaDTO[T], err error) error {
+	if err != nil {
+		if errors.Is(err, dto.ErrConsultaInvalida) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, pagina)
+}
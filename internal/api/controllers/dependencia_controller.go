@@ -26,7 +26,7 @@ func (c *DependenciaController) CreateDependencia(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.service.CreateDependencia(dependencia); err != nil {
+	if err := c.service.CreateDependencia(ctx.Request().Context(), dependencia); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -50,12 +50,13 @@ func (c *DependenciaController) GetDependencia(ctx echo.Context) error {
 
 // GetAllDependencias maneja la obtención de todas las dependencias
 func (c *DependenciaController) GetAllDependencias(ctx echo.Context) error {
-	dependencias, err := c.service.GetAllDependencias()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al obtener dependencias"})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, dependencias)
+	dependencias, err := c.service.GetAllDependencias(consulta)
+	return responderPagina(ctx, dependencias, err)
 }
 
 // UpdateDependencia maneja la actualización de una dependencia existente
@@ -71,7 +72,7 @@ func (c *DependenciaController) UpdateDependencia(ctx echo.Context) error {
 	}
 
 	dependencia.ID = uint(id)
-	if err := c.service.UpdateDependencia(dependencia); err != nil {
+	if err := c.service.UpdateDependencia(ctx.Request().Context(), dependencia); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -85,7 +86,7 @@ func (c *DependenciaController) DeleteDependencia(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.service.DeleteDependencia(uint(id)); err != nil {
+	if err := c.service.DeleteDependencia(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
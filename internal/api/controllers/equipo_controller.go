@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"tum_inv_backend/internal/domain/models"
@@ -30,7 +31,7 @@ func (c *EquipoController) CreateEquipo(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.equipoService.CreateEquipo(equipo); err != nil {
+	if err := c.equipoService.CreateEquipo(ctx.Request().Context(), equipo); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -65,7 +66,7 @@ func (c *EquipoController) UpdateEquipo(ctx echo.Context) error {
 	}
 
 	equipo.ID = uint(id)
-	if err := c.equipoService.UpdateEquipo(equipo); err != nil {
+	if err := c.equipoService.UpdateEquipo(ctx.Request().Context(), equipo); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -79,7 +80,7 @@ func (c *EquipoController) DeleteEquipo(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.equipoService.DeleteEquipo(uint(id)); err != nil {
+	if err := c.equipoService.DeleteEquipo(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -88,12 +89,13 @@ func (c *EquipoController) DeleteEquipo(ctx echo.Context) error {
 
 // GetAllEquipos obtiene todos los equipos
 func (c *EquipoController) GetAllEquipos(ctx echo.Context) error {
-	equipos, err := c.equipoService.GetAllEquipos()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, equipos)
+	equipos, err := c.equipoService.GetAllEquipos(consulta)
+	return responderPagina(ctx, equipos, err)
 }
 
 // GetEquiposByDependencia obtiene todos los equipos de una dependencia
@@ -128,12 +130,13 @@ func (c *EquipoController) GetEquipoUsuDepByID(ctx echo.Context) error {
 
 // GetAllEquipos obtiene todos los equipos con detalle
 func (c *EquipoController) GetAllEquiposDetalle(ctx echo.Context) error {
-	equipos, err := c.equipoService.GetAllEquiposDetalle()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, equipos)
+	equipos, err := c.equipoService.GetAllEquiposDetalle(consulta)
+	return responderPagina(ctx, equipos, err)
 }
 
 // AsignarResponsable asigna un usuario responsable a un equipo (solo cambia el FK)
@@ -144,15 +147,118 @@ func (c *EquipoController) AsignarResponsable(ctx echo.Context) error {
 	}
 
 	var body struct {
-		UsuarioResponsableID *uint `json:"UsuarioResponsableID"`
+		UsuarioResponsableID *uint  `json:"UsuarioResponsableID"`
+		Motivo               string `json:"Motivo"`
 	}
 	if err := ctx.Bind(&body); err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.equipoService.AsignarResponsable(uint(id), body.UsuarioResponsableID); err != nil {
+	asignacion, err := c.equipoService.AsignarResponsable(ctx.Request().Context(), uint(id), body.UsuarioResponsableID, body.Motivo)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if asignacion == nil {
+		return ctx.JSON(http.StatusOK, map[string]string{"mensaje": "El equipo ya está asignado a este responsable"})
+	}
+
+	// El acta de entrega se genera a partir de la asignación registrada
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"mensaje":    "Responsable asignado correctamente",
+		"asignacion": asignacion,
+		"acta_url":   fmt.Sprintf("/api/asignaciones/%d/acta", asignacion.ID),
+	})
+}
+
+// GetAsignacionesByEquipo obtiene el historial de custodia de un equipo
+func (c *EquipoController) GetAsignacionesByEquipo(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	asignaciones, err := c.equipoService.GetAsignacionesByEquipo(uint(id))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, asignaciones)
+}
+
+// GetAsignacionesByResponsable obtiene los equipos que ha tenido a cargo un usuario responsable
+func (c *EquipoController) GetAsignacionesByResponsable(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	asignaciones, err := c.equipoService.GetAsignacionesByResponsable(uint(id))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, asignaciones)
+}
+
+// SubirActaFirmada sube el acta de entrega firmada de una asignación
+func (c *EquipoController) SubirActaFirmada(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	// Obtener el archivo del formulario multipart
+	file, err := ctx.FormFile("archivo")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Debe enviar un archivo PDF"})
+	}
+
+	// Validar que sea un PDF
+	if file.Header.Get("Content-Type") != "application/pdf" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El archivo debe ser un PDF"})
+	}
+
+	// Validar tamaño (máx 10MB)
+	if file.Size > 10*1024*1024 {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El archivo no debe superar los 10MB"})
+	}
+
+	// Leer el contenido del archivo
+	src, err := file.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al leer el archivo"})
+	}
+	defer src.Close()
+
+	fileData, err := io.ReadAll(src)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al procesar el archivo"})
+	}
+
+	asignacion, err := c.equipoService.SubirActaFirmada(ctx.Request().Context(), uint(id), fileData, "application/pdf")
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"message":    "Acta de entrega firmada subida correctamente",
+		"asignacion": asignacion,
+	})
+}
+
+// DescargarActaFirmada genera una URL temporal para descargar el acta de entrega firmada
+func (c *EquipoController) DescargarActaFirmada(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	url, err := c.equipoService.ObtenerURLActaFirmada(uint(id))
+	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]string{"mensaje": "Responsable asignado correctamente"})
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"url": url,
+	})
 }
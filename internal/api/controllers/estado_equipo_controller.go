@@ -27,11 +27,13 @@ func NewEstadoEquipoController(service *services.EstadoEquipoService) *EstadoEqu
 // @Failure 500 {object} map[string]string
 // @Router /estado-equipos [get]
 func (c *EstadoEquipoController) GetAllEstados(ctx echo.Context) error {
-	estados, err := c.service.GetAllEstados()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	return ctx.JSON(http.StatusOK, estados)
+
+	estados, err := c.service.GetAllEstados(consulta)
+	return responderPagina(ctx, estados, err)
 }
 
 // GetActiveEstados obtiene todos los estados de equipo activos
@@ -94,7 +96,7 @@ func (c *EstadoEquipoController) CreateEstado(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos: " + err.Error()})
 	}
 
-	if err := c.service.CreateEstado(&estado); err != nil {
+	if err := c.service.CreateEstado(ctx.Request().Context(), &estado); err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
@@ -125,7 +127,7 @@ func (c *EstadoEquipoController) UpdateEstado(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos: " + err.Error()})
 	}
 
-	if err := c.service.UpdateEstado(uint(id), &estado); err != nil {
+	if err := c.service.UpdateEstado(ctx.Request().Context(), uint(id), &estado); err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
@@ -156,7 +158,7 @@ func (c *EstadoEquipoController) DeleteEstado(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.service.DeleteEstado(uint(id)); err != nil {
+	if err := c.service.DeleteEstado(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
@@ -181,7 +183,7 @@ func (c *EstadoEquipoController) ToggleActivo(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.service.ToggleActivo(uint(id)); err != nil {
+	if err := c.service.ToggleActivo(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ExportacionController maneja la exportación del inventario a XLSX y CSV
+type ExportacionController struct {
+	exportacionService services.ExportacionService
+}
+
+// NewExportacionController crea una nueva instancia de ExportacionController
+func NewExportacionController(exportacionService services.ExportacionService) *ExportacionController {
+	return &ExportacionController{exportacionService: exportacionService}
+}
+
+// ExportarEquipos descarga el inventario de equipos en XLSX, una hoja por secretaría.
+// Acepta los mismos filtros y orden que GET /api/equipos.
+func (c *ExportacionController) ExportarEquipos(ctx echo.Context) error {
+	return c.exportar(ctx, "inventario_equipos", ".xlsx", services.TipoContenidoXLSX, c.exportacionService.ExportarEquiposXLSX)
+}
+
+// ExportarPerifericos descarga los periféricos en CSV con los filtros de GET /api/perifericos
+func (c *ExportacionController) ExportarPerifericos(ctx echo.Context) error {
+	return c.exportar(ctx, "perifericos", ".csv", services.TipoContenidoCSV, c.exportacionService.ExportarPerifericosCSV)
+}
+
+// ExportarSoftware descarga el software en CSV con los filtros de GET /api/software
+func (c *ExportacionController) ExportarSoftware(ctx echo.Context) error {
+	return c.exportar(ctx, "software", ".csv", services.TipoContenidoCSV, c.exportacionService.ExportarSoftwareCSV)
+}
+
+// ExportarReportesServicio descarga los reportes de servicio en CSV con los filtros de
+// GET /api/reportes-servicio
+func (c *ExportacionController) ExportarReportesServicio(ctx echo.Context) error {
+	return c.exportar(ctx, "reportes_servicio", ".csv", services.TipoContenidoCSV, c.exportacionService.ExportarReportesServicioCSV)
+}
+
+// exportar lee los filtros (sin paginación) y envía el archivo a medida que se genera
+func (c *ExportacionController) exportar(ctx echo.Context, nombre, extension, tipoContenido string, generar func(dto.ConsultaDTO, io.Writer) error) error {
+	consulta, err := parseConsulta(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	descarga := &respuestaDescarga{
+		ctx:           ctx,
+		tipoContenido: tipoContenido,
+		archivo:       fmt.Sprintf("%s_%s%s", nombre, time.Now().Format("20060102"), extension),
+	}
+	if err := generar(consulta, descarga); err != nil {
+		// Si el archivo ya empezó a enviarse no se puede cambiar la respuesta
+		if descarga.iniciada {
+			log.Printf("Error al exportar %s: %v", nombre, err)
+			return nil
+		}
+		if errors.Is(err, dto.ErrConsultaInvalida) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al exportar: " + err.Error()})
+	}
+	return nil
+}
+
+// respuestaDescarga escribe los encabezados de descarga junto con el primer byte del
+// archivo, para que un error antes de empezar todavía se pueda responder como JSON
+type respuestaDescarga struct {
+	ctx           echo.Context
+	tipoContenido string
+	archivo       string
+	iniciada      bool
+}
+
+func (r *respuestaDescarga) Write(p []byte) (int, error) {
+	if !r.iniciada {
+		r.iniciada = true
+		r.ctx.Response().Header().Set(echo.HeaderContentType, r.tipoContenido)
+		r.ctx.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+r.archivo)
+		r.ctx.Response().WriteHeader(http.StatusOK)
+	}
+	return r.ctx.Response().Write(p)
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/services"
+	"tum_inv_backend/internal/infrastructure/firma"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Límites de lectura de los archivos recibidos
+const (
+	tamanoMaximoCertificado  = 64 * 1024        // Un .p12 con su cadena ocupa pocos KB
+	tamanoMaximoVerificacion = 20 * 1024 * 1024 // PDF a verificar
+)
+
+// FirmaDigitalController maneja la firma digital de reportes y la verificación de firmas
+type FirmaDigitalController struct {
+	firmaService services.FirmaDigitalService
+}
+
+// NewFirmaDigitalController crea una nueva instancia del controlador
+func NewFirmaDigitalController(firmaService services.FirmaDigitalService) *FirmaDigitalController {
+	return &FirmaDigitalController{firmaService: firmaService}
+}
+
+// FirmarReporte firma el PDF del reporte en el servidor y cierra el reporte.
+// Opcionalmente recibe en multipart el certificado del técnico (certificado, .p12) y su contraseña (clave);
+// el certificado solo se usa para esta firma, no se guarda y debe ser del usuario autenticado.
+// POST /api/reportes-servicio/:id/firmar
+func (c *FirmaDigitalController) FirmarReporte(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+	usuarioID, ok := ctx.Get("user_id").(uint)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{"error": "Usuario no autenticado"})
+	}
+
+	var tecnico *firma.Firmante
+	if archivo, err := ctx.FormFile("certificado"); err == nil {
+		src, err := archivo.Open()
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al leer el certificado"})
+		}
+		defer src.Close()
+		datos, err := io.ReadAll(io.LimitReader(src, tamanoMaximoCertificado+1))
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al leer el certificado"})
+		}
+		if len(datos) > tamanoMaximoCertificado {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El certificado es demasiado grande"})
+		}
+		tecnico, err = firma.CargarPKCS12(datos, ctx.FormValue("clave"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	reporte, err := c.firmaService.FirmarReporte(ctx.Request().Context(), uint(id), usuarioID, tecnico)
+	if err != nil {
+		if errors.Is(err, firma.ErrFirmaNoConfigurada) {
+			return ctx.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, services.ErrCertificadoAjeno) {
+			return ctx.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		var invalido *dto.ErrorValidacionPDF
+		if errors.As(err, &invalido) {
+			return ctx.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":     "El PDF firmado no es válido",
+				"problemas": invalido.Problemas,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Reporte firmado digitalmente y cerrado correctamente",
+		"reporte": reporte,
+	})
+}
+
+// VerificarFirma verifica las firmas digitales de un PDF enviado en el campo archivo
+// POST /api/reportes-servicio/verificar-firma
+func (c *FirmaDigitalController) VerificarFirma(ctx echo.Context) error {
+	archivo, err := ctx.FormFile("archivo")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Debe enviar un archivo PDF"})
+	}
+	src, err := archivo.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al leer el archivo"})
+	}
+	defer src.Close()
+	datos, err := io.ReadAll(io.LimitReader(src, tamanoMaximoVerificacion+1))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al procesar el archivo"})
+	}
+	if len(datos) > tamanoMaximoVerificacion {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El archivo supera los 20 MB"})
+	}
+
+	firmas, err := c.firmaService.VerificarFirmas(datos)
+	if err != nil && !errors.Is(err, firma.ErrSinFirmas) {
+		return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// El documento es válido si todas las firmas lo son y la última cubre el archivo completo
+	valido := len(firmas) > 0 && firmas[len(firmas)-1].CubreDocumento
+	for _, f := range firmas {
+		valido = valido && f.Valida
+	}
+	if firmas == nil {
+		firmas = []firma.ResultadoFirma{}
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"valido": valido,
+		"firmas": firmas,
+	})
+}
@@ -28,7 +28,7 @@ func (c *HardwareInternoController) CreateHardwareInterno(ctx echo.Context) erro
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.hardwareService.CreateHardwareInterno(hardware); err != nil {
+	if err := c.hardwareService.CreateHardwareInterno(ctx.Request().Context(), hardware); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -63,7 +63,7 @@ func (c *HardwareInternoController) UpdateHardwareInterno(ctx echo.Context) erro
 	}
 
 	hardware.ID = uint(id)
-	if err := c.hardwareService.UpdateHardwareInterno(hardware); err != nil {
+	if err := c.hardwareService.UpdateHardwareInterno(ctx.Request().Context(), hardware); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -77,7 +77,7 @@ func (c *HardwareInternoController) DeleteHardwareInterno(ctx echo.Context) erro
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.hardwareService.DeleteHardwareInterno(uint(id)); err != nil {
+	if err := c.hardwareService.DeleteHardwareInterno(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -86,12 +86,13 @@ func (c *HardwareInternoController) DeleteHardwareInterno(ctx echo.Context) erro
 
 // GetAllHardwareInterno obtiene todos los componentes de hardware interno
 func (c *HardwareInternoController) GetAllHardwareInterno(ctx echo.Context) error {
-	hardwareInternos, err := c.hardwareService.GetAllHardwareInterno()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, hardwareInternos)
+	hardwareInternos, err := c.hardwareService.GetAllHardwareInterno(consulta)
+	return responderPagina(ctx, hardwareInternos, err)
 }
 
 // GetHardwareInternoByEquipo obtiene todos los componentes de hardware interno asociados a un equipo
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ImportacionController maneja la importación masiva de inventario
+type ImportacionController struct {
+	importacionService services.ImportacionService
+}
+
+// NewImportacionController crea una nueva instancia de ImportacionController
+func NewImportacionController(importacionService services.ImportacionService) *ImportacionController {
+	return &ImportacionController{importacionService: importacionService}
+}
+
+// ImportarInventario recibe un XLSX o CSV (campo "archivo") y crea los equipos que contiene.
+// Con ?dry_run=true solo valida el archivo y reporta los errores por fila.
+func (c *ImportacionController) ImportarInventario(ctx echo.Context) error {
+	dryRun := false
+	if valor := ctx.QueryParam("dry_run"); valor != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(valor); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "dry_run debe ser true o false"})
+		}
+	}
+
+	file, err := ctx.FormFile("archivo")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Debe enviar un archivo XLSX o CSV"})
+	}
+
+	// Validar tamaño (máx 10MB)
+	if file.Size > 10*1024*1024 {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El archivo no debe superar los 10MB"})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al leer el archivo"})
+	}
+	defer src.Close()
+
+	datos, err := io.ReadAll(src)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al procesar el archivo"})
+	}
+
+	resultado, err := c.importacionService.ImportarInventario(ctx.Request().Context(), file.Filename, datos, dryRun)
+	if err != nil {
+		if errors.Is(err, dto.ErrImportacionInvalida) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	switch {
+	case len(resultado.Errores) > 0:
+		return ctx.JSON(http.StatusUnprocessableEntity, resultado)
+	case dryRun:
+		return ctx.JSON(http.StatusOK, resultado)
+	default:
+		return ctx.JSON(http.StatusCreated, resultado)
+	}
+}
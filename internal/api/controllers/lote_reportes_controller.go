@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// LoteReportesController maneja la descarga de los PDF de varios reportes de servicio
+type LoteReportesController struct {
+	loteService services.LoteReportesService
+}
+
+// NewLoteReportesController crea una nueva instancia de LoteReportesController
+func NewLoteReportesController(loteService services.LoteReportesService) *LoteReportesController {
+	return &LoteReportesController{loteService: loteService}
+}
+
+// DescargarLote descarga los PDF de los reportes que cumplen los filtros de
+// GET /api/reportes-servicio (desde, hasta, dependencia, creado_por, estado, ...).
+// ?formato=zip (por defecto) entrega un PDF por reporte en un ZIP; ?formato=pdf, uno solo.
+// GET /api/reportes-servicio/pdf-lote
+func (c *LoteReportesController) DescargarLote(ctx echo.Context) error {
+	consulta, err := parseConsulta(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	usuarioID, ok := ctx.Get("user_id").(uint)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{"error": "Usuario no autenticado"})
+	}
+
+	formato := ctx.QueryParam("formato")
+	if formato == "" {
+		formato = services.FormatoLoteZIP
+	}
+	tipoContenido := "application/zip"
+	if formato == services.FormatoLotePDF {
+		tipoContenido = "application/pdf"
+	}
+
+	descarga := &respuestaDescarga{
+		ctx:           ctx,
+		tipoContenido: tipoContenido,
+		archivo:       fmt.Sprintf("reportes_servicio_%s.%s", time.Now().Format("20060102"), formato),
+	}
+	if err := c.loteService.GenerarLote(ctx.Request().Context(), consulta, formato, usuarioID, descarga, nil); err != nil {
+		// Si el archivo ya empezó a enviarse no se puede cambiar la respuesta
+		if descarga.iniciada {
+			log.Printf("Error al generar el lote de reportes: %v", err)
+			return nil
+		}
+		switch {
+		case errors.Is(err, services.ErrFormatoLote), errors.Is(err, dto.ErrConsultaInvalida):
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrLoteVacio):
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrLoteExcedido):
+			return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al generar los PDF: " + err.Error()})
+	}
+	return nil
+}
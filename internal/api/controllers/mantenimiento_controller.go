@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MantenimientoController maneja los planes de mantenimiento preventivo y los mantenimientos pendientes
+type MantenimientoController struct {
+	service services.MantenimientoService
+}
+
+// NewMantenimientoController crea una nueva instancia de MantenimientoController
+func NewMantenimientoController(service services.MantenimientoService) *MantenimientoController {
+	return &MantenimientoController{service: service}
+}
+
+// GetPendientes lista, agrupados por dependencia, los equipos con el mantenimiento preventivo
+// vencido o que vence en los próximos ?dias= (30 por defecto). ?dependencia_id= limita a una dependencia.
+// GET /api/mantenimientos/pendientes
+func (c *MantenimientoController) GetPendientes(ctx echo.Context) error {
+	dias := services.DiasAnticipacionMantenimiento
+	if valor := ctx.QueryParam("dias"); valor != "" {
+		var err error
+		if dias, err = strconv.Atoi(valor); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": services.ErrDiasAnticipacionMantenimiento.Error()})
+		}
+	}
+
+	var dependenciaID *uint
+	if valor := ctx.QueryParam("dependencia_id"); valor != "" {
+		id, err := strconv.ParseUint(valor, 10, 32)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "dependencia_id inválido"})
+		}
+		dependencia := uint(id)
+		dependenciaID = &dependencia
+	}
+
+	pendientes, err := c.service.GetPendientes(dias, dependenciaID)
+	if err != nil {
+		return responderErrorMantenimiento(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, pendientes)
+}
+
+// GetEquipos lista todos los equipos con plan activo y la fecha de su próximo mantenimiento
+// GET /api/mantenimientos/equipos
+func (c *MantenimientoController) GetEquipos(ctx echo.Context) error {
+	equipos, err := c.service.CalcularEquipos(services.DiasAnticipacionMantenimiento)
+	if err != nil {
+		return responderErrorMantenimiento(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, equipos)
+}
+
+// GetAllPlanes lista los planes de mantenimiento
+// GET /api/mantenimientos/planes
+func (c *MantenimientoController) GetAllPlanes(ctx echo.Context) error {
+	planes, err := c.service.GetPlanes()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, planes)
+}
+
+// GetPlan obtiene un plan de mantenimiento por su ID
+// GET /api/mantenimientos/planes/:id
+func (c *MantenimientoController) GetPlan(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	plan, err := c.service.GetPlan(uint(id))
+	if err != nil {
+		return responderErrorMantenimiento(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, plan)
+}
+
+// CreatePlan crea un plan para un tipo de dispositivo (TipoDispositivo) o para un equipo (EquipoID)
+// POST /api/mantenimientos/planes
+func (c *MantenimientoController) CreatePlan(ctx echo.Context) error {
+	plan := &models.PlanMantenimiento{Activo: true}
+	if err := ctx.Bind(plan); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	plan.ID = 0
+	plan.Equipo = nil
+
+	if err := c.service.CreatePlan(ctx.Request().Context(), plan); err != nil {
+		return responderErrorMantenimiento(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, plan)
+}
+
+// UpdatePlan actualiza un plan de mantenimiento
+// PUT /api/mantenimientos/planes/:id
+func (c *MantenimientoController) UpdatePlan(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	plan := new(models.PlanMantenimiento)
+	if err := ctx.Bind(plan); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	plan.ID = uint(id)
+	plan.Equipo = nil
+
+	if err := c.service.UpdatePlan(ctx.Request().Context(), plan); err != nil {
+		return responderErrorMantenimiento(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, plan)
+}
+
+// DeletePlan elimina un plan de mantenimiento
+// DELETE /api/mantenimientos/planes/:id
+func (c *MantenimientoController) DeletePlan(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	if err := c.service.DeletePlan(ctx.Request().Context(), uint(id)); err != nil {
+		return responderErrorMantenimiento(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Plan de mantenimiento eliminado correctamente"})
+}
+
+// responderErrorMantenimiento traduce los errores del servicio de mantenimiento a respuestas HTTP
+func responderErrorMantenimiento(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrPlanMantenimientoNoEncontrado):
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrPlanMantenimientoDuplicado):
+		return ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrPlanMantenimientoInvalido):
+		return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrDiasAnticipacionMantenimiento):
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// intervaloSondeoStream es cada cuánto el stream busca notificaciones creadas por otra
+	// instancia del servidor; las de esta instancia llegan de inmediato
+	intervaloSondeoStream = 5 * time.Second
+	// intervaloLatidoStream mantiene viva la conexión a través de proxies que cortan las inactivas
+	intervaloLatidoStream = 25 * time.Second
+)
+
+// NotificacionController maneja el centro de notificaciones del usuario autenticado
+type NotificacionController struct {
+	service services.NotificacionService
+}
+
+// NewNotificacionController crea una nueva instancia de NotificacionController
+func NewNotificacionController(service services.NotificacionService) *NotificacionController {
+	return &NotificacionController{service: service}
+}
+
+// GetNotificaciones lista las notificaciones del usuario (filtros: leida, tipo)
+// GET /api/notificaciones
+func (c *NotificacionController) GetNotificaciones(ctx echo.Context) error {
+	consulta, err := parseConsulta(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	usuarioID, _ := usuarioSesion(ctx)
+	notificaciones, err := c.service.GetAll(usuarioID, consulta)
+	return responderPagina(ctx, notificaciones, err)
+}
+
+// ContarNoLeidas retorna la cantidad de notificaciones sin leer
+// GET /api/notificaciones/no-leidas
+func (c *NotificacionController) ContarNoLeidas(ctx echo.Context) error {
+	usuarioID, _ := usuarioSesion(ctx)
+	total, err := c.service.ContarNoLeidas(usuarioID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]int64{"no_leidas": total})
+}
+
+// MarcarLeida marca una notificación como leída
+// POST /api/notificaciones/:id/leer
+func (c *NotificacionController) MarcarLeida(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	usuarioID, _ := usuarioSesion(ctx)
+	notificacion, err := c.service.MarcarLeida(ctx.Request().Context(), usuarioID, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrNotificacionNoEncontrada) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, notificacion)
+}
+
+// MarcarTodasLeidas marca como leídas todas las notificaciones del usuario
+// POST /api/notificaciones/leer-todas
+func (c *NotificacionController) MarcarTodasLeidas(ctx echo.Context) error {
+	usuarioID, _ := usuarioSesion(ctx)
+	total, err := c.service.MarcarTodasLeidas(ctx.Request().Context(), usuarioID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]int64{"marcadas": total})
+}
+
+// Stream envía las notificaciones nuevas como server-sent events: un evento "notificacion"
+// por cada una (con su ID como id del evento) y un evento "no-leidas" cuando cambia la
+// cantidad sin leer. Al reconectar, el encabezado Last-Event-ID retoma desde la última recibida.
+// GET /api/notificaciones/stream
+func (c *NotificacionController) Stream(ctx echo.Context) error {
+	usuarioID, _ := usuarioSesion(ctx)
+	var ultimoID uint
+	if ultimo := ctx.Request().Header.Get("Last-Event-ID"); ultimo != "" {
+		id, err := strconv.ParseUint(ultimo, 10, 32)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Last-Event-ID inválido"})
+		}
+		ultimoID = uint(id)
+	} else {
+		id, err := c.service.UltimoID(usuarioID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		ultimoID = id
+	}
+
+	// Suscribirse antes de la primera consulta para no perder un aviso intermedio
+	aviso, cancelar := c.service.Suscribir(usuarioID)
+	defer cancelar()
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // Evita que nginx acumule los eventos
+	res.WriteHeader(http.StatusOK)
+
+	sondeo := time.NewTicker(intervaloSondeoStream)
+	defer sondeo.Stop()
+	latido := time.NewTicker(intervaloLatidoStream)
+	defer latido.Stop()
+
+	noLeidas := int64(-1)
+	for {
+		nuevas, err := c.service.Nuevas(usuarioID, ultimoID)
+		if err != nil {
+			return nil
+		}
+		for _, notificacion := range nuevas {
+			if err := enviarEvento(res, "notificacion", strconv.FormatUint(uint64(notificacion.ID), 10), notificacion); err != nil {
+				return nil
+			}
+			ultimoID = notificacion.ID
+		}
+		total, err := c.service.ContarNoLeidas(usuarioID)
+		if err != nil {
+			return nil
+		}
+		if total != noLeidas {
+			if err := enviarEvento(res, "no-leidas", "", map[string]int64{"no_leidas": total}); err != nil {
+				return nil
+			}
+			noLeidas = total
+		}
+		res.Flush()
+
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case <-aviso:
+		case <-sondeo.C:
+		case <-latido.C:
+			if _, err := fmt.Fprint(res, ": latido\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// enviarEvento escribe un server-sent event con los datos en JSON
+func enviarEvento(res *echo.Response, evento, id string, datos any) error {
+	contenido, err := json.Marshal(datos)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(res, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", evento, contenido)
+	return err
+}
@@ -31,19 +31,9 @@ func (c *PDFController) GenerarReportePDF(ctx echo.Context) error {
 	}
 
 	// Obtener el usuario de la sesión (del contexto JWT)
-	usuarioID, ok := ctx.Get("usuario_id").(uint)
+	usuarioID, ok := ctx.Get("user_id").(uint)
 	if !ok {
-		// Si no hay usuario en contexto, intentar obtenerlo del query param (para pruebas)
-		userIDParam := ctx.QueryParam("usuario_id")
-		if userIDParam != "" {
-			parsedID, err := strconv.ParseUint(userIDParam, 10, 32)
-			if err != nil {
-				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID de usuario inválido"})
-			}
-			usuarioID = uint(parsedID)
-		} else {
-			return ctx.JSON(http.StatusUnauthorized, map[string]string{"error": "Usuario no autenticado"})
-		}
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{"error": "Usuario no autenticado"})
 	}
 
 	// Generar el PDF
@@ -70,19 +60,9 @@ func (c *PDFController) VisualizarReportePDF(ctx echo.Context) error {
 	}
 
 	// Obtener el usuario de la sesión (del contexto JWT)
-	usuarioID, ok := ctx.Get("usuario_id").(uint)
+	usuarioID, ok := ctx.Get("user_id").(uint)
 	if !ok {
-		// Si no hay usuario en contexto, intentar obtenerlo del query param (para pruebas)
-		userIDParam := ctx.QueryParam("usuario_id")
-		if userIDParam != "" {
-			parsedID, err := strconv.ParseUint(userIDParam, 10, 32)
-			if err != nil {
-				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID de usuario inválido"})
-			}
-			usuarioID = uint(parsedID)
-		} else {
-			return ctx.JSON(http.StatusUnauthorized, map[string]string{"error": "Usuario no autenticado"})
-		}
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{"error": "Usuario no autenticado"})
 	}
 
 	// Generar el PDF
@@ -97,3 +77,105 @@ func (c *PDFController) VisualizarReportePDF(ctx echo.Context) error {
 
 	return ctx.Blob(http.StatusOK, "application/pdf", pdfBytes)
 }
+
+// GenerarActaEntregaPDF genera el acta de entrega de una asignación de equipo
+// GET /api/asignaciones/:id/acta
+func (c *PDFController) GenerarActaEntregaPDF(ctx echo.Context) error {
+	asignacionID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID de asignación inválido"})
+	}
+
+	pdfBytes, err := c.pdfService.GenerarPDFActaEntrega(uint(asignacionID))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Configurar headers para descarga del PDF
+	ctx.Response().Header().Set("Content-Type", "application/pdf")
+	ctx.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=acta_entrega_%d.pdf", asignacionID))
+	ctx.Response().Header().Set("Content-Length", strconv.Itoa(len(pdfBytes)))
+
+	return ctx.Blob(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// VisualizarActaEntregaPDF genera el acta de entrega para visualización en el navegador
+// GET /api/asignaciones/:id/acta/view
+func (c *PDFController) VisualizarActaEntregaPDF(ctx echo.Context) error {
+	asignacionID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID de asignación inválido"})
+	}
+
+	pdfBytes, err := c.pdfService.GenerarPDFActaEntrega(uint(asignacionID))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Configurar headers para visualización inline
+	ctx.Response().Header().Set("Content-Type", "application/pdf")
+	ctx.Response().Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=acta_entrega_%d.pdf", asignacionID))
+
+	return ctx.Blob(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// GenerarHojaVidaPDF genera la hoja de vida de un equipo
+// GET /api/equipos/:equipoId/hv/pdf
+func (c *PDFController) GenerarHojaVidaPDF(ctx echo.Context) error {
+	equipoID, err := strconv.ParseUint(ctx.Param("equipoId"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID de equipo inválido"})
+	}
+
+	pdfBytes, err := c.pdfService.GenerarPDFHojaVida(uint(equipoID))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Configurar headers para descarga del PDF
+	ctx.Response().Header().Set("Content-Type", "application/pdf")
+	ctx.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=hoja_vida_%d.pdf", equipoID))
+	ctx.Response().Header().Set("Content-Length", strconv.Itoa(len(pdfBytes)))
+
+	return ctx.Blob(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// VisualizarHojaVidaPDF genera la hoja de vida de un equipo para visualización en el navegador
+// GET /api/equipos/:equipoId/hv/pdf/view
+func (c *PDFController) VisualizarHojaVidaPDF(ctx echo.Context) error {
+	equipoID, err := strconv.ParseUint(ctx.Param("equipoId"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID de equipo inválido"})
+	}
+
+	pdfBytes, err := c.pdfService.GenerarPDFHojaVida(uint(equipoID))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Configurar headers para visualización inline
+	ctx.Response().Header().Set("Content-Type", "application/pdf")
+	ctx.Response().Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=hoja_vida_%d.pdf", equipoID))
+
+	return ctx.Blob(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// DescargarHojasVidaDependencia genera un ZIP con las hojas de vida de los equipos de una dependencia
+// GET /api/dependencias/:id/hojas-vida
+func (c *PDFController) DescargarHojasVidaDependencia(ctx echo.Context) error {
+	dependenciaID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID de dependencia inválido"})
+	}
+
+	zipBytes, err := c.pdfService.GenerarZIPHojasVidaDependencia(uint(dependenciaID))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	ctx.Response().Header().Set("Content-Type", "application/zip")
+	ctx.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=hojas_vida_dependencia_%d.zip", dependenciaID))
+	ctx.Response().Header().Set("Content-Length", strconv.Itoa(len(zipBytes)))
+
+	return ctx.Blob(http.StatusOK, "application/zip", zipBytes)
+}
@@ -29,7 +29,7 @@ func (c *PerifericoController) CreatePeriferico(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.perifericoService.CreatePeriferico(periferico); err != nil {
+	if err := c.perifericoService.CreatePeriferico(ctx.Request().Context(), periferico); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -64,7 +64,7 @@ func (c *PerifericoController) UpdatePeriferico(ctx echo.Context) error {
 	}
 
 	periferico.ID = uint(id)
-	if err := c.perifericoService.UpdatePeriferico(periferico); err != nil {
+	if err := c.perifericoService.UpdatePeriferico(ctx.Request().Context(), periferico); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -78,7 +78,7 @@ func (c *PerifericoController) DeletePeriferico(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.perifericoService.DeletePeriferico(uint(id)); err != nil {
+	if err := c.perifericoService.DeletePeriferico(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -87,12 +87,13 @@ func (c *PerifericoController) DeletePeriferico(ctx echo.Context) error {
 
 // GetAllPerifericos obtiene todos los periféricos
 func (c *PerifericoController) GetAllPerifericos(ctx echo.Context) error {
-	perifericos, err := c.perifericoService.GetAllPerifericos()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, perifericos)
+	perifericos, err := c.perifericoService.GetAllPerifericos(consulta)
+	return responderPagina(ctx, perifericos, err)
 }
 
 // GetPerifericosByEquipo obtiene todos los periféricos asociados a un equipo
@@ -137,7 +138,7 @@ func (c *PerifericoController) AsignarEquipo(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.perifericoService.AsignarEquipo(uint(id), body.EquipoID); err != nil {
+	if err := c.perifericoService.AsignarEquipo(ctx.Request().Context(), uint(id), body.EquipoID); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
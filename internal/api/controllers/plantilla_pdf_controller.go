@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// tamanoMaximoLogo es el tamaño máximo de un logo subido (2 MB)
+const tamanoMaximoLogo = 2 * 1024 * 1024
+
+// PlantillaPDFController maneja las plantillas de los PDF institucionales
+type PlantillaPDFController struct {
+	service    services.PlantillaPDFService
+	pdfService *services.PDFReporteService
+}
+
+// NewPlantillaPDFController crea una nueva instancia del controlador
+func NewPlantillaPDFController(service services.PlantillaPDFService, pdfService *services.PDFReporteService) *PlantillaPDFController {
+	return &PlantillaPDFController{service: service, pdfService: pdfService}
+}
+
+// GetAllPlantillas lista las plantillas
+// GET /api/plantillas-pdf
+func (c *PlantillaPDFController) GetAllPlantillas(ctx echo.Context) error {
+	plantillas, err := c.service.GetAll()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, plantillas)
+}
+
+// GetPlantillaPredeterminada retorna la definición del diseño predeterminado, como base para una plantilla nueva
+// GET /api/plantillas-pdf/predeterminada
+func (c *PlantillaPDFController) GetPlantillaPredeterminada(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, services.PlantillaPredeterminada())
+}
+
+// GetPlantilla obtiene una plantilla por su ID
+// GET /api/plantillas-pdf/:id
+func (c *PlantillaPDFController) GetPlantilla(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	plantilla, err := c.service.GetByID(uint(id))
+	if err != nil {
+		return responderErrorPlantilla(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, plantilla)
+}
+
+// CreatePlantilla crea una plantilla inactiva
+// POST /api/plantillas-pdf
+func (c *PlantillaPDFController) CreatePlantilla(ctx echo.Context) error {
+	plantilla := new(models.PlantillaPDF)
+	if err := ctx.Bind(plantilla); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	plantilla.ID = 0
+
+	if err := c.service.Create(ctx.Request().Context(), plantilla); err != nil {
+		return responderErrorPlantilla(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, plantilla)
+}
+
+// UpdatePlantilla actualiza el nombre y la definición de una plantilla
+// PUT /api/plantillas-pdf/:id
+func (c *PlantillaPDFController) UpdatePlantilla(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	plantilla := new(models.PlantillaPDF)
+	if err := ctx.Bind(plantilla); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	plantilla.ID = uint(id)
+
+	if err := c.service.Update(ctx.Request().Context(), plantilla); err != nil {
+		return responderErrorPlantilla(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, plantilla)
+}
+
+// DeletePlantilla elimina una plantilla
+// DELETE /api/plantillas-pdf/:id
+func (c *PlantillaPDFController) DeletePlantilla(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	if err := c.service.Delete(ctx.Request().Context(), uint(id)); err != nil {
+		return responderErrorPlantilla(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Plantilla eliminada correctamente"})
+}
+
+// ActivarPlantilla hace que la plantilla se use en los PDF generados
+// POST /api/plantillas-pdf/:id/activar
+func (c *PlantillaPDFController) ActivarPlantilla(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	if err := c.service.Activar(ctx.Request().Context(), uint(id)); err != nil {
+		return responderErrorPlantilla(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Plantilla activada correctamente"})
+}
+
+// VistaPreviaPlantilla genera un reporte de servicio de ejemplo con una plantilla guardada
+// GET /api/plantillas-pdf/:id/vista-previa
+func (c *PlantillaPDFController) VistaPreviaPlantilla(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	plantilla, err := c.service.GetByID(uint(id))
+	if err != nil {
+		return responderErrorPlantilla(ctx, err)
+	}
+	return c.responderVistaPrevia(ctx, &plantilla.Definicion)
+}
+
+// VistaPreviaDefinicion genera un reporte de servicio de ejemplo con una definición sin guardar
+// POST /api/plantillas-pdf/vista-previa
+func (c *PlantillaPDFController) VistaPreviaDefinicion(ctx echo.Context) error {
+	definicion := new(models.DefinicionPlantilla)
+	if err := ctx.Bind(definicion); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	if err := c.service.Validar(definicion); err != nil {
+		return responderErrorPlantilla(ctx, err)
+	}
+	return c.responderVistaPrevia(ctx, definicion)
+}
+
+// responderVistaPrevia envía el PDF de ejemplo para verlo en el navegador
+func (c *PlantillaPDFController) responderVistaPrevia(ctx echo.Context, definicion *models.DefinicionPlantilla) error {
+	pdfBytes, err := c.pdfService.VistaPreviaPlantilla(definicion)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	ctx.Response().Header().Set("Content-Disposition", "inline; filename=vista_previa_plantilla.pdf")
+	return ctx.Blob(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// SubirLogo sube una imagen PNG o JPEG (campo archivo, máx. 2 MB) para usarla en las plantillas
+// POST /api/plantillas-pdf/logos
+func (c *PlantillaPDFController) SubirLogo(ctx echo.Context) error {
+	archivo, err := ctx.FormFile("archivo")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Debe enviar una imagen"})
+	}
+	if archivo.Size > tamanoMaximoLogo {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El logo no debe superar los 2 MB"})
+	}
+
+	src, err := archivo.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al leer el archivo"})
+	}
+	defer src.Close()
+	datos, err := io.ReadAll(io.LimitReader(src, tamanoMaximoLogo))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al procesar el archivo"})
+	}
+
+	logo, err := c.service.SubirLogo(datos)
+	if err != nil {
+		return responderErrorPlantilla(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, logo)
+}
+
+// responderErrorPlantilla traduce los errores del servicio de plantillas a respuestas HTTP
+func responderErrorPlantilla(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrPlantillaNoEncontrada):
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrPlantillaInvalida):
+		return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"tum_inv_backend/internal/domain/models"
 	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/services"
@@ -30,7 +32,7 @@ func (c *ReporteServicioController) CreateReporteServicio(ctx echo.Context) erro
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.reporteService.CreateReporteServicio(reporte); err != nil {
+	if err := c.reporteService.CreateReporteServicio(ctx.Request().Context(), reporte); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -65,7 +67,7 @@ func (c *ReporteServicioController) UpdateReporteServicio(ctx echo.Context) erro
 	}
 
 	reporte.ID = uint(id)
-	if err := c.reporteService.UpdateReporteServicio(reporte); err != nil {
+	if err := c.reporteService.UpdateReporteServicio(ctx.Request().Context(), reporte); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -79,7 +81,7 @@ func (c *ReporteServicioController) DeleteReporteServicio(ctx echo.Context) erro
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.reporteService.DeleteReporteServicio(uint(id)); err != nil {
+	if err := c.reporteService.DeleteReporteServicio(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -88,12 +90,13 @@ func (c *ReporteServicioController) DeleteReporteServicio(ctx echo.Context) erro
 
 // GetAllReportesServicio obtiene todos los reportes de servicio
 func (c *ReporteServicioController) GetAllReportesServicio(ctx echo.Context) error {
-	reportes, err := c.reporteService.GetAllReportesServicio()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, reportes)
+	reportes, err := c.reporteService.GetAllReportesServicio(consulta)
+	return responderPagina(ctx, reportes, err)
 }
 
 // GetReportesServicioByEquipo obtiene todos los reportes de servicio asociados a un equipo
@@ -159,6 +162,9 @@ func (c *ReporteServicioController) CrearReporteConTipo(ctx echo.Context) error
 				"error": "La cantidad del repuesto en la posición " + strconv.Itoa(i) + " debe ser mayor a 0",
 			})
 		}
+		if repuesto.ArticuloID != nil {
+			continue // El serial y la descripción se toman del almacén
+		}
 		if repuesto.SerialNumeroParte == "" {
 			return ctx.JSON(http.StatusBadRequest, map[string]string{
 				"error": "El serial/número de parte del repuesto en la posición " + strconv.Itoa(i) + " es obligatorio",
@@ -172,8 +178,14 @@ func (c *ReporteServicioController) CrearReporteConTipo(ctx echo.Context) error
 	}
 
 	// Crear el reporte completo
-	reporte, err := c.reporteService.CrearReporteConTipo(reporteData)
+	reporte, err := c.reporteService.CrearReporteConTipo(ctx.Request().Context(), reporteData)
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrStockInsuficiente):
+			return ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrArticuloNoEncontrado):
+			return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
 			"error": err.Error(),
 		})
@@ -185,7 +197,8 @@ func (c *ReporteServicioController) CrearReporteConTipo(ctx echo.Context) error
 	})
 }
 
-// SubirFirmado sube un PDF firmado y cierra el reporte
+// SubirFirmado sube un PDF firmado y cierra el reporte. Si el PDF no pasa la validación
+// responde 422 con la lista de problemas y el reporte sigue abierto.
 func (c *ReporteServicioController) SubirFirmado(ctx echo.Context) error {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
@@ -198,16 +211,6 @@ func (c *ReporteServicioController) SubirFirmado(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Debe enviar un archivo PDF"})
 	}
 
-	// Validar que sea un PDF
-	if file.Header.Get("Content-Type") != "application/pdf" {
-		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El archivo debe ser un PDF"})
-	}
-
-	// Validar tamaño (máx 10MB)
-	if file.Size > 10*1024*1024 {
-		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El archivo no debe superar los 10MB"})
-	}
-
 	// Leer el contenido del archivo
 	src, err := file.Open()
 	if err != nil {
@@ -215,14 +218,83 @@ func (c *ReporteServicioController) SubirFirmado(ctx echo.Context) error {
 	}
 	defer src.Close()
 
-	fileData, err := io.ReadAll(src)
+	// El formato y el tamaño se validan en el servicio; se lee un byte más del límite
+	// para detectar el exceso sin cargar en memoria un archivo enorme
+	var lector io.Reader = src
+	if limite := c.reporteService.TamanoMaximoFirmado(); limite > 0 {
+		lector = io.LimitReader(src, limite+1)
+	}
+	fileData, err := io.ReadAll(lector)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al procesar el archivo"})
 	}
 
 	// Subir y cerrar el reporte
-	reporte, err := c.reporteService.SubirFirmado(uint(id), fileData, "application/pdf")
+	reporte, err := c.reporteService.SubirFirmado(ctx.Request().Context(), uint(id), fileData, "application/pdf")
 	if err != nil {
+		var invalido *dto.ErrorValidacionPDF
+		if errors.As(err, &invalido) {
+			return ctx.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":     "El PDF firmado no es válido",
+				"problemas": invalido.Problemas,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"message": "PDF firmado subido y reporte cerrado correctamente",
+		"reporte": reporte,
+	})
+}
+
+// PrepararSubidaFirmado genera una URL PUT prefirmada para subir el PDF firmado directamente
+// al almacenamiento (S3), sin pasar por la API
+func (c *ReporteServicioController) PrepararSubidaFirmado(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	subida, err := c.reporteService.PrepararSubidaFirmado(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrSubidaDirectaNoDisponible) {
+			return ctx.JSON(http.StatusNotImplemented, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, subida)
+}
+
+// ConfirmarSubidaFirmado valida el PDF subido con la URL de PrepararSubidaFirmado y cierra el reporte
+func (c *ReporteServicioController) ConfirmarSubidaFirmado(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	var solicitud struct {
+		Archivo string `json:"archivo"`
+	}
+	if err := ctx.Bind(&solicitud); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+
+	reporte, err := c.reporteService.ConfirmarSubidaFirmado(ctx.Request().Context(), uint(id), solicitud.Archivo)
+	if err != nil {
+		var invalido *dto.ErrorValidacionPDF
+		switch {
+		case errors.As(err, &invalido):
+			return ctx.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":     "El PDF firmado no es válido",
+				"problemas": invalido.Problemas,
+			})
+		case errors.Is(err, services.ErrSubidaDirectaInvalida):
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrSubidaDirectaNoDisponible):
+			return ctx.JSON(http.StatusNotImplemented, map[string]string{"error": err.Error()})
+		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -249,14 +321,60 @@ func (c *ReporteServicioController) DescargarFirmado(ctx echo.Context) error {
 	})
 }
 
-// ReabrirReporte elimina el PDF firmado y reabre el reporte
+// GetDocumentosFirmados lista las versiones del PDF firmado de un reporte, de la más reciente a la más antigua
+func (c *ReporteServicioController) GetDocumentosFirmados(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	documentos, err := c.reporteService.GetDocumentosFirmados(uint(id))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, documentos)
+}
+
+// DescargarDocumentoFirmado genera una URL temporal para descargar una versión del PDF firmado
+func (c *ReporteServicioController) DescargarDocumentoFirmado(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil || version < 1 {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Versión inválida"})
+	}
+
+	url, err := c.reporteService.ObtenerURLDocumentoFirmado(uint(id), version)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"url": url,
+	})
+}
+
+// ReabrirReporte reabre el reporte; el PDF firmado se conserva en el historial con el motivo
 func (c *ReporteServicioController) ReabrirReporte(ctx echo.Context) error {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.reporteService.ReabrirReporte(uint(id)); err != nil {
+	var body struct {
+		Motivo string `json:"Motivo"`
+	}
+	if err := ctx.Bind(&body); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	if strings.TrimSpace(body.Motivo) == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El motivo de la reapertura es obligatorio"})
+	}
+
+	if err := c.reporteService.ReabrirReporte(ctx.Request().Context(), uint(id), body.Motivo); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
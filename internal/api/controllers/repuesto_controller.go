@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"tum_inv_backend/internal/domain/models"
@@ -28,7 +29,13 @@ func (c *RepuestoController) CreateRepuesto(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.repuestoService.CreateRepuesto(repuesto); err != nil {
+	if err := c.repuestoService.CreateRepuesto(ctx.Request().Context(), repuesto); err != nil {
+		switch {
+		case errors.Is(err, services.ErrStockInsuficiente):
+			return ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrArticuloNoEncontrado):
+			return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -63,7 +70,7 @@ func (c *RepuestoController) UpdateRepuesto(ctx echo.Context) error {
 	}
 
 	repuesto.ID = uint(id)
-	if err := c.repuestoService.UpdateRepuesto(repuesto); err != nil {
+	if err := c.repuestoService.UpdateRepuesto(ctx.Request().Context(), repuesto); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -77,7 +84,7 @@ func (c *RepuestoController) DeleteRepuesto(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.repuestoService.DeleteRepuesto(uint(id)); err != nil {
+	if err := c.repuestoService.DeleteRepuesto(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -86,12 +93,13 @@ func (c *RepuestoController) DeleteRepuesto(ctx echo.Context) error {
 
 // GetAllRepuestos obtiene todos los repuestos
 func (c *RepuestoController) GetAllRepuestos(ctx echo.Context) error {
-	repuestos, err := c.repuestoService.GetAllRepuestos()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, repuestos)
+	repuestos, err := c.repuestoService.GetAllRepuestos(consulta)
+	return responderPagina(ctx, repuestos, err)
 }
 
 // GetRepuestosByReporte obtiene todos los repuestos asociados a un reporte
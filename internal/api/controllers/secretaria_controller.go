@@ -26,7 +26,7 @@ func (c *SecretariaController) CreateSecretaria(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.service.CreateSecretaria(secretaria); err != nil {
+	if err := c.service.CreateSecretaria(ctx.Request().Context(), secretaria); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -50,12 +50,13 @@ func (c *SecretariaController) GetSecretaria(ctx echo.Context) error {
 
 // GetAllSecretarias obtiene todas las Secretarias
 func (c *SecretariaController) GetAllSecretarias(ctx echo.Context) error {
-	secretarias, err := c.service.GetAllSecretarias()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al obtener secretarías/s"})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, secretarias)
+	secretarias, err := c.service.GetAllSecretarias(consulta)
+	return responderPagina(ctx, secretarias, err)
 }
 
 // UpdateSecretaria actualiza una Secretaria existente
@@ -71,7 +72,7 @@ func (c *SecretariaController) UpdateSecretaria(ctx echo.Context) error {
 	}
 
 	secretaria.ID = uint(id)
-	if err := c.service.UpdateSecretaria(secretaria); err != nil {
+	if err := c.service.UpdateSecretaria(ctx.Request().Context(), secretaria); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -85,7 +86,7 @@ func (c *SecretariaController) DeleteSecretaria(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.service.DeleteSecretaria(uint(id)); err != nil {
+	if err := c.service.DeleteSecretaria(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SLAController maneja las políticas de SLA y el cumplimiento de los reportes de servicio
+type SLAController struct {
+	service services.SLAService
+}
+
+// NewSLAController crea una nueva instancia de SLAController
+func NewSLAController(service services.SLAService) *SLAController {
+	return &SLAController{service: service}
+}
+
+// GetCumplimiento resume el cumplimiento de SLA general, por técnico y por dependencia, con
+// los reportes incumplidos. Acepta desde, hasta y los filtros del listado de reportes.
+// GET /api/reportes-servicio/sla
+func (c *SLAController) GetCumplimiento(ctx echo.Context) error {
+	consulta, err := parseConsulta(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	resumen, err := c.service.GetCumplimiento(consulta)
+	if err != nil {
+		return responderErrorSLA(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, resumen)
+}
+
+// GetReporteSLA mide un reporte contra su política de SLA
+// GET /api/reportes-servicio/:id/sla
+func (c *SLAController) GetReporteSLA(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	evaluado, err := c.service.EvaluarReporte(uint(id))
+	if err != nil {
+		return responderErrorSLA(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, evaluado)
+}
+
+// GetAllPoliticas lista las políticas de SLA
+// GET /api/sla/politicas
+func (c *SLAController) GetAllPoliticas(ctx echo.Context) error {
+	politicas, err := c.service.GetPoliticas()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, politicas)
+}
+
+// GetPolitica obtiene una política de SLA por su ID
+// GET /api/sla/politicas/:id
+func (c *SLAController) GetPolitica(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	politica, err := c.service.GetPolitica(uint(id))
+	if err != nil {
+		return responderErrorSLA(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, politica)
+}
+
+// CreatePolitica crea una política para un tipo de mantenimiento y una prioridad
+// POST /api/sla/politicas
+func (c *SLAController) CreatePolitica(ctx echo.Context) error {
+	politica := &models.PoliticaSLA{Activo: true}
+	if err := ctx.Bind(politica); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	politica.ID = 0
+
+	if err := c.service.CreatePolitica(ctx.Request().Context(), politica); err != nil {
+		return responderErrorSLA(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, politica)
+}
+
+// UpdatePolitica actualiza una política de SLA
+// PUT /api/sla/politicas/:id
+func (c *SLAController) UpdatePolitica(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	politica := new(models.PoliticaSLA)
+	if err := ctx.Bind(politica); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+	politica.ID = uint(id)
+
+	if err := c.service.UpdatePolitica(ctx.Request().Context(), politica); err != nil {
+		return responderErrorSLA(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, politica)
+}
+
+// DeletePolitica elimina una política de SLA
+// DELETE /api/sla/politicas/:id
+func (c *SLAController) DeletePolitica(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	if err := c.service.DeletePolitica(ctx.Request().Context(), uint(id)); err != nil {
+		return responderErrorSLA(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Política de SLA eliminada correctamente"})
+}
+
+// responderErrorSLA traduce los errores del servicio de SLA a respuestas HTTP
+func responderErrorSLA(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrPoliticaSLANoEncontrada), errors.Is(err, services.ErrReporteSLANoEncontrado):
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrPoliticaSLADuplicada):
+		return ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrPoliticaSLAInvalida):
+		return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, dto.ErrConsultaInvalida):
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
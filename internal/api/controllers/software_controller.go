@@ -26,7 +26,7 @@ func (c *SoftwareController) CreateSoftware(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.softwareService.CreateSoftware((software)); err != nil {
+	if err := c.softwareService.CreateSoftware(ctx.Request().Context(), (software)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -60,7 +60,7 @@ func (c *SoftwareController) UpdateSoftware(ctx echo.Context) error {
 	}
 
 	software.ID = uint(id)
-	if err := c.softwareService.UpdateSoftware(software); err != nil {
+	if err := c.softwareService.UpdateSoftware(ctx.Request().Context(), software); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -74,7 +74,7 @@ func (c *SoftwareController) DeleteSoftware(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.softwareService.DeleteSoftware(uint(id)); err != nil {
+	if err := c.softwareService.DeleteSoftware(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -83,12 +83,13 @@ func (c *SoftwareController) DeleteSoftware(ctx echo.Context) error {
 
 // GetAllSoftware obtiene todos los software
 func (c *SoftwareController) GetAllSoftware(ctx echo.Context) error {
-	AllSoftware, err := c.softwareService.GetAllSoftware()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, AllSoftware)
+	AllSoftware, err := c.softwareService.GetAllSoftware(consulta)
+	return responderPagina(ctx, AllSoftware, err)
 }
 
 // GetAllSoftwareByEquipo obtiene todos los software asociados a un equipo
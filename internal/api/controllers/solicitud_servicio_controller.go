@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tum_inv_backend/internal/api/middleware"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SolicitudServicioController maneja las solicitudes de servicio y su flujo de atención
+type SolicitudServicioController struct {
+	service services.SolicitudServicioService
+}
+
+// NewSolicitudServicioController crea una nueva instancia de SolicitudServicioController
+func NewSolicitudServicioController(service services.SolicitudServicioService) *SolicitudServicioController {
+	return &SolicitudServicioController{service: service}
+}
+
+// usuarioSesion obtiene el ID y el rol del usuario autenticado
+func usuarioSesion(ctx echo.Context) (uint, string) {
+	usuarioID, _ := ctx.Get(middleware.ContextUserID).(uint)
+	rol, _ := ctx.Get(middleware.ContextRol).(string)
+	return usuarioID, rol
+}
+
+// CreateSolicitud abre una solicitud sobre un equipo (EquipoID, Descripcion, Prioridad)
+// POST /api/solicitudes-servicio
+func (c *SolicitudServicioController) CreateSolicitud(ctx echo.Context) error {
+	solicitud := new(models.SolicitudServicio)
+	if err := ctx.Bind(solicitud); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+
+	usuarioID, rol := usuarioSesion(ctx)
+	if err := c.service.Crear(ctx.Request().Context(), solicitud, usuarioID, rol); err != nil {
+		return responderErrorSolicitud(ctx, err)
+	}
+	creada, err := c.service.GetByID(solicitud.ID, usuarioID, rol)
+	if err != nil {
+		return responderErrorSolicitud(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, creada)
+}
+
+// GetAllSolicitudes lista las solicitudes (filtros: estado, prioridad, equipo, tecnico, solicitante, dependencia)
+// GET /api/solicitudes-servicio
+func (c *SolicitudServicioController) GetAllSolicitudes(ctx echo.Context) error {
+	consulta, err := parseConsulta(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	usuarioID, rol := usuarioSesion(ctx)
+	solicitudes, err := c.service.GetAll(consulta, usuarioID, rol)
+	if errors.Is(err, services.ErrSolicitudSinDependencia) {
+		return responderErrorSolicitud(ctx, err)
+	}
+	return responderPagina(ctx, solicitudes, err)
+}
+
+// GetSolicitud obtiene una solicitud por su ID
+// GET /api/solicitudes-servicio/:id
+func (c *SolicitudServicioController) GetSolicitud(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	usuarioID, rol := usuarioSesion(ctx)
+	solicitud, err := c.service.GetByID(uint(id), usuarioID, rol)
+	if err != nil {
+		return responderErrorSolicitud(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, solicitud)
+}
+
+// AsignarSolicitud asigna o reasigna la solicitud a un técnico
+// POST /api/solicitudes-servicio/:id/asignar
+func (c *SolicitudServicioController) AsignarSolicitud(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	datos := new(dto.AsignarSolicitudDTO)
+	if err := ctx.Bind(datos); err != nil || datos.TecnicoID == 0 {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Debe indicar tecnico_id"})
+	}
+
+	solicitud, err := c.service.Asignar(ctx.Request().Context(), uint(id), datos.TecnicoID)
+	if err != nil {
+		return responderErrorSolicitud(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, solicitud)
+}
+
+// IniciarSolicitud marca la solicitud como en progreso
+// POST /api/solicitudes-servicio/:id/iniciar
+func (c *SolicitudServicioController) IniciarSolicitud(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	usuarioID, rol := usuarioSesion(ctx)
+	solicitud, err := c.service.Iniciar(ctx.Request().Context(), uint(id), usuarioID, rol)
+	if err != nil {
+		return responderErrorSolicitud(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, solicitud)
+}
+
+// ResolverSolicitud resuelve la solicitud con un reporte existente (reporte_id) o con uno
+// nuevo (reporte, con los mismos campos de POST /api/reportes-servicio/completo)
+// POST /api/solicitudes-servicio/:id/resolver
+func (c *SolicitudServicioController) ResolverSolicitud(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	datos := new(dto.ResolverSolicitudDTO)
+	if err := ctx.Bind(datos); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos: " + err.Error()})
+	}
+	if datos.Reporte != nil {
+		for i, repuesto := range datos.Reporte.Repuestos {
+			if repuesto.Cantidad <= 0 {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{
+					"error": "La cantidad del repuesto en la posición " + strconv.Itoa(i) + " debe ser mayor a 0",
+				})
+			}
+			if repuesto.ArticuloID == nil && (repuesto.SerialNumeroParte == "" || repuesto.Descripcion == "") {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{
+					"error": "El repuesto en la posición " + strconv.Itoa(i) + " debe tener articulo_id o serial y descripción",
+				})
+			}
+		}
+	}
+
+	usuarioID, rol := usuarioSesion(ctx)
+	solicitud, err := c.service.Resolver(ctx.Request().Context(), uint(id), datos, usuarioID, rol)
+	if err != nil {
+		return responderErrorSolicitud(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, solicitud)
+}
+
+// CancelarSolicitud cancela una solicitud sin resolver
+// POST /api/solicitudes-servicio/:id/cancelar
+func (c *SolicitudServicioController) CancelarSolicitud(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	datos := new(dto.CancelarSolicitudDTO)
+	if err := ctx.Bind(datos); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
+	}
+
+	usuarioID, rol := usuarioSesion(ctx)
+	solicitud, err := c.service.Cancelar(ctx.Request().Context(), uint(id), datos.Motivo, usuarioID, rol)
+	if err != nil {
+		return responderErrorSolicitud(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, solicitud)
+}
+
+// responderErrorSolicitud traduce los errores de las solicitudes de servicio a respuestas HTTP
+func responderErrorSolicitud(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSolicitudNoEncontrada):
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrSolicitudNoPermitida), errors.Is(err, services.ErrSolicitudSinDependencia):
+		return ctx.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrSolicitudEstado), errors.Is(err, services.ErrStockInsuficiente):
+		return ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrSolicitudInvalida), errors.Is(err, services.ErrArticuloNoEncontrado):
+		return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -28,7 +28,7 @@ func (c *TipoMantenimientoController) CreateTipoMantenimiento(ctx echo.Context)
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.tipoService.CreateTipoMantenimiento(tipo); err != nil {
+	if err := c.tipoService.CreateTipoMantenimiento(ctx.Request().Context(), tipo); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -63,7 +63,7 @@ func (c *TipoMantenimientoController) UpdateTipoMantenimiento(ctx echo.Context)
 	}
 
 	tipo.ID = uint(id)
-	if err := c.tipoService.UpdateTipoMantenimiento(tipo); err != nil {
+	if err := c.tipoService.UpdateTipoMantenimiento(ctx.Request().Context(), tipo); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -77,7 +77,7 @@ func (c *TipoMantenimientoController) DeleteTipoMantenimiento(ctx echo.Context)
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.tipoService.DeleteTipoMantenimiento(uint(id)); err != nil {
+	if err := c.tipoService.DeleteTipoMantenimiento(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -86,12 +86,13 @@ func (c *TipoMantenimientoController) DeleteTipoMantenimiento(ctx echo.Context)
 
 // GetAllTiposMantenimiento obtiene todos los tipos de mantenimiento
 func (c *TipoMantenimientoController) GetAllTiposMantenimiento(ctx echo.Context) error {
-	tipos, err := c.tipoService.GetAllTiposMantenimiento()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, tipos)
+	tipos, err := c.tipoService.GetAllTiposMantenimiento(consulta)
+	return responderPagina(ctx, tipos, err)
 }
 
 // GetTiposMantenimientoByReporte obtiene todos los tipos de mantenimiento asociados a un reporte
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"tum_inv_backend/internal/api/middleware"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// permisosTrabajo es el recurso y la acción que exige encolar cada tipo de trabajo, los
+// mismos de su endpoint directo
+var permisosTrabajo = map[string]struct{ recurso, accion string }{
+	models.TrabajoExportarEquipos:     {"equipos", middleware.AccionLeer},
+	models.TrabajoExportarPerifericos: {"perifericos", middleware.AccionLeer},
+	models.TrabajoExportarSoftware:    {"software", middleware.AccionLeer},
+	models.TrabajoExportarReportes:    {"reportes-servicio", middleware.AccionLeer},
+	models.TrabajoImportarInventario:  {"equipos", middleware.AccionCrear},
+	models.TrabajoPDFLoteReportes:     {"reportes-servicio", middleware.AccionLeer},
+}
+
+// TrabajoController maneja los trabajos en segundo plano
+type TrabajoController struct {
+	trabajoService services.TrabajoService
+}
+
+// NewTrabajoController crea una nueva instancia de TrabajoController
+func NewTrabajoController(trabajoService services.TrabajoService) *TrabajoController {
+	return &TrabajoController{trabajoService: trabajoService}
+}
+
+// CrearTrabajo encola un trabajo y responde 202 con el trabajo creado.
+// ?tipo_trabajo= indica qué hacer; los demás parámetros son los del endpoint directo
+// (filtros, sort, order, desde, hasta, formato, dry_run). La importación recibe el archivo
+// en el campo "archivo".
+// POST /api/jobs
+func (c *TrabajoController) CrearTrabajo(ctx echo.Context) error {
+	tipo := ctx.FormValue("tipo_trabajo")
+	permiso, ok := permisosTrabajo[tipo]
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "tipo_trabajo inválido"})
+	}
+	rol, _ := ctx.Get(middleware.ContextRol).(string)
+	if !middleware.TienePermiso(rol, permiso.recurso, permiso.accion) {
+		return ctx.JSON(http.StatusForbidden, map[string]string{"error": "No tiene permisos para acceder a este recurso"})
+	}
+
+	consulta, err := parseConsulta(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	delete(consulta.Filtros, "tipo_trabajo")
+	delete(consulta.Filtros, "formato")
+
+	trabajo := &models.Trabajo{
+		Tipo: tipo,
+		Parametros: models.ParametrosTrabajo{
+			Filtros:     consulta.Filtros,
+			Orden:       consulta.Orden,
+			Descendente: consulta.Descendente,
+			Desde:       consulta.Desde,
+			Hasta:       consulta.Hasta,
+			Formato:     ctx.FormValue("formato"),
+		},
+	}
+
+	var entrada []byte
+	if tipo == models.TrabajoImportarInventario {
+		if valor := ctx.FormValue("dry_run"); valor != "" {
+			if trabajo.Parametros.DryRun, err = strconv.ParseBool(valor); err != nil {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "dry_run debe ser true o false"})
+			}
+		}
+		file, err := ctx.FormFile("archivo")
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Debe enviar un archivo XLSX o CSV"})
+		}
+		if file.Size > 10*1024*1024 {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "El archivo no debe superar los 10MB"})
+		}
+		src, err := file.Open()
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al leer el archivo"})
+		}
+		defer src.Close()
+		if entrada, err = io.ReadAll(src); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error al procesar el archivo"})
+		}
+		trabajo.Parametros.Archivo = file.Filename
+		trabajo.Parametros.Filtros = nil
+	}
+
+	if err := c.trabajoService.Encolar(ctx.Request().Context(), trabajo, entrada); err != nil {
+		if errors.Is(err, services.ErrTrabajoInvalido) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusAccepted, trabajo)
+}
+
+// GetTrabajo consulta el estado, el progreso y el resumen de un trabajo
+// GET /api/jobs/:id
+func (c *TrabajoController) GetTrabajo(ctx echo.Context) error {
+	trabajo, err := c.trabajoPropio(ctx)
+	if err != nil {
+		return responderErrorTrabajo(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, trabajo)
+}
+
+// DescargarResultado genera una URL temporal para descargar el archivo del trabajo
+// GET /api/jobs/:id/descargar
+func (c *TrabajoController) DescargarResultado(ctx echo.Context) error {
+	trabajo, err := c.trabajoPropio(ctx)
+	if err != nil {
+		return responderErrorTrabajo(ctx, err)
+	}
+	url, err := c.trabajoService.URLResultado(trabajo)
+	if err != nil {
+		return responderErrorTrabajo(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"url":     url,
+		"archivo": trabajo.ResultadoNombre,
+	})
+}
+
+// trabajoPropio obtiene el trabajo de la ruta; solo lo ven quien lo creó y el administrador
+func (c *TrabajoController) trabajoPropio(ctx echo.Context) (*models.Trabajo, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return nil, services.ErrTrabajoNoEncontrado
+	}
+	trabajo, err := c.trabajoService.GetByID(uint(id))
+	if err != nil {
+		return nil, err
+	}
+	usuarioID, _ := ctx.Get(middleware.ContextUserID).(uint)
+	rol, _ := ctx.Get(middleware.ContextRol).(string)
+	if trabajo.CreadoPorID != usuarioID && rol != models.RolAdmin {
+		return nil, services.ErrTrabajoNoEncontrado
+	}
+	return trabajo, nil
+}
+
+// responderErrorTrabajo convierte los errores de trabajos en respuestas HTTP
+func responderErrorTrabajo(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrTrabajoNoEncontrado):
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrTrabajoSinResultado):
+		return ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
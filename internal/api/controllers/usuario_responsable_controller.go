@@ -28,7 +28,7 @@ func (c *UsuarioResponsableController) CreateUsuarioResponsable(ctx echo.Context
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.usuarioService.CreateUsuarioResponsable(usuario); err != nil {
+	if err := c.usuarioService.CreateUsuarioResponsable(ctx.Request().Context(), usuario); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -63,7 +63,7 @@ func (c *UsuarioResponsableController) UpdateUsuarioResponsable(ctx echo.Context
 	}
 
 	usuario.ID = uint(id)
-	if err := c.usuarioService.UpdateUsuarioResponsable(usuario); err != nil {
+	if err := c.usuarioService.UpdateUsuarioResponsable(ctx.Request().Context(), usuario); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -77,7 +77,7 @@ func (c *UsuarioResponsableController) DeleteUsuarioResponsable(ctx echo.Context
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.usuarioService.DeleteUsuarioResponsable(uint(id)); err != nil {
+	if err := c.usuarioService.DeleteUsuarioResponsable(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -86,12 +86,13 @@ func (c *UsuarioResponsableController) DeleteUsuarioResponsable(ctx echo.Context
 
 // GetAllUsuariosResponsables obtiene todos los usuarios responsables
 func (c *UsuarioResponsableController) GetAllUsuariosResponsables(ctx echo.Context) error {
-	usuarios, err := c.usuarioService.GetAllUsuariosResponsables()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, usuarios)
+	usuarios, err := c.usuarioService.GetAllUsuariosResponsables(consulta)
+	return responderPagina(ctx, usuarios, err)
 }
 
 // GetUsuarioResponsableByCedula obtiene un usuario responsable por su cédula
@@ -133,7 +134,7 @@ func (c *UsuarioResponsableController) AsignarDependencia(ctx echo.Context) erro
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.usuarioService.AsignarDependencia(uint(id), body.DependenciaID); err != nil {
+	if err := c.usuarioService.AsignarDependencia(ctx.Request().Context(), uint(id), body.DependenciaID); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
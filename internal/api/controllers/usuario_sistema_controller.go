@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"tum_inv_backend/internal/domain/models"
@@ -28,7 +29,7 @@ func (c *UsuarioSistemaController) CreateUsuarioSistema(ctx echo.Context) error
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Datos inválidos"})
 	}
 
-	if err := c.usuarioService.CreateUsuarioSistema(usuario); err != nil {
+	if err := c.usuarioService.CreateUsuarioSistema(ctx.Request().Context(), usuario); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -63,7 +64,7 @@ func (c *UsuarioSistemaController) UpdateUsuarioSistema(ctx echo.Context) error
 	}
 
 	usuario.ID = uint(id)
-	if err := c.usuarioService.UpdateUsuarioSistema(usuario); err != nil {
+	if err := c.usuarioService.UpdateUsuarioSistema(ctx.Request().Context(), usuario); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -77,7 +78,7 @@ func (c *UsuarioSistemaController) DeleteUsuarioSistema(ctx echo.Context) error
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
 	}
 
-	if err := c.usuarioService.DeleteUsuarioSistema(uint(id)); err != nil {
+	if err := c.usuarioService.DeleteUsuarioSistema(ctx.Request().Context(), uint(id)); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -86,12 +87,13 @@ func (c *UsuarioSistemaController) DeleteUsuarioSistema(ctx echo.Context) error
 
 // GetAllUsuariosSistema obtiene todos los usuarios del sistema
 func (c *UsuarioSistemaController) GetAllUsuariosSistema(ctx echo.Context) error {
-	usuarios, err := c.usuarioService.GetAllUsuariosSistema()
+	consulta, err := parseConsulta(ctx)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, usuarios)
+	usuarios, err := c.usuarioService.GetAllUsuariosSistema(consulta)
+	return responderPagina(ctx, usuarios, err)
 }
 
 // GetUsuariosSistemaByEquipo obtiene todos los usuarios del sistema asociados a un equipo
@@ -123,4 +125,22 @@ func (c *UsuarioSistemaController) GetUsuarioSistemaByNombreUsuario(ctx echo.Con
 	}
 
 	return ctx.JSON(http.StatusOK, usuario)
-}
\ No newline at end of file
+}
+
+// RevelarContrasena retorna la contraseña descifrada de un usuario del sistema y deja registro de la consulta
+func (c *UsuarioSistemaController) RevelarContrasena(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "ID inválido"})
+	}
+
+	contrasena, err := c.usuarioService.RevelarContrasena(ctx.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrRevelarSinAuditoria) {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"contrasena": contrasena})
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"tum_inv_backend/internal/domain/services"
+	"tum_inv_backend/internal/infrastructure/verificacion"
+
+	"github.com/labstack/echo/v4"
+)
+
+// VerificacionController atiende la verificación pública de los reportes impresos
+type VerificacionController struct {
+	service services.VerificacionService
+}
+
+// NewVerificacionController crea una nueva instancia del controlador
+func NewVerificacionController(service services.VerificacionService) *VerificacionController {
+	return &VerificacionController{service: service}
+}
+
+// VerificarReporte retorna los datos del reporte si el código impreso en su PDF es auténtico.
+// No requiere token: se consulta escaneando el QR del reporte.
+func (c *VerificacionController) VerificarReporte(ctx echo.Context) error {
+	resultado, err := c.service.VerificarReporte(ctx.Param("codigo"))
+	if err != nil {
+		if errors.Is(err, verificacion.ErrCodigoInvalido) || errors.Is(err, services.ErrReporteNoVerificable) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Error verificando el reporte"})
+	}
+
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+	return ctx.JSON(http.StatusOK, resultado)
+}
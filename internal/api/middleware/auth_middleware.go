@@ -1,13 +1,22 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"tum_inv_backend/internal/domain/services"
+	"tum_inv_backend/internal/infrastructure/auditoria"
 
 	"github.com/labstack/echo/v4"
 )
 
+// Claves usadas para guardar la identidad del usuario en el contexto de Echo
+const (
+	ContextUserID = "user_id"
+	ContextRol    = "rol"
+	ContextClaims = "claims"
+)
+
 // JWTMiddleware es un middleware para validar tokens JWT
 type JWTMiddleware struct {
 	authService services.AuthService
@@ -20,7 +29,8 @@ func NewJWTMiddleware(authService services.AuthService) *JWTMiddleware {
 	}
 }
 
-// Authenticate valida el token JWT y establece el ID de usuario en el contexto
+// Authenticate valida el token JWT, verifica que la cuenta siga activa y establece el ID y el
+// rol actual del usuario en el contexto
 func (m *JWTMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		// Obtener token del encabezado Authorization
@@ -38,44 +48,70 @@ func (m *JWTMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 		token := parts[1]
 
 		// Validar token
-		userID, err := m.authService.ValidateToken(token)
+		claims, err := m.authService.ValidateToken(token)
 		if err != nil {
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 		}
 
-		// Establecer ID de usuario en el contexto
-		c.Set("user_id", userID)
+		// El rol del token puede estar desactualizado: se usa el rol actual, y una cuenta
+		// desactivada o eliminada deja de tener acceso aunque su token no haya vencido
+		rol, err := m.authService.RolVigente(claims.UserID)
+		if err != nil {
+			if errors.Is(err, services.ErrCuentaNoVigente) {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+			}
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "No se pudo verificar la cuenta"})
+		}
+		claims.Rol = rol
+
+		// Establecer identidad del usuario en el contexto
+		c.Set(ContextUserID, claims.UserID)
+		c.Set(ContextRol, claims.Rol)
+		c.Set(ContextClaims, claims)
+
+		// Propagar el actor al contexto de la petición para la auditoría de cambios
+		actor := auditoria.Actor{UsuarioID: claims.UserID, Username: claims.Username}
+		c.SetRequest(c.Request().WithContext(auditoria.ConActor(c.Request().Context(), actor)))
 
 		return next(c)
 	}
 }
 
-// RequireRole verifica si el usuario tiene el rol requerido
-func (m *JWTMiddleware) RequireRole(role string, next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		// Primero autenticar
-		err := m.Authenticate(func(c echo.Context) error { return nil })(c)
-		if err != nil {
-			return err
-		}
-
-		// Obtener ID de usuario del contexto
-		userID, ok := c.Get("user_id").(uint)
-		if !ok {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "No se pudo obtener el ID de usuario"})
+// RequireRole permite el acceso solo a los usuarios con alguno de los roles indicados.
+// Debe usarse después de Authenticate.
+func (m *JWTMiddleware) RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rol, _ := c.Get(ContextRol).(string)
+			for _, r := range roles {
+				if r == rol {
+					return next(c)
+				}
+			}
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "No tiene permisos para acceder a este recurso"})
 		}
+	}
+}
 
-		// Obtener usuario
-		usuario, err := m.authService.GetUserByID(userID)
-		if err != nil {
-			return c.JSON(http.StatusNotFound, map[string]string{"error": "Usuario no encontrado"})
-		}
+// Authorize verifica en la tabla de permisos que el rol del usuario pueda
+// ejecutar la acción sobre el recurso. Debe usarse después de Authenticate.
+func (m *JWTMiddleware) Authorize(recurso, accion string) echo.MiddlewareFunc {
+	// Validar al registrar la ruta para detectar permisos mal escritos al iniciar
+	if _, ok := tablaPermisos[recurso][accion]; !ok {
+		panic("permiso no definido: " + recurso + ":" + accion)
+	}
 
-		// Verificar rol
-		if usuario.Rol != role {
-			return c.JSON(http.StatusForbidden, map[string]string{"error": "No tiene permisos para acceder a este recurso"})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rol, _ := c.Get(ContextRol).(string)
+			if !TienePermiso(rol, recurso, accion) {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error":   "No tiene permisos para realizar esta acción",
+					"recurso": recurso,
+					"accion":  accion,
+				})
+			}
+			return next(c)
 		}
-
-		return next(c)
 	}
-}
\ No newline at end of file
+}
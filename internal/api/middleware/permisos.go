@@ -0,0 +1,196 @@
+package middleware
+
+import "tum_inv_backend/internal/domain/models"
+
+// Acciones que se pueden autorizar sobre un recurso
+const (
+	AccionLeer       = "leer"
+	AccionCrear      = "crear"
+	AccionActualizar = "actualizar"
+	AccionEliminar   = "eliminar"
+	AccionAsignar    = "asignar"
+	AccionCerrar     = "cerrar"
+	AccionReabrir    = "reabrir"
+	AccionRevelar    = "revelar"
+	AccionCancelar   = "cancelar"
+)
+
+// Grupos de roles reutilizados en la tabla de permisos
+var (
+	rolesTodos   = []string{models.RolAdmin, models.RolTecnico, models.RolUsuario}
+	rolesGestion = []string{models.RolAdmin, models.RolTecnico}
+	rolesAdmin   = []string{models.RolAdmin}
+)
+
+// tablaPermisos define, por recurso y acción, los roles autorizados
+var tablaPermisos = map[string]map[string][]string{
+	"dashboard": {
+		AccionLeer: rolesTodos,
+	},
+	"busqueda": {
+		AccionLeer: rolesTodos,
+	},
+	"usuarios": {
+		AccionLeer:  rolesAdmin,
+		AccionCrear: rolesAdmin,
+	},
+	"equipos": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesAdmin,
+		AccionAsignar:    rolesGestion,
+	},
+	"perifericos": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+		AccionAsignar:    rolesGestion,
+	},
+	"software": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+	},
+	"usuarios-responsables": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesAdmin,
+		AccionAsignar:    rolesGestion,
+	},
+	"hardware-interno": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+	},
+	"configuraciones-red": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+	},
+	// Contienen credenciales, por eso no son visibles para el rol usuario
+	"usuarios-sistema": {
+		AccionLeer:       rolesGestion,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+		AccionRevelar:    rolesAdmin,
+	},
+	"accesos-remotos": {
+		AccionLeer:       rolesGestion,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+		AccionRevelar:    rolesAdmin,
+	},
+	"backups": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+	},
+	"reportes-servicio": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesAdmin,
+		AccionCerrar:     rolesGestion,
+		AccionReabrir:    rolesAdmin,
+	},
+	"tipos-mantenimiento": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+	},
+	"mantenimientos": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+	},
+	"repuestos": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesGestion,
+	},
+	"almacen": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionEliminar:   rolesAdmin,
+	},
+	// El rol usuario abre solicitudes y cancela las suyas; el servicio limita cuáles ve
+	"solicitudes-servicio": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesTodos,
+		AccionAsignar:    rolesGestion,
+		AccionActualizar: rolesGestion,
+		AccionCerrar:     rolesGestion,
+		AccionCancelar:   rolesTodos,
+	},
+	// El cumplimiento por técnico es información de la Oficina; los plazos los fija el administrador
+	"sla": {
+		AccionLeer:       rolesGestion,
+		AccionCrear:      rolesAdmin,
+		AccionActualizar: rolesAdmin,
+		AccionEliminar:   rolesAdmin,
+	},
+	"secretarias": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesAdmin,
+		AccionActualizar: rolesAdmin,
+		AccionEliminar:   rolesAdmin,
+	},
+	"dependencias": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesAdmin,
+		AccionActualizar: rolesAdmin,
+		AccionEliminar:   rolesAdmin,
+	},
+	"historial-equipos": {
+		AccionLeer: rolesGestion,
+	},
+	"auditoria": {
+		AccionLeer: rolesAdmin,
+	},
+	// Solo el administrador cambia el diseño de los documentos oficiales
+	"plantillas-pdf": {
+		AccionLeer:       rolesAdmin,
+		AccionCrear:      rolesAdmin,
+		AccionActualizar: rolesAdmin,
+		AccionEliminar:   rolesAdmin,
+	},
+	// Cada usuario solo ve y marca sus propias notificaciones
+	"notificaciones": {
+		AccionLeer:       rolesTodos,
+		AccionActualizar: rolesTodos,
+	},
+	// Cada tipo de trabajo exige además el permiso de su endpoint directo
+	"jobs": {
+		AccionLeer:  rolesTodos,
+		AccionCrear: rolesTodos,
+	},
+	"estados-equipo": {
+		AccionLeer:       rolesTodos,
+		AccionCrear:      rolesAdmin,
+		AccionActualizar: rolesAdmin,
+		AccionEliminar:   rolesAdmin,
+	},
+}
+
+// TienePermiso indica si el rol puede ejecutar la acción sobre el recurso
+func TienePermiso(rol, recurso, accion string) bool {
+	for _, r := range tablaPermisos[recurso][accion] {
+		if r == rol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tum_inv_backend/internal/domain/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestTienePermiso(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		rol     string
+		recurso string
+		accion  string
+		want    bool
+	}{
+		{"admin elimina equipos", models.RolAdmin, "equipos", AccionEliminar, true},
+		{"tecnico no elimina equipos", models.RolTecnico, "equipos", AccionEliminar, false},
+		{"tecnico crea equipos", models.RolTecnico, "equipos", AccionCrear, true},
+		{"usuario lee equipos", models.RolUsuario, "equipos", AccionLeer, true},
+		{"usuario no crea equipos", models.RolUsuario, "equipos", AccionCrear, false},
+		{"usuario no lee credenciales", models.RolUsuario, "usuarios-sistema", AccionLeer, false},
+		{"tecnico no revela contraseñas", models.RolTecnico, "accesos-remotos", AccionRevelar, false},
+		{"admin revela contraseñas", models.RolAdmin, "accesos-remotos", AccionRevelar, true},
+		{"solo admin reabre reportes", models.RolTecnico, "reportes-servicio", AccionReabrir, false},
+		{"usuario cancela solicitudes", models.RolUsuario, "solicitudes-servicio", AccionCancelar, true},
+		{"usuario no asigna solicitudes", models.RolUsuario, "solicitudes-servicio", AccionAsignar, false},
+		{"tecnico no lee auditoría", models.RolTecnico, "auditoria", AccionLeer, false},
+		{"rol vacío", "", "dashboard", AccionLeer, false},
+		{"rol desconocido", "invitado", "dashboard", AccionLeer, false},
+		{"recurso desconocido", models.RolAdmin, "inexistente", AccionLeer, false},
+		{"acción no definida", models.RolAdmin, "auditoria", AccionEliminar, false},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			if got := TienePermiso(tc.rol, tc.recurso, tc.accion); got != tc.want {
+				t.Errorf("TienePermiso(%q, %q, %q) = %v, se esperaba %v", tc.rol, tc.recurso, tc.accion, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		rol     any
+		recurso string
+		accion  string
+		want    int
+	}{
+		{"rol autorizado", models.RolTecnico, "equipos", AccionActualizar, http.StatusOK},
+		{"rol sin permiso", models.RolUsuario, "equipos", AccionActualizar, http.StatusForbidden},
+		{"sin rol en el contexto", nil, "equipos", AccionLeer, http.StatusForbidden},
+		{"rol con tipo inválido", 1, "equipos", AccionLeer, http.StatusForbidden},
+	}
+
+	m := &JWTMiddleware{}
+	e := echo.New()
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			if tc.rol != nil {
+				c.Set(ContextRol, tc.rol)
+			}
+
+			handler := m.Authorize(tc.recurso, tc.accion)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+			if err := handler(c); err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if rec.Code != tc.want {
+				t.Errorf("código = %d, se esperaba %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizePermisoNoDefinido(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		recurso string
+		accion  string
+	}{
+		{"recurso inexistente", "inexistente", AccionLeer},
+		{"acción inexistente", "auditoria", AccionEliminar},
+	}
+
+	m := &JWTMiddleware{}
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Authorize(%q, %q) no falló con un permiso no definido", tc.recurso, tc.accion)
+				}
+			}()
+			m.Authorize(tc.recurso, tc.accion)
+		})
+	}
+}
@@ -1,19 +1,24 @@
 package routes
 
 import (
+	"context"
 	"tum_inv_backend/internal/api/controllers"
 	"tum_inv_backend/internal/api/middleware"
 	"tum_inv_backend/internal/domain/repositories"
 	"tum_inv_backend/internal/domain/services"
 	"tum_inv_backend/internal/infrastructure/config"
+	"tum_inv_backend/internal/infrastructure/correo"
+	"tum_inv_backend/internal/infrastructure/firma"
 	"tum_inv_backend/internal/infrastructure/storage"
+	"tum_inv_backend/internal/infrastructure/verificacion"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-// SetupRoutes configura todas las rutas de la aplicación
-func SetupRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
+// SetupRoutes configura todas las rutas de la aplicación. Los procesos en segundo plano
+// (cola de trabajos, envío de correos y revisión de notificaciones) se detienen al cancelar ctx.
+func SetupRoutes(ctx context.Context, e *echo.Echo, db *gorm.DB, cfg *config.Config, almacenamiento storage.Provider, verificador *verificacion.Verificador, firmaDigital *firma.FirmaDigital, remitente correo.Remitente) {
 	// Repositorios
 	equipoRepo := repositories.NewEquipoRepository(db)
 	perifericoRepo := repositories.NewPerifericoRepository(db)
@@ -31,25 +36,58 @@ func SetupRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
 	secretariaRepo := repositories.NewSecretariaRepository(db)
 	dependenciaRepo := repositories.NewDependenciaRepository(db)
 	estadoEquipoRepo := repositories.NewEstadoEquipoRepository(db)
+	auditoriaRepo := repositories.NewAuditoriaRepository(db)
+	asignacionEquipoRepo := repositories.NewAsignacionEquipoRepository(db)
+	busquedaRepo := repositories.NewBusquedaRepository(db)
+	importacionRepo := repositories.NewImportacionRepository(db)
+	documentoReporteRepo := repositories.NewDocumentoReporteRepository(db)
+	plantillaPDFRepo := repositories.NewPlantillaPDFRepository(db)
+	trabajoRepo := repositories.NewTrabajoRepository(db)
+	planMantenimientoRepo := repositories.NewPlanMantenimientoRepository(db)
+	almacenRepo := repositories.NewAlmacenRepository(db)
+	solicitudServicioRepo := repositories.NewSolicitudServicioRepository(db)
+	politicaSLARepo := repositories.NewPoliticaSLARepository(db)
+	correoSalienteRepo := repositories.NewCorreoSalienteRepository(db)
+	notificacionRepo := repositories.NewNotificacionRepository(db)
+	transacciones := repositories.NewTransacciones(db)
 
 	// Servicios
-	equipoService := services.NewEquipoService(equipoRepo)
+	auditoriaService := services.NewAuditoriaService(auditoriaRepo)
+	mantenimientoService := services.NewMantenimientoService(planMantenimientoRepo, equipoRepo)
+	notificacionService := services.NewNotificacionService(notificacionRepo, mantenimientoService, services.OpcionesNotificacionesDesde(cfg))
+	equipoService := services.NewEquipoService(equipoRepo, asignacionEquipoRepo, notificacionService, almacenamiento)
 	perifericoService := services.NewPerifericoService(perifericoRepo)
 	softwareService := services.NewSoftwareService((softwareRepo))
 	usuarioResponsableService := services.NewUsuarioResponsableService(usuarioResponsableRepo)
 	hardwareInternoService := services.NewHardwareInternoService(hardwareInternoRepo)
 	configuracionRedService := services.NewConfiguracionRedService(configuracionRedRepo)
-	usuarioSistemaService := services.NewUsuarioSistemaService(usuarioSistemaRepo)
-	accesoRemotoService := services.NewAccesoRemotoService(accesoRemotoRepo)
+	usuarioSistemaService := services.NewUsuarioSistemaService(usuarioSistemaRepo, auditoriaService)
+	accesoRemotoService := services.NewAccesoRemotoService(accesoRemotoRepo, auditoriaService)
 	backupService := services.NewBackupService(backupRepo)
-	reporteServicioService := services.NewReporteServicioService(reporteServicioRepo, storage.NewSupabaseStorage(cfg))
+	notificacionCorreoService := services.NewNotificacionCorreoService(correoSalienteRepo, remitente, services.OpcionesCorreosDesde(cfg))
+	reporteServicioService := services.NewReporteServicioService(reporteServicioRepo, documentoReporteRepo, transacciones, services.OpcionesValidacionPDFDesde(cfg), notificacionCorreoService, almacenamiento)
 	tipoMantenimientoService := services.NewTipoMantenimientoService(tipoMantenimientoRepo)
 	repuestoService := services.NewRepuestoService(repuestoRepo)
 	authService := services.NewAuthService(usuarioRepo, cfg)
-	pdfReporteService := services.NewPDFReporteService(db)
+	pdfReporteService := services.NewPDFReporteService(db, verificador, plantillaPDFRepo, almacenamiento)
 	secretariaService := services.NewSecretariaService(secretariaRepo, dependenciaRepo)
 	dependenciaService := services.NewDependenciaService(dependenciaRepo)
 	estadoEquipoService := services.NewEstadoEquipoService(estadoEquipoRepo)
+	busquedaService := services.NewBusquedaService(busquedaRepo)
+	importacionService := services.NewImportacionService(importacionRepo, usuarioResponsableRepo, dependenciaRepo, estadoEquipoRepo)
+	exportacionService := services.NewExportacionService(equipoRepo, perifericoRepo, softwareRepo, reporteServicioRepo)
+	verificacionService := services.NewVerificacionService(reporteServicioRepo, documentoReporteRepo, verificador)
+	firmaDigitalService := services.NewFirmaDigitalService(reporteServicioService, usuarioRepo, pdfReporteService, firmaDigital)
+	plantillaPDFService := services.NewPlantillaPDFService(plantillaPDFRepo, almacenamiento)
+	loteReportesService := services.NewLoteReportesService(reporteServicioRepo, pdfReporteService, cfg.PDFLoteTrabajadores, cfg.PDFLoteMaximo)
+	trabajoService := services.NewTrabajoService(trabajoRepo, almacenamiento, services.OpcionesTrabajosDesde(cfg), exportacionService, importacionService, loteReportesService)
+	almacenService := services.NewAlmacenService(almacenRepo)
+	solicitudServicioService := services.NewSolicitudServicioService(solicitudServicioRepo, usuarioRepo, reporteServicioService, notificacionService, transacciones)
+	slaService := services.NewSLAService(politicaSLARepo, reporteServicioRepo, solicitudServicioRepo)
+	// Los trabajadores de la cola, el envío de correos y la revisión de plazos corren hasta que se cancele ctx
+	trabajoService.Iniciar(ctx)
+	notificacionCorreoService.Iniciar(ctx)
+	notificacionService.Iniciar(ctx)
 
 	// Controladores
 	equipoController := controllers.NewEquipoController(equipoService)
@@ -69,13 +107,29 @@ func SetupRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
 	dependenciaController := controllers.NewDependenciaController(dependenciaService)
 	estadoEquipoController := controllers.NewEstadoEquipoController(estadoEquipoService)
 	pdfController := controllers.NewPDFController(pdfReporteService)
+	auditoriaController := controllers.NewAuditoriaController(auditoriaService)
+	busquedaController := controllers.NewBusquedaController(busquedaService)
+	importacionController := controllers.NewImportacionController(importacionService)
+	exportacionController := controllers.NewExportacionController(exportacionService)
+	verificacionController := controllers.NewVerificacionController(verificacionService)
+	firmaDigitalController := controllers.NewFirmaDigitalController(firmaDigitalService)
+	plantillaPDFController := controllers.NewPlantillaPDFController(plantillaPDFService, pdfReporteService)
+	loteReportesController := controllers.NewLoteReportesController(loteReportesService)
+	trabajoController := controllers.NewTrabajoController(trabajoService)
+	mantenimientoController := controllers.NewMantenimientoController(mantenimientoService)
+	almacenController := controllers.NewAlmacenController(almacenService)
+	solicitudServicioController := controllers.NewSolicitudServicioController(solicitudServicioService)
+	slaController := controllers.NewSLAController(slaService)
+	notificacionController := controllers.NewNotificacionController(notificacionService)
 
 	// Dashboard
-	dashboardService := services.NewDashboardService(db)
+	dashboardService := services.NewDashboardService(db, mantenimientoService)
 	dashboardController := controllers.NewDashboardController(dashboardService)
 
 	// Middleware
 	jwtMiddleware := middleware.NewJWTMiddleware(authService)
+	// permiso aplica la tabla de permisos por rol (ver middleware/permisos.go)
+	permiso := jwtMiddleware.Authorize
 
 	// Grupo de rutas para API
 	api := e.Group("/api")
@@ -88,201 +142,328 @@ func SetupRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
 		})
 	})
 
-	// Dashboard - estadísticas en una sola petición
-	api.GET("/dashboard/stats", dashboardController.GetDashboardStats)
-	api.GET("/dashboard/sin-secretaria", dashboardController.GetSinSecretaria)
-
 	// Rutas de autenticación (públicas)
 	auth := api.Group("/auth")
-	auth.POST("/register", authController.Register)
 	auth.POST("/login", authController.Login)
 	auth.POST("/refresh", authController.RefreshToken)
 
+	// Verificación de reportes impresos con el código del QR (pública)
+	api.GET("/verificar/:codigo", verificacionController.VerificarReporte)
+
+	// Registro de usuarios (solo admin)
+	auth.POST("/register", authController.Register, jwtMiddleware.Authenticate, permiso("usuarios", middleware.AccionCrear))
 	// Ruta protegida para obtener perfil de usuario
 	auth.GET("/profile", authController.GetProfile, jwtMiddleware.Authenticate)
 	// Ruta protegida para obtener todos los usuarios (solo admin)
-	auth.GET("/users", authController.GetAllUsers, jwtMiddleware.Authenticate)
+	auth.GET("/users", authController.GetAllUsers, jwtMiddleware.Authenticate, permiso("usuarios", middleware.AccionLeer))
+
+	// Dashboard - estadísticas en una sola petición
+	dashboard := api.Group("/dashboard", jwtMiddleware.Authenticate)
+	dashboard.GET("/stats", dashboardController.GetDashboardStats, permiso("dashboard", middleware.AccionLeer))
+	dashboard.GET("/sin-secretaria", dashboardController.GetSinSecretaria, permiso("dashboard", middleware.AccionLeer))
+
+	// Descarga de archivos del almacenamiento local (storage.RutaDescargaLocal), autorizada por la URL firmada
+	if local, ok := almacenamiento.(*storage.LocalStorage); ok {
+		archivoController := controllers.NewArchivoController(local)
+		api.GET("/archivos/*", archivoController.Descargar)
+	}
+
+	// Búsqueda global en el inventario
+	api.GET("/buscar", busquedaController.Buscar, jwtMiddleware.Authenticate, permiso("busqueda", middleware.AccionLeer))
 
 	// Rutas para Equipos
-	equipos := api.Group("/equipos")
-	equipos.POST("", equipoController.CreateEquipo)
-	equipos.GET("", equipoController.GetAllEquipos)
-	equipos.GET("/AllDetalle", equipoController.GetAllEquiposDetalle)
-	equipos.GET("/:id", equipoController.GetEquipo)
-	equipos.PUT("/:id", equipoController.UpdateEquipo)
-	equipos.DELETE("/:id", equipoController.DeleteEquipo)
+	equipos := api.Group("/equipos", jwtMiddleware.Authenticate)
+	equipos.POST("", equipoController.CreateEquipo, permiso("equipos", middleware.AccionCrear))
+	equipos.GET("", equipoController.GetAllEquipos, permiso("equipos", middleware.AccionLeer))
+	equipos.GET("/AllDetalle", equipoController.GetAllEquiposDetalle, permiso("equipos", middleware.AccionLeer))
+	// Importación masiva desde XLSX o CSV (?dry_run=true solo valida)
+	equipos.POST("/importar", importacionController.ImportarInventario, permiso("equipos", middleware.AccionCrear))
+	// Exportación a XLSX (una hoja por secretaría), con los mismos filtros del listado
+	equipos.GET("/exportar", exportacionController.ExportarEquipos, permiso("equipos", middleware.AccionLeer))
+	equipos.GET("/:id", equipoController.GetEquipo, permiso("equipos", middleware.AccionLeer))
+	equipos.PUT("/:id", equipoController.UpdateEquipo, permiso("equipos", middleware.AccionActualizar))
+	equipos.DELETE("/:id", equipoController.DeleteEquipo, permiso("equipos", middleware.AccionEliminar))
 	// Ruta para asignar un responsable a un equipo (solo cambia el FK)
-	equipos.PATCH("/:id/asignar-responsable", equipoController.AsignarResponsable)
+	equipos.PATCH("/:id/asignar-responsable", equipoController.AsignarResponsable, permiso("equipos", middleware.AccionAsignar))
+	// Ruta para obtener el historial de custodia (asignaciones) del equipo
+	equipos.GET("/:id/asignaciones", equipoController.GetAsignacionesByEquipo, permiso("equipos", middleware.AccionLeer))
 	// Ruta para obtener equipos dpor dependencia
-	equipos.GET("/:dependenciaId/dependencia", equipoController.GetEquiposByDependencia)
+	equipos.GET("/:dependenciaId/dependencia", equipoController.GetEquiposByDependencia, permiso("equipos", middleware.AccionLeer))
 	// Ruta para obtener la hoja de vida del equipo
-	equipos.GET("/:equipoId/hv", equipoController.GetEquipoUsuDepByID)
+	equipos.GET("/:equipoId/hv", equipoController.GetEquipoUsuDepByID, permiso("equipos", middleware.AccionLeer))
+	equipos.GET("/:equipoId/hv/pdf", pdfController.GenerarHojaVidaPDF, permiso("equipos", middleware.AccionLeer))
+	equipos.GET("/:equipoId/hv/pdf/view", pdfController.VisualizarHojaVidaPDF, permiso("equipos", middleware.AccionLeer))
+	// Ruta para obtener el historial de cambios del equipo y sus componentes
+	equipos.GET("/:id/historial", auditoriaController.GetHistorialEquipo, permiso("historial-equipos", middleware.AccionLeer))
+
+	// Rutas para Actas de Entrega (asignaciones de equipo)
+	asignaciones := api.Group("/asignaciones", jwtMiddleware.Authenticate)
+	asignaciones.GET("/:id/acta", pdfController.GenerarActaEntregaPDF, permiso("equipos", middleware.AccionLeer))
+	asignaciones.GET("/:id/acta/view", pdfController.VisualizarActaEntregaPDF, permiso("equipos", middleware.AccionLeer))
+	asignaciones.POST("/:id/subir-acta-firmada", equipoController.SubirActaFirmada, permiso("equipos", middleware.AccionAsignar))
+	asignaciones.GET("/:id/descargar-acta-firmada", equipoController.DescargarActaFirmada, permiso("equipos", middleware.AccionLeer))
 
 	// Rutas para Periféricos
-	perifericos := api.Group("/perifericos")
-	perifericos.POST("", perifericoController.CreatePeriferico)
-	perifericos.GET("", perifericoController.GetAllPerifericos)
-	perifericos.GET("/sin-equipo", perifericoController.GetPerifericosSinEquipo)
-	perifericos.GET("/:id", perifericoController.GetPeriferico)
-	perifericos.PUT("/:id", perifericoController.UpdatePeriferico)
-	perifericos.DELETE("/:id", perifericoController.DeletePeriferico)
-	perifericos.PATCH("/:id/asignar-equipo", perifericoController.AsignarEquipo)
+	perifericos := api.Group("/perifericos", jwtMiddleware.Authenticate)
+	perifericos.POST("", perifericoController.CreatePeriferico, permiso("perifericos", middleware.AccionCrear))
+	perifericos.GET("", perifericoController.GetAllPerifericos, permiso("perifericos", middleware.AccionLeer))
+	perifericos.GET("/sin-equipo", perifericoController.GetPerifericosSinEquipo, permiso("perifericos", middleware.AccionLeer))
+	perifericos.GET("/exportar", exportacionController.ExportarPerifericos, permiso("perifericos", middleware.AccionLeer))
+	perifericos.GET("/:id", perifericoController.GetPeriferico, permiso("perifericos", middleware.AccionLeer))
+	perifericos.PUT("/:id", perifericoController.UpdatePeriferico, permiso("perifericos", middleware.AccionActualizar))
+	perifericos.DELETE("/:id", perifericoController.DeletePeriferico, permiso("perifericos", middleware.AccionEliminar))
+	perifericos.PATCH("/:id/asignar-equipo", perifericoController.AsignarEquipo, permiso("perifericos", middleware.AccionAsignar))
 
 	// Ruta para obtener periféricos por equipo
-	equipos.GET("/:equipoId/perifericos", perifericoController.GetPerifericosByEquipo)
+	equipos.GET("/:equipoId/perifericos", perifericoController.GetPerifericosByEquipo, permiso("perifericos", middleware.AccionLeer))
 
 	// Rutas para Software
-	software := api.Group("/software")
-	software.POST("", softwareController.CreateSoftware)
-	software.GET("", softwareController.GetAllSoftware)
-	software.GET("/:id", softwareController.GetSoftware)
-	software.PUT("/:id", softwareController.UpdateSoftware)
-	software.DELETE("/:id", softwareController.DeleteSoftware)
+	software := api.Group("/software", jwtMiddleware.Authenticate)
+	software.POST("", softwareController.CreateSoftware, permiso("software", middleware.AccionCrear))
+	software.GET("", softwareController.GetAllSoftware, permiso("software", middleware.AccionLeer))
+	software.GET("/exportar", exportacionController.ExportarSoftware, permiso("software", middleware.AccionLeer))
+	software.GET("/:id", softwareController.GetSoftware, permiso("software", middleware.AccionLeer))
+	software.PUT("/:id", softwareController.UpdateSoftware, permiso("software", middleware.AccionActualizar))
+	software.DELETE("/:id", softwareController.DeleteSoftware, permiso("software", middleware.AccionEliminar))
 
 	// Ruta para obtener periféricos por equipo
-	equipos.GET("/:equipoId/software", softwareController.GetAllSoftwareByEquipo)
+	equipos.GET("/:equipoId/software", softwareController.GetAllSoftwareByEquipo, permiso("software", middleware.AccionLeer))
 
 	// Rutas para Usuarios Responsables
-	usuariosResponsables := api.Group("/usuarios-responsables")
-	usuariosResponsables.POST("", usuarioResponsableController.CreateUsuarioResponsable)
-	usuariosResponsables.GET("", usuarioResponsableController.GetAllUsuariosResponsables)
-	usuariosResponsables.GET("/buscar", usuarioResponsableController.GetUsuarioResponsableByCedula)
-	usuariosResponsables.GET("/:id", usuarioResponsableController.GetUsuarioResponsable)
-	usuariosResponsables.PUT("/:id", usuarioResponsableController.UpdateUsuarioResponsable)
-	usuariosResponsables.DELETE("/:id", usuarioResponsableController.DeleteUsuarioResponsable)
+	usuariosResponsables := api.Group("/usuarios-responsables", jwtMiddleware.Authenticate)
+	usuariosResponsables.POST("", usuarioResponsableController.CreateUsuarioResponsable, permiso("usuarios-responsables", middleware.AccionCrear))
+	usuariosResponsables.GET("", usuarioResponsableController.GetAllUsuariosResponsables, permiso("usuarios-responsables", middleware.AccionLeer))
+	usuariosResponsables.GET("/buscar", usuarioResponsableController.GetUsuarioResponsableByCedula, permiso("usuarios-responsables", middleware.AccionLeer))
+	usuariosResponsables.GET("/:id", usuarioResponsableController.GetUsuarioResponsable, permiso("usuarios-responsables", middleware.AccionLeer))
+	usuariosResponsables.PUT("/:id", usuarioResponsableController.UpdateUsuarioResponsable, permiso("usuarios-responsables", middleware.AccionActualizar))
+	usuariosResponsables.DELETE("/:id", usuarioResponsableController.DeleteUsuarioResponsable, permiso("usuarios-responsables", middleware.AccionEliminar))
 	// Ruta para asignar una dependencia a un usuario responsable (solo cambia el FK)
-	usuariosResponsables.PATCH("/:id/asignar-dependencia", usuarioResponsableController.AsignarDependencia)
+	usuariosResponsables.PATCH("/:id/asignar-dependencia", usuarioResponsableController.AsignarDependencia, permiso("usuarios-responsables", middleware.AccionAsignar))
+	// Ruta para obtener los equipos que ha tenido a cargo el usuario responsable
+	usuariosResponsables.GET("/:id/asignaciones", equipoController.GetAsignacionesByResponsable, permiso("usuarios-responsables", middleware.AccionLeer))
 	// usuariosResponsables.GET("/:id/equipos", usuarioResponsableController.GetEquiposByUsuarioResponsable)
-	usuariosResponsables.GET("/:dependenciaId/dependencia", usuarioResponsableController.GetUsuariosByDependencia)
+	usuariosResponsables.GET("/:dependenciaId/dependencia", usuarioResponsableController.GetUsuariosByDependencia, permiso("usuarios-responsables", middleware.AccionLeer))
 
 	// Rutas para Hardware Interno
-	hardwareInterno := api.Group("/hardware-interno")
-	hardwareInterno.POST("", hardwareInternoController.CreateHardwareInterno)
-	hardwareInterno.GET("", hardwareInternoController.GetAllHardwareInterno)
-	hardwareInterno.GET("/:id", hardwareInternoController.GetHardwareInterno)
-	hardwareInterno.PUT("/:id", hardwareInternoController.UpdateHardwareInterno)
-	hardwareInterno.DELETE("/:id", hardwareInternoController.DeleteHardwareInterno)
+	hardwareInterno := api.Group("/hardware-interno", jwtMiddleware.Authenticate)
+	hardwareInterno.POST("", hardwareInternoController.CreateHardwareInterno, permiso("hardware-interno", middleware.AccionCrear))
+	hardwareInterno.GET("", hardwareInternoController.GetAllHardwareInterno, permiso("hardware-interno", middleware.AccionLeer))
+	hardwareInterno.GET("/:id", hardwareInternoController.GetHardwareInterno, permiso("hardware-interno", middleware.AccionLeer))
+	hardwareInterno.PUT("/:id", hardwareInternoController.UpdateHardwareInterno, permiso("hardware-interno", middleware.AccionActualizar))
+	hardwareInterno.DELETE("/:id", hardwareInternoController.DeleteHardwareInterno, permiso("hardware-interno", middleware.AccionEliminar))
 
 	// Ruta para obtener hardware interno por equipo
-	equipos.GET("/:equipoId/hardware-interno", hardwareInternoController.GetHardwareInternoByEquipo)
+	equipos.GET("/:equipoId/hardware-interno", hardwareInternoController.GetHardwareInternoByEquipo, permiso("hardware-interno", middleware.AccionLeer))
 
 	// Rutas para Configuración de Red
-	configuracionesRed := api.Group("/configuraciones-red")
-	configuracionesRed.POST("", configuracionRedController.CreateConfiguracionRed)
-	configuracionesRed.GET("", configuracionRedController.GetAllConfiguracionesRed)
-	configuracionesRed.GET("/:id", configuracionRedController.GetConfiguracionRed)
-	configuracionesRed.PUT("/:id", configuracionRedController.UpdateConfiguracionRed)
-	configuracionesRed.DELETE("/:id", configuracionRedController.DeleteConfiguracionRed)
+	configuracionesRed := api.Group("/configuraciones-red", jwtMiddleware.Authenticate)
+	configuracionesRed.POST("", configuracionRedController.CreateConfiguracionRed, permiso("configuraciones-red", middleware.AccionCrear))
+	configuracionesRed.GET("", configuracionRedController.GetAllConfiguracionesRed, permiso("configuraciones-red", middleware.AccionLeer))
+	configuracionesRed.GET("/:id", configuracionRedController.GetConfiguracionRed, permiso("configuraciones-red", middleware.AccionLeer))
+	configuracionesRed.PUT("/:id", configuracionRedController.UpdateConfiguracionRed, permiso("configuraciones-red", middleware.AccionActualizar))
+	configuracionesRed.DELETE("/:id", configuracionRedController.DeleteConfiguracionRed, permiso("configuraciones-red", middleware.AccionEliminar))
 
 	// Ruta para obtener configuración de red por equipo
-	equipos.GET("/:equipoId/configuracion-red", configuracionRedController.GetConfiguracionRedByEquipo)
+	equipos.GET("/:equipoId/configuracion-red", configuracionRedController.GetConfiguracionRedByEquipo, permiso("configuraciones-red", middleware.AccionLeer))
 
 	// Rutas para Usuarios del Sistema
-	usuariosSistema := api.Group("/usuarios-sistema")
-	usuariosSistema.POST("", usuarioSistemaController.CreateUsuarioSistema)
-	usuariosSistema.GET("", usuarioSistemaController.GetAllUsuariosSistema)
-	usuariosSistema.GET("/buscar", usuarioSistemaController.GetUsuarioSistemaByNombreUsuario)
-	usuariosSistema.GET("/:id", usuarioSistemaController.GetUsuarioSistema)
-	usuariosSistema.PUT("/:id", usuarioSistemaController.UpdateUsuarioSistema)
-	usuariosSistema.DELETE("/:id", usuarioSistemaController.DeleteUsuarioSistema)
+	usuariosSistema := api.Group("/usuarios-sistema", jwtMiddleware.Authenticate)
+	usuariosSistema.POST("", usuarioSistemaController.CreateUsuarioSistema, permiso("usuarios-sistema", middleware.AccionCrear))
+	usuariosSistema.GET("", usuarioSistemaController.GetAllUsuariosSistema, permiso("usuarios-sistema", middleware.AccionLeer))
+	usuariosSistema.GET("/buscar", usuarioSistemaController.GetUsuarioSistemaByNombreUsuario, permiso("usuarios-sistema", middleware.AccionLeer))
+	usuariosSistema.GET("/:id", usuarioSistemaController.GetUsuarioSistema, permiso("usuarios-sistema", middleware.AccionLeer))
+	usuariosSistema.PUT("/:id", usuarioSistemaController.UpdateUsuarioSistema, permiso("usuarios-sistema", middleware.AccionActualizar))
+	usuariosSistema.DELETE("/:id", usuarioSistemaController.DeleteUsuarioSistema, permiso("usuarios-sistema", middleware.AccionEliminar))
+	// Ruta para revelar la contraseña descifrada (solo admin, queda registrada)
+	usuariosSistema.GET("/:id/contrasena", usuarioSistemaController.RevelarContrasena, permiso("usuarios-sistema", middleware.AccionRevelar))
 
 	// Ruta para obtener usuarios del sistema por equipo
-	equipos.GET("/:equipoId/usuarios-sistema", usuarioSistemaController.GetUsuariosSistemaByEquipo)
+	equipos.GET("/:equipoId/usuarios-sistema", usuarioSistemaController.GetUsuariosSistemaByEquipo, permiso("usuarios-sistema", middleware.AccionLeer))
 
 	// Rutas para Accesos Remotos
-	accesosRemotos := api.Group("/accesos-remotos")
-	accesosRemotos.POST("", accesoRemotoController.CreateAccesoRemoto)
-	accesosRemotos.GET("", accesoRemotoController.GetAllAccesosRemotos)
-	accesosRemotos.GET("/:id", accesoRemotoController.GetAccesoRemoto)
-	accesosRemotos.PUT("/:id", accesoRemotoController.UpdateAccesoRemoto)
-	accesosRemotos.DELETE("/:id", accesoRemotoController.DeleteAccesoRemoto)
+	accesosRemotos := api.Group("/accesos-remotos", jwtMiddleware.Authenticate)
+	accesosRemotos.POST("", accesoRemotoController.CreateAccesoRemoto, permiso("accesos-remotos", middleware.AccionCrear))
+	accesosRemotos.GET("", accesoRemotoController.GetAllAccesosRemotos, permiso("accesos-remotos", middleware.AccionLeer))
+	accesosRemotos.GET("/:id", accesoRemotoController.GetAccesoRemoto, permiso("accesos-remotos", middleware.AccionLeer))
+	accesosRemotos.PUT("/:id", accesoRemotoController.UpdateAccesoRemoto, permiso("accesos-remotos", middleware.AccionActualizar))
+	accesosRemotos.DELETE("/:id", accesoRemotoController.DeleteAccesoRemoto, permiso("accesos-remotos", middleware.AccionEliminar))
+	// Ruta para revelar la contraseña descifrada (solo admin, queda registrada)
+	accesosRemotos.GET("/:id/contrasena", accesoRemotoController.RevelarContrasena, permiso("accesos-remotos", middleware.AccionRevelar))
 
 	// Ruta para obtener accesos remotos por equipo
-	equipos.GET("/:equipoId/accesos-remotos", accesoRemotoController.GetAccesosRemotosByEquipo)
+	equipos.GET("/:equipoId/accesos-remotos", accesoRemotoController.GetAccesosRemotosByEquipo, permiso("accesos-remotos", middleware.AccionLeer))
 
 	// Rutas para Backups
-	backups := api.Group("/backups")
-	backups.POST("", backupController.CreateBackup)
-	backups.GET("", backupController.GetAllBackups)
-	backups.GET("/:id", backupController.GetBackup)
-	backups.PUT("/:id", backupController.UpdateBackup)
-	backups.DELETE("/:id", backupController.DeleteBackup)
+	backups := api.Group("/backups", jwtMiddleware.Authenticate)
+	backups.POST("", backupController.CreateBackup, permiso("backups", middleware.AccionCrear))
+	backups.GET("", backupController.GetAllBackups, permiso("backups", middleware.AccionLeer))
+	backups.GET("/:id", backupController.GetBackup, permiso("backups", middleware.AccionLeer))
+	backups.PUT("/:id", backupController.UpdateBackup, permiso("backups", middleware.AccionActualizar))
+	backups.DELETE("/:id", backupController.DeleteBackup, permiso("backups", middleware.AccionEliminar))
 
 	// Ruta para obtener backups por equipo
-	equipos.GET("/:equipoId/backups", backupController.GetBackupsByEquipo)
+	equipos.GET("/:equipoId/backups", backupController.GetBackupsByEquipo, permiso("backups", middleware.AccionLeer))
 
 	// Rutas para Reportes de Servicio
-	reportesServicio := api.Group("/reportes-servicio")
-	reportesServicio.POST("", reporteServicioController.CreateReporteServicio)
-	reportesServicio.POST("/completo", reporteServicioController.CrearReporteConTipo)
-	reportesServicio.GET("", reporteServicioController.GetAllReportesServicio)
-	reportesServicio.GET("/:id", reporteServicioController.GetReporteServicio)
-	reportesServicio.PUT("/:id", reporteServicioController.UpdateReporteServicio)
-	reportesServicio.DELETE("/:id", reporteServicioController.DeleteReporteServicio)
+	reportesServicio := api.Group("/reportes-servicio", jwtMiddleware.Authenticate)
+	reportesServicio.POST("", reporteServicioController.CreateReporteServicio, permiso("reportes-servicio", middleware.AccionCrear))
+	reportesServicio.POST("/completo", reporteServicioController.CrearReporteConTipo, permiso("reportes-servicio", middleware.AccionCrear))
+	reportesServicio.GET("", reporteServicioController.GetAllReportesServicio, permiso("reportes-servicio", middleware.AccionLeer))
+	reportesServicio.GET("/exportar", exportacionController.ExportarReportesServicio, permiso("reportes-servicio", middleware.AccionLeer))
+	// PDF de varios reportes (ZIP o un solo PDF) con los filtros del listado
+	reportesServicio.GET("/pdf-lote", loteReportesController.DescargarLote, permiso("reportes-servicio", middleware.AccionLeer))
+	reportesServicio.GET("/sla", slaController.GetCumplimiento, permiso("sla", middleware.AccionLeer))
+	reportesServicio.GET("/:id", reporteServicioController.GetReporteServicio, permiso("reportes-servicio", middleware.AccionLeer))
+	reportesServicio.PUT("/:id", reporteServicioController.UpdateReporteServicio, permiso("reportes-servicio", middleware.AccionActualizar))
+	reportesServicio.DELETE("/:id", reporteServicioController.DeleteReporteServicio, permiso("reportes-servicio", middleware.AccionEliminar))
 	// Rutas para generar PDF del reporte
-	reportesServicio.GET("/:id/pdf", pdfController.GenerarReportePDF)
-	reportesServicio.GET("/:id/pdf/view", pdfController.VisualizarReportePDF)
-	reportesServicio.POST("/:id/subir-firmado", reporteServicioController.SubirFirmado)
-	reportesServicio.GET("/:id/descargar-firmado", reporteServicioController.DescargarFirmado)
-	reportesServicio.POST("/:id/reabrir", reporteServicioController.ReabrirReporte)
+	reportesServicio.GET("/:id/pdf", pdfController.GenerarReportePDF, permiso("reportes-servicio", middleware.AccionLeer))
+	reportesServicio.GET("/:id/pdf/view", pdfController.VisualizarReportePDF, permiso("reportes-servicio", middleware.AccionLeer))
+	reportesServicio.POST("/:id/subir-firmado", reporteServicioController.SubirFirmado, permiso("reportes-servicio", middleware.AccionCerrar))
+	// Subida directa del firmado al bucket S3 con una URL PUT prefirmada
+	reportesServicio.POST("/:id/subida-firmado", reporteServicioController.PrepararSubidaFirmado, permiso("reportes-servicio", middleware.AccionCerrar))
+	reportesServicio.POST("/:id/subida-firmado/confirmar", reporteServicioController.ConfirmarSubidaFirmado, permiso("reportes-servicio", middleware.AccionCerrar))
+	reportesServicio.GET("/:id/descargar-firmado", reporteServicioController.DescargarFirmado, permiso("reportes-servicio", middleware.AccionLeer))
+	// Firma digital en el servidor (alternativa a subir-firmado) y verificación de firmas
+	reportesServicio.POST("/:id/firmar", firmaDigitalController.FirmarReporte, permiso("reportes-servicio", middleware.AccionCerrar))
+	reportesServicio.POST("/verificar-firma", firmaDigitalController.VerificarFirma, permiso("reportes-servicio", middleware.AccionLeer))
+	// Historial de versiones del PDF firmado (se conservan al reabrir)
+	reportesServicio.GET("/:id/documentos", reporteServicioController.GetDocumentosFirmados, permiso("reportes-servicio", middleware.AccionLeer))
+	reportesServicio.GET("/:id/documentos/:version/descargar", reporteServicioController.DescargarDocumentoFirmado, permiso("reportes-servicio", middleware.AccionLeer))
+	reportesServicio.POST("/:id/reabrir", reporteServicioController.ReabrirReporte, permiso("reportes-servicio", middleware.AccionReabrir))
+	reportesServicio.GET("/:id/sla", slaController.GetReporteSLA, permiso("sla", middleware.AccionLeer))
+
+	// Políticas de SLA: plazos de resolución y de firma por tipo de mantenimiento y prioridad
+	politicasSLA := api.Group("/sla/politicas", jwtMiddleware.Authenticate)
+	politicasSLA.GET("", slaController.GetAllPoliticas, permiso("sla", middleware.AccionLeer))
+	politicasSLA.POST("", slaController.CreatePolitica, permiso("sla", middleware.AccionCrear))
+	politicasSLA.GET("/:id", slaController.GetPolitica, permiso("sla", middleware.AccionLeer))
+	politicasSLA.PUT("/:id", slaController.UpdatePolitica, permiso("sla", middleware.AccionActualizar))
+	politicasSLA.DELETE("/:id", slaController.DeletePolitica, permiso("sla", middleware.AccionEliminar))
 
 	// Ruta para obtener reportes de servicio por equipo
-	equipos.GET("/:equipoId/reportes-servicio", reporteServicioController.GetReportesServicioByEquipo)
+	equipos.GET("/:equipoId/reportes-servicio", reporteServicioController.GetReportesServicioByEquipo, permiso("reportes-servicio", middleware.AccionLeer))
 	// Ruta para obtener resumen de reportes de servicio por equipo
-	equipos.GET("/:equipoId/reportes-servicio/resumen", reporteServicioController.GetReportesResumenByEquipo)
+	equipos.GET("/:equipoId/reportes-servicio/resumen", reporteServicioController.GetReportesResumenByEquipo, permiso("reportes-servicio", middleware.AccionLeer))
+
+	// Rutas para Plantillas de PDF (encabezado, logos y pie de los PDF institucionales)
+	plantillasPDF := api.Group("/plantillas-pdf", jwtMiddleware.Authenticate)
+	plantillasPDF.GET("", plantillaPDFController.GetAllPlantillas, permiso("plantillas-pdf", middleware.AccionLeer))
+	plantillasPDF.POST("", plantillaPDFController.CreatePlantilla, permiso("plantillas-pdf", middleware.AccionCrear))
+	plantillasPDF.GET("/predeterminada", plantillaPDFController.GetPlantillaPredeterminada, permiso("plantillas-pdf", middleware.AccionLeer))
+	plantillasPDF.POST("/vista-previa", plantillaPDFController.VistaPreviaDefinicion, permiso("plantillas-pdf", middleware.AccionLeer))
+	plantillasPDF.POST("/logos", plantillaPDFController.SubirLogo, permiso("plantillas-pdf", middleware.AccionCrear))
+	plantillasPDF.GET("/:id", plantillaPDFController.GetPlantilla, permiso("plantillas-pdf", middleware.AccionLeer))
+	plantillasPDF.PUT("/:id", plantillaPDFController.UpdatePlantilla, permiso("plantillas-pdf", middleware.AccionActualizar))
+	plantillasPDF.DELETE("/:id", plantillaPDFController.DeletePlantilla, permiso("plantillas-pdf", middleware.AccionEliminar))
+	plantillasPDF.POST("/:id/activar", plantillaPDFController.ActivarPlantilla, permiso("plantillas-pdf", middleware.AccionActualizar))
+	plantillasPDF.GET("/:id/vista-previa", plantillaPDFController.VistaPreviaPlantilla, permiso("plantillas-pdf", middleware.AccionLeer))
+
+	// Trabajos en segundo plano (exportaciones, importaciones y PDF por lote que superan el tiempo de espera)
+	trabajos := api.Group("/jobs", jwtMiddleware.Authenticate)
+	trabajos.POST("", trabajoController.CrearTrabajo, permiso("jobs", middleware.AccionCrear))
+	trabajos.GET("/:id", trabajoController.GetTrabajo, permiso("jobs", middleware.AccionLeer))
+	trabajos.GET("/:id/descargar", trabajoController.DescargarResultado, permiso("jobs", middleware.AccionLeer))
+
+	// Mantenimiento preventivo: planes por tipo de dispositivo o por equipo y equipos pendientes
+	mantenimientos := api.Group("/mantenimientos", jwtMiddleware.Authenticate)
+	mantenimientos.GET("/pendientes", mantenimientoController.GetPendientes, permiso("mantenimientos", middleware.AccionLeer))
+	mantenimientos.GET("/equipos", mantenimientoController.GetEquipos, permiso("mantenimientos", middleware.AccionLeer))
+	mantenimientos.GET("/planes", mantenimientoController.GetAllPlanes, permiso("mantenimientos", middleware.AccionLeer))
+	mantenimientos.POST("/planes", mantenimientoController.CreatePlan, permiso("mantenimientos", middleware.AccionCrear))
+	mantenimientos.GET("/planes/:id", mantenimientoController.GetPlan, permiso("mantenimientos", middleware.AccionLeer))
+	mantenimientos.PUT("/planes/:id", mantenimientoController.UpdatePlan, permiso("mantenimientos", middleware.AccionActualizar))
+	mantenimientos.DELETE("/planes/:id", mantenimientoController.DeletePlan, permiso("mantenimientos", middleware.AccionEliminar))
 
 	// Rutas para Tipos de Mantenimiento
-	tiposMantenimiento := api.Group("/tipos-mantenimiento")
-	tiposMantenimiento.POST("", tipoMantenimientoController.CreateTipoMantenimiento)
-	tiposMantenimiento.GET("", tipoMantenimientoController.GetAllTiposMantenimiento)
-	tiposMantenimiento.GET("/:id", tipoMantenimientoController.GetTipoMantenimiento)
-	tiposMantenimiento.PUT("/:id", tipoMantenimientoController.UpdateTipoMantenimiento)
-	tiposMantenimiento.DELETE("/:id", tipoMantenimientoController.DeleteTipoMantenimiento)
+	tiposMantenimiento := api.Group("/tipos-mantenimiento", jwtMiddleware.Authenticate)
+	tiposMantenimiento.POST("", tipoMantenimientoController.CreateTipoMantenimiento, permiso("tipos-mantenimiento", middleware.AccionCrear))
+	tiposMantenimiento.GET("", tipoMantenimientoController.GetAllTiposMantenimiento, permiso("tipos-mantenimiento", middleware.AccionLeer))
+	tiposMantenimiento.GET("/:id", tipoMantenimientoController.GetTipoMantenimiento, permiso("tipos-mantenimiento", middleware.AccionLeer))
+	tiposMantenimiento.PUT("/:id", tipoMantenimientoController.UpdateTipoMantenimiento, permiso("tipos-mantenimiento", middleware.AccionActualizar))
+	tiposMantenimiento.DELETE("/:id", tipoMantenimientoController.DeleteTipoMantenimiento, permiso("tipos-mantenimiento", middleware.AccionEliminar))
 
 	// Ruta para obtener tipos de mantenimiento por reporte
-	reportesServicio.GET("/:reporteId/tipos-mantenimiento", tipoMantenimientoController.GetTiposMantenimientoByReporte)
+	reportesServicio.GET("/:reporteId/tipos-mantenimiento", tipoMantenimientoController.GetTiposMantenimientoByReporte, permiso("tipos-mantenimiento", middleware.AccionLeer))
 
 	// Rutas para Repuestos
-	repuestos := api.Group("/repuestos")
-	repuestos.POST("", repuestoController.CreateRepuesto)
-	repuestos.GET("", repuestoController.GetAllRepuestos)
-	repuestos.GET("/:id", repuestoController.GetRepuesto)
-	repuestos.PUT("/:id", repuestoController.UpdateRepuesto)
-	repuestos.DELETE("/:id", repuestoController.DeleteRepuesto)
+	repuestos := api.Group("/repuestos", jwtMiddleware.Authenticate)
+	repuestos.POST("", repuestoController.CreateRepuesto, permiso("repuestos", middleware.AccionCrear))
+	repuestos.GET("", repuestoController.GetAllRepuestos, permiso("repuestos", middleware.AccionLeer))
+	repuestos.GET("/:id", repuestoController.GetRepuesto, permiso("repuestos", middleware.AccionLeer))
+	repuestos.PUT("/:id", repuestoController.UpdateRepuesto, permiso("repuestos", middleware.AccionActualizar))
+	repuestos.DELETE("/:id", repuestoController.DeleteRepuesto, permiso("repuestos", middleware.AccionEliminar))
+
+	// Almacén de repuestos: catálogo con existencias, movimientos y kardex. Los repuestos de
+	// los reportes que indican un artículo (o cuyo serial está en el catálogo) descuentan del stock
+	almacen := api.Group("/almacen/articulos", jwtMiddleware.Authenticate)
+	almacen.GET("", almacenController.GetAllArticulos, permiso("almacen", middleware.AccionLeer))
+	almacen.POST("", almacenController.CreateArticulo, permiso("almacen", middleware.AccionCrear))
+	almacen.GET("/bajo-stock", almacenController.GetBajoStock, permiso("almacen", middleware.AccionLeer))
+	almacen.GET("/:id", almacenController.GetArticulo, permiso("almacen", middleware.AccionLeer))
+	almacen.PUT("/:id", almacenController.UpdateArticulo, permiso("almacen", middleware.AccionActualizar))
+	almacen.DELETE("/:id", almacenController.DeleteArticulo, permiso("almacen", middleware.AccionEliminar))
+	almacen.POST("/:id/movimientos", almacenController.RegistrarMovimiento, permiso("almacen", middleware.AccionActualizar))
+	almacen.GET("/:id/kardex", almacenController.GetKardex, permiso("almacen", middleware.AccionLeer))
+
+	// Solicitudes de servicio: abierta → asignada → en-progreso → resuelta (o cancelada). El rol
+	// usuario las abre y consulta solo para su dependencia; al resolverlas queda el reporte de servicio
+	solicitudes := api.Group("/solicitudes-servicio", jwtMiddleware.Authenticate)
+	solicitudes.POST("", solicitudServicioController.CreateSolicitud, permiso("solicitudes-servicio", middleware.AccionCrear))
+	solicitudes.GET("", solicitudServicioController.GetAllSolicitudes, permiso("solicitudes-servicio", middleware.AccionLeer))
+	solicitudes.GET("/:id", solicitudServicioController.GetSolicitud, permiso("solicitudes-servicio", middleware.AccionLeer))
+	solicitudes.POST("/:id/asignar", solicitudServicioController.AsignarSolicitud, permiso("solicitudes-servicio", middleware.AccionAsignar))
+	solicitudes.POST("/:id/iniciar", solicitudServicioController.IniciarSolicitud, permiso("solicitudes-servicio", middleware.AccionActualizar))
+	solicitudes.POST("/:id/resolver", solicitudServicioController.ResolverSolicitud, permiso("solicitudes-servicio", middleware.AccionCerrar))
+	solicitudes.POST("/:id/cancelar", solicitudServicioController.CancelarSolicitud, permiso("solicitudes-servicio", middleware.AccionCancelar))
+
+	// Centro de notificaciones del usuario autenticado. El stream (server-sent events) queda
+	// fuera del tiempo de espera de las peticiones (ver server.go)
+	notificaciones := api.Group("/notificaciones", jwtMiddleware.Authenticate)
+	notificaciones.GET("", notificacionController.GetNotificaciones, permiso("notificaciones", middleware.AccionLeer))
+	notificaciones.GET("/no-leidas", notificacionController.ContarNoLeidas, permiso("notificaciones", middleware.AccionLeer))
+	notificaciones.GET("/stream", notificacionController.Stream, permiso("notificaciones", middleware.AccionLeer))
+	notificaciones.POST("/leer-todas", notificacionController.MarcarTodasLeidas, permiso("notificaciones", middleware.AccionActualizar))
+	notificaciones.POST("/:id/leer", notificacionController.MarcarLeida, permiso("notificaciones", middleware.AccionActualizar))
 
 	// Ruta para obtener repuestos por reporte
-	reportesServicio.GET("/:reporteId/repuestos", repuestoController.GetRepuestosByReporte)
+	reportesServicio.GET("/:reporteId/repuestos", repuestoController.GetRepuestosByReporte, permiso("repuestos", middleware.AccionLeer))
 
 	// Rutas para Secretarías/s
-	secretarias := api.Group("/secretarias")
-	secretarias.POST("", secretariaController.CreateSecretaria)
-	secretarias.GET("", secretariaController.GetAllSecretarias)
-	secretarias.GET("/:id", secretariaController.GetSecretaria)
-	secretarias.PUT("/:id", secretariaController.UpdateSecretaria)
-	secretarias.DELETE("/:id", secretariaController.DeleteSecretaria)
-	secretarias.GET("/:id/dependencias", secretariaController.GetDependenciasBySecretaria)
+	secretarias := api.Group("/secretarias", jwtMiddleware.Authenticate)
+	secretarias.POST("", secretariaController.CreateSecretaria, permiso("secretarias", middleware.AccionCrear))
+	secretarias.GET("", secretariaController.GetAllSecretarias, permiso("secretarias", middleware.AccionLeer))
+	secretarias.GET("/:id", secretariaController.GetSecretaria, permiso("secretarias", middleware.AccionLeer))
+	secretarias.PUT("/:id", secretariaController.UpdateSecretaria, permiso("secretarias", middleware.AccionActualizar))
+	secretarias.DELETE("/:id", secretariaController.DeleteSecretaria, permiso("secretarias", middleware.AccionEliminar))
+	secretarias.GET("/:id/dependencias", secretariaController.GetDependenciasBySecretaria, permiso("dependencias", middleware.AccionLeer))
 
 	// Rutas para Dependencias
-	dependencias := api.Group("/dependencias")
-	dependencias.POST("", dependenciaController.CreateDependencia)
-	dependencias.GET("", dependenciaController.GetAllDependencias)
-	dependencias.GET("/:id", dependenciaController.GetDependencia)
-	dependencias.PUT("/:id", dependenciaController.UpdateDependencia)
-	dependencias.DELETE("/:id", dependenciaController.DeleteDependencia)
-	dependencias.GET("/:id/usuarios", dependenciaController.GetUsuariosByDependencia)
+	dependencias := api.Group("/dependencias", jwtMiddleware.Authenticate)
+	dependencias.POST("", dependenciaController.CreateDependencia, permiso("dependencias", middleware.AccionCrear))
+	dependencias.GET("", dependenciaController.GetAllDependencias, permiso("dependencias", middleware.AccionLeer))
+	dependencias.GET("/:id", dependenciaController.GetDependencia, permiso("dependencias", middleware.AccionLeer))
+	dependencias.PUT("/:id", dependenciaController.UpdateDependencia, permiso("dependencias", middleware.AccionActualizar))
+	dependencias.DELETE("/:id", dependenciaController.DeleteDependencia, permiso("dependencias", middleware.AccionEliminar))
+	dependencias.GET("/:id/usuarios", dependenciaController.GetUsuariosByDependencia, permiso("usuarios-responsables", middleware.AccionLeer))
+	dependencias.GET("/:id/hojas-vida", pdfController.DescargarHojasVidaDependencia, permiso("equipos", middleware.AccionLeer))
 	// dependencias.GET("/:id/equipos", dependenciaController.GetEquiposByDependencia)
 
 	// Ruta para obtener dependencias por secretaría
-	secretarias.GET("/:secretariaId/dependencias", dependenciaController.GetDependenciasBySecretaria)
+	secretarias.GET("/:secretariaId/dependencias", dependenciaController.GetDependenciasBySecretaria, permiso("dependencias", middleware.AccionLeer))
 
 	// Rutas para Estados de Equipo
-	estadosEquipo := api.Group("/estados-equipo")
-	estadosEquipo.POST("", estadoEquipoController.CreateEstado)
-	estadosEquipo.GET("", estadoEquipoController.GetAllEstados)
-	estadosEquipo.GET("/activos", estadoEquipoController.GetActiveEstados)
-	estadosEquipo.GET("/:id", estadoEquipoController.GetEstadoByID)
-	estadosEquipo.PUT("/:id", estadoEquipoController.UpdateEstado)
-	estadosEquipo.DELETE("/:id", estadoEquipoController.DeleteEstado)
-	estadosEquipo.PATCH("/:id/toggle-activo", estadoEquipoController.ToggleActivo)
-	estadosEquipo.GET("/:id/equipos", estadoEquipoController.GetEquiposByEstado)
+	estadosEquipo := api.Group("/estados-equipo", jwtMiddleware.Authenticate)
+	estadosEquipo.POST("", estadoEquipoController.CreateEstado, permiso("estados-equipo", middleware.AccionCrear))
+	estadosEquipo.GET("", estadoEquipoController.GetAllEstados, permiso("estados-equipo", middleware.AccionLeer))
+	estadosEquipo.GET("/activos", estadoEquipoController.GetActiveEstados, permiso("estados-equipo", middleware.AccionLeer))
+	estadosEquipo.GET("/:id", estadoEquipoController.GetEstadoByID, permiso("estados-equipo", middleware.AccionLeer))
+	estadosEquipo.PUT("/:id", estadoEquipoController.UpdateEstado, permiso("estados-equipo", middleware.AccionActualizar))
+	estadosEquipo.DELETE("/:id", estadoEquipoController.DeleteEstado, permiso("estados-equipo", middleware.AccionEliminar))
+	estadosEquipo.PATCH("/:id/toggle-activo", estadoEquipoController.ToggleActivo, permiso("estados-equipo", middleware.AccionActualizar))
+	estadosEquipo.GET("/:id/equipos", estadoEquipoController.GetEquiposByEstado, permiso("equipos", middleware.AccionLeer))
+
+	// Auditoría de cambios (solo admin)
+	auditoria := api.Group("/auditoria", jwtMiddleware.Authenticate)
+	auditoria.GET("", auditoriaController.GetAuditoria, permiso("auditoria", middleware.AccionLeer))
 }
@@ -0,0 +1,64 @@
+package models
+
+// ComponentesActa son los datos del equipo y sus componentes tal como constan en un acta de
+// entrega, para que el acta no cambie cuando después se modifica el equipo
+type ComponentesActa struct {
+	TipoDispositivo string
+	PlacaInventario string
+	Marca           string
+	Modelo          string
+	Serial          string
+	Estado          string
+	Perifericos     []PerifericoActa
+	HardwareInterno []HardwareActa
+	Software        []SoftwareActa
+}
+
+// PerifericoActa es un periférico en el acta de entrega
+type PerifericoActa struct {
+	TipoPeriferico  string
+	Marca           string
+	Serial          string
+	PlacaInventario string
+}
+
+// HardwareActa es un componente de hardware interno en el acta de entrega
+type HardwareActa struct {
+	Componente string
+	Tecnologia string
+	Capacidad  string
+}
+
+// SoftwareActa es un software instalado en el acta de entrega
+type SoftwareActa struct {
+	Nombre       string
+	Version      string
+	TipoLicencia string
+	Categoria    string
+}
+
+// NuevosComponentesActa toma los datos del equipo, que debe tener cargados su estado,
+// periféricos, hardware interno y software
+func NuevosComponentesActa(equipo *Equipo) *ComponentesActa {
+	componentes := &ComponentesActa{
+		TipoDispositivo: equipo.TipoDispositivo,
+		PlacaInventario: equipo.PlacaInventario,
+		Marca:           equipo.Marca,
+		Modelo:          equipo.Modelo,
+		Serial:          equipo.Serial,
+		Estado:          equipo.EstadoEquipo.Nombre,
+		Perifericos:     make([]PerifericoActa, 0, len(equipo.Perifericos)),
+		HardwareInterno: make([]HardwareActa, 0, len(equipo.HardwareInterno)),
+		Software:        make([]SoftwareActa, 0, len(equipo.Software)),
+	}
+	for _, p := range equipo.Perifericos {
+		componentes.Perifericos = append(componentes.Perifericos, PerifericoActa{TipoPeriferico: p.TipoPeriferico, Marca: p.Marca, Serial: p.Serial, PlacaInventario: p.PlacaInventario})
+	}
+	for _, h := range equipo.HardwareInterno {
+		componentes.HardwareInterno = append(componentes.HardwareInterno, HardwareActa{Componente: h.Componente, Tecnologia: h.Tecnologia, Capacidad: h.Capacidad})
+	}
+	for _, sw := range equipo.Software {
+		componentes.Software = append(componentes.Software, SoftwareActa{Nombre: sw.Nombre, Version: sw.Version, TipoLicencia: sw.TipoLicencia, Categoria: sw.Categoria})
+	}
+	return componentes
+}
@@ -0,0 +1,54 @@
+package models
+
+import "gorm.io/gorm"
+
+// Tipos de movimiento del almacén de repuestos
+const (
+	MovimientoEntrada = "entrada" // Compra, inventario inicial o devolución de un reporte
+	MovimientoSalida  = "salida"  // Uso en un reporte de servicio o baja del repuesto
+	MovimientoAjuste  = "ajuste"  // Corrección tras un conteo físico; la cantidad lleva signo
+)
+
+// ArticuloRepuesto es un repuesto del catálogo del almacén con sus existencias. Los
+// Repuesto de los reportes que lo usan descuentan de Stock. El serial es único entre los
+// artículos no eliminados.
+type ArticuloRepuesto struct {
+	gorm.Model
+	SerialNumeroParte string `gorm:"uniqueIndex:idx_articulos_repuesto_serial_vigente,where:deleted_at IS NULL;not null"`
+	Descripcion       string `gorm:"not null"`
+	Marca             string
+	Tecnologia        string
+	Capacidad         string
+	Stock             int `gorm:"not null;default:0;check:stock >= 0"` // Solo cambia con movimientos
+	StockMinimo       int `gorm:"not null;default:0;check:stock_minimo >= 0"`
+}
+
+// TableName fija el nombre de la tabla del catálogo del almacén
+func (ArticuloRepuesto) TableName() string {
+	return "articulos_repuesto"
+}
+
+// BajoStock indica si las existencias llegaron al mínimo
+func (a ArticuloRepuesto) BajoStock() bool {
+	return a.Stock <= a.StockMinimo
+}
+
+// MovimientoRepuesto es una línea del kardex de un artículo del almacén
+type MovimientoRepuesto struct {
+	gorm.Model
+	ArticuloID           uint   `gorm:"not null;index"`
+	Tipo                 string `gorm:"not null;check:tipo IN ('entrada', 'salida', 'ajuste')"`
+	Cantidad             int    `gorm:"not null"` // Positiva en entradas y salidas; con signo en ajustes
+	Saldo                int    `gorm:"not null"` // Stock del artículo después del movimiento
+	ReporteID            *uint  `gorm:"index"`    // Reporte de servicio que consumió o devolvió el repuesto
+	RepuestoID           *uint  // Repuesto del reporte
+	Documento            string // Factura u orden de compra de las entradas
+	Observaciones        string
+	RealizadoPorID       *uint
+	RealizadoPorUsername string
+}
+
+// TableName fija el nombre de la tabla de movimientos del almacén
+func (MovimientoRepuesto) TableName() string {
+	return "movimientos_repuesto"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// Auditoria registra un cambio realizado sobre una entidad del inventario
+type Auditoria struct {
+	gorm.Model
+	UsuarioID *uint `gorm:"index"`
+	Username  string
+	Entidad   string `gorm:"index;not null"` // Nombre de la tabla afectada
+	EntidadID uint   `gorm:"index;not null"`
+	EquipoID  *uint  `gorm:"index"` // Equipo relacionado, para armar su historial
+	Accion    string `gorm:"not null"`
+
+	// Estado del registro antes y después del cambio, y los campos modificados
+	Antes   json.RawMessage `gorm:"type:jsonb"`
+	Despues json.RawMessage `gorm:"type:jsonb"`
+	Cambios json.RawMessage `gorm:"type:jsonb"`
+}
+
+// TableName fija el nombre de la tabla de auditoría
+func (Auditoria) TableName() string {
+	return "auditorias"
+}
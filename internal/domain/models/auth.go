@@ -7,6 +7,13 @@ import (
 	"gorm.io/gorm"
 )
 
+// Roles del sistema (valores válidos de Usuario.Rol)
+const (
+	RolAdmin   = "admin"
+	RolTecnico = "tecnico"
+	RolUsuario = "usuario"
+)
+
 // Usuario representa un usuario del sistema con capacidad de autenticación
 type Usuario struct {
 	gorm.Model
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Eventos que generan correos
+const (
+	CorreoReporteCreado    = "reporte-creado"
+	CorreoReporteCerrado   = "reporte-cerrado"
+	CorreoReporteReabierto = "reporte-reabierto"
+)
+
+// Estados de un correo saliente
+const (
+	CorreoPendiente = "pendiente"  // En cola, o esperando para reintentar
+	CorreoEnEnvio   = "en-proceso" // Arrendado por un trabajador
+	CorreoEnviado   = "enviado"
+	CorreoFallido   = "fallido"
+)
+
+// CorreoSaliente es un correo de notificación en la bandeja de salida. Se guarda ya armado
+// y un trabajador lo envía en segundo plano, con reintentos, como los trabajos de la cola.
+type CorreoSaliente struct {
+	gorm.Model
+	Evento         string `gorm:"not null;index"`
+	ReporteID      *uint  `gorm:"index"`
+	Destinatarios  string `gorm:"not null"` // Direcciones separadas por coma
+	Asunto         string `gorm:"not null"`
+	HTML           string `gorm:"column:html;type:text"`
+	Texto          string `gorm:"type:text"`
+	Estado         string `gorm:"not null;index:idx_correos_salientes_cola,priority:1"`
+	Intentos       int
+	MaxIntentos    int
+	DisponibleEn   time.Time  `gorm:"not null;index:idx_correos_salientes_cola,priority:2"` // No se envía antes (espera entre reintentos)
+	ArrendadoHasta *time.Time // Mientras no venza, ningún otro trabajador lo toma
+	Trabajador     string
+	Error          string // Último error de envío
+	EnviadoEn      *time.Time
+}
+
+// TableName fija el nombre de la tabla de la bandeja de salida
+func (CorreoSaliente) TableName() string {
+	return "correos_salientes"
+}
@@ -0,0 +1,27 @@
+package models
+
+import "encoding/json"
+
+// ContrasenaOculta es el valor que reemplaza a las contraseñas en las respuestas JSON.
+// Si un cliente lo reenvía al actualizar, se conserva la contraseña almacenada.
+const ContrasenaOculta = "********"
+
+// MarshalJSON enmascara la contraseña del usuario del sistema
+func (u UsuarioSistema) MarshalJSON() ([]byte, error) {
+	type alias UsuarioSistema
+	a := alias(u)
+	if a.Contrasena != "" {
+		a.Contrasena = ContrasenaOculta
+	}
+	return json.Marshal(a)
+}
+
+// MarshalJSON enmascara la contraseña del acceso remoto
+func (a AccesoRemoto) MarshalJSON() ([]byte, error) {
+	type alias AccesoRemoto
+	v := alias(a)
+	if v.Contrasena != "" {
+		v.Contrasena = ContrasenaOculta
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,23 @@
+package dto
+
+// Tipos de resultado de la búsqueda global
+const (
+	TipoResultadoEquipo           = "equipo"
+	TipoResultadoPeriferico       = "periferico"
+	TipoResultadoResponsable      = "responsable"
+	TipoResultadoConfiguracionRed = "configuracion_red"
+	TipoResultadoSoftware         = "software"
+)
+
+// ResultadoBusquedaDTO es una coincidencia de la búsqueda global
+type ResultadoBusquedaDTO struct {
+	Tipo        string  // equipo, periferico, responsable, configuracion_red o software
+	ID          uint    // ID del registro encontrado
+	Campo       string  // Campo que coincidió, por ejemplo serial o direccion_ip
+	Valor       string  // Valor del campo que coincidió
+	Descripcion string  // Texto corto para mostrar el resultado
+	EquipoID    *uint   // Equipo al que pertenece el registro; NULL si no tiene
+	EquipoPlaca string  // Placa de inventario del equipo
+	Rango       float64 // Relevancia, mayor es mejor
+	Enlace      string  `gorm:"-"` // Ruta de la API del equipo o, si no tiene, del registro
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+// Valores por defecto y límites de la paginación
+const (
+	PaginaPorDefecto       = 1
+	TamanoPaginaPorDefecto = 20
+	TamanoPaginaMaximo     = 100
+)
+
+// ErrConsultaInvalida indica que el orden o algún filtro de la consulta no es válido
+var ErrConsultaInvalida = errors.New("consulta inválida")
+
+// ConsultaDTO contiene la paginación, el orden y los filtros de un listado
+type ConsultaDTO struct {
+	Pagina       int
+	TamanoPagina int
+	Orden        string            // Campo por el que se ordena (?sort=)
+	Descendente  bool              // ?order=desc
+	Filtros      map[string]string // Filtros por campo, por ejemplo ?marca=HP
+	Desde        *time.Time
+	Hasta        *time.Time
+}
+
+// Offset calcula el número de registros que se saltan para llegar a la página
+func (c ConsultaDTO) Offset() int {
+	return (c.Pagina - 1) * c.TamanoPagina
+}
+
+// PaginaDTO es la respuesta de los listados paginados
+type PaginaDTO[T any] struct {
+	Datos        []T
+	Total        int64
+	Pagina       int
+	TamanoPagina int
+	TotalPaginas int
+}
+
+// NuevaPagina arma la respuesta paginada con los datos de la página y el total de registros
+func NuevaPagina[T any](datos []T, total int64, consulta ConsultaDTO) PaginaDTO[T] {
+	if datos == nil {
+		datos = []T{}
+	}
+	totalPaginas := 0
+	if consulta.TamanoPagina > 0 {
+		totalPaginas = int((total + int64(consulta.TamanoPagina) - 1) / int64(consulta.TamanoPagina))
+	}
+	return PaginaDTO[T]{
+		Datos:        datos,
+		Total:        total,
+		Pagina:       consulta.Pagina,
+		TamanoPagina: consulta.TamanoPagina,
+		TotalPaginas: totalPaginas,
+	}
+}
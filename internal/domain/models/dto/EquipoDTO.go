@@ -1,5 +1,7 @@
 package dto
 
+import "tum_inv_backend/internal/domain/models"
+
 // DTO para la consulta
 type EquipoConResponsableDTO struct {
 	Marca                  string
@@ -13,4 +15,7 @@ type EquipoConResponsableDTO struct {
 	FechaDiligenciamiento  string
 	TipoDispositivo        string
 	Estado                 string
+
+	// Historial de custodia del equipo (no viene de la consulta principal)
+	Asignaciones []models.AsignacionEquipo `gorm:"-"`
 }
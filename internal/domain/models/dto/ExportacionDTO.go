@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+	"tum_inv_backend/internal/domain/models"
+)
+
+// EquipoExportacionDTO es una fila de la exportación del inventario de equipos
+type EquipoExportacionDTO struct {
+	ID                     uint
+	PlacaInventario        string
+	Serial                 string
+	TipoDispositivo        string
+	Marca                  string
+	Modelo                 string
+	Estado                 string
+	ResponsableNombre      string
+	ResponsableCedula      string
+	DependenciaNombre      string
+	SecretariaID           *uint // NULL si el equipo no tiene responsable con dependencia
+	SecretariaNombre       string
+	DireccionIP            string
+	NombreDispositivo      string
+	FechaDiligenciamiento  time.Time
+	ObservacionesGenerales string
+	HardwareInterno        []models.HardwareInterno `gorm:"-"`
+}
+
+// PerifericoExportacionDTO es un periférico con la placa del equipo al que está conectado
+type PerifericoExportacionDTO struct {
+	models.Periferico
+	EquipoPlaca string
+}
+
+// SoftwareExportacionDTO es un software con la placa del equipo donde está instalado
+type SoftwareExportacionDTO struct {
+	models.Software
+	EquipoPlaca string
+}
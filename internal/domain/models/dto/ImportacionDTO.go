@@ -0,0 +1,23 @@
+package dto
+
+import "errors"
+
+// ErrImportacionInvalida indica que el archivo no se puede leer o sus columnas no son las esperadas
+var ErrImportacionInvalida = errors.New("archivo de importación inválido")
+
+// ResultadoImportacionDTO es la respuesta de la importación masiva de inventario
+type ResultadoImportacionDTO struct {
+	DryRun             bool // true si solo se validó el archivo
+	TotalFilas         int  // Filas con datos, sin contar el encabezado ni las filas vacías
+	EquiposCreados     int  // 0 en dry-run o si hay errores
+	ResponsablesNuevos int  // Responsables que no existían (por cédula) y se crean con la importación
+	Errores            []ErrorFilaImportacionDTO
+}
+
+// ErrorFilaImportacionDTO describe un error de validación en una fila del archivo
+type ErrorFilaImportacionDTO struct {
+	Fila    int    // Número de fila en la hoja, el encabezado es la fila 1
+	Columna string // Columna con el error; vacío si afecta a toda la fila
+	Valor   string
+	Mensaje string
+}
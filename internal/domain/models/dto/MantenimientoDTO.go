@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+// EquipoMantenimientoDTO es un equipo con plan de mantenimiento preventivo y su próxima fecha
+type EquipoMantenimientoDTO struct {
+	EquipoID              uint
+	TipoDispositivo       string
+	PlacaInventario       string
+	Marca                 string
+	Modelo                string
+	Serial                string
+	FechaRegistro         time.Time // FechaDiligenciamiento del equipo; base si nunca tuvo preventivo
+	Responsable           *string   // Nombre del usuario responsable; NULL si no tiene
+	DependenciaID         *uint     // NULL si el equipo no está asignado a una dependencia
+	Dependencia           *string
+	UltimoPreventivo      *time.Time `gorm:"-"` // Fecha del último reporte PREVENTIVO; NULL si no tiene
+	UltimoReporteID       *uint      `gorm:"-"`
+	PlanID                uint       `gorm:"-"`
+	IntervaloMeses        int        `gorm:"-"`
+	ProximoMantenimiento  time.Time  `gorm:"-"`
+	DiasParaMantenimiento int        `gorm:"-"` // Negativo si está vencido
+	Estado                string     `gorm:"-"` // vencido, proximo o al-dia
+}
+
+// PreventivoEquipoDTO es el último mantenimiento preventivo de un equipo
+type PreventivoEquipoDTO struct {
+	EquipoID  uint
+	ReporteID uint
+	Fecha     time.Time
+}
+
+// MantenimientosDependenciaDTO agrupa por dependencia los equipos con mantenimiento vencido o próximo
+type MantenimientosDependenciaDTO struct {
+	DependenciaID *uint // NULL agrupa los equipos sin dependencia
+	Dependencia   string
+	Vencidos      int
+	Proximos      int
+	Equipos       []EquipoMantenimientoDTO
+}
@@ -0,0 +1,8 @@
+package dto
+
+// LogoPlantillaDTO es el logo subido para usar en una plantilla de PDF
+type LogoPlantillaDTO struct {
+	Archivo string // Valor para LogoPlantilla.Archivo
+	AnchoPx int
+	AltoPx  int
+}
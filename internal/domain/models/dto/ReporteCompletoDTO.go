@@ -39,8 +39,10 @@ type TipoMantenimientoDTO struct {
 	DescripcionOtro string `json:"descripcion_otro,omitempty"`
 }
 
-// RepuestoDTO representa los datos para crear un repuesto
+// RepuestoDTO representa los datos para crear un repuesto. Con articulo_id el repuesto sale
+// del almacén y el serial, la descripción y los demás datos vacíos se toman del artículo
 type RepuestoDTO struct {
+	ArticuloID        *uint     `json:"articulo_id,omitempty"`
 	Cantidad          int       `json:"cantidad" validate:"required,min=1"`
 	SerialNumeroParte string    `json:"serial_numero_parte" validate:"required"`
 	Marca             string    `json:"marca,omitempty"`
@@ -88,6 +90,7 @@ func (dto *CrearReporteCompletoDTO) ToRepuestos(reporteID uint) []models.Repuest
 		repuestosReporteID := reporteID
 		repuestos[i] = models.Repuesto{
 			ReporteID:         &repuestosReporteID,
+			ArticuloID:        repuestoDTO.ArticuloID,
 			Cantidad:          repuestoDTO.Cantidad,
 			SerialNumeroParte: repuestoDTO.SerialNumeroParte,
 			Marca:             repuestoDTO.Marca,
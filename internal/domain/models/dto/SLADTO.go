@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// ReporteSLADTO es un reporte de servicio medido contra su política de SLA
+type ReporteSLADTO struct {
+	ReporteID         uint
+	EquipoID          uint
+	TecnicoID         uint
+	Tecnico           string
+	Dependencia       string
+	TipoMantenimiento string // PREVENTIVO, CORRECTIVO u OTRO
+	Prioridad         string // De la solicitud de servicio; vacío si el reporte no tiene solicitud
+	PoliticaID        *uint  // NULL si ninguna política aplica
+	FechaInicio       time.Time
+	FechaFinalizacion *time.Time
+	FechaCierre       *time.Time
+	HorasResolucion   *float64 // NULL mientras no termina
+	HorasFirma        *float64 // NULL mientras no se cierra con el PDF firmado
+	LimiteResolucion  int
+	LimiteFirma       int
+	EstadoResolucion  string // cumplido, incumplido, pendiente o sin-politica
+	EstadoFirma       string
+	Estado            string // incumplido si falla cualquiera de los dos plazos
+}
+
+// CumplimientoSLADTO resume el cumplimiento de un grupo de reportes (un técnico o una
+// dependencia). El porcentaje solo cuenta los reportes cumplidos e incumplidos.
+type CumplimientoSLADTO struct {
+	TecnicoID   *uint  `json:",omitempty"`
+	Tecnico     string `json:",omitempty"`
+	Dependencia string `json:",omitempty"`
+	Total       int
+	Cumplidos   int
+	Incumplidos int
+	Pendientes  int
+	SinPolitica int
+	Porcentaje  *float64 // NULL si no hay reportes cumplidos ni incumplidos
+}
+
+// ResumenSLADTO es el cumplimiento de SLA de los reportes de servicio de un periodo
+type ResumenSLADTO struct {
+	Desde          *time.Time
+	Hasta          *time.Time
+	General        CumplimientoSLADTO
+	PorTecnico     []CumplimientoSLADTO
+	PorDependencia []CumplimientoSLADTO
+	Incumplidos    []ReporteSLADTO // Reportes que incumplieron, del más antiguo al más reciente
+}
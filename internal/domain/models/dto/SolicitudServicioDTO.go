@@ -0,0 +1,18 @@
+package dto
+
+// AsignarSolicitudDTO representa el técnico al que se asigna una solicitud de servicio
+type AsignarSolicitudDTO struct {
+	TecnicoID uint `json:"tecnico_id" validate:"required"`
+}
+
+// ResolverSolicitudDTO representa el reporte de servicio con el que se resuelve una
+// solicitud: un reporte existente (ReporteID) o los datos de uno nuevo (Reporte)
+type ResolverSolicitudDTO struct {
+	ReporteID *uint                    `json:"reporte_id,omitempty"`
+	Reporte   *CrearReporteCompletoDTO `json:"reporte,omitempty"`
+}
+
+// CancelarSolicitudDTO representa el motivo de la cancelación de una solicitud de servicio
+type CancelarSolicitudDTO struct {
+	Motivo string `json:"motivo" validate:"required"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+// SubidaDirectaDTO es la URL con la que el cliente sube un archivo directamente al
+// almacenamiento; Archivo se envía después para confirmar la subida
+type SubidaDirectaDTO struct {
+	URL     string    `json:"url"`     // URL PUT prefirmada
+	Archivo string    `json:"archivo"` // Nombre del archivo en el almacenamiento
+	Vence   time.Time `json:"vence"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import "strings"
+
+// Códigos de los problemas encontrados al validar un PDF subido
+const (
+	ProblemaPDFTamano    = "tamano_excedido"
+	ProblemaPDFFormato   = "no_es_pdf"
+	ProblemaPDFCorrupto  = "pdf_corrupto"
+	ProblemaPDFCifrado   = "pdf_cifrado"
+	ProblemaPDFPaginas   = "paginas_insuficientes"
+	ProblemaPDFDocumento = "documento_no_coincide"
+)
+
+// ProblemaPDFDTO describe un problema encontrado en un PDF subido
+type ProblemaPDFDTO struct {
+	Codigo  string // Uno de los códigos ProblemaPDF*
+	Mensaje string
+}
+
+// ErrorValidacionPDF indica que el PDF subido no pasó la validación; Problemas tiene el detalle
+type ErrorValidacionPDF struct {
+	Problemas []ProblemaPDFDTO
+}
+
+// Error une los mensajes de todos los problemas
+func (e *ErrorValidacionPDF) Error() string {
+	mensajes := make([]string, len(e.Problemas))
+	for i, p := range e.Problemas {
+		mensajes[i] = p.Mensaje
+	}
+	return "PDF inválido: " + strings.Join(mensajes, "; ")
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// VerificacionReporteDTO son los datos de un reporte de servicio que se muestran al verificar
+// el código impreso en su PDF. La ruta es pública, así que no incluye datos personales.
+type VerificacionReporteDTO struct {
+	Codigo            string
+	ReporteID         uint
+	FechaInicio       time.Time
+	FechaFinalizacion *time.Time
+	Dependencia       string
+	Ubicacion         string
+	TipoMantenimiento string // PREVENTIVO, CORRECTIVO u OTRO
+	EquipoPlaca       string
+	EquipoSerial      string
+	EquipoMarca       string
+	EquipoModelo      string
+	Cerrado           bool
+	FechaCierre       *time.Time
+	Firmado           bool   // El reporte está cerrado con un PDF firmado registrado
+	VersionFirmada    int    // Versión vigente del PDF firmado; 0 si no hay
+	SHA256Firmado     string // Hash de la versión vigente, para comparar con una copia del archivo
+}
@@ -121,8 +121,8 @@ type UsuarioSistema struct {
 	gorm.Model
 	EquipoID        uint   `gorm:"not null"`
 	NombreUsuario   string `gorm:"not null"`
-	Contrasena      string
-	EsAdministrador bool `gorm:"default:false"`
+	Contrasena      string `gorm:"serializer:cifrado"` // Cifrada en reposo, enmascarada en JSON
+	EsAdministrador bool   `gorm:"default:false"`
 }
 
 // AccesoRemoto representa credenciales de acceso remoto
@@ -131,7 +131,7 @@ type AccesoRemoto struct {
 	EquipoID   uint   `gorm:"not null"`
 	Plataforma string `gorm:"default:'AnyDesk'"`
 	Usuario    string `gorm:"not null"`
-	Contrasena string
+	Contrasena string `gorm:"serializer:cifrado"` // Cifrada en reposo, enmascarada en JSON
 	IDConexion string `gorm:"not null"`
 }
 
@@ -158,7 +158,7 @@ type ReporteServicio struct {
 	DiagnosticoFalla   string
 	ActividadRealizada string `gorm:"not null"`
 	Observaciones      string
-	ArchivoFirmadoURL  string     // URL del PDF firmado en Supabase Storage
+	ArchivoFirmadoURL  string     // Ruta de la versión vigente del PDF firmado (ver DocumentoReporte)
 	FechaCierre        *time.Time // NULL = abierto, con fecha = cerrado
 
 	// Relaciones
@@ -168,6 +168,25 @@ type ReporteServicio struct {
 	Repuestos         []Repuesto        `gorm:"foreignKey:ReporteID"`
 }
 
+// DocumentoReporte es una versión del PDF firmado de un reporte de servicio. Cada subida
+// crea una versión nueva y al reabrir el reporte la versión se conserva con el motivo.
+type DocumentoReporte struct {
+	gorm.Model
+	ReporteID            uint   `gorm:"not null;uniqueIndex:idx_documento_reporte_version"`
+	Version              int    `gorm:"not null;uniqueIndex:idx_documento_reporte_version"` // 1, 2, ... por reporte
+	Archivo              string `gorm:"not null"`                                           // Nombre del archivo en el almacenamiento
+	RutaObjeto           string `gorm:"not null"`                                           // Ruta retornada por el almacenamiento
+	SHA256               string `gorm:"column:sha256;size:64"`                              // Vacío en versiones anteriores al historial
+	Tamano               int64  // Bytes
+	SubidoPorID          *uint
+	SubidoPorUsername    string
+	FechaSubida          time.Time  `gorm:"not null"`
+	MotivoReapertura     string     // Motivo con el que se reabrió el reporte después de esta versión
+	FechaReapertura      *time.Time // NULL = versión vigente o última subida
+	ReabiertoPorID       *uint
+	ReabiertoPorUsername string
+}
+
 // TipoMantenimiento representa el tipo de mantenimiento realizado
 type TipoMantenimiento struct {
 	gorm.Model
@@ -187,6 +206,7 @@ type TipoMantenimiento struct {
 type Repuesto struct {
 	gorm.Model
 	ReporteID         *uint
+	ArticuloID        *uint  `gorm:"index"` // Artículo del almacén del que salió; NULL si no se lleva en el almacén
 	Cantidad          int    `gorm:"check:cantidad > 0"`
 	SerialNumeroParte string `gorm:"not null"`
 	Marca             string
@@ -196,6 +216,10 @@ type Repuesto struct {
 	FechaUtilizacion  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
+// EstadoEquipoDadoDeBaja es el nombre del estado de los equipos retirados del inventario;
+// el plan de mantenimiento no los incluye
+const EstadoEquipoDadoDeBaja = "Dado de Baja"
+
 // EstadoEquipo representa el estado actual del equipo
 type EstadoEquipo struct {
 	gorm.Model
@@ -206,3 +230,27 @@ type EstadoEquipo struct {
 	// Relaciones
 	Equipos []Equipo `gorm:"foreignKey:EstadoEquipoID"`
 }
+
+// AsignacionEquipo representa un periodo de custodia de un equipo por un usuario responsable
+type AsignacionEquipo struct {
+	gorm.Model
+	EquipoID              uint       `gorm:"not null;index"`
+	ResponsableAnteriorID *uint      `gorm:"index"`
+	ResponsableNuevoID    *uint      `gorm:"index"` // NULL = el equipo queda sin responsable
+	FechaInicio           time.Time  `gorm:"not null"`
+	FechaFin              *time.Time // NULL = asignación vigente
+	Motivo                string
+	RealizadoPorID        *uint // Usuario (técnico) que realizó la entrega
+	RealizadoPorUsername  string
+	ActaFirmadaURL        string     // Ruta del acta de entrega firmada en Supabase Storage
+	ActaFirmadaArchivo    string     // Nombre del acta firmada en el almacenamiento
+	FechaActaFirmada      *time.Time // NULL = acta pendiente de firma
+	// Equipo y componentes que constan en el acta; se fijan al subir el acta firmada o al
+	// cerrar la asignación. NULL = el acta muestra los componentes actuales del equipo.
+	Componentes *ComponentesActa `gorm:"serializer:json;type:jsonb"`
+
+	// Relaciones
+	Equipo              Equipo              `gorm:"foreignKey:EquipoID"`
+	ResponsableAnterior *UsuarioResponsable `gorm:"foreignKey:ResponsableAnteriorID"`
+	ResponsableNuevo    *UsuarioResponsable `gorm:"foreignKey:ResponsableNuevoID"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tipos de notificación
+const (
+	NotificacionReporteSinFirma      = "reporte-sin-firma"
+	NotificacionSolicitudAsignada    = "solicitud-asignada"
+	NotificacionEquipoAsignado       = "equipo-asignado"
+	NotificacionMantenimientoVencido = "mantenimiento-vencido"
+)
+
+// Notificacion es un aviso para un usuario del sistema en su centro de notificaciones
+type Notificacion struct {
+	gorm.Model
+	UsuarioID uint   `gorm:"not null;index:idx_notificaciones_usuario,priority:1;uniqueIndex:idx_notificaciones_clave,priority:1"`
+	Tipo      string `gorm:"not null"`
+	Titulo    string `gorm:"not null"`
+	Mensaje   string
+	Recurso   string // Recurso al que se refiere: reportes-servicio, solicitudes-servicio, equipos
+	RecursoID *uint
+	// Clave identifica el aviso de una revisión periódica para no repetirlo; NULL en los
+	// avisos de una acción, que no se repiten
+	Clave   *string    `gorm:"uniqueIndex:idx_notificaciones_clave,priority:2"`
+	LeidaEn *time.Time `gorm:"index:idx_notificaciones_usuario,priority:2"` // NULL = sin leer
+}
+
+// TableName fija el nombre de la tabla de notificaciones
+func (Notificacion) TableName() string {
+	return "notificaciones"
+}
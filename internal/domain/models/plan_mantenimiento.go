@@ -0,0 +1,31 @@
+package models
+
+import "gorm.io/gorm"
+
+// Estados del mantenimiento preventivo de un equipo según su plan
+const (
+	MantenimientoVencido = "vencido" // La fecha del próximo mantenimiento ya pasó
+	MantenimientoProximo = "proximo" // Vence dentro de los días de anticipación
+	MantenimientoAlDia   = "al-dia"
+)
+
+// PlanMantenimiento fija cada cuántos meses se hace el mantenimiento preventivo de los
+// equipos de un tipo de dispositivo o de un equipo en particular. El plan del equipo tiene
+// prioridad sobre el de su tipo.
+type PlanMantenimiento struct {
+	gorm.Model
+	// Se usa uno de los dos: TipoDispositivo (vacío en los planes de un equipo) o EquipoID
+	TipoDispositivo string `gorm:"index;check:tipo_dispositivo IN ('', 'Todo en Uno', 'Escritorio', 'Portátil', 'Impresora', 'Escáner', 'Otro')"`
+	EquipoID        *uint  `gorm:"index"`
+	IntervaloMeses  int    `gorm:"not null;check:intervalo_meses > 0"`
+	Activo          bool   `gorm:"default:true"`
+	Descripcion     string
+
+	// Relaciones
+	Equipo *Equipo `gorm:"foreignKey:EquipoID"`
+}
+
+// TableName fija el nombre de la tabla de planes de mantenimiento
+func (PlanMantenimiento) TableName() string {
+	return "planes_mantenimiento"
+}
@@ -0,0 +1,61 @@
+package models
+
+import "gorm.io/gorm"
+
+// Tipos de documento que usan la plantilla, para su código y versión
+const (
+	DocumentoReporteServicio = "reporte-servicio"
+	DocumentoActaEntrega     = "acta-entrega"
+	DocumentoHojaVida        = "hoja-vida"
+)
+
+// PlantillaPDF define el encabezado, los logos, la marca de agua y el pie de página de los PDF
+// institucionales (reporte de servicio, acta de entrega y hoja de vida). Se usa la plantilla
+// activa; si no hay ninguna, el diseño predeterminado de la Alcaldía de Tumaco.
+// Los índices únicos ignoran las plantillas eliminadas, así su nombre se puede volver a usar,
+// y no permiten más de una plantilla activa.
+type PlantillaPDF struct {
+	gorm.Model
+	Nombre     string              `gorm:"uniqueIndex:idx_plantillas_pdf_nombre_vigente,where:deleted_at IS NULL;not null"`
+	Activa     bool                `gorm:"uniqueIndex:idx_plantillas_pdf_activa_unica,where:activa AND deleted_at IS NULL"`
+	Definicion DefinicionPlantilla `gorm:"serializer:json;type:jsonb"`
+}
+
+// TableName fija el nombre de la tabla de plantillas
+func (PlantillaPDF) TableName() string {
+	return "plantillas_pdf"
+}
+
+// DefinicionPlantilla es el diseño de la plantilla. Las medidas están en milímetros sobre
+// una hoja carta (216 x 279).
+type DefinicionPlantilla struct {
+	Encabezado []LineaPlantilla           // Líneas centradas en la parte superior
+	Logos      []LogoPlantilla            // Se dibujan en cada página
+	MarcaAgua  *LogoPlantilla             // Imagen detrás del contenido; nil para no usarla
+	PiePagina  []string                   // Líneas centradas en la parte inferior
+	Documentos map[string]CodigoDocumento // Código y versión por tipo de documento (DocumentoReporteServicio, ...)
+}
+
+// LineaPlantilla es una línea de texto del encabezado
+type LineaPlantilla struct {
+	Texto   string
+	Tamano  float64 // Puntos; 11 si es 0
+	Negrita bool
+}
+
+// LogoPlantilla es una imagen PNG o JPEG y su posición en la página.
+// Archivo es la ruta de un logo incluido en el servidor (assets/logos/...) o el nombre que
+// retorna la subida de logos (plantillas/logos/...).
+type LogoPlantilla struct {
+	Archivo string
+	X       float64
+	Y       float64
+	Ancho   float64
+	Alto    float64
+}
+
+// CodigoDocumento es la identificación del formato en el sistema de gestión de calidad
+type CodigoDocumento struct {
+	Codigo  string
+	Version string
+}
@@ -0,0 +1,29 @@
+package models
+
+import "gorm.io/gorm"
+
+// Estado de un reporte de servicio frente a su política de SLA
+const (
+	SLACumplido    = "cumplido"
+	SLAIncumplido  = "incumplido"
+	SLAPendiente   = "pendiente"    // Aún no termina y está dentro del plazo
+	SLASinPolitica = "sin-politica" // Ninguna política activa aplica al reporte
+)
+
+// PoliticaSLA fija los plazos de atención de los reportes de servicio de un tipo de
+// mantenimiento y una prioridad. Un campo vacío aplica a cualquier valor; para cada reporte
+// se usa la política activa más específica.
+type PoliticaSLA struct {
+	gorm.Model
+	TipoMantenimiento string `gorm:"index;check:tipo_mantenimiento IN ('', 'PREVENTIVO', 'CORRECTIVO', 'OTRO')"`
+	Prioridad         string `gorm:"index;check:prioridad IN ('', 'baja', 'media', 'alta', 'critica')"` // La de la solicitud de servicio del reporte
+	HorasResolucion   int    `gorm:"not null;check:horas_resolucion > 0"`                               // De FechaInicio a FechaFinalizacion
+	HorasFirma        int    `gorm:"not null;check:horas_firma > 0"`                                    // De FechaFinalizacion a FechaCierre
+	Activo            bool   `gorm:"default:true"`
+	Descripcion       string
+}
+
+// TableName fija el nombre de la tabla de políticas de SLA
+func (PoliticaSLA) TableName() string {
+	return "politicas_sla"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Estados de una solicitud de servicio, en el orden en que avanza
+const (
+	SolicitudAbierta    = "abierta"
+	SolicitudAsignada   = "asignada"
+	SolicitudEnProgreso = "en-progreso"
+	SolicitudResuelta   = "resuelta"
+	SolicitudCancelada  = "cancelada"
+)
+
+// Prioridades de una solicitud de servicio
+const (
+	PrioridadBaja    = "baja"
+	PrioridadMedia   = "media"
+	PrioridadAlta    = "alta"
+	PrioridadCritica = "critica"
+)
+
+// SolicitudServicio es el pedido de soporte que hace un funcionario sobre un equipo antes de
+// que exista el reporte de servicio. Al resolverla queda asociada al ReporteServicio del trabajo.
+type SolicitudServicio struct {
+	gorm.Model
+	EquipoID            uint  `gorm:"not null;index"`
+	DependenciaID       *uint `gorm:"index"` // Dependencia del equipo al abrir la solicitud
+	SolicitanteID       uint  `gorm:"not null;index"`
+	SolicitanteUsername string
+	Descripcion         string `gorm:"not null"`
+	Prioridad           string `gorm:"not null;default:'media';check:prioridad IN ('baja', 'media', 'alta', 'critica')"`
+	Estado              string `gorm:"not null;default:'abierta';index;check:estado IN ('abierta', 'asignada', 'en-progreso', 'resuelta', 'cancelada')"`
+	TecnicoID           *uint  `gorm:"index"`
+	ReporteID           *uint  `gorm:"uniqueIndex"` // Reporte de servicio con el que se resolvió
+	MotivoCancelacion   string
+	FechaAsignacion     *time.Time
+	FechaInicio         *time.Time // Paso a en-progreso
+	FechaResolucion     *time.Time // Paso a resuelta o cancelada
+
+	// Relaciones
+	Equipo      Equipo           `gorm:"foreignKey:EquipoID"`
+	Dependencia *Dependencia     `gorm:"foreignKey:DependenciaID"`
+	Solicitante Usuario          `gorm:"foreignKey:SolicitanteID"`
+	Tecnico     *Usuario         `gorm:"foreignKey:TecnicoID"`
+	Reporte     *ReporteServicio `gorm:"foreignKey:ReporteID"`
+}
+
+// TableName fija el nombre de la tabla de solicitudes de servicio
+func (SolicitudServicio) TableName() string {
+	return "solicitudes_servicio"
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tipos de trabajo en segundo plano
+const (
+	TrabajoExportarEquipos     = "exportar-equipos"
+	TrabajoExportarPerifericos = "exportar-perifericos"
+	TrabajoExportarSoftware    = "exportar-software"
+	TrabajoExportarReportes    = "exportar-reportes-servicio"
+	TrabajoImportarInventario  = "importar-inventario"
+	TrabajoPDFLoteReportes     = "pdf-lote-reportes"
+)
+
+// Estados de un trabajo
+const (
+	TrabajoPendiente  = "pendiente"  // En cola, o esperando para reintentar
+	TrabajoEnProceso  = "en-proceso" // Arrendado por un trabajador
+	TrabajoCompletado = "completado"
+	TrabajoFallido    = "fallido"
+)
+
+// Trabajo es una tarea pesada (exportación, importación, PDF por lote) que se ejecuta en
+// segundo plano. La tabla es la cola: un trabajador toma el trabajo pendiente más antiguo
+// y lo arrienda por un tiempo; si el proceso muere, al vencer el arriendo otro lo retoma.
+type Trabajo struct {
+	gorm.Model
+	Tipo              string            `gorm:"not null;index"`
+	Estado            string            `gorm:"not null;index:idx_trabajos_cola,priority:1"`
+	Parametros        ParametrosTrabajo `gorm:"serializer:json;type:jsonb"`
+	Progreso          int               // Porcentaje de 0 a 100
+	Intentos          int               // Veces que un trabajador lo ha tomado
+	MaxIntentos       int
+	DisponibleEn      time.Time       `gorm:"not null;index:idx_trabajos_cola,priority:2"` // No se toma antes (espera entre reintentos)
+	ArrendadoHasta    *time.Time      // Mientras no venza, ningún otro trabajador lo toma
+	Trabajador        string          // Proceso que lo tiene arrendado
+	Error             string          // Último error; se conserva si un reintento termina bien
+	Resultado         string          // Ruta del archivo generado en el almacenamiento
+	ResultadoNombre   string          // Nombre con el que se descarga
+	Resumen           json.RawMessage `gorm:"type:jsonb"` // Resultado sin archivo, por ejemplo el de una importación
+	CreadoPorID       uint            `gorm:"not null;index"`
+	CreadoPorUsername string
+	IniciadoEn        *time.Time
+	FinalizadoEn      *time.Time
+}
+
+// TableName fija el nombre de la tabla de la cola de trabajos
+func (Trabajo) TableName() string {
+	return "trabajos"
+}
+
+// ParametrosTrabajo son los datos con los que se ejecuta el trabajo
+type ParametrosTrabajo struct {
+	Filtros     map[string]string // Filtros del listado, como en ?campo=valor
+	Orden       string
+	Descendente bool
+	Desde       *time.Time
+	Hasta       *time.Time
+	Formato     string // zip o pdf, para el PDF por lote
+	DryRun      bool   // Importación: solo validar
+	Archivo     string // Importación: nombre del archivo subido
+	Entrada     string // Importación: ruta del archivo en el almacenamiento
+}
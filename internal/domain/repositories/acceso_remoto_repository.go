@@ -1,18 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // AccesoRemotoRepository define las operaciones del repositorio para AccesoRemoto
 type AccesoRemotoRepository interface {
-	Create(acceso *models.AccesoRemoto) error
+	Create(ctx context.Context, acceso *models.AccesoRemoto) error
 	FindByID(id uint) (*models.AccesoRemoto, error)
-	Update(acceso *models.AccesoRemoto) error
-	Delete(id uint) error
-	FindAll() ([]models.AccesoRemoto, error)
+	Update(ctx context.Context, acceso *models.AccesoRemoto) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.AccesoRemoto, int64, error)
 	FindByEquipoID(equipoID uint) ([]models.AccesoRemoto, error)
 }
 
@@ -27,8 +29,8 @@ func NewAccesoRemotoRepository(db *gorm.DB) AccesoRemotoRepository {
 }
 
 // Create crea un nuevo acceso remoto en la base de datos
-func (r *accesoRemotoRepository) Create(acceso *models.AccesoRemoto) error {
-	return r.db.Create(acceso).Error
+func (r *accesoRemotoRepository) Create(ctx context.Context, acceso *models.AccesoRemoto) error {
+	return r.db.WithContext(ctx).Create(acceso).Error
 }
 
 // FindByID busca un acceso remoto por su ID
@@ -42,20 +44,28 @@ func (r *accesoRemotoRepository) FindByID(id uint) (*models.AccesoRemoto, error)
 }
 
 // Update actualiza un acceso remoto existente
-func (r *accesoRemotoRepository) Update(acceso *models.AccesoRemoto) error {
-	return r.db.Save(acceso).Error
+func (r *accesoRemotoRepository) Update(ctx context.Context, acceso *models.AccesoRemoto) error {
+	return r.db.WithContext(ctx).Save(acceso).Error
 }
 
 // Delete elimina un acceso remoto por su ID
-func (r *accesoRemotoRepository) Delete(id uint) error {
-	return r.db.Delete(&models.AccesoRemoto{}, id).Error
+func (r *accesoRemotoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.AccesoRemoto{}, id).Error
 }
 
-// FindAll retorna todos los accesos remotos
-func (r *accesoRemotoRepository) FindAll() ([]models.AccesoRemoto, error) {
-	var accesos []models.AccesoRemoto
-	err := r.db.Find(&accesos).Error
-	return accesos, err
+// FindAll retorna una página de accesos remotos según la consulta
+func (r *accesoRemotoRepository) FindAll(consulta dto.ConsultaDTO) ([]models.AccesoRemoto, int64, error) {
+	return paginar[models.AccesoRemoto](r.db.Model(&models.AccesoRemoto{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "plataforma": "plataforma", "usuario": "usuario", "created_at": "created_at"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"equipo":     filtroID("equipo", "equipo_id = ?"),
+			"plataforma": filtroIgual("plataforma"),
+			"usuario":    filtroTexto("usuario"),
+		},
+		fecha: "created_at",
+	})
 }
 
 // FindByEquipoID retorna todos los accesos remotos asociados a un equipo
@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/infrastructure/auditoria"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrStockInsuficiente indica que una salida o un ajuste dejaría el stock de un artículo en negativo
+	ErrStockInsuficiente = errors.New("stock insuficiente")
+	// ErrArticuloNoEncontrado indica que el artículo del almacén de un repuesto no existe
+	ErrArticuloNoEncontrado = errors.New("artículo del almacén no encontrado")
+	// ErrArticuloConStock indica que se intentó eliminar un artículo que todavía tiene stock
+	ErrArticuloConStock = errors.New("el artículo todavía tiene stock; registre la salida o el ajuste antes de eliminarlo")
+	// ErrArticuloEnUso indica que se intentó eliminar un artículo que usan repuestos de reportes
+	ErrArticuloEnUso = errors.New("el artículo lo usan repuestos de reportes de servicio; elimínelos antes de eliminarlo")
+)
+
+// AlmacenRepository define las operaciones de acceso a datos del almacén de repuestos
+type AlmacenRepository interface {
+	FindAll(consulta dto.ConsultaDTO) ([]models.ArticuloRepuesto, int64, error)
+	FindByID(id uint) (*models.ArticuloRepuesto, error)
+	FindBajoStock() ([]models.ArticuloRepuesto, error)
+	ExisteSerial(serial string, excluirID uint) (bool, error)
+	Create(ctx context.Context, articulo *models.ArticuloRepuesto, stockInicial int) error
+	Update(ctx context.Context, articulo *models.ArticuloRepuesto) error
+	Delete(ctx context.Context, id uint) error
+	RegistrarMovimiento(ctx context.Context, movimiento *models.MovimientoRepuesto) error
+	FindMovimientos(articuloID uint, consulta dto.ConsultaDTO) ([]models.MovimientoRepuesto, int64, error)
+}
+
+// almacenRepository implementa AlmacenRepository
+type almacenRepository struct {
+	db *gorm.DB
+}
+
+// NewAlmacenRepository crea una nueva instancia de AlmacenRepository
+func NewAlmacenRepository(db *gorm.DB) AlmacenRepository {
+	return &almacenRepository{db: db}
+}
+
+// FindAll retorna una página del catálogo según la consulta
+func (r *almacenRepository) FindAll(consulta dto.ConsultaDTO) ([]models.ArticuloRepuesto, int64, error) {
+	return paginar[models.ArticuloRepuesto](r.db.Model(&models.ArticuloRepuesto{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "serial": "serial_numero_parte", "descripcion": "descripcion", "marca": "marca", "stock": "stock"},
+		ordenPorDef: "descripcion",
+		filtros: map[string]filtroConsulta{
+			"serial":      filtroTexto("serial_numero_parte"),
+			"descripcion": filtroTexto("descripcion"),
+			"marca":       filtroTexto("marca"),
+			"tecnologia":  filtroTexto("tecnologia"),
+			"bajo_stock": func(db *gorm.DB, valor string) (*gorm.DB, error) {
+				bajo, err := strconv.ParseBool(valor)
+				if err != nil {
+					return nil, fmt.Errorf("%w: bajo_stock debe ser true o false", dto.ErrConsultaInvalida)
+				}
+				if bajo {
+					return db.Where("stock <= stock_minimo"), nil
+				}
+				return db.Where("stock > stock_minimo"), nil
+			},
+		},
+	})
+}
+
+// FindByID busca un artículo por su ID
+func (r *almacenRepository) FindByID(id uint) (*models.ArticuloRepuesto, error) {
+	var articulo models.ArticuloRepuesto
+	if err := r.db.First(&articulo, id).Error; err != nil {
+		return nil, err
+	}
+	return &articulo, nil
+}
+
+// FindBajoStock retorna los artículos cuyo stock llegó al mínimo, los más escasos primero
+func (r *almacenRepository) FindBajoStock() ([]models.ArticuloRepuesto, error) {
+	var articulos []models.ArticuloRepuesto
+	err := r.db.Where("stock <= stock_minimo").Order("stock - stock_minimo, descripcion, id").Find(&articulos).Error
+	return articulos, err
+}
+
+// ExisteSerial indica si otro artículo ya usa el serial o número de parte
+func (r *almacenRepository) ExisteSerial(serial string, excluirID uint) (bool, error) {
+	var total int64
+	err := r.db.Model(&models.ArticuloRepuesto{}).
+		Where("serial_numero_parte = ? AND id <> ?", serial, excluirID).Count(&total).Error
+	return total > 0, err
+}
+
+// Create crea un artículo y, si stockInicial es mayor que cero, registra la entrada del
+// inventario inicial en la misma transacción
+func (r *almacenRepository) Create(ctx context.Context, articulo *models.ArticuloRepuesto, stockInicial int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		articulo.Stock = 0
+		if err := tx.Create(articulo).Error; err != nil {
+			return err
+		}
+		if stockInicial <= 0 {
+			return nil
+		}
+		movimiento := &models.MovimientoRepuesto{
+			ArticuloID:    articulo.ID,
+			Tipo:          models.MovimientoEntrada,
+			Cantidad:      stockInicial,
+			Observaciones: "Inventario inicial",
+		}
+		if err := registrarMovimiento(tx, movimiento); err != nil {
+			return err
+		}
+		articulo.Stock = movimiento.Saldo
+		return nil
+	})
+}
+
+// Update guarda los datos del artículo; el stock solo cambia con movimientos
+func (r *almacenRepository) Update(ctx context.Context, articulo *models.ArticuloRepuesto) error {
+	return r.db.WithContext(ctx).Model(articulo).
+		Select("serial_numero_parte", "descripcion", "marca", "tecnologia", "capacidad", "stock_minimo").
+		Updates(articulo).Error
+}
+
+// Delete elimina (soft delete) un artículo; su kardex se conserva. Retorna ErrArticuloConStock
+// si tiene stock y ErrArticuloEnUso si algún repuesto vigente salió de él, porque eliminar ese
+// repuesto devuelve la cantidad al artículo. La fila queda bloqueada hasta el final para que
+// ningún movimiento ni repuesto nuevo cambie el resultado de esas revisiones.
+func (r *almacenRepository) Delete(ctx context.Context, id uint) error {
+	return conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var articulo models.ArticuloRepuesto
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&articulo, id).Error; err != nil {
+			return err
+		}
+		if articulo.Stock > 0 {
+			return ErrArticuloConStock
+		}
+
+		var repuestos int64
+		if err := tx.Model(&models.Repuesto{}).Where("articulo_id = ?", id).Count(&repuestos).Error; err != nil {
+			return err
+		}
+		if repuestos > 0 {
+			return fmt.Errorf("%w (%d)", ErrArticuloEnUso, repuestos)
+		}
+		return tx.Delete(&articulo).Error
+	})
+}
+
+// RegistrarMovimiento actualiza el stock del artículo y agrega el movimiento al kardex
+func (r *almacenRepository) RegistrarMovimiento(ctx context.Context, movimiento *models.MovimientoRepuesto) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return registrarMovimiento(tx, movimiento)
+	})
+}
+
+// FindMovimientos retorna una página del kardex de un artículo, del movimiento más antiguo
+// al más reciente
+func (r *almacenRepository) FindMovimientos(articuloID uint, consulta dto.ConsultaDTO) ([]models.MovimientoRepuesto, int64, error) {
+	base := r.db.Model(&models.MovimientoRepuesto{}).Where("articulo_id = ?", articuloID)
+	return paginar[models.MovimientoRepuesto](base, consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "fecha": "created_at"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"tipo":    filtroIgual("tipo"),
+			"reporte": filtroID("reporte", "reporte_id = ?"),
+		},
+		fecha: "created_at",
+	})
+}
+
+// registrarMovimiento suma o resta la cantidad del movimiento al stock del artículo y lo
+// guarda con el saldo resultante. Debe llamarse dentro de una transacción: el UPDATE
+// bloquea la fila del artículo hasta el final, así que el saldo de movimientos simultáneos
+// sobre el mismo artículo no se cruza. Retorna ErrStockInsuficiente si el stock quedaría
+// negativo y gorm.ErrRecordNotFound si el artículo no existe.
+func registrarMovimiento(tx *gorm.DB, movimiento *models.MovimientoRepuesto) error {
+	delta := movimiento.Cantidad
+	if movimiento.Tipo == models.MovimientoSalida {
+		delta = -movimiento.Cantidad
+	}
+	if movimiento.RealizadoPorID == nil {
+		if actor, ok := auditoria.ActorDesde(tx.Statement.Context); ok {
+			movimiento.RealizadoPorID = &actor.UsuarioID
+			movimiento.RealizadoPorUsername = actor.Username
+		}
+	}
+
+	resultado := tx.Model(&models.ArticuloRepuesto{}).
+		Where("id = ? AND stock + ? >= 0", movimiento.ArticuloID, delta).
+		Update("stock", gorm.Expr("stock + ?", delta))
+	if resultado.Error != nil {
+		return resultado.Error
+	}
+
+	var articulo models.ArticuloRepuesto
+	if err := tx.Select("id", "serial_numero_parte", "stock").First(&articulo, movimiento.ArticuloID).Error; err != nil {
+		return err
+	}
+	if resultado.RowsAffected == 0 {
+		return fmt.Errorf("%w de %s: hay %d", ErrStockInsuficiente, articulo.SerialNumeroParte, articulo.Stock)
+	}
+	movimiento.Saldo = articulo.Stock
+	return tx.Create(movimiento).Error
+}
+
+// vincularArticulo asocia un repuesto de un reporte con el artículo del almacén que indica.
+// Solo se descuenta del almacén lo que indica el artículo explícitamente: un repuesto sin
+// artículo no afecta el almacén aunque su serial esté en el catálogo. Los datos vacíos del
+// repuesto se completan con los del artículo.
+func vincularArticulo(tx *gorm.DB, repuesto *models.Repuesto) error {
+	if repuesto.ArticuloID == nil {
+		return nil
+	}
+	var articulo models.ArticuloRepuesto
+	if err := tx.First(&articulo, *repuesto.ArticuloID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %d", ErrArticuloNoEncontrado, *repuesto.ArticuloID)
+		}
+		return err
+	}
+
+	if repuesto.SerialNumeroParte == "" {
+		repuesto.SerialNumeroParte = articulo.SerialNumeroParte
+	}
+	if repuesto.Descripcion == "" {
+		repuesto.Descripcion = articulo.Descripcion
+	}
+	if repuesto.Marca == "" {
+		repuesto.Marca = articulo.Marca
+	}
+	if repuesto.Tecnologia == "" {
+		repuesto.Tecnologia = articulo.Tecnologia
+	}
+	if repuesto.Capacidad == "" {
+		repuesto.Capacidad = articulo.Capacidad
+	}
+	return nil
+}
+
+// registrarSalidaRepuesto descuenta del almacén un repuesto ya guardado que tiene artículo
+func registrarSalidaRepuesto(tx *gorm.DB, repuesto *models.Repuesto) error {
+	if repuesto.ArticuloID == nil {
+		return nil
+	}
+	return registrarMovimiento(tx, &models.MovimientoRepuesto{
+		ArticuloID: *repuesto.ArticuloID,
+		Tipo:       models.MovimientoSalida,
+		Cantidad:   repuesto.Cantidad,
+		ReporteID:  repuesto.ReporteID,
+		RepuestoID: &repuesto.ID,
+	})
+}
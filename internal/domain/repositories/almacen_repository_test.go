@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/infrastructure/auditoria"
+
+	"gorm.io/gorm"
+)
+
+func TestRegistrarMovimiento(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		inicial   int
+		tipo      string
+		cantidad  int
+		wantErr   error
+		wantStock int
+	}{
+		{"entrada", 5, models.MovimientoEntrada, 3, nil, 8},
+		{"salida parcial", 5, models.MovimientoSalida, 2, nil, 3},
+		{"salida de todo el stock", 5, models.MovimientoSalida, 5, nil, 0},
+		{"salida mayor que el stock", 5, models.MovimientoSalida, 6, ErrStockInsuficiente, 5},
+		{"salida sin stock", 0, models.MovimientoSalida, 1, ErrStockInsuficiente, 0},
+		{"ajuste positivo", 5, models.MovimientoAjuste, 2, nil, 7},
+		{"ajuste negativo", 5, models.MovimientoAjuste, -5, nil, 0},
+		{"ajuste que deja stock negativo", 5, models.MovimientoAjuste, -6, ErrStockInsuficiente, 5},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			db := dbDePrueba(t, &models.ArticuloRepuesto{}, &models.MovimientoRepuesto{})
+			repo := NewAlmacenRepository(db)
+			ctx := auditoria.ConActor(context.Background(), auditoria.Actor{UsuarioID: 7, Username: "almacenista"})
+
+			articulo := &models.ArticuloRepuesto{SerialNumeroParte: "RAM-8GB", Descripcion: "Memoria RAM"}
+			if err := repo.Create(ctx, articulo, tc.inicial); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			movimiento := &models.MovimientoRepuesto{ArticuloID: articulo.ID, Tipo: tc.tipo, Cantidad: tc.cantidad, Observaciones: "prueba"}
+			err := repo.RegistrarMovimiento(ctx, movimiento)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tc.wantErr)
+			}
+
+			guardado, err := repo.FindByID(articulo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if guardado.Stock != tc.wantStock {
+				t.Errorf("stock = %d, se esperaba %d", guardado.Stock, tc.wantStock)
+			}
+
+			var kardex []models.MovimientoRepuesto
+			if err := db.Where("articulo_id = ?", articulo.ID).Order("id").Find(&kardex).Error; err != nil {
+				t.Fatal(err)
+			}
+			movimientosEsperados := 1
+			if tc.inicial == 0 {
+				movimientosEsperados = 0
+			}
+			if tc.wantErr == nil {
+				movimientosEsperados++
+			}
+			if len(kardex) != movimientosEsperados {
+				t.Fatalf("el kardex tiene %d movimientos, se esperaban %d", len(kardex), movimientosEsperados)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+
+			ultimo := kardex[len(kardex)-1]
+			if ultimo.Saldo != tc.wantStock || movimiento.Saldo != tc.wantStock {
+				t.Errorf("saldo del movimiento = %d, se esperaba %d", ultimo.Saldo, tc.wantStock)
+			}
+			if ultimo.RealizadoPorID == nil || *ultimo.RealizadoPorID != 7 || ultimo.RealizadoPorUsername != "almacenista" {
+				t.Errorf("el movimiento no registra el usuario del contexto: %v %q", ultimo.RealizadoPorID, ultimo.RealizadoPorUsername)
+			}
+		})
+	}
+}
+
+func TestRegistrarMovimientoArticuloInexistente(t *testing.T) {
+	db := dbDePrueba(t, &models.ArticuloRepuesto{}, &models.MovimientoRepuesto{})
+	repo := NewAlmacenRepository(db)
+
+	err := repo.RegistrarMovimiento(context.Background(), &models.MovimientoRepuesto{ArticuloID: 99, Tipo: models.MovimientoEntrada, Cantidad: 1})
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("error = %v, se esperaba gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestMovimientosDeRepuestos(t *testing.T) {
+	reporteID := uint(3)
+
+	casos := []struct {
+		nombre      string
+		cantidad    int
+		conArticulo bool
+		eliminar    bool
+		wantErr     error
+		wantStock   int
+		wantTipos   []string // Movimientos del kardex después del inventario inicial
+	}{
+		{"uso en un reporte descuenta", 2, true, false, nil, 3, []string{models.MovimientoSalida}},
+		{"eliminar el repuesto lo devuelve", 2, true, true, nil, 5, []string{models.MovimientoSalida, models.MovimientoEntrada}},
+		{"uso mayor que el stock", 6, true, false, ErrStockInsuficiente, 5, nil},
+		{"repuesto sin artículo no afecta el almacén", 2, false, true, nil, 5, nil},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			db := dbDePrueba(t, &models.ArticuloRepuesto{}, &models.MovimientoRepuesto{}, &models.Repuesto{})
+			almacen := NewAlmacenRepository(db)
+			repuestos := NewRepuestoRepository(db)
+			ctx := context.Background()
+
+			articulo := &models.ArticuloRepuesto{SerialNumeroParte: "SSD-480", Descripcion: "Disco SSD", Marca: "Kingston"}
+			if err := almacen.Create(ctx, articulo, 5); err != nil {
+				t.Fatal(err)
+			}
+
+			repuesto := &models.Repuesto{ReporteID: &reporteID, Cantidad: tc.cantidad, SerialNumeroParte: "OTRO", Descripcion: "Repuesto"}
+			if tc.conArticulo {
+				repuesto.ArticuloID = &articulo.ID
+				repuesto.SerialNumeroParte = ""
+				repuesto.Descripcion = ""
+			}
+			err := repuestos.Create(ctx, repuesto)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tc.wantErr)
+			}
+			if err == nil && tc.conArticulo && (repuesto.SerialNumeroParte != "SSD-480" || repuesto.Marca != "Kingston") {
+				t.Errorf("el repuesto no tomó los datos del artículo: %+v", repuesto)
+			}
+
+			if tc.eliminar {
+				if err := repuestos.Delete(ctx, repuesto.ID); err != nil {
+					t.Fatalf("Delete: %v", err)
+				}
+				// Eliminarlo otra vez no debe devolver la cantidad de nuevo
+				if err := repuestos.Delete(ctx, repuesto.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+					t.Fatalf("segundo Delete: error = %v, se esperaba gorm.ErrRecordNotFound", err)
+				}
+			}
+
+			guardado, err := almacen.FindByID(articulo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if guardado.Stock != tc.wantStock {
+				t.Errorf("stock = %d, se esperaba %d", guardado.Stock, tc.wantStock)
+			}
+
+			var kardex []models.MovimientoRepuesto
+			if err := db.Where("articulo_id = ?", articulo.ID).Order("id").Find(&kardex).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(kardex) != 1+len(tc.wantTipos) {
+				t.Fatalf("el kardex tiene %d movimientos, se esperaban %d", len(kardex), 1+len(tc.wantTipos))
+			}
+			for i, tipo := range tc.wantTipos {
+				m := kardex[i+1]
+				if m.Tipo != tipo || m.Cantidad != tc.cantidad {
+					t.Errorf("movimiento %d = %s de %d, se esperaba %s de %d", i, m.Tipo, m.Cantidad, tipo, tc.cantidad)
+				}
+				if m.ReporteID == nil || *m.ReporteID != reporteID || m.RepuestoID == nil || *m.RepuestoID != repuesto.ID {
+					t.Errorf("movimiento %d no referencia el reporte y el repuesto", i)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteArticulo(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		stock   int
+		enUso   bool
+		wantErr error
+	}{
+		{"sin stock ni uso", 0, false, nil},
+		{"con stock", 2, false, ErrArticuloConStock},
+		{"usado en un reporte", 0, true, ErrArticuloEnUso},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			db := dbDePrueba(t, &models.ArticuloRepuesto{}, &models.MovimientoRepuesto{}, &models.Repuesto{})
+			almacen := NewAlmacenRepository(db)
+			ctx := context.Background()
+
+			articulo := &models.ArticuloRepuesto{SerialNumeroParte: "FUENTE-500", Descripcion: "Fuente de poder"}
+			if err := almacen.Create(ctx, articulo, tc.stock); err != nil {
+				t.Fatal(err)
+			}
+			if tc.enUso {
+				repuesto := &models.Repuesto{ArticuloID: &articulo.ID, Cantidad: 1, SerialNumeroParte: "FUENTE-500", Descripcion: "Fuente de poder"}
+				if err := db.Create(repuesto).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := almacen.Delete(ctx, articulo.ID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tc.wantErr)
+			}
+			_, err = almacen.FindByID(articulo.ID)
+			if eliminado := errors.Is(err, gorm.ErrRecordNotFound); eliminado != (tc.wantErr == nil) {
+				t.Errorf("artículo eliminado = %v, se esperaba %v", eliminado, tc.wantErr == nil)
+			}
+		})
+	}
+
+	t.Run("el serial de un artículo eliminado se puede reutilizar", func(t *testing.T) {
+		db := dbDePrueba(t, &models.ArticuloRepuesto{}, &models.MovimientoRepuesto{}, &models.Repuesto{})
+		almacen := NewAlmacenRepository(db)
+		ctx := context.Background()
+
+		primero := &models.ArticuloRepuesto{SerialNumeroParte: "TECLADO", Descripcion: "Teclado"}
+		if err := almacen.Create(ctx, primero, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := almacen.Delete(ctx, primero.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := almacen.Create(ctx, &models.ArticuloRepuesto{SerialNumeroParte: "TECLADO", Descripcion: "Teclado"}, 0); err != nil {
+			t.Errorf("Create con el serial de un artículo eliminado: %v", err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AsignacionEquipoRepository define las operaciones del repositorio para AsignacionEquipo
+type AsignacionEquipoRepository interface {
+	Create(ctx context.Context, asignacion *models.AsignacionEquipo) error
+	FindByID(id uint) (*models.AsignacionEquipo, error)
+	RegistrarActaFirmada(ctx context.Context, id uint, archivo, actaURL string) error
+	FindByEquipoID(equipoID uint) ([]models.AsignacionEquipo, error)
+	FindByResponsableID(responsableID uint) ([]models.AsignacionEquipo, error)
+}
+
+// asignacionEquipoRepository implementa AsignacionEquipoRepository
+type asignacionEquipoRepository struct {
+	db *gorm.DB
+}
+
+// NewAsignacionEquipoRepository crea una nueva instancia de AsignacionEquipoRepository
+func NewAsignacionEquipoRepository(db *gorm.DB) AsignacionEquipoRepository {
+	return &asignacionEquipoRepository{db: db}
+}
+
+// Create registra una asignación en el historial de custodia
+func (r *asignacionEquipoRepository) Create(ctx context.Context, asignacion *models.AsignacionEquipo) error {
+	return r.db.WithContext(ctx).Create(asignacion).Error
+}
+
+// FindByID busca una asignación por su ID con los responsables involucrados
+func (r *asignacionEquipoRepository) FindByID(id uint) (*models.AsignacionEquipo, error) {
+	var asignacion models.AsignacionEquipo
+	err := r.db.Preload("ResponsableAnterior").
+		Preload("ResponsableNuevo").
+		First(&asignacion, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &asignacion, nil
+}
+
+// RegistrarActaFirmada guarda el nombre y la ruta del acta de entrega firmada y la fecha en
+// que se subió. Si la asignación aún no tiene los componentes del acta, se fijan los
+// actuales del equipo: son los que constan en el acta que se firmó.
+func (r *asignacionEquipoRepository) RegistrarActaFirmada(ctx context.Context, id uint, archivo, actaURL string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var asignacion models.AsignacionEquipo
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "equipo_id", "componentes").First(&asignacion, id).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		asignacion.ActaFirmadaURL = actaURL
+		asignacion.ActaFirmadaArchivo = archivo
+		asignacion.FechaActaFirmada = &now
+		columnas := []string{"acta_firmada_url", "acta_firmada_archivo", "fecha_acta_firmada"}
+		if asignacion.Componentes == nil {
+			if asignacion.Componentes, err = fotoComponentes(tx, asignacion.EquipoID); err != nil {
+				return err
+			}
+			columnas = append(columnas, "componentes")
+		}
+		return tx.Model(&asignacion).Select(columnas).Updates(&asignacion).Error
+	})
+}
+
+// fotoComponentes toma los datos y componentes actuales del equipo para fijarlos en un acta
+func fotoComponentes(tx *gorm.DB, equipoID uint) (*models.ComponentesActa, error) {
+	var equipo models.Equipo
+	err := tx.Preload("EstadoEquipo").Preload("Perifericos").Preload("HardwareInterno").Preload("Software").
+		First(&equipo, equipoID).Error
+	if err != nil {
+		return nil, err
+	}
+	return models.NuevosComponentesActa(&equipo), nil
+}
+
+// FindByEquipoID obtiene el historial de custodia de un equipo, del más reciente al más antiguo
+func (r *asignacionEquipoRepository) FindByEquipoID(equipoID uint) ([]models.AsignacionEquipo, error) {
+	var asignaciones []models.AsignacionEquipo
+	err := r.db.Preload("ResponsableAnterior").
+		Preload("ResponsableNuevo").
+		Where("equipo_id = ?", equipoID).
+		Order("fecha_inicio DESC, id DESC").
+		Find(&asignaciones).Error
+	return asignaciones, err
+}
+
+// FindByResponsableID obtiene los equipos que ha tenido a cargo un usuario responsable,
+// del más reciente al más antiguo
+func (r *asignacionEquipoRepository) FindByResponsableID(responsableID uint) ([]models.AsignacionEquipo, error) {
+	var asignaciones []models.AsignacionEquipo
+	err := r.db.Preload("Equipo").
+		Preload("ResponsableAnterior").
+		Where("responsable_nuevo_id = ?", responsableID).
+		Order("fecha_inicio DESC, id DESC").
+		Find(&asignaciones).Error
+	return asignaciones, err
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+
+	"gorm.io/gorm"
+)
+
+// AuditoriaRepository define las operaciones del repositorio para Auditoria
+type AuditoriaRepository interface {
+	Create(ctx context.Context, auditoria *models.Auditoria) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.Auditoria, int64, error)
+	FindByEquipoID(equipoID uint) ([]models.Auditoria, error)
+}
+
+// auditoriaRepository implementa AuditoriaRepository
+type auditoriaRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditoriaRepository crea una nueva instancia de AuditoriaRepository
+func NewAuditoriaRepository(db *gorm.DB) AuditoriaRepository {
+	return &auditoriaRepository{db: db}
+}
+
+// Create registra un evento de auditoría
+func (r *auditoriaRepository) Create(ctx context.Context, auditoria *models.Auditoria) error {
+	return r.db.WithContext(ctx).Create(auditoria).Error
+}
+
+// FindAll obtiene una página de registros de auditoría, por defecto del más reciente al más antiguo
+func (r *auditoriaRepository) FindAll(consulta dto.ConsultaDTO) ([]models.Auditoria, int64, error) {
+	return paginar[models.Auditoria](r.db.Model(&models.Auditoria{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "created_at": "created_at", "entidad": "entidad", "usuario": "username"},
+		ordenPorDef: "created_at",
+		descPorDef:  true,
+		filtros: map[string]filtroConsulta{
+			"entidad":    filtroIgual("entidad"),
+			"entidad_id": filtroID("entidad_id", "entidad_id = ?"),
+			"usuario_id": filtroID("usuario_id", "usuario_id = ?"),
+			"accion":     filtroIgual("accion"),
+		},
+		fecha: "created_at",
+	})
+}
+
+// FindByEquipoID obtiene los cambios del equipo y de sus componentes, del más reciente al más antiguo
+func (r *auditoriaRepository) FindByEquipoID(equipoID uint) ([]models.Auditoria, error) {
+	var registros []models.Auditoria
+	err := r.db.Where("equipo_id = ?", equipoID).Order("created_at DESC, id DESC").Find(&registros).Error
+	return registros, err
+}
@@ -1,18 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // BackupRepository define las operaciones del repositorio para Backup
 type BackupRepository interface {
-	Create(backup *models.Backup) error
+	Create(ctx context.Context, backup *models.Backup) error
 	FindByID(id uint) (*models.Backup, error)
-	Update(backup *models.Backup) error
-	Delete(id uint) error
-	FindAll() ([]models.Backup, error)
+	Update(ctx context.Context, backup *models.Backup) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.Backup, int64, error)
 	FindByEquipoID(equipoID uint) ([]models.Backup, error)
 }
 
@@ -27,8 +29,8 @@ func NewBackupRepository(db *gorm.DB) BackupRepository {
 }
 
 // Create crea un nuevo backup en la base de datos
-func (r *backupRepository) Create(backup *models.Backup) error {
-	return r.db.Create(backup).Error
+func (r *backupRepository) Create(ctx context.Context, backup *models.Backup) error {
+	return r.db.WithContext(ctx).Create(backup).Error
 }
 
 // FindByID busca un backup por su ID
@@ -42,20 +44,29 @@ func (r *backupRepository) FindByID(id uint) (*models.Backup, error) {
 }
 
 // Update actualiza un backup existente
-func (r *backupRepository) Update(backup *models.Backup) error {
-	return r.db.Save(backup).Error
+func (r *backupRepository) Update(ctx context.Context, backup *models.Backup) error {
+	return r.db.WithContext(ctx).Save(backup).Error
 }
 
 // Delete elimina un backup por su ID
-func (r *backupRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Backup{}, id).Error
+func (r *backupRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Backup{}, id).Error
 }
 
-// FindAll retorna todos los backups
-func (r *backupRepository) FindAll() ([]models.Backup, error) {
-	var backups []models.Backup
-	err := r.db.Find(&backups).Error
-	return backups, err
+// FindAll retorna una página de backups según la consulta
+func (r *backupRepository) FindAll(consulta dto.ConsultaDTO) ([]models.Backup, int64, error) {
+	return paginar[models.Backup](r.db.Model(&models.Backup{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "fecha": "fecha", "num_carpetas": "num_carpetas"},
+		ordenPorDef: "fecha",
+		descPorDef:  true,
+		filtros: map[string]filtroConsulta{
+			"equipo":    filtroID("equipo", "equipo_id = ?"),
+			"realizado": filtroBool("realizado", "se_realizo_backup"),
+			"ruta":      filtroTexto("ruta_backup"),
+		},
+		fecha: "fecha",
+	})
 }
 
 // FindByEquipoID retorna todos los backups asociados a un equipo
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"tum_inv_backend/internal/domain/models/dto"
+
+	"gorm.io/gorm"
+)
+
+// BusquedaRepository define la búsqueda global sobre el inventario
+type BusquedaRepository interface {
+	Buscar(texto string, limite int) ([]dto.ResultadoBusquedaDTO, error)
+}
+
+type busquedaRepository struct {
+	db *gorm.DB
+}
+
+// NewBusquedaRepository crea una nueva instancia de BusquedaRepository
+func NewBusquedaRepository(db *gorm.DB) BusquedaRepository {
+	return &busquedaRepository{db: db}
+}
+
+// campoBusqueda es una columna en la que se busca. Las columnas con texto libre
+// (nombres) también se comparan por palabras con búsqueda de texto completo.
+type campoBusqueda struct {
+	tipo        string
+	tabla       string // Tabla con alias, por ejemplo "equipos e"
+	joins       string
+	id          string
+	columna     string
+	campo       string
+	descripcion string
+	equipoID    string
+	placa       string
+	textoLibre  bool
+}
+
+// Columnas con índice trigram (y de texto completo en los nombres), ver database.CrearIndicesBusqueda
+var camposBusqueda = []campoBusqueda{
+	{
+		tipo: dto.TipoResultadoEquipo, tabla: "equipos e",
+		id: "e.id", columna: "e.serial", campo: "serial",
+		descripcion: "e.tipo_dispositivo || ' ' || e.marca || ' ' || COALESCE(e.modelo, '')",
+		equipoID:    "e.id", placa: "e.placa_inventario",
+	},
+	{
+		tipo: dto.TipoResultadoEquipo, tabla: "equipos e",
+		id: "e.id", columna: "e.placa_inventario", campo: "placa_inventario",
+		descripcion: "e.tipo_dispositivo || ' ' || e.marca || ' ' || COALESCE(e.modelo, '')",
+		equipoID:    "e.id", placa: "e.placa_inventario",
+	},
+	{
+		tipo: dto.TipoResultadoPeriferico, tabla: "perifericos p",
+		joins: "LEFT JOIN equipos e ON e.id = p.equipo_id AND e.deleted_at IS NULL",
+		id:    "p.id", columna: "p.serial", campo: "serial",
+		descripcion: "COALESCE(p.tipo_periferico, '') || ' ' || COALESCE(p.marca, '')",
+		equipoID:    "e.id", placa: "e.placa_inventario",
+	},
+	// Un responsable aparece una vez por cada equipo a su cargo, o una sola vez si no tiene equipos
+	{
+		tipo: dto.TipoResultadoResponsable, tabla: "usuario_responsables ur",
+		joins: "LEFT JOIN equipos e ON e.usuario_responsable_id = ur.id AND e.deleted_at IS NULL",
+		id:    "ur.id", columna: "ur.nombres_apellidos", campo: "nombres_apellidos",
+		descripcion: "'C.C. ' || ur.cedula",
+		equipoID:    "e.id", placa: "e.placa_inventario", textoLibre: true,
+	},
+	{
+		tipo: dto.TipoResultadoResponsable, tabla: "usuario_responsables ur",
+		joins: "LEFT JOIN equipos e ON e.usuario_responsable_id = ur.id AND e.deleted_at IS NULL",
+		id:    "ur.id", columna: "ur.cedula", campo: "cedula",
+		descripcion: "ur.nombres_apellidos",
+		equipoID:    "e.id", placa: "e.placa_inventario",
+	},
+	{
+		tipo: dto.TipoResultadoConfiguracionRed, tabla: "configuracion_reds cr",
+		joins: "LEFT JOIN equipos e ON e.id = cr.equipo_id AND e.deleted_at IS NULL",
+		id:    "cr.id", columna: "cr.nombre_dispositivo", campo: "nombre_dispositivo",
+		descripcion: "cr.direccion_ip",
+		equipoID:    "e.id", placa: "e.placa_inventario",
+	},
+	{
+		tipo: dto.TipoResultadoConfiguracionRed, tabla: "configuracion_reds cr",
+		joins: "LEFT JOIN equipos e ON e.id = cr.equipo_id AND e.deleted_at IS NULL",
+		id:    "cr.id", columna: "cr.direccion_ip", campo: "direccion_ip",
+		descripcion: "cr.nombre_dispositivo",
+		equipoID:    "e.id", placa: "e.placa_inventario",
+	},
+	{
+		tipo: dto.TipoResultadoSoftware, tabla: "softwares s",
+		joins: "LEFT JOIN equipos e ON e.id = s.equipo_id AND e.deleted_at IS NULL",
+		id:    "s.id", columna: "s.nombre", campo: "nombre",
+		descripcion: "s.nombre || ' ' || COALESCE(s.version, '')",
+		equipoID:    "e.id", placa: "e.placa_inventario", textoLibre: true,
+	},
+}
+
+// consultaBusqueda arma la consulta de una columna: coincidencia parcial (ILIKE) o
+// similitud por trigramas (<%), más texto completo en las columnas de nombres.
+// El rango prioriza coincidencia exacta, luego prefijo, luego similitud.
+func (c campoBusqueda) consultaBusqueda() string {
+	condicion := fmt.Sprintf("%s ILIKE @patron OR @texto <%% %s", c.columna, c.columna)
+	rango := fmt.Sprintf("word_similarity(@texto, %s)", c.columna)
+	if c.textoLibre {
+		vector := fmt.Sprintf("to_tsvector('simple', %s)", c.columna)
+		condicion += fmt.Sprintf(" OR %s @@ plainto_tsquery('simple', @texto)", vector)
+		rango = fmt.Sprintf("GREATEST(%s, ts_rank(%s, plainto_tsquery('simple', @texto)))", rango, vector)
+	}
+	return fmt.Sprintf(`SELECT '%s' AS tipo, %s AS id, '%s' AS campo, %s AS valor,
+		%s AS descripcion, %s AS equipo_id, COALESCE(%s, '') AS equipo_placa,
+		CASE WHEN lower(%s) = lower(@texto) THEN 3
+			WHEN %s ILIKE @prefijo THEN 2
+			ELSE %s END AS rango
+		FROM %s %s
+		WHERE %s.deleted_at IS NULL AND (%s)`,
+		c.tipo, c.id, c.campo, c.columna,
+		c.descripcion, c.equipoID, c.placa,
+		c.columna, c.columna, rango,
+		c.tabla, c.joins,
+		strings.Fields(c.tabla)[1], condicion)
+}
+
+// Buscar devuelve las coincidencias del texto en todas las columnas, ordenadas por relevancia
+func (r *busquedaRepository) Buscar(texto string, limite int) ([]dto.ResultadoBusquedaDTO, error) {
+	consultas := make([]string, len(camposBusqueda))
+	for i, c := range camposBusqueda {
+		consultas[i] = c.consultaBusqueda()
+	}
+	consulta := strings.Join(consultas, "\nUNION ALL\n") +
+		"\nORDER BY rango DESC, tipo, id\nLIMIT @limite"
+
+	escapado := escaparLike(texto)
+	var resultados []dto.ResultadoBusquedaDTO
+	err := r.db.Raw(consulta,
+		sql.Named("texto", texto),
+		sql.Named("patron", "%"+escapado+"%"),
+		sql.Named("prefijo", escapado+"%"),
+		sql.Named("limite", limite),
+	).Scan(&resultados).Error
+	return resultados, err
+}
@@ -1,18 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // ConfiguracionRedRepository define las operaciones del repositorio para ConfiguracionRed
 type ConfiguracionRedRepository interface {
-	Create(configuracion *models.ConfiguracionRed) error
+	Create(ctx context.Context, configuracion *models.ConfiguracionRed) error
 	FindByID(id uint) (*models.ConfiguracionRed, error)
-	Update(configuracion *models.ConfiguracionRed) error
-	Delete(id uint) error
-	FindAll() ([]models.ConfiguracionRed, error)
+	Update(ctx context.Context, configuracion *models.ConfiguracionRed) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.ConfiguracionRed, int64, error)
 	FindByEquipoID(equipoID uint) (*models.ConfiguracionRed, error)
 }
 
@@ -27,8 +29,8 @@ func NewConfiguracionRedRepository(db *gorm.DB) ConfiguracionRedRepository {
 }
 
 // Create crea una nueva configuración de red en la base de datos
-func (r *configuracionRedRepository) Create(configuracion *models.ConfiguracionRed) error {
-	return r.db.Create(configuracion).Error
+func (r *configuracionRedRepository) Create(ctx context.Context, configuracion *models.ConfiguracionRed) error {
+	return r.db.WithContext(ctx).Create(configuracion).Error
 }
 
 // FindByID busca una configuración de red por su ID
@@ -42,20 +44,30 @@ func (r *configuracionRedRepository) FindByID(id uint) (*models.ConfiguracionRed
 }
 
 // Update actualiza una configuración de red existente
-func (r *configuracionRedRepository) Update(configuracion *models.ConfiguracionRed) error {
-	return r.db.Save(configuracion).Error
+func (r *configuracionRedRepository) Update(ctx context.Context, configuracion *models.ConfiguracionRed) error {
+	return r.db.WithContext(ctx).Save(configuracion).Error
 }
 
 // Delete elimina una configuración de red por su ID
-func (r *configuracionRedRepository) Delete(id uint) error {
-	return r.db.Delete(&models.ConfiguracionRed{}, id).Error
+func (r *configuracionRedRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ConfiguracionRed{}, id).Error
 }
 
-// FindAll retorna todas las configuraciones de red
-func (r *configuracionRedRepository) FindAll() ([]models.ConfiguracionRed, error) {
-	var configuraciones []models.ConfiguracionRed
-	err := r.db.Find(&configuraciones).Error
-	return configuraciones, err
+// FindAll retorna una página de configuraciones de red según la consulta
+func (r *configuracionRedRepository) FindAll(consulta dto.ConsultaDTO) ([]models.ConfiguracionRed, int64, error) {
+	return paginar[models.ConfiguracionRed](r.db.Model(&models.ConfiguracionRed{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "direccion_ip": "direccion_ip", "nombre_dispositivo": "nombre_dispositivo", "created_at": "created_at"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"equipo":             filtroID("equipo", "equipo_id = ?"),
+			"direccion_ip":       filtroTexto("direccion_ip"),
+			"asignacion_ip":      filtroIgual("asignacion_ip"),
+			"nombre_dispositivo": filtroTexto("nombre_dispositivo"),
+			"conectividad":       filtroIgual("conectividad"),
+		},
+		fecha: "created_at",
+	})
 }
 
 // FindByEquipoID retorna la configuración de red asociada a un equipo
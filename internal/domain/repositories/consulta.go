@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+
+	"gorm.io/gorm"
+)
+
+// filtroConsulta aplica a la consulta el valor recibido en un parámetro de la URL
+type filtroConsulta func(db *gorm.DB, valor string) (*gorm.DB, error)
+
+// opcionesConsulta define, para un listado, los campos por los que se puede ordenar y filtrar
+type opcionesConsulta struct {
+	id          string                    // Columna ID, desempata el orden
+	orden       map[string]string         // Parámetro sort -> columna
+	ordenPorDef string                    // Columna de orden cuando no se indica sort
+	descPorDef  bool                      // Dirección cuando no se indica sort
+	filtros     map[string]filtroConsulta // Parámetro -> filtro
+	fecha       string                    // Columna usada por desde/hasta
+	columnas    string                    // Select de la página; vacío = todas las columnas del modelo
+	relaciones  []string                  // Preload de la página; no se aplican al conteo
+}
+
+// paginar aplica filtros, rango de fechas, orden y paginación a la consulta base y
+// devuelve la página pedida junto con el total de registros que cumplen los filtros
+func paginar[T any](base *gorm.DB, consulta dto.ConsultaDTO, opciones opcionesConsulta) ([]T, int64, error) {
+	q, orden, err := aplicarConsulta(base, consulta, opciones)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// La sesión permite reutilizar las condiciones para el conteo y para la página
+	q = q.Session(&gorm.Session{})
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if opciones.columnas != "" {
+		q = q.Select(opciones.columnas)
+	}
+	for _, relacion := range opciones.relaciones {
+		q = q.Preload(relacion)
+	}
+
+	var datos []T
+	err = q.Order(orden).Offset(consulta.Offset()).Limit(consulta.TamanoPagina).Find(&datos).Error
+	return datos, total, err
+}
+
+// tamanoLoteRecorrido es el número de registros que se leen por consulta al recorrer un listado completo
+const tamanoLoteRecorrido = 500
+
+// recorrerConsulta aplica los mismos filtros y orden que paginar, pero recorre todos los
+// registros por lotes, por ejemplo para exportarlos sin cargarlos todos en memoria
+func recorrerConsulta[T any](base *gorm.DB, consulta dto.ConsultaDTO, opciones opcionesConsulta, procesar func(lote []T) error) error {
+	q, orden, err := aplicarConsulta(base, consulta, opciones)
+	if err != nil {
+		return err
+	}
+	if opciones.columnas != "" {
+		q = q.Select(opciones.columnas)
+	}
+	q = q.Order(orden).Session(&gorm.Session{})
+
+	for offset := 0; ; offset += tamanoLoteRecorrido {
+		var lote []T
+		if err := q.Offset(offset).Limit(tamanoLoteRecorrido).Find(&lote).Error; err != nil {
+			return err
+		}
+		if len(lote) == 0 {
+			return nil
+		}
+		if err := procesar(lote); err != nil {
+			return err
+		}
+		if len(lote) < tamanoLoteRecorrido {
+			return nil
+		}
+	}
+}
+
+// aplicarConsulta aplica filtros y rango de fechas a la consulta base y devuelve
+// también la cláusula de orden, con el ID como desempate
+func aplicarConsulta(base *gorm.DB, consulta dto.ConsultaDTO, opciones opcionesConsulta) (*gorm.DB, string, error) {
+	q := base
+	for nombre, valor := range consulta.Filtros {
+		filtro, ok := opciones.filtros[nombre]
+		if !ok || valor == "" {
+			continue
+		}
+		var err error
+		if q, err = filtro(q, valor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if consulta.Desde != nil || consulta.Hasta != nil {
+		if opciones.fecha == "" {
+			return nil, "", fmt.Errorf("%w: este listado no admite filtro por fechas", dto.ErrConsultaInvalida)
+		}
+		if consulta.Desde != nil {
+			q = q.Where(opciones.fecha+" >= ?", *consulta.Desde)
+		}
+		if consulta.Hasta != nil {
+			q = q.Where(opciones.fecha+" <= ?", *consulta.Hasta)
+		}
+	}
+
+	columna := opciones.ordenPorDef
+	desc := opciones.descPorDef
+	if consulta.Orden != "" {
+		var ok bool
+		if columna, ok = opciones.orden[consulta.Orden]; !ok {
+			return nil, "", fmt.Errorf("%w: no se puede ordenar por %s", dto.ErrConsultaInvalida, consulta.Orden)
+		}
+		desc = consulta.Descendente
+	}
+	direccion := " ASC"
+	if desc {
+		direccion = " DESC"
+	}
+
+	orden := columna + direccion
+	if columna != opciones.id {
+		orden += ", " + opciones.id + direccion
+	}
+	return q, orden, nil
+}
+
+// filtroIgual filtra por igualdad exacta con la columna
+func filtroIgual(columna string) filtroConsulta {
+	return func(db *gorm.DB, valor string) (*gorm.DB, error) {
+		return db.Where(columna+" = ?", valor), nil
+	}
+}
+
+// filtroTexto filtra por coincidencia parcial, sin distinguir mayúsculas
+func filtroTexto(columna string) filtroConsulta {
+	return func(db *gorm.DB, valor string) (*gorm.DB, error) {
+		return db.Where(columna+" ILIKE ?", "%"+escaparLike(valor)+"%"), nil
+	}
+}
+
+// filtroID filtra por un ID; la condición recibe el ID como único argumento
+func filtroID(nombre, condicion string) filtroConsulta {
+	return func(db *gorm.DB, valor string) (*gorm.DB, error) {
+		id, err := strconv.ParseUint(valor, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s debe ser un ID", dto.ErrConsultaInvalida, nombre)
+		}
+		return db.Where(condicion, uint(id)), nil
+	}
+}
+
+// filtroPorID valida que el valor sea un ID y aplica la condición armada con él
+func filtroPorID(nombre string, aplicar func(db *gorm.DB, id uint) *gorm.DB) filtroConsulta {
+	return func(db *gorm.DB, valor string) (*gorm.DB, error) {
+		id, err := strconv.ParseUint(valor, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s debe ser un ID", dto.ErrConsultaInvalida, nombre)
+		}
+		return aplicar(db, uint(id)), nil
+	}
+}
+
+// filtroBool filtra una columna booleana con true/false
+func filtroBool(nombre, columna string) filtroConsulta {
+	return func(db *gorm.DB, valor string) (*gorm.DB, error) {
+		b, err := strconv.ParseBool(valor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s debe ser true o false", dto.ErrConsultaInvalida, nombre)
+		}
+		return db.Where(columna+" = ?", b), nil
+	}
+}
+
+// dependenciasDeSecretaria es la subconsulta de los IDs de las dependencias de una secretaría
+func dependenciasDeSecretaria(db *gorm.DB, secretariaID uint) *gorm.DB {
+	return db.Model(&models.Dependencia{}).Select("id").Where("secretaria_id = ?", secretariaID)
+}
+
+// responsablesDeDependencias es la subconsulta de los IDs de los usuarios responsables
+// que pertenecen a las dependencias indicadas (un ID o una subconsulta)
+func responsablesDeDependencias(db *gorm.DB, dependencias interface{}) *gorm.DB {
+	return db.Model(&models.UsuarioResponsable{}).Select("id").Where("dependencia_id IN (?)", dependencias)
+}
+
+// escaparLike evita que % y _ del valor se interpreten como comodines
+func escaparLike(valor string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(valor)
+}
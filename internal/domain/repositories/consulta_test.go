@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	_ "tum_inv_backend/internal/infrastructure/cifrado"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dbSinConexion arma las consultas de Postgres sin ejecutarlas, para revisar el SQL generado
+func dbSinConexion(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return db
+}
+
+func TestAplicarConsultaOrden(t *testing.T) {
+	db := dbSinConexion(t)
+	opciones := (&equipoRepository{db: db}).opcionesConsulta("")
+	conPrefijo := (&equipoRepository{db: db}).opcionesConsulta("e.")
+	sinDesempate := opcionesConsulta{id: "id", orden: map[string]string{"id": "id"}, ordenPorDef: "id", descPorDef: true}
+
+	casos := []struct {
+		nombre   string
+		consulta dto.ConsultaDTO
+		opciones opcionesConsulta
+		want     string
+		wantErr  bool
+	}{
+		{"por defecto", dto.ConsultaDTO{}, opciones, "id ASC", false},
+		{"por defecto descendente", dto.ConsultaDTO{}, sinDesempate, "id DESC", false},
+		{"campo permitido", dto.ConsultaDTO{Orden: "marca"}, opciones, "marca ASC, id ASC", false},
+		{"campo con otro nombre de columna", dto.ConsultaDTO{Orden: "placa", Descendente: true}, opciones, "placa_inventario DESC, id DESC", false},
+		{"por ID no repite el desempate", dto.ConsultaDTO{Orden: "id", Descendente: true}, opciones, "id DESC", false},
+		{"con alias de tabla", dto.ConsultaDTO{Orden: "serial"}, conPrefijo, "e.serial ASC, e.id ASC", false},
+		{"descendente sin campo usa el orden por defecto", dto.ConsultaDTO{Descendente: true}, opciones, "id ASC", false},
+		{"campo no permitido", dto.ConsultaDTO{Orden: "password"}, opciones, "", true},
+		{"columna real fuera de la lista", dto.ConsultaDTO{Orden: "placa_inventario"}, opciones, "", true},
+		{"inyección SQL", dto.ConsultaDTO{Orden: "id; DROP TABLE equipos"}, opciones, "", true},
+		{"expresión", dto.ConsultaDTO{Orden: "(SELECT 1)"}, opciones, "", true},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			_, orden, err := aplicarConsulta(db.Model(&models.Equipo{}), tc.consulta, tc.opciones)
+			if tc.wantErr {
+				if !errors.Is(err, dto.ErrConsultaInvalida) {
+					t.Fatalf("error = %v, se esperaba ErrConsultaInvalida", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if orden != tc.want {
+				t.Errorf("orden = %q, se esperaba %q", orden, tc.want)
+			}
+		})
+	}
+}
+
+func TestAplicarConsultaFiltros(t *testing.T) {
+	db := dbSinConexion(t)
+	opciones := (&equipoRepository{db: db}).opcionesConsulta("")
+	desde := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sinFecha := opciones
+	sinFecha.fecha = ""
+
+	casos := []struct {
+		nombre     string
+		consulta   dto.ConsultaDTO
+		opciones   opcionesConsulta
+		contiene   []string
+		noContiene []string
+		wantErr    bool
+	}{
+		{"sin filtros", dto.ConsultaDTO{}, opciones, []string{`WHERE "equipos"."deleted_at" IS NULL ORDER BY id ASC`}, []string{" AND "}, false},
+		{"texto parcial", dto.ConsultaDTO{Filtros: map[string]string{"marca": "HP"}}, opciones, []string{"marca ILIKE '%HP%'", "ORDER BY id ASC"}, nil, false},
+		{"comodines escapados", dto.ConsultaDTO{Filtros: map[string]string{"serial": "50%_a"}}, opciones, []string{`serial ILIKE '%50\%\_a%'`}, nil, false},
+		{"igualdad", dto.ConsultaDTO{Filtros: map[string]string{"tipo_dispositivo": "Portátil"}}, opciones, []string{"tipo_dispositivo = 'Portátil'"}, nil, false},
+		{"estado por ID", dto.ConsultaDTO{Filtros: map[string]string{"estado": "3"}}, opciones, []string{"estado_equipo_id = 3"}, nil, false},
+		{"estado por nombre", dto.ConsultaDTO{Filtros: map[string]string{"estado": "Activo"}}, opciones, []string{`estado_equipo_id IN (SELECT "id" FROM "estado_equipos" WHERE nombre = 'Activo'`}, nil, false},
+		{"filtro desconocido se ignora", dto.ConsultaDTO{Filtros: map[string]string{"clave": "x"}}, opciones, nil, []string{"clave", " AND "}, false},
+		{"filtro vacío se ignora", dto.ConsultaDTO{Filtros: map[string]string{"marca": ""}}, opciones, nil, []string{"marca", " AND "}, false},
+		{"rango de fechas", dto.ConsultaDTO{Desde: &desde}, opciones, []string{"fecha_diligenciamiento >= '2024-01-01"}, nil, false},
+		{"responsable no numérico", dto.ConsultaDTO{Filtros: map[string]string{"responsable": "abc"}}, opciones, nil, nil, true},
+		{"fechas en listado sin fecha", dto.ConsultaDTO{Desde: &desde}, sinFecha, nil, nil, true},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			var errConsulta error
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				q, orden, err := aplicarConsulta(tx.Model(&models.Equipo{}), tc.consulta, tc.opciones)
+				if err != nil {
+					errConsulta = err
+					return tx
+				}
+				var equipos []models.Equipo
+				return q.Order(orden).Find(&equipos)
+			})
+
+			if tc.wantErr {
+				if !errors.Is(errConsulta, dto.ErrConsultaInvalida) {
+					t.Fatalf("error = %v, se esperaba ErrConsultaInvalida", errConsulta)
+				}
+				return
+			}
+			if errConsulta != nil {
+				t.Fatalf("error inesperado: %v", errConsulta)
+			}
+			for _, s := range tc.contiene {
+				if !strings.Contains(sql, s) {
+					t.Errorf("el SQL no contiene %q:\n%s", s, sql)
+				}
+			}
+			for _, s := range tc.noContiene {
+				if strings.Contains(sql, s) {
+					t.Errorf("el SQL no debe contener %q:\n%s", s, sql)
+				}
+			}
+		})
+	}
+}
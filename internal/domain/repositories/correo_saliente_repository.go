@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CorreoSalienteRepository define las operaciones de la bandeja de salida de correos
+type CorreoSalienteRepository interface {
+	Create(ctx context.Context, correo *models.CorreoSaliente) error
+	Arrendar(ctx context.Context, trabajador string, duracion time.Duration) (*models.CorreoSaliente, error)
+	Finalizar(ctx context.Context, correo *models.CorreoSaliente, trabajador string) (bool, error)
+	DeleteEnviadosAntes(ctx context.Context, fecha time.Time) (int64, error)
+	FindReporte(ctx context.Context, id uint) (*models.ReporteServicio, error)
+	CorreoDependencia(dependenciaID *uint, nombre string) (string, error)
+}
+
+// correoSalienteRepository implementa CorreoSalienteRepository
+type correoSalienteRepository struct {
+	db *gorm.DB
+}
+
+// NewCorreoSalienteRepository crea una nueva instancia de CorreoSalienteRepository
+func NewCorreoSalienteRepository(db *gorm.DB) CorreoSalienteRepository {
+	return &correoSalienteRepository{db: db}
+}
+
+// Create deja un correo en la bandeja de salida, en la transacción del contexto si la hay
+func (r *correoSalienteRepository) Create(ctx context.Context, correo *models.CorreoSaliente) error {
+	return conexion(ctx, r.db).Create(correo).Error
+}
+
+// Arrendar toma el correo disponible más antiguo: uno pendiente cuya espera terminó o uno en
+// envío cuyo arriendo venció. Retorna gorm.ErrRecordNotFound si no hay correos por enviar.
+func (r *correoSalienteRepository) Arrendar(ctx context.Context, trabajador string, duracion time.Duration) (*models.CorreoSaliente, error) {
+	var correo models.CorreoSaliente
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ahora := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(estado = ? AND disponible_en <= ?) OR (estado = ? AND arrendado_hasta < ?)",
+				models.CorreoPendiente, ahora, models.CorreoEnEnvio, ahora).
+			Order("disponible_en, id").Take(&correo).Error
+		if err != nil {
+			return err
+		}
+
+		hasta := ahora.Add(duracion)
+		correo.Estado = models.CorreoEnEnvio
+		correo.Trabajador = trabajador
+		correo.ArrendadoHasta = &hasta
+		correo.Intentos++
+		return tx.Model(&correo).Select("estado", "trabajador", "arrendado_hasta", "intentos").Updates(&correo).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &correo, nil
+}
+
+// Finalizar guarda el resultado del envío (enviado, fallido o a la espera de otro intento)
+// si el trabajador todavía tiene el correo. Retorna false si ya no lo tiene.
+func (r *correoSalienteRepository) Finalizar(ctx context.Context, correo *models.CorreoSaliente, trabajador string) (bool, error) {
+	resultado := r.db.WithContext(ctx).Model(correo).
+		Where("estado = ? AND trabajador = ?", models.CorreoEnEnvio, trabajador).
+		Select("estado", "disponible_en", "arrendado_hasta", "trabajador", "error", "enviado_en").
+		Updates(correo)
+	return resultado.RowsAffected > 0, resultado.Error
+}
+
+// DeleteEnviadosAntes elimina definitivamente los correos enviados antes de la fecha; los
+// fallidos se conservan para revisarlos
+func (r *correoSalienteRepository) DeleteEnviadosAntes(ctx context.Context, fecha time.Time) (int64, error) {
+	resultado := r.db.WithContext(ctx).Unscoped().
+		Where("estado = ? AND enviado_en < ?", models.CorreoEnviado, fecha).
+		Delete(&models.CorreoSaliente{})
+	return resultado.RowsAffected, resultado.Error
+}
+
+// FindReporte carga el reporte con los datos del correo: técnico, equipo y responsable. Lee
+// en la transacción del contexto para ver el reporte que se acaba de crear o cerrar.
+func (r *correoSalienteRepository) FindReporte(ctx context.Context, id uint) (*models.ReporteServicio, error) {
+	var reporte models.ReporteServicio
+	if err := conexion(ctx, r.db).Preload("CreadoPor").Preload("Equipo.UsuarioResponsable").First(&reporte, id).Error; err != nil {
+		return nil, err
+	}
+	return &reporte, nil
+}
+
+// CorreoDependencia obtiene el correo institucional de la dependencia por su ID o, si es nil,
+// por su nombre sin distinguir mayúsculas. Retorna "" si no existe o el nombre es ambiguo.
+func (r *correoSalienteRepository) CorreoDependencia(dependenciaID *uint, nombre string) (string, error) {
+	var correos []string
+	consulta := r.db.Model(&models.Dependencia{})
+	if dependenciaID != nil {
+		consulta = consulta.Where("id = ?", *dependenciaID)
+	} else {
+		consulta = consulta.Where("LOWER(nombre) = LOWER(?)", nombre)
+	}
+	if err := consulta.Limit(2).Pluck("correo_institucional", &correos).Error; err != nil {
+		return "", err
+	}
+	if len(correos) != 1 {
+		return "", nil
+	}
+	return correos[0], nil
+}
@@ -1,20 +1,23 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // DependenciaRepository define las operaciones del repositorio para Dependencia
 type DependenciaRepository interface {
-	CreateDependencia(dependencia *models.Dependencia) error
+	CreateDependencia(ctx context.Context, dependencia *models.Dependencia) error
 	GetDependenciaByID(id uint) (*models.Dependencia, error)
-	GetAllDependencias() ([]models.Dependencia, error)
-	UpdateDependencia(dependencia *models.Dependencia) error
-	DeleteDependencia(id uint) error
+	GetAllDependencias(consulta dto.ConsultaDTO) ([]models.Dependencia, int64, error)
+	UpdateDependencia(ctx context.Context, dependencia *models.Dependencia) error
+	DeleteDependencia(ctx context.Context, id uint) error
 	GetDependenciasBySecretariaID(secretariaID uint) ([]models.Dependencia, error)
-	LiberarUsuariosDeDependencia(dependenciaID uint) error
+	GetDependenciasByNombre(nombre string) ([]models.Dependencia, error)
+	LiberarUsuariosDeDependencia(ctx context.Context, dependenciaID uint) error
 }
 
 // dependenciaRepository implementa DependenciaRepository
@@ -28,8 +31,8 @@ func NewDependenciaRepository(db *gorm.DB) DependenciaRepository {
 }
 
 // CreateDependencia crea una nueva dependencia en la base de datos
-func (r *dependenciaRepository) CreateDependencia(dependencia *models.Dependencia) error {
-	return r.db.Create(dependencia).Error
+func (r *dependenciaRepository) CreateDependencia(ctx context.Context, dependencia *models.Dependencia) error {
+	return r.db.WithContext(ctx).Create(dependencia).Error
 }
 
 // GetDependenciaByID busca una dependencia por su ID
@@ -42,21 +45,29 @@ func (r *dependenciaRepository) GetDependenciaByID(id uint) (*models.Dependencia
 	return &dependencia, nil
 }
 
-// GetAllDependencias retorna todas las dependencias
-func (r *dependenciaRepository) GetAllDependencias() ([]models.Dependencia, error) {
-	var dependencias []models.Dependencia
-	err := r.db.Find(&dependencias).Error
-	return dependencias, err
+// GetAllDependencias retorna una página de dependencias según la consulta
+func (r *dependenciaRepository) GetAllDependencias(consulta dto.ConsultaDTO) ([]models.Dependencia, int64, error) {
+	return paginar[models.Dependencia](r.db.Model(&models.Dependencia{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "nombre": "nombre", "created_at": "created_at"},
+		ordenPorDef: "nombre",
+		filtros: map[string]filtroConsulta{
+			"secretaria": filtroID("secretaria", "secretaria_id = ?"),
+			"nombre":     filtroTexto("nombre"),
+			"jefe":       filtroTexto("jefe_oficina"),
+		},
+		fecha: "created_at",
+	})
 }
 
 // UpdateDependencia actualiza una dependencia existente
-func (r *dependenciaRepository) UpdateDependencia(dependencia *models.Dependencia) error {
-	return r.db.Save(dependencia).Error
+func (r *dependenciaRepository) UpdateDependencia(ctx context.Context, dependencia *models.Dependencia) error {
+	return r.db.WithContext(ctx).Save(dependencia).Error
 }
 
 // DeleteDependencia elimina una dependencia por su ID
-func (r *dependenciaRepository) DeleteDependencia(id uint) error {
-	return r.db.Delete(&models.Dependencia{}, id).Error
+func (r *dependenciaRepository) DeleteDependencia(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Dependencia{}, id).Error
 }
 
 // GetDependenciasBySecretariaID retorna todas las dependencias de una secretaría
@@ -66,7 +77,14 @@ func (r *dependenciaRepository) GetDependenciasBySecretariaID(secretariaID uint)
 	return dependencias, err
 }
 
+// GetDependenciasByNombre busca dependencias por nombre exacto, sin distinguir mayúsculas
+func (r *dependenciaRepository) GetDependenciasByNombre(nombre string) ([]models.Dependencia, error) {
+	var dependencias []models.Dependencia
+	err := r.db.Where("LOWER(nombre) = LOWER(?)", nombre).Find(&dependencias).Error
+	return dependencias, err
+}
+
 // LiberarUsuariosDeDependencia desvincula usuarios responsables de una dependencia sin eliminarlos
-func (r *dependenciaRepository) LiberarUsuariosDeDependencia(dependenciaID uint) error {
-	return r.db.Model(&models.UsuarioResponsable{}).Where("dependencia_id = ?", dependenciaID).Update("dependencia_id", gorm.Expr("NULL")).Error
+func (r *dependenciaRepository) LiberarUsuariosDeDependencia(ctx context.Context, dependenciaID uint) error {
+	return r.db.WithContext(ctx).Model(&models.UsuarioResponsable{}).Where("dependencia_id = ?", dependenciaID).Update("dependencia_id", gorm.Expr("NULL")).Error
 }
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"tum_inv_backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// DocumentoReporteRepository consulta el historial de versiones del PDF firmado de los reportes.
+// Las versiones se crean y se marcan como reabiertas desde ReporteServicioRepository.
+type DocumentoReporteRepository interface {
+	FindByReporteID(reporteID uint) ([]models.DocumentoReporte, error)
+	FindVersion(reporteID uint, version int) (*models.DocumentoReporte, error)
+	FindUltima(reporteID uint) (*models.DocumentoReporte, error)
+}
+
+// documentoReporteRepository implementa DocumentoReporteRepository
+type documentoReporteRepository struct {
+	db *gorm.DB
+}
+
+// NewDocumentoReporteRepository crea una nueva instancia de DocumentoReporteRepository
+func NewDocumentoReporteRepository(db *gorm.DB) DocumentoReporteRepository {
+	return &documentoReporteRepository{db: db}
+}
+
+// FindByReporteID obtiene todas las versiones del PDF firmado de un reporte, de la más reciente a la más antigua
+func (r *documentoReporteRepository) FindByReporteID(reporteID uint) ([]models.DocumentoReporte, error) {
+	var documentos []models.DocumentoReporte
+	err := r.db.Where("reporte_id = ?", reporteID).Order("version DESC").Find(&documentos).Error
+	return documentos, err
+}
+
+// FindVersion busca una versión específica del PDF firmado de un reporte
+func (r *documentoReporteRepository) FindVersion(reporteID uint, version int) (*models.DocumentoReporte, error) {
+	var documento models.DocumentoReporte
+	err := r.db.Where("reporte_id = ? AND version = ?", reporteID, version).Take(&documento).Error
+	if err != nil {
+		return nil, err
+	}
+	return &documento, nil
+}
+
+// FindUltima busca la versión más reciente del PDF firmado de un reporte
+func (r *documentoReporteRepository) FindUltima(reporteID uint) (*models.DocumentoReporte, error) {
+	var documento models.DocumentoReporte
+	err := r.db.Where("reporte_id = ?", reporteID).Order("version DESC").Take(&documento).Error
+	if err != nil {
+		return nil, err
+	}
+	return &documento, nil
+}
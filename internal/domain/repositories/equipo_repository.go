@@ -1,26 +1,30 @@
 package repositories
 
 import (
+	"context"
+	"strconv"
 	"tum_inv_backend/internal/domain/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"tum_inv_backend/internal/domain/models/dto"
 )
 
 // EquipoRepository define las operaciones del repositorio para Equipo
 type EquipoRepository interface {
-	Create(equipo *models.Equipo) error
+	Create(ctx context.Context, equipo *models.Equipo) error
 	FindByID(id uint) (*models.Equipo, error)
-	Update(equipo *models.Equipo) error
-	Delete(id uint) error
-	FindAll() ([]models.Equipo, error)
+	Update(ctx context.Context, equipo *models.Equipo) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.Equipo, int64, error)
 	FindByDependenciaID(dependenciaID uint) ([]models.Equipo, error)
 	FindEquiUsuDepByID(id uint) (dto.EquipoConResponsableDTO, error)
-	FindAllEquiposDetalle() ([]dto.EquipoConResponsableDTO, error)
-	AsignarResponsable(equipoID uint, usuarioResponsableID *uint) error
-	LiberarPerifericos(equipoID uint) error
-	EliminarDatosAsociados(equipoID uint) error
+	FindAllEquiposDetalle(consulta dto.ConsultaDTO) ([]dto.EquipoConResponsableDTO, int64, error)
+	ExportarEquipos(consulta dto.ConsultaDTO, procesar func(lote []dto.EquipoExportacionDTO) error) error
+	AsignarResponsable(ctx context.Context, asignacion *models.AsignacionEquipo) error
+	LiberarPerifericos(ctx context.Context, equipoID uint) error
+	EliminarDatosAsociados(ctx context.Context, equipoID uint) error
 }
 
 // equipoRepository implementa EquipoRepository
@@ -34,8 +38,8 @@ func NewEquipoRepository(db *gorm.DB) EquipoRepository {
 }
 
 // Create crea un nuevo equipo en la base de datos
-func (r *equipoRepository) Create(equipo *models.Equipo) error {
-	return r.db.Create(equipo).Error
+func (r *equipoRepository) Create(ctx context.Context, equipo *models.Equipo) error {
+	return r.db.WithContext(ctx).Create(equipo).Error
 }
 
 // FindByID busca un equipo por su ID
@@ -57,21 +61,73 @@ func (r *equipoRepository) FindByID(id uint) (*models.Equipo, error) {
 	return &equipo, nil
 }
 
-// Update actualiza un equipo existente
-func (r *equipoRepository) Update(equipo *models.Equipo) error {
-	return r.db.Save(equipo).Error
+// Update actualiza un equipo existente. El responsable no se cambia aquí sino con
+// AsignarResponsable, que registra la entrega; equipo queda con el responsable vigente.
+func (r *equipoRepository) Update(ctx context.Context, equipo *models.Equipo) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Omit("usuario_responsable_id", "UsuarioResponsable").Save(equipo).Error; err != nil {
+		return err
+	}
+	var actual models.Equipo
+	if err := db.Select("id", "usuario_responsable_id").First(&actual, equipo.ID).Error; err != nil {
+		return err
+	}
+	equipo.UsuarioResponsableID = actual.UsuarioResponsableID
+	equipo.UsuarioResponsable = nil
+	return nil
 }
 
 // Delete elimina un equipo por su ID
-func (r *equipoRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Equipo{}, id).Error
+func (r *equipoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Equipo{}, id).Error
 }
 
-// FindAll retorna todos los equipos
-func (r *equipoRepository) FindAll() ([]models.Equipo, error) {
-	var equipos []models.Equipo
-	err := r.db.Find(&equipos).Error
-	return equipos, err
+// FindAll retorna una página de equipos según la consulta
+func (r *equipoRepository) FindAll(consulta dto.ConsultaDTO) ([]models.Equipo, int64, error) {
+	return paginar[models.Equipo](r.db.Model(&models.Equipo{}), consulta, r.opcionesConsulta(""))
+}
+
+// opcionesConsulta define el orden y los filtros de los listados de equipos. El prefijo
+// es el alias de la tabla equipos cuando la consulta tiene joins.
+func (r *equipoRepository) opcionesConsulta(prefijo string) opcionesConsulta {
+	return opcionesConsulta{
+		id: prefijo + "id",
+		orden: map[string]string{
+			"id":                     prefijo + "id",
+			"marca":                  prefijo + "marca",
+			"modelo":                 prefijo + "modelo",
+			"tipo_dispositivo":       prefijo + "tipo_dispositivo",
+			"placa":                  prefijo + "placa_inventario",
+			"serial":                 prefijo + "serial",
+			"fecha_diligenciamiento": prefijo + "fecha_diligenciamiento",
+			"created_at":             prefijo + "created_at",
+		},
+		ordenPorDef: prefijo + "id",
+		filtros: map[string]filtroConsulta{
+			"marca":            filtroTexto(prefijo + "marca"),
+			"modelo":           filtroTexto(prefijo + "modelo"),
+			"tipo_dispositivo": filtroIgual(prefijo + "tipo_dispositivo"),
+			"placa":            filtroTexto(prefijo + "placa_inventario"),
+			"serial":           filtroTexto(prefijo + "serial"),
+			"responsable":      filtroID("responsable", prefijo+"usuario_responsable_id = ?"),
+			// El estado se puede filtrar por ID o por nombre
+			"estado": func(db *gorm.DB, valor string) (*gorm.DB, error) {
+				if id, err := strconv.ParseUint(valor, 10, 32); err == nil {
+					return db.Where(prefijo+"estado_equipo_id = ?", uint(id)), nil
+				}
+				return db.Where(prefijo+"estado_equipo_id IN (?)",
+					r.db.Model(&models.EstadoEquipo{}).Select("id").Where("nombre = ?", valor)), nil
+			},
+			"dependencia": filtroPorID("dependencia", func(db *gorm.DB, id uint) *gorm.DB {
+				return db.Where(prefijo+"usuario_responsable_id IN (?)", responsablesDeDependencias(r.db, id))
+			}),
+			"secretaria": filtroPorID("secretaria", func(db *gorm.DB, id uint) *gorm.DB {
+				return db.Where(prefijo+"usuario_responsable_id IN (?)",
+					responsablesDeDependencias(r.db, dependenciasDeSecretaria(r.db, id)))
+			}),
+		},
+		fecha: prefijo + "fecha_diligenciamiento",
+	}
 }
 
 // FindByDependenciaID retorna todos los equipos de una dependencia
@@ -111,17 +167,61 @@ func (r *equipoRepository) FindEquiUsuDepByID(equipoID uint) (dto.EquipoConRespo
         WHERE e.id = ?`, equipoID).Scan(&equipo).Error
 	return equipo, err
 }
-func (r *equipoRepository) FindAllEquiposDetalle() ([]dto.EquipoConResponsableDTO, error) {
-	var equipos []dto.EquipoConResponsableDTO
-	err := r.db.Raw(`
-        SELECT e.marca, e.modelo, e.observaciones_generales, 
+
+// FindAllEquiposDetalle retorna una página de equipos con su responsable, ubicación y estado
+func (r *equipoRepository) FindAllEquiposDetalle(consulta dto.ConsultaDTO) ([]dto.EquipoConResponsableDTO, int64, error) {
+	base := r.db.Table("equipos e").
+		Joins("JOIN usuario_responsables ur ON ur.id = e.usuario_responsable_id").
+		Joins("JOIN dependencia d ON d.id = ur.dependencia_id").
+		Joins("JOIN estado_equipos es ON es.id = e.estado_equipo_id").
+		Where("e.deleted_at IS NULL")
+
+	opciones := r.opcionesConsulta("e.")
+	opciones.columnas = `e.marca, e.modelo, e.observaciones_generales,
 		e.placa_inventario, e.serial, e.tipo_dispositivo, e.fecha_diligenciamiento,
-		ur.nombres_apellidos, ur.cedula, d.ubicacion_oficina, es.nombre as Estado
-        FROM equipos e 
-        JOIN usuario_responsables ur ON ur.id = e.usuario_responsable_id
-        JOIN dependencia d ON d.id = ur.dependencia_id
-        JOIN estado_equipos es ON es.id = e.estado_equipo_id`).Scan(&equipos).Error
-	return equipos, err
+		ur.nombres_apellidos, ur.cedula, d.ubicacion_oficina, es.nombre as Estado`
+	return paginar[dto.EquipoConResponsableDTO](base, consulta, opciones)
+}
+
+// ExportarEquipos recorre por lotes todos los equipos que cumplen la consulta, con su
+// responsable, dependencia, secretaría, estado, IP y hardware interno. Los equipos
+// vienen agrupados por secretaría (los que no tienen van al final) y, dentro de cada
+// secretaría, en el orden pedido.
+func (r *equipoRepository) ExportarEquipos(consulta dto.ConsultaDTO, procesar func(lote []dto.EquipoExportacionDTO) error) error {
+	base := r.db.Table("equipos e").
+		Joins("LEFT JOIN usuario_responsables ur ON ur.id = e.usuario_responsable_id AND ur.deleted_at IS NULL").
+		Joins("LEFT JOIN dependencia d ON d.id = ur.dependencia_id AND d.deleted_at IS NULL").
+		Joins("LEFT JOIN secretaria s ON s.id = d.secretaria_id AND s.deleted_at IS NULL").
+		Joins("LEFT JOIN estado_equipos es ON es.id = e.estado_equipo_id").
+		Joins("LEFT JOIN configuracion_reds cr ON cr.equipo_id = e.id AND cr.deleted_at IS NULL").
+		Where("e.deleted_at IS NULL").
+		Order("s.nombre IS NULL, s.nombre, s.id")
+
+	opciones := r.opcionesConsulta("e.")
+	opciones.columnas = `e.id, e.placa_inventario, e.serial, e.tipo_dispositivo, e.marca, e.modelo,
+		e.fecha_diligenciamiento, e.observaciones_generales, COALESCE(es.nombre, '') AS estado,
+		COALESCE(ur.nombres_apellidos, '') AS responsable_nombre, COALESCE(ur.cedula, '') AS responsable_cedula,
+		COALESCE(d.nombre, '') AS dependencia_nombre, s.id AS secretaria_id, COALESCE(s.nombre, '') AS secretaria_nombre,
+		COALESCE(cr.direccion_ip, '') AS direccion_ip, COALESCE(cr.nombre_dispositivo, '') AS nombre_dispositivo`
+
+	return recorrerConsulta(base, consulta, opciones, func(lote []dto.EquipoExportacionDTO) error {
+		ids := make([]uint, len(lote))
+		for i, equipo := range lote {
+			ids[i] = equipo.ID
+		}
+		var hardware []models.HardwareInterno
+		if err := r.db.Where("equipo_id IN ?", ids).Order("id").Find(&hardware).Error; err != nil {
+			return err
+		}
+		porEquipo := make(map[uint][]models.HardwareInterno, len(lote))
+		for _, h := range hardware {
+			porEquipo[h.EquipoID] = append(porEquipo[h.EquipoID], h)
+		}
+		for i := range lote {
+			lote[i].HardwareInterno = porEquipo[lote[i].ID]
+		}
+		return procesar(lote)
+	})
 }
 
 // // FindByDependenciaID retorna todos los equipos de una dependencia
@@ -150,18 +250,66 @@ func (r *equipoRepository) FindAllEquiposDetalle() ([]dto.EquipoConResponsableDT
 // 	return equipos, err
 // }
 
-// AsignarResponsable actualiza solo el UsuarioResponsableID de un equipo
-func (r *equipoRepository) AsignarResponsable(equipoID uint, usuarioResponsableID *uint) error {
-	return r.db.Model(&models.Equipo{}).Where("id = ?", equipoID).Update("usuario_responsable_id", usuarioResponsableID).Error
+// AsignarResponsable cambia el UsuarioResponsableID de un equipo y registra la entrega
+// en el historial de custodia, cerrando la asignación vigente. Todo en una transacción, con
+// el equipo bloqueado para que dos asignaciones simultáneas no dejen dos entregas vigentes.
+func (r *equipoRepository) AsignarResponsable(ctx context.Context, asignacion *models.AsignacionEquipo) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var equipo models.Equipo
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "usuario_responsable_id").First(&equipo, asignacion.EquipoID).Error
+		if err != nil {
+			return err
+		}
+		asignacion.ResponsableAnteriorID = equipo.UsuarioResponsableID
+
+		// Reasignar al mismo responsable no genera una nueva entrega
+		if mismoResponsable(asignacion.ResponsableAnteriorID, asignacion.ResponsableNuevoID) {
+			return nil
+		}
+
+		// 1. Cerrar la asignación vigente; si su acta no se firmó, se fijan en ella los
+		// componentes con los que termina
+		componentes, err := fotoComponentes(tx, asignacion.EquipoID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.AsignacionEquipo{}).
+			Where("equipo_id = ? AND fecha_fin IS NULL AND componentes IS NULL", asignacion.EquipoID).
+			Select("componentes").Updates(&models.AsignacionEquipo{Componentes: componentes}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.AsignacionEquipo{}).
+			Where("equipo_id = ? AND fecha_fin IS NULL", asignacion.EquipoID).
+			Update("fecha_fin", asignacion.FechaInicio).Error; err != nil {
+			return err
+		}
+
+		// 2. Registrar la nueva asignación
+		if err := tx.Create(asignacion).Error; err != nil {
+			return err
+		}
+
+		// 3. Actualizar el responsable del equipo
+		return tx.Model(&models.Equipo{}).Where("id = ?", asignacion.EquipoID).
+			Update("usuario_responsable_id", asignacion.ResponsableNuevoID).Error
+	})
+}
+
+// mismoResponsable compara dos IDs de responsable opcionales
+func mismoResponsable(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 // LiberarPerifericos pone EquipoID en NULL para todos los periféricos de un equipo
-func (r *equipoRepository) LiberarPerifericos(equipoID uint) error {
-	return r.db.Model(&models.Periferico{}).Where("equipo_id = ?", equipoID).Update("equipo_id", nil).Error
+func (r *equipoRepository) LiberarPerifericos(ctx context.Context, equipoID uint) error {
+	return r.db.WithContext(ctx).Model(&models.Periferico{}).Where("equipo_id = ?", equipoID).Update("equipo_id", nil).Error
 }
 
 // EliminarDatosAsociados elimina Software, HardwareInterno, ConfiguracionRed, UsuariosSistema, AccesosRemotos y Backups de un equipo
-func (r *equipoRepository) EliminarDatosAsociados(equipoID uint) error {
+func (r *equipoRepository) EliminarDatosAsociados(ctx context.Context, equipoID uint) error {
 	modelos := []interface{}{
 		&models.Software{},
 		&models.HardwareInterno{},
@@ -171,7 +319,7 @@ func (r *equipoRepository) EliminarDatosAsociados(equipoID uint) error {
 		&models.Backup{},
 	}
 	for _, m := range modelos {
-		if err := r.db.Where("equipo_id = ?", equipoID).Delete(m).Error; err != nil {
+		if err := r.db.WithContext(ctx).Where("equipo_id = ?", equipoID).Delete(m).Error; err != nil {
 			return err
 		}
 	}
@@ -1,7 +1,9 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
@@ -14,11 +16,17 @@ func NewEstadoEquipoRepository(db *gorm.DB) *EstadoEquipoRepository {
 	return &EstadoEquipoRepository{db: db}
 }
 
-// GetAll obtiene todos los estados de equipo
-func (r *EstadoEquipoRepository) GetAll() ([]models.EstadoEquipo, error) {
-	var estados []models.EstadoEquipo
-	err := r.db.Find(&estados).Error
-	return estados, err
+// GetAll obtiene una página de estados de equipo según la consulta
+func (r *EstadoEquipoRepository) GetAll(consulta dto.ConsultaDTO) ([]models.EstadoEquipo, int64, error) {
+	return paginar[models.EstadoEquipo](r.db.Model(&models.EstadoEquipo{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "nombre": "nombre"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"nombre": filtroTexto("nombre"),
+			"activo": filtroBool("activo", "activo"),
+		},
+	})
 }
 
 // GetByID obtiene un estado de equipo por su ID
@@ -31,6 +39,16 @@ func (r *EstadoEquipoRepository) GetByID(id uint) (*models.EstadoEquipo, error)
 	return &estado, nil
 }
 
+// GetByNombre obtiene un estado de equipo por su nombre, sin distinguir mayúsculas
+func (r *EstadoEquipoRepository) GetByNombre(nombre string) (*models.EstadoEquipo, error) {
+	var estado models.EstadoEquipo
+	err := r.db.Where("LOWER(nombre) = LOWER(?)", nombre).First(&estado).Error
+	if err != nil {
+		return nil, err
+	}
+	return &estado, nil
+}
+
 // GetActive obtiene todos los estados de equipo activos
 func (r *EstadoEquipoRepository) GetActive() ([]models.EstadoEquipo, error) {
 	var estados []models.EstadoEquipo
@@ -39,18 +57,18 @@ func (r *EstadoEquipoRepository) GetActive() ([]models.EstadoEquipo, error) {
 }
 
 // Create crea un nuevo estado de equipo
-func (r *EstadoEquipoRepository) Create(estado *models.EstadoEquipo) error {
-	return r.db.Create(estado).Error
+func (r *EstadoEquipoRepository) Create(ctx context.Context, estado *models.EstadoEquipo) error {
+	return r.db.WithContext(ctx).Create(estado).Error
 }
 
 // Update actualiza un estado de equipo existente
-func (r *EstadoEquipoRepository) Update(estado *models.EstadoEquipo) error {
-	return r.db.Save(estado).Error
+func (r *EstadoEquipoRepository) Update(ctx context.Context, estado *models.EstadoEquipo) error {
+	return r.db.WithContext(ctx).Save(estado).Error
 }
 
 // Delete elimina (soft delete) un estado de equipo
-func (r *EstadoEquipoRepository) Delete(id uint) error {
-	return r.db.Delete(&models.EstadoEquipo{}, id).Error
+func (r *EstadoEquipoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.EstadoEquipo{}, id).Error
 }
 
 // ExistsByName verifica si ya existe un estado con el mismo nombre
@@ -1,18 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // HardwareInternoRepository define las operaciones del repositorio para HardwareInterno
 type HardwareInternoRepository interface {
-	Create(hardware *models.HardwareInterno) error
+	Create(ctx context.Context, hardware *models.HardwareInterno) error
 	FindByID(id uint) (*models.HardwareInterno, error)
-	Update(hardware *models.HardwareInterno) error
-	Delete(id uint) error
-	FindAll() ([]models.HardwareInterno, error)
+	Update(ctx context.Context, hardware *models.HardwareInterno) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.HardwareInterno, int64, error)
 	FindByEquipoID(equipoID uint) ([]models.HardwareInterno, error)
 }
 
@@ -27,8 +29,8 @@ func NewHardwareInternoRepository(db *gorm.DB) HardwareInternoRepository {
 }
 
 // Create crea un nuevo hardware interno en la base de datos
-func (r *hardwareInternoRepository) Create(hardware *models.HardwareInterno) error {
-	return r.db.Create(hardware).Error
+func (r *hardwareInternoRepository) Create(ctx context.Context, hardware *models.HardwareInterno) error {
+	return r.db.WithContext(ctx).Create(hardware).Error
 }
 
 // FindByID busca un hardware interno por su ID
@@ -42,20 +44,29 @@ func (r *hardwareInternoRepository) FindByID(id uint) (*models.HardwareInterno,
 }
 
 // Update actualiza un hardware interno existente
-func (r *hardwareInternoRepository) Update(hardware *models.HardwareInterno) error {
-	return r.db.Save(hardware).Error
+func (r *hardwareInternoRepository) Update(ctx context.Context, hardware *models.HardwareInterno) error {
+	return r.db.WithContext(ctx).Save(hardware).Error
 }
 
 // Delete elimina un hardware interno por su ID
-func (r *hardwareInternoRepository) Delete(id uint) error {
-	return r.db.Delete(&models.HardwareInterno{}, id).Error
+func (r *hardwareInternoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.HardwareInterno{}, id).Error
 }
 
-// FindAll retorna todos los componentes de hardware interno
-func (r *hardwareInternoRepository) FindAll() ([]models.HardwareInterno, error) {
-	var hardwareInternos []models.HardwareInterno
-	err := r.db.Find(&hardwareInternos).Error
-	return hardwareInternos, err
+// FindAll retorna una página de componentes de hardware interno según la consulta
+func (r *hardwareInternoRepository) FindAll(consulta dto.ConsultaDTO) ([]models.HardwareInterno, int64, error) {
+	return paginar[models.HardwareInterno](r.db.Model(&models.HardwareInterno{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "componente": "componente", "tecnologia": "tecnologia", "capacidad": "capacidad", "created_at": "created_at"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"equipo":     filtroID("equipo", "equipo_id = ?"),
+			"componente": filtroIgual("componente"),
+			"tecnologia": filtroTexto("tecnologia"),
+			"capacidad":  filtroTexto("capacidad"),
+		},
+		fecha: "created_at",
+	})
 }
 
 // FindByEquipoID retorna todos los componentes de hardware interno asociados a un equipo
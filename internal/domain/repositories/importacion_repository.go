@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"tum_inv_backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// EquipoImportado es un equipo leído del archivo de importación, con su responsable
+// (existente o nuevo) y la asignación inicial del historial de custodia
+type EquipoImportado struct {
+	Equipo      *models.Equipo
+	Responsable *models.UsuarioResponsable // nil si el equipo no tiene responsable
+	Asignacion  *models.AsignacionEquipo   // EquipoID y ResponsableNuevoID se completan al guardar
+}
+
+// ImportacionRepository define las operaciones de la importación masiva de inventario
+type ImportacionRepository interface {
+	SerialesExistentes(seriales []string) (map[string]bool, error)
+	PlacasExistentes(placas []string) (map[string]bool, error)
+	Importar(ctx context.Context, responsables []*models.UsuarioResponsable, equipos []EquipoImportado) error
+}
+
+// importacionRepository implementa ImportacionRepository
+type importacionRepository struct {
+	db *gorm.DB
+}
+
+// NewImportacionRepository crea una nueva instancia de ImportacionRepository
+func NewImportacionRepository(db *gorm.DB) ImportacionRepository {
+	return &importacionRepository{db: db}
+}
+
+// SerialesExistentes indica cuáles de los seriales ya están registrados. Incluye los equipos
+// eliminados, porque el índice único de la columna también los incluye.
+func (r *importacionRepository) SerialesExistentes(seriales []string) (map[string]bool, error) {
+	return r.valoresExistentes("serial", seriales)
+}
+
+// PlacasExistentes indica cuáles de las placas de inventario ya están registradas
+func (r *importacionRepository) PlacasExistentes(placas []string) (map[string]bool, error) {
+	return r.valoresExistentes("placa_inventario", placas)
+}
+
+func (r *importacionRepository) valoresExistentes(columna string, valores []string) (map[string]bool, error) {
+	existentes := map[string]bool{}
+	if len(valores) == 0 {
+		return existentes, nil
+	}
+	var encontrados []string
+	err := r.db.Unscoped().Model(&models.Equipo{}).Where(columna+" IN ?", valores).Pluck(columna, &encontrados).Error
+	for _, v := range encontrados {
+		existentes[v] = true
+	}
+	return existentes, err
+}
+
+// Importar crea los responsables nuevos y los equipos con su hardware, software y
+// configuración de red en una sola transacción; si algo falla no se guarda nada
+func (r *importacionRepository) Importar(ctx context.Context, responsables []*models.UsuarioResponsable, equipos []EquipoImportado) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Responsables nuevos, para tener sus IDs
+		for _, responsable := range responsables {
+			if err := tx.Create(responsable).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, importado := range equipos {
+			// 2. Equipo con sus componentes (HardwareInterno, Software y ConfiguracionRed)
+			if importado.Responsable != nil {
+				importado.Equipo.UsuarioResponsableID = &importado.Responsable.ID
+			}
+			if err := tx.Create(importado.Equipo).Error; err != nil {
+				return err
+			}
+
+			// 3. Asignación inicial del historial de custodia
+			if importado.Responsable != nil && importado.Asignacion != nil {
+				importado.Asignacion.EquipoID = importado.Equipo.ID
+				importado.Asignacion.ResponsableNuevoID = importado.Equipo.UsuarioResponsableID
+				if err := tx.Create(importado.Asignacion).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
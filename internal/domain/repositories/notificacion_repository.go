@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificacionRepository define las operaciones de acceso a datos de las notificaciones
+type NotificacionRepository interface {
+	Create(ctx context.Context, notificacion *models.Notificacion) (bool, error)
+	FindByUsuario(usuarioID uint, consulta dto.ConsultaDTO) ([]models.Notificacion, int64, error)
+	FindNuevas(usuarioID, despuesDeID uint, limite int) ([]models.Notificacion, error)
+	UltimoID(usuarioID uint) (uint, error)
+	ContarNoLeidas(usuarioID uint) (int64, error)
+	MarcarLeida(ctx context.Context, usuarioID, id uint) (*models.Notificacion, error)
+	MarcarTodasLeidas(ctx context.Context, usuarioID uint) (int64, error)
+	DeleteLeidasAntes(ctx context.Context, fecha time.Time) (int64, error)
+	FindReportesSinFirma(antes time.Time) ([]models.ReporteServicio, error)
+	FindUsuariosGestion() ([]uint, error)
+	UsuarioDeResponsable(responsableID uint) (*uint, error)
+	FindEquipo(id uint) (*models.Equipo, error)
+}
+
+// notificacionRepository implementa NotificacionRepository
+type notificacionRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificacionRepository crea una nueva instancia de NotificacionRepository
+func NewNotificacionRepository(db *gorm.DB) NotificacionRepository {
+	return &notificacionRepository{db: db}
+}
+
+// Create guarda la notificación. Retorna false si el usuario ya tenía una con la misma clave.
+func (r *notificacionRepository) Create(ctx context.Context, notificacion *models.Notificacion) (bool, error) {
+	resultado := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(notificacion)
+	return resultado.RowsAffected > 0, resultado.Error
+}
+
+// FindByUsuario retorna una página de las notificaciones del usuario según la consulta
+func (r *notificacionRepository) FindByUsuario(usuarioID uint, consulta dto.ConsultaDTO) ([]models.Notificacion, int64, error) {
+	base := r.db.Model(&models.Notificacion{}).Where("usuario_id = ?", usuarioID)
+	return paginar[models.Notificacion](base, consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "fecha": "created_at", "tipo": "tipo"},
+		ordenPorDef: "id",
+		descPorDef:  true,
+		filtros: map[string]filtroConsulta{
+			"tipo": filtroIgual("tipo"),
+			"leida": func(db *gorm.DB, valor string) (*gorm.DB, error) {
+				switch valor {
+				case "true":
+					return db.Where("leida_en IS NOT NULL"), nil
+				case "false":
+					return db.Where("leida_en IS NULL"), nil
+				}
+				return nil, fmt.Errorf("%w: leida debe ser true o false", dto.ErrConsultaInvalida)
+			},
+		},
+		fecha: "created_at",
+	})
+}
+
+// FindNuevas retorna, en orden, las notificaciones del usuario posteriores al ID indicado
+func (r *notificacionRepository) FindNuevas(usuarioID, despuesDeID uint, limite int) ([]models.Notificacion, error) {
+	var notificaciones []models.Notificacion
+	err := r.db.Where("usuario_id = ? AND id > ?", usuarioID, despuesDeID).
+		Order("id").Limit(limite).Find(&notificaciones).Error
+	return notificaciones, err
+}
+
+// UltimoID retorna el ID de la notificación más reciente del usuario; 0 si no tiene
+func (r *notificacionRepository) UltimoID(usuarioID uint) (uint, error) {
+	var ultimo uint
+	err := r.db.Model(&models.Notificacion{}).Where("usuario_id = ?", usuarioID).
+		Select("COALESCE(MAX(id), 0)").Scan(&ultimo).Error
+	return ultimo, err
+}
+
+// ContarNoLeidas cuenta las notificaciones sin leer del usuario
+func (r *notificacionRepository) ContarNoLeidas(usuarioID uint) (int64, error) {
+	var total int64
+	err := r.db.Model(&models.Notificacion{}).Where("usuario_id = ? AND leida_en IS NULL", usuarioID).Count(&total).Error
+	return total, err
+}
+
+// MarcarLeida marca como leída una notificación del usuario; si ya estaba leída conserva la
+// fecha. Retorna gorm.ErrRecordNotFound si no existe o es de otro usuario.
+func (r *notificacionRepository) MarcarLeida(ctx context.Context, usuarioID, id uint) (*models.Notificacion, error) {
+	err := r.db.WithContext(ctx).Model(&models.Notificacion{}).
+		Where("id = ? AND usuario_id = ? AND leida_en IS NULL", id, usuarioID).
+		Update("leida_en", time.Now()).Error
+	if err != nil {
+		return nil, err
+	}
+	var notificacion models.Notificacion
+	if err := r.db.Where("usuario_id = ?", usuarioID).First(&notificacion, id).Error; err != nil {
+		return nil, err
+	}
+	return &notificacion, nil
+}
+
+// MarcarTodasLeidas marca como leídas todas las notificaciones del usuario y retorna cuántas cambió
+func (r *notificacionRepository) MarcarTodasLeidas(ctx context.Context, usuarioID uint) (int64, error) {
+	resultado := r.db.WithContext(ctx).Model(&models.Notificacion{}).
+		Where("usuario_id = ? AND leida_en IS NULL", usuarioID).
+		Update("leida_en", time.Now())
+	return resultado.RowsAffected, resultado.Error
+}
+
+// DeleteLeidasAntes elimina las notificaciones leídas antes de la fecha. Los avisos de una
+// acción se eliminan definitivamente; los de la revisión periódica se eliminan con soft delete,
+// así su clave sigue en el índice único y el aviso no se repite mientras la condición continúe.
+func (r *notificacionRepository) DeleteLeidasAntes(ctx context.Context, fecha time.Time) (int64, error) {
+	db := r.db.WithContext(ctx)
+	sinClave := db.Unscoped().Where("leida_en < ? AND clave IS NULL", fecha).Delete(&models.Notificacion{})
+	if sinClave.Error != nil {
+		return 0, sinClave.Error
+	}
+	conClave := db.Where("leida_en < ? AND clave IS NOT NULL", fecha).Delete(&models.Notificacion{})
+	return sinClave.RowsAffected + conClave.RowsAffected, conClave.Error
+}
+
+// FindReportesSinFirma retorna los reportes abiertos creados antes de la fecha, con su equipo
+func (r *notificacionRepository) FindReportesSinFirma(antes time.Time) ([]models.ReporteServicio, error) {
+	var reportes []models.ReporteServicio
+	err := r.db.Select("id", "creado_por_id", "equipo_id", "dependencia", "fecha_inicio", "created_at").
+		Preload("Equipo", func(db *gorm.DB) *gorm.DB { return db.Unscoped().Select("id", "placa_inventario", "serial") }).
+		Where("fecha_cierre IS NULL AND created_at < ?", antes).Order("id").Find(&reportes).Error
+	return reportes, err
+}
+
+// FindUsuariosGestion retorna los IDs de los administradores y técnicos activos
+func (r *notificacionRepository) FindUsuariosGestion() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Usuario{}).
+		Where("rol IN ? AND activo = ?", []string{models.RolAdmin, models.RolTecnico}, true).
+		Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// UsuarioDeResponsable obtiene el usuario activo del sistema con la misma cédula del usuario
+// responsable; nil si el responsable no tiene usuario
+func (r *notificacionRepository) UsuarioDeResponsable(responsableID uint) (*uint, error) {
+	var ids []uint
+	err := r.db.Raw(`
+		SELECT u.id
+		FROM usuario_responsables ur
+		JOIN usuarios u ON u.cedula = ur.cedula AND u.deleted_at IS NULL AND u.activo
+		WHERE ur.id = ? AND ur.deleted_at IS NULL AND ur.cedula <> ''
+		LIMIT 1
+	`, responsableID).Scan(&ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	return &ids[0], nil
+}
+
+// FindEquipo obtiene los datos de identificación de un equipo para el texto del aviso
+func (r *notificacionRepository) FindEquipo(id uint) (*models.Equipo, error) {
+	var equipo models.Equipo
+	if err := r.db.Select("id", "placa_inventario", "serial").First(&equipo, id).Error; err != nil {
+		return nil, err
+	}
+	return &equipo, nil
+}
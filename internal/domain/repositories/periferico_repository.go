@@ -1,21 +1,24 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // PerifericoRepository define las operaciones del repositorio para Periferico
 type PerifericoRepository interface {
-	Create(periferico *models.Periferico) error
+	Create(ctx context.Context, periferico *models.Periferico) error
 	FindByID(id uint) (*models.Periferico, error)
-	Update(periferico *models.Periferico) error
-	Delete(id uint) error
-	FindAll() ([]models.Periferico, error)
+	Update(ctx context.Context, periferico *models.Periferico) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.Periferico, int64, error)
+	ExportarPerifericos(consulta dto.ConsultaDTO, procesar func(lote []dto.PerifericoExportacionDTO) error) error
 	FindByEquipoID(equipoID uint) ([]models.Periferico, error)
 	FindSinEquipo() ([]models.Periferico, error)
-	AsignarEquipo(perifericoID uint, equipoID *uint) error
+	AsignarEquipo(ctx context.Context, perifericoID uint, equipoID *uint) error
 }
 
 // perifericoRepository implementa PerifericoRepository
@@ -29,8 +32,8 @@ func NewPerifericoRepository(db *gorm.DB) PerifericoRepository {
 }
 
 // Create crea un nuevo periférico en la base de datos
-func (r *perifericoRepository) Create(periferico *models.Periferico) error {
-	return r.db.Create(periferico).Error
+func (r *perifericoRepository) Create(ctx context.Context, periferico *models.Periferico) error {
+	return r.db.WithContext(ctx).Create(periferico).Error
 }
 
 // FindByID busca un periférico por su ID
@@ -44,20 +47,43 @@ func (r *perifericoRepository) FindByID(id uint) (*models.Periferico, error) {
 }
 
 // Update actualiza un periférico existente
-func (r *perifericoRepository) Update(periferico *models.Periferico) error {
-	return r.db.Save(periferico).Error
+func (r *perifericoRepository) Update(ctx context.Context, periferico *models.Periferico) error {
+	return r.db.WithContext(ctx).Save(periferico).Error
 }
 
 // Delete elimina un periférico por su ID
-func (r *perifericoRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Periferico{}, id).Error
+func (r *perifericoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Periferico{}, id).Error
 }
 
-// FindAll retorna todos los periféricos
-func (r *perifericoRepository) FindAll() ([]models.Periferico, error) {
-	var perifericos []models.Periferico
-	err := r.db.Find(&perifericos).Error
-	return perifericos, err
+// FindAll retorna una página de periféricos según la consulta
+func (r *perifericoRepository) FindAll(consulta dto.ConsultaDTO) ([]models.Periferico, int64, error) {
+	return paginar[models.Periferico](r.db.Model(&models.Periferico{}), consulta, r.opcionesConsulta())
+}
+
+// ExportarPerifericos recorre por lotes todos los periféricos que cumplen la consulta,
+// con la placa de su equipo
+func (r *perifericoRepository) ExportarPerifericos(consulta dto.ConsultaDTO, procesar func(lote []dto.PerifericoExportacionDTO) error) error {
+	opciones := r.opcionesConsulta()
+	opciones.columnas = "perifericos.*, (SELECT e.placa_inventario FROM equipos e WHERE e.id = perifericos.equipo_id) AS equipo_placa"
+	return recorrerConsulta(r.db.Model(&models.Periferico{}), consulta, opciones, procesar)
+}
+
+// opcionesConsulta define el orden y los filtros de los listados de periféricos
+func (r *perifericoRepository) opcionesConsulta() opcionesConsulta {
+	return opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "tipo_periferico": "tipo_periferico", "marca": "marca", "serial": "serial", "placa": "placa_inventario", "created_at": "created_at"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"equipo":          filtroID("equipo", "equipo_id = ?"),
+			"tipo_periferico": filtroIgual("tipo_periferico"),
+			"marca":           filtroTexto("marca"),
+			"serial":          filtroTexto("serial"),
+			"placa":           filtroTexto("placa_inventario"),
+		},
+		fecha: "created_at",
+	}
 }
 
 // FindByEquipoID retorna todos los periféricos asociados a un equipo
@@ -75,6 +101,6 @@ func (r *perifericoRepository) FindSinEquipo() ([]models.Periferico, error) {
 }
 
 // AsignarEquipo actualiza solo el EquipoID de un periférico
-func (r *perifericoRepository) AsignarEquipo(perifericoID uint, equipoID *uint) error {
-	return r.db.Model(&models.Periferico{}).Where("id = ?", perifericoID).Update("equipo_id", equipoID).Error
+func (r *perifericoRepository) AsignarEquipo(ctx context.Context, perifericoID uint, equipoID *uint) error {
+	return r.db.WithContext(ctx).Model(&models.Periferico{}).Where("id = ?", perifericoID).Update("equipo_id", equipoID).Error
 }
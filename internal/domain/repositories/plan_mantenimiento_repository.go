@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+
+	"gorm.io/gorm"
+)
+
+// PlanMantenimientoRepository define las operaciones de acceso a datos de los planes de
+// mantenimiento preventivo
+type PlanMantenimientoRepository interface {
+	FindAll() ([]models.PlanMantenimiento, error)
+	FindByID(id uint) (*models.PlanMantenimiento, error)
+	FindActivos() ([]models.PlanMantenimiento, error)
+	ExisteDuplicado(plan *models.PlanMantenimiento) (bool, error)
+	Create(ctx context.Context, plan *models.PlanMantenimiento) error
+	Update(ctx context.Context, plan *models.PlanMantenimiento) error
+	Delete(ctx context.Context, id uint) error
+	FindEquipos() ([]dto.EquipoMantenimientoDTO, error)
+	FindUltimosPreventivos() ([]dto.PreventivoEquipoDTO, error)
+}
+
+// planMantenimientoRepository implementa PlanMantenimientoRepository
+type planMantenimientoRepository struct {
+	db *gorm.DB
+}
+
+// NewPlanMantenimientoRepository crea una nueva instancia de PlanMantenimientoRepository
+func NewPlanMantenimientoRepository(db *gorm.DB) PlanMantenimientoRepository {
+	return &planMantenimientoRepository{db: db}
+}
+
+// FindAll obtiene todos los planes: primero los de tipo de dispositivo y luego los de equipo
+func (r *planMantenimientoRepository) FindAll() ([]models.PlanMantenimiento, error) {
+	var planes []models.PlanMantenimiento
+	err := r.db.Preload("Equipo").Order("equipo_id NULLS FIRST, tipo_dispositivo, id").Find(&planes).Error
+	return planes, err
+}
+
+// FindByID busca un plan por su ID
+func (r *planMantenimientoRepository) FindByID(id uint) (*models.PlanMantenimiento, error) {
+	var plan models.PlanMantenimiento
+	if err := r.db.Preload("Equipo").First(&plan, id).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// FindActivos obtiene los planes activos
+func (r *planMantenimientoRepository) FindActivos() ([]models.PlanMantenimiento, error) {
+	var planes []models.PlanMantenimiento
+	err := r.db.Where("activo = ?", true).Order("id").Find(&planes).Error
+	return planes, err
+}
+
+// ExisteDuplicado indica si ya hay otro plan para el mismo equipo o tipo de dispositivo
+func (r *planMantenimientoRepository) ExisteDuplicado(plan *models.PlanMantenimiento) (bool, error) {
+	q := r.db.Model(&models.PlanMantenimiento{}).Where("id <> ?", plan.ID)
+	if plan.EquipoID != nil {
+		q = q.Where("equipo_id = ?", *plan.EquipoID)
+	} else {
+		q = q.Where("equipo_id IS NULL AND tipo_dispositivo = ?", plan.TipoDispositivo)
+	}
+	var total int64
+	err := q.Count(&total).Error
+	return total > 0, err
+}
+
+// Create crea un plan
+func (r *planMantenimientoRepository) Create(ctx context.Context, plan *models.PlanMantenimiento) error {
+	return r.db.WithContext(ctx).Create(plan).Error
+}
+
+// Update guarda todos los campos de un plan
+func (r *planMantenimientoRepository) Update(ctx context.Context, plan *models.PlanMantenimiento) error {
+	return r.db.WithContext(ctx).Model(plan).
+		Select("tipo_dispositivo", "equipo_id", "intervalo_meses", "activo", "descripcion").Updates(plan).Error
+}
+
+// Delete elimina (soft delete) un plan
+func (r *planMantenimientoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.PlanMantenimiento{}, id).Error
+}
+
+// FindEquipos obtiene los equipos vigentes (sin los dados de baja) con su responsable y
+// dependencia, para calcular su próximo mantenimiento
+func (r *planMantenimientoRepository) FindEquipos() ([]dto.EquipoMantenimientoDTO, error) {
+	var equipos []dto.EquipoMantenimientoDTO
+	err := r.db.Raw(`
+		SELECT e.id AS equipo_id, e.tipo_dispositivo, e.placa_inventario, e.marca, e.modelo, e.serial,
+			e.fecha_diligenciamiento AS fecha_registro,
+			ur.nombres_apellidos AS responsable,
+			d.id AS dependencia_id, d.nombre AS dependencia
+		FROM equipos e
+		LEFT JOIN estado_equipos es ON es.id = e.estado_equipo_id
+		LEFT JOIN usuario_responsables ur ON ur.id = e.usuario_responsable_id AND ur.deleted_at IS NULL
+		LEFT JOIN dependencia d ON d.id = ur.dependencia_id AND d.deleted_at IS NULL
+		WHERE e.deleted_at IS NULL
+		AND (es.nombre IS NULL OR es.nombre <> ?)
+		ORDER BY e.id
+	`, models.EstadoEquipoDadoDeBaja).Scan(&equipos).Error
+	return equipos, err
+}
+
+// FindUltimosPreventivos obtiene, por equipo, el reporte PREVENTIVO más reciente
+func (r *planMantenimientoRepository) FindUltimosPreventivos() ([]dto.PreventivoEquipoDTO, error) {
+	var preventivos []dto.PreventivoEquipoDTO
+	err := r.db.Raw(`
+		SELECT equipo_id, reporte_id, fecha FROM (
+			SELECT r.equipo_id, r.id AS reporte_id, r.fecha_inicio AS fecha,
+				ROW_NUMBER() OVER (PARTITION BY r.equipo_id ORDER BY r.fecha_inicio DESC, r.id DESC) AS n
+			FROM reporte_servicios r
+			JOIN tipo_mantenimientos tm ON tm.reporte_id = r.id AND tm.deleted_at IS NULL
+			WHERE r.deleted_at IS NULL AND tm.tipo = 'PREVENTIVO'
+		) ultimos
+		WHERE n = 1
+	`).Scan(&preventivos).Error
+	return preventivos, err
+}
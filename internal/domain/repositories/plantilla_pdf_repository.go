@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"tum_inv_backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// PlantillaPDFRepository define las operaciones de acceso a datos de las plantillas de PDF
+type PlantillaPDFRepository interface {
+	FindAll() ([]models.PlantillaPDF, error)
+	FindByID(id uint) (*models.PlantillaPDF, error)
+	FindActiva() (*models.PlantillaPDF, error)
+	Create(ctx context.Context, plantilla *models.PlantillaPDF) error
+	Update(ctx context.Context, plantilla *models.PlantillaPDF) error
+	Delete(ctx context.Context, id uint) error
+	Activar(ctx context.Context, id uint) error
+}
+
+// plantillaPDFRepository implementa PlantillaPDFRepository
+type plantillaPDFRepository struct {
+	db *gorm.DB
+}
+
+// NewPlantillaPDFRepository crea una nueva instancia de PlantillaPDFRepository
+func NewPlantillaPDFRepository(db *gorm.DB) PlantillaPDFRepository {
+	return &plantillaPDFRepository{db: db}
+}
+
+// FindAll obtiene todas las plantillas ordenadas por nombre
+func (r *plantillaPDFRepository) FindAll() ([]models.PlantillaPDF, error) {
+	var plantillas []models.PlantillaPDF
+	err := r.db.Order("nombre").Find(&plantillas).Error
+	return plantillas, err
+}
+
+// FindByID busca una plantilla por su ID
+func (r *plantillaPDFRepository) FindByID(id uint) (*models.PlantillaPDF, error) {
+	var plantilla models.PlantillaPDF
+	if err := r.db.First(&plantilla, id).Error; err != nil {
+		return nil, err
+	}
+	return &plantilla, nil
+}
+
+// FindActiva busca la plantilla activa; retorna gorm.ErrRecordNotFound si no hay ninguna
+func (r *plantillaPDFRepository) FindActiva() (*models.PlantillaPDF, error) {
+	var plantilla models.PlantillaPDF
+	if err := r.db.Where("activa = ?", true).Take(&plantilla).Error; err != nil {
+		return nil, err
+	}
+	return &plantilla, nil
+}
+
+// Create crea una plantilla
+func (r *plantillaPDFRepository) Create(ctx context.Context, plantilla *models.PlantillaPDF) error {
+	return r.db.WithContext(ctx).Create(plantilla).Error
+}
+
+// Update guarda el nombre y la definición de una plantilla, sin cambiar si está activa
+func (r *plantillaPDFRepository) Update(ctx context.Context, plantilla *models.PlantillaPDF) error {
+	return r.db.WithContext(ctx).Model(plantilla).Select("nombre", "definicion").Updates(plantilla).Error
+}
+
+// Delete elimina (soft delete) una plantilla
+func (r *plantillaPDFRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.PlantillaPDF{}, id).Error
+}
+
+// Activar marca la plantilla como la única activa
+func (r *plantillaPDFRepository) Activar(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var plantilla models.PlantillaPDF
+		if err := tx.First(&plantilla, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PlantillaPDF{}).Where("activa = ? AND id <> ?", true, id).Update("activa", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&plantilla).Update("activa", true).Error
+	})
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"tum_inv_backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// PoliticaSLARepository define las operaciones de acceso a datos de las políticas de SLA
+type PoliticaSLARepository interface {
+	FindAll() ([]models.PoliticaSLA, error)
+	FindByID(id uint) (*models.PoliticaSLA, error)
+	FindActivas() ([]models.PoliticaSLA, error)
+	ExisteDuplicado(politica *models.PoliticaSLA) (bool, error)
+	Create(ctx context.Context, politica *models.PoliticaSLA) error
+	Update(ctx context.Context, politica *models.PoliticaSLA) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// politicaSLARepository implementa PoliticaSLARepository
+type politicaSLARepository struct {
+	db *gorm.DB
+}
+
+// NewPoliticaSLARepository crea una nueva instancia de PoliticaSLARepository
+func NewPoliticaSLARepository(db *gorm.DB) PoliticaSLARepository {
+	return &politicaSLARepository{db: db}
+}
+
+// FindAll obtiene todas las políticas, activas e inactivas
+func (r *politicaSLARepository) FindAll() ([]models.PoliticaSLA, error) {
+	var politicas []models.PoliticaSLA
+	err := r.db.Order("tipo_mantenimiento, prioridad, id").Find(&politicas).Error
+	return politicas, err
+}
+
+// FindByID busca una política por su ID
+func (r *politicaSLARepository) FindByID(id uint) (*models.PoliticaSLA, error) {
+	var politica models.PoliticaSLA
+	if err := r.db.First(&politica, id).Error; err != nil {
+		return nil, err
+	}
+	return &politica, nil
+}
+
+// FindActivas obtiene las políticas activas
+func (r *politicaSLARepository) FindActivas() ([]models.PoliticaSLA, error) {
+	var politicas []models.PoliticaSLA
+	err := r.db.Where("activo = ?", true).Order("id").Find(&politicas).Error
+	return politicas, err
+}
+
+// ExisteDuplicado indica si ya hay otra política para el mismo tipo de mantenimiento y prioridad
+func (r *politicaSLARepository) ExisteDuplicado(politica *models.PoliticaSLA) (bool, error) {
+	var total int64
+	err := r.db.Model(&models.PoliticaSLA{}).
+		Where("id <> ? AND tipo_mantenimiento = ? AND prioridad = ?", politica.ID, politica.TipoMantenimiento, politica.Prioridad).
+		Count(&total).Error
+	return total > 0, err
+}
+
+// Create crea una política
+func (r *politicaSLARepository) Create(ctx context.Context, politica *models.PoliticaSLA) error {
+	return r.db.WithContext(ctx).Create(politica).Error
+}
+
+// Update guarda todos los campos de una política
+func (r *politicaSLARepository) Update(ctx context.Context, politica *models.PoliticaSLA) error {
+	return r.db.WithContext(ctx).Model(politica).
+		Select("tipo_mantenimiento", "prioridad", "horas_resolucion", "horas_firma", "activo", "descripcion").Updates(politica).Error
+}
+
+// Delete elimina (soft delete) una política
+func (r *politicaSLARepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.PoliticaSLA{}, id).Error
+}
@@ -1,25 +1,40 @@
 package repositories
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ReporteServicioRepository define las operaciones del repositorio para ReporteServicio
 type ReporteServicioRepository interface {
-	Create(reporte *models.ReporteServicio) error
+	Create(ctx context.Context, reporte *models.ReporteServicio) error
 	FindByID(id uint) (*models.ReporteServicio, error)
-	Update(reporte *models.ReporteServicio) error
-	Delete(id uint) error
-	FindAll() ([]models.ReporteServicio, error)
+	Update(ctx context.Context, reporte *models.ReporteServicio) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.ReporteServicio, int64, error)
+	ExportarReportes(consulta dto.ConsultaDTO, procesar func(lote []models.ReporteServicio) error) error
+	FindIDs(consulta dto.ConsultaDTO, limite int) ([]uint, error)
 	FindByEquipoID(equipoID uint) ([]models.ReporteServicio, error)
-	CreateReporteCompleto(reporte *models.ReporteServicio, tipoMantenimiento *models.TipoMantenimiento, repuestos []models.Repuesto) error
-	CerrarReporte(id uint, archivoURL string) error
-	ReabrirReporte(id uint) error
+	CreateReporteCompleto(ctx context.Context, reporte *models.ReporteServicio, tipoMantenimiento *models.TipoMantenimiento, repuestos []models.Repuesto) error
+	CerrarReporte(ctx context.Context, documento *models.DocumentoReporte) error
+	ReabrirReporte(ctx context.Context, id uint, motivo string, reabiertoPorID *uint, reabiertoPorUsername string) error
 }
 
+var (
+	// ErrReporteCerrado indica que el reporte ya tiene un PDF firmado vigente
+	ErrReporteCerrado = errors.New("el reporte ya está cerrado")
+	// ErrReporteAbierto indica que el reporte no está cerrado
+	ErrReporteAbierto = errors.New("el reporte no está cerrado")
+)
+
 // reporteServicioRepository implementa ReporteServicioRepository
 type reporteServicioRepository struct {
 	db *gorm.DB
@@ -31,8 +46,8 @@ func NewReporteServicioRepository(db *gorm.DB) ReporteServicioRepository {
 }
 
 // Create crea un nuevo reporte de servicio en la base de datos
-func (r *reporteServicioRepository) Create(reporte *models.ReporteServicio) error {
-	return r.db.Create(reporte).Error
+func (r *reporteServicioRepository) Create(ctx context.Context, reporte *models.ReporteServicio) error {
+	return conexion(ctx, r.db).Create(reporte).Error
 }
 
 // FindByID busca un reporte de servicio por su ID
@@ -45,21 +60,136 @@ func (r *reporteServicioRepository) FindByID(id uint) (*models.ReporteServicio,
 	return &reporte, nil
 }
 
-// Update actualiza un reporte de servicio existente
-func (r *reporteServicioRepository) Update(reporte *models.ReporteServicio) error {
-	return r.db.Save(reporte).Error
+// Update actualiza los datos de un reporte de servicio existente. El cierre (FechaCierre y
+// ArchivoFirmadoURL), el creador, el tipo de mantenimiento y los repuestos no cambian: el
+// cierre solo cambia con CerrarReporte y ReabrirReporte, que llevan el historial de versiones.
+// Al terminar, reporte queda con los datos guardados.
+func (r *reporteServicioRepository) Update(ctx context.Context, reporte *models.ReporteServicio) error {
+	db := conexion(ctx, r.db)
+	resultado := db.Model(reporte).
+		Select("equipo_id", "fecha_inicio", "fecha_finalizacion", "dependencia", "ubicacion",
+			"diagnostico_falla", "actividad_realizada", "observaciones").
+		Updates(reporte)
+	if resultado.Error != nil {
+		return resultado.Error
+	}
+	if resultado.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	var actual models.ReporteServicio
+	err := db.Preload("TipoMantenimiento").Preload("Repuestos").Preload("CreadoPor").Preload("Equipo.UsuarioResponsable").
+		First(&actual, reporte.ID).Error
+	if err != nil {
+		return err
+	}
+	*reporte = actual
+	return nil
+}
+
+// Delete elimina un reporte de servicio por su ID junto con sus repuestos. Los repuestos que
+// salieron del almacén devuelven la cantidad al stock, como al eliminar un repuesto; el
+// reporte se bloquea para que dos eliminaciones simultáneas no la devuelvan dos veces.
+func (r *reporteServicioRepository) Delete(ctx context.Context, id uint) error {
+	return conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if _, err := bloquearReporte(tx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		var repuestos []models.Repuesto
+		if err := tx.Where("reporte_id = ?", id).Find(&repuestos).Error; err != nil {
+			return err
+		}
+		for i := range repuestos {
+			if err := tx.Delete(&repuestos[i]).Error; err != nil {
+				return err
+			}
+			if repuestos[i].ArticuloID == nil {
+				continue
+			}
+			err := registrarMovimiento(tx, &models.MovimientoRepuesto{
+				ArticuloID:    *repuestos[i].ArticuloID,
+				Tipo:          models.MovimientoEntrada,
+				Cantidad:      repuestos[i].Cantidad,
+				ReporteID:     &id,
+				RepuestoID:    &repuestos[i].ID,
+				Observaciones: "Devolución por eliminación del reporte",
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.ReporteServicio{}, id).Error
+	})
+}
+
+// FindAll retorna una página de reportes de servicio según la consulta; las relaciones
+// se cargan solo para los reportes de la página
+func (r *reporteServicioRepository) FindAll(consulta dto.ConsultaDTO) ([]models.ReporteServicio, int64, error) {
+	opciones := r.opcionesConsulta()
+	opciones.relaciones = []string{"TipoMantenimiento", "Repuestos", "CreadoPor", "Equipo.UsuarioResponsable"}
+	return paginar[models.ReporteServicio](r.db.Model(&models.ReporteServicio{}), consulta, opciones)
 }
 
-// Delete elimina un reporte de servicio por su ID
-func (r *reporteServicioRepository) Delete(id uint) error {
-	return r.db.Delete(&models.ReporteServicio{}, id).Error
+// ExportarReportes recorre por lotes todos los reportes que cumplen la consulta, con su
+// tipo de mantenimiento, equipo y técnico
+func (r *reporteServicioRepository) ExportarReportes(consulta dto.ConsultaDTO, procesar func(lote []models.ReporteServicio) error) error {
+	base := r.db.Model(&models.ReporteServicio{}).Preload("TipoMantenimiento").Preload("CreadoPor").Preload("Equipo")
+	return recorrerConsulta(base, consulta, r.opcionesConsulta(), procesar)
 }
 
-// FindAll retorna todos los reportes de servicio
-func (r *reporteServicioRepository) FindAll() ([]models.ReporteServicio, error) {
-	var reportes []models.ReporteServicio
-	err := r.db.Preload("TipoMantenimiento").Preload("Repuestos").Preload("CreadoPor").Preload("Equipo.UsuarioResponsable").Find(&reportes).Error
-	return reportes, err
+// FindIDs retorna, en el orden de la consulta, los IDs de hasta limite reportes que la cumplen
+func (r *reporteServicioRepository) FindIDs(consulta dto.ConsultaDTO, limite int) ([]uint, error) {
+	q, orden, err := aplicarConsulta(r.db.Model(&models.ReporteServicio{}), consulta, r.opcionesConsulta())
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint
+	err = q.Order(orden).Limit(limite).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// opcionesConsulta define el orden y los filtros de los listados de reportes de servicio
+func (r *reporteServicioRepository) opcionesConsulta() opcionesConsulta {
+	return opcionesConsulta{
+		id: "id",
+		orden: map[string]string{
+			"id":                 "id",
+			"fecha_inicio":       "fecha_inicio",
+			"fecha_finalizacion": "fecha_finalizacion",
+			"fecha_cierre":       "fecha_cierre",
+			"dependencia":        "dependencia",
+			"created_at":         "created_at",
+		},
+		ordenPorDef: "fecha_inicio",
+		descPorDef:  true,
+		filtros: map[string]filtroConsulta{
+			"equipo":      filtroID("equipo", "equipo_id = ?"),
+			"creado_por":  filtroID("creado_por", "creado_por_id = ?"),
+			"dependencia": filtroTexto("dependencia"),
+			"ubicacion":   filtroTexto("ubicacion"),
+			"tipo": func(db *gorm.DB, valor string) (*gorm.DB, error) {
+				return db.Where("id IN (?)", r.db.Model(&models.TipoMantenimiento{}).Select("reporte_id").Where("tipo = ?", strings.ToUpper(valor))), nil
+			},
+			// abierto = sin fecha de cierre, cerrado = con fecha de cierre
+			"estado": func(db *gorm.DB, valor string) (*gorm.DB, error) {
+				switch valor {
+				case "abierto":
+					return db.Where("fecha_cierre IS NULL"), nil
+				case "cerrado":
+					return db.Where("fecha_cierre IS NOT NULL"), nil
+				}
+				return nil, fmt.Errorf("%w: estado debe ser abierto o cerrado", dto.ErrConsultaInvalida)
+			},
+			"secretaria": filtroPorID("secretaria", func(db *gorm.DB, id uint) *gorm.DB {
+				return db.Where("equipo_id IN (?)", r.db.Model(&models.Equipo{}).Select("id").
+					Where("usuario_responsable_id IN (?)", responsablesDeDependencias(r.db, dependenciasDeSecretaria(r.db, id))))
+			}),
+		},
+		fecha: "fecha_inicio",
+	}
 }
 
 // FindByEquipoID retorna todos los reportes de servicio asociados a un equipo
@@ -70,61 +200,109 @@ func (r *reporteServicioRepository) FindByEquipoID(equipoID uint) ([]models.Repo
 }
 
 // CreateReporteCompleto crea un reporte completo con todas sus relaciones en una transacción
-func (r *reporteServicioRepository) CreateReporteCompleto(reporte *models.ReporteServicio, tipoMantenimiento *models.TipoMantenimiento, repuestos []models.Repuesto) error {
-	// Iniciar transacción
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
-
-	// Función para hacer rollback en caso de error
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+func (r *reporteServicioRepository) CreateReporteCompleto(ctx context.Context, reporte *models.ReporteServicio, tipoMantenimiento *models.TipoMantenimiento, repuestos []models.Repuesto) error {
+	return conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// 1. Crear el reporte principal
+		if err := tx.Create(reporte).Error; err != nil {
+			return err
 		}
-	}()
 
-	// 1. Crear el reporte principal
-	if err := tx.Create(reporte).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// 2. Crear el tipo de mantenimiento
-	tipoMantenimiento.ReporteID = reporte.ID
-	if err := tx.Create(tipoMantenimiento).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		// 2. Crear el tipo de mantenimiento
+		tipoMantenimiento.ReporteID = reporte.ID
+		if err := tx.Create(tipoMantenimiento).Error; err != nil {
+			return err
+		}
 
-	// 3. Crear los repuestos (si los hay)
-	if len(repuestos) > 0 {
+		// 3. Crear los repuestos (si los hay)
 		for i := range repuestos {
 			repuestos[i].ReporteID = &reporte.ID
+			if err := vincularArticulo(tx, &repuestos[i]); err != nil {
+				return err
+			}
 			if err := tx.Create(&repuestos[i]).Error; err != nil {
-				tx.Rollback()
+				return err
+			}
+			// Los repuestos del almacén se descuentan del stock
+			if err := registrarSalidaRepuesto(tx, &repuestos[i]); err != nil {
 				return err
 			}
 		}
-	}
+		return nil
+	})
+}
+
+// CerrarReporte registra una nueva versión del PDF firmado y cierra el reporte con ella.
+// La versión se numera dentro de la transacción, con el reporte bloqueado, para que dos
+// subidas simultáneas no cierren el reporte dos veces.
+func (r *reporteServicioRepository) CerrarReporte(ctx context.Context, documento *models.DocumentoReporte) error {
+	return conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		reporte, err := bloquearReporte(tx, documento.ReporteID)
+		if err != nil {
+			return err
+		}
+		if reporte.FechaCierre != nil {
+			return ErrReporteCerrado
+		}
+
+		var ultima int
+		if err := tx.Model(&models.DocumentoReporte{}).Unscoped().Where("reporte_id = ?", documento.ReporteID).
+			Select("COALESCE(MAX(version), 0)").Scan(&ultima).Error; err != nil {
+			return err
+		}
+		documento.Version = ultima + 1
+
+		if err := tx.Create(documento).Error; err != nil {
+			return err
+		}
 
-	// Confirmar transacción
-	return tx.Commit().Error
+		return tx.Model(&models.ReporteServicio{}).Where("id = ?", documento.ReporteID).Updates(map[string]interface{}{
+			"archivo_firmado_url": documento.RutaObjeto,
+			"fecha_cierre":        documento.FechaSubida,
+		}).Error
+	})
 }
 
-// CerrarReporte marca un reporte como cerrado con la URL del archivo firmado
-func (r *reporteServicioRepository) CerrarReporte(id uint, archivoURL string) error {
-	now := time.Now()
-	return r.db.Model(&models.ReporteServicio{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"archivo_firmado_url": archivoURL,
-		"fecha_cierre":        now,
-	}).Error
+// ReabrirReporte reabre un reporte cerrado. La versión vigente del PDF firmado se conserva
+// en el historial con el motivo y el usuario que reabrió el reporte.
+func (r *reporteServicioRepository) ReabrirReporte(ctx context.Context, id uint, motivo string, reabiertoPorID *uint, reabiertoPorUsername string) error {
+	return conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		reporte, err := bloquearReporte(tx, id)
+		if err != nil {
+			return err
+		}
+		if reporte.FechaCierre == nil {
+			return ErrReporteAbierto
+		}
+
+		var vigente models.DocumentoReporte
+		err = tx.Where("reporte_id = ?", id).Order("version DESC").Take(&vigente).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			if err := tx.Model(&vigente).Updates(map[string]interface{}{
+				"motivo_reapertura":      motivo,
+				"fecha_reapertura":       time.Now(),
+				"reabierto_por_id":       reabiertoPorID,
+				"reabierto_por_username": reabiertoPorUsername,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.ReporteServicio{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"archivo_firmado_url": "",
+			"fecha_cierre":        nil,
+		}).Error
+	})
 }
 
-// ReabrirReporte reabre un reporte cerrado limpiando la URL del archivo y la fecha de cierre
-func (r *reporteServicioRepository) ReabrirReporte(id uint) error {
-	return r.db.Model(&models.ReporteServicio{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"archivo_firmado_url": "",
-		"fecha_cierre":        nil,
-	}).Error
+// bloquearReporte lee el estado de cierre del reporte bloqueando la fila hasta el fin de la transacción
+func bloquearReporte(tx *gorm.DB, id uint) (*models.ReporteServicio, error) {
+	var reporte models.ReporteServicio
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "fecha_cierre").First(&reporte, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reporte, nil
 }
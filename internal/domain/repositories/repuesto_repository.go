@@ -1,18 +1,21 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // RepuestoRepository define las operaciones del repositorio para Repuesto
 type RepuestoRepository interface {
-	Create(repuesto *models.Repuesto) error
+	Create(ctx context.Context, repuesto *models.Repuesto) error
 	FindByID(id uint) (*models.Repuesto, error)
-	Update(repuesto *models.Repuesto) error
-	Delete(id uint) error
-	FindAll() ([]models.Repuesto, error)
+	Update(ctx context.Context, repuesto *models.Repuesto) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.Repuesto, int64, error)
 	FindByReporteID(reporteID uint) ([]models.Repuesto, error)
 }
 
@@ -26,9 +29,17 @@ func NewRepuestoRepository(db *gorm.DB) RepuestoRepository {
 	return &repuestoRepository{db: db}
 }
 
-// Create crea un nuevo repuesto en la base de datos
-func (r *repuestoRepository) Create(repuesto *models.Repuesto) error {
-	return r.db.Create(repuesto).Error
+// Create crea un nuevo repuesto en la base de datos; si está en el almacén, lo descuenta del stock
+func (r *repuestoRepository) Create(ctx context.Context, repuesto *models.Repuesto) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := vincularArticulo(tx, repuesto); err != nil {
+			return err
+		}
+		if err := tx.Create(repuesto).Error; err != nil {
+			return err
+		}
+		return registrarSalidaRepuesto(tx, repuesto)
+	})
 }
 
 // FindByID busca un repuesto por su ID
@@ -42,20 +53,55 @@ func (r *repuestoRepository) FindByID(id uint) (*models.Repuesto, error) {
 }
 
 // Update actualiza un repuesto existente
-func (r *repuestoRepository) Update(repuesto *models.Repuesto) error {
-	return r.db.Save(repuesto).Error
+func (r *repuestoRepository) Update(ctx context.Context, repuesto *models.Repuesto) error {
+	return r.db.WithContext(ctx).Save(repuesto).Error
 }
 
-// Delete elimina un repuesto por su ID
-func (r *repuestoRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Repuesto{}, id).Error
+// Delete elimina un repuesto por su ID; si salió del almacén, devuelve la cantidad al stock.
+// La fila del repuesto queda bloqueada hasta el final, así que dos eliminaciones simultáneas
+// del mismo repuesto no devuelven la cantidad dos veces: la segunda no lo encuentra.
+func (r *repuestoRepository) Delete(ctx context.Context, id uint) error {
+	return conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var repuesto models.Repuesto
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&repuesto, id).Error; err != nil {
+			return err
+		}
+		resultado := tx.Delete(&repuesto)
+		if resultado.Error != nil {
+			return resultado.Error
+		}
+		if resultado.RowsAffected != 1 {
+			return gorm.ErrRecordNotFound
+		}
+		if repuesto.ArticuloID == nil {
+			return nil
+		}
+		return registrarMovimiento(tx, &models.MovimientoRepuesto{
+			ArticuloID:    *repuesto.ArticuloID,
+			Tipo:          models.MovimientoEntrada,
+			Cantidad:      repuesto.Cantidad,
+			ReporteID:     repuesto.ReporteID,
+			RepuestoID:    &repuesto.ID,
+			Observaciones: "Devolución por eliminación del repuesto",
+		})
+	})
 }
 
-// FindAll retorna todos los repuestos
-func (r *repuestoRepository) FindAll() ([]models.Repuesto, error) {
-	var repuestos []models.Repuesto
-	err := r.db.Find(&repuestos).Error
-	return repuestos, err
+// FindAll retorna una página de repuestos según la consulta
+func (r *repuestoRepository) FindAll(consulta dto.ConsultaDTO) ([]models.Repuesto, int64, error) {
+	return paginar[models.Repuesto](r.db.Model(&models.Repuesto{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "fecha_utilizacion": "fecha_utilizacion", "marca": "marca", "cantidad": "cantidad"},
+		ordenPorDef: "fecha_utilizacion",
+		descPorDef:  true,
+		filtros: map[string]filtroConsulta{
+			"reporte":     filtroID("reporte", "reporte_id = ?"),
+			"marca":       filtroTexto("marca"),
+			"serial":      filtroTexto("serial_numero_parte"),
+			"descripcion": filtroTexto("descripcion"),
+		},
+		fecha: "fecha_utilizacion",
+	})
 }
 
 // FindByReporteID retorna todos los repuestos asociados a un reporte
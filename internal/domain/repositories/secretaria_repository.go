@@ -1,18 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // SecretariaRepository define las operaciones del repositorio para Secretaria
 type SecretariaRepository interface {
-	CreateSecretaria(secretaria *models.Secretaria) error
+	CreateSecretaria(ctx context.Context, secretaria *models.Secretaria) error
 	GetSecretariaByID(id uint) (*models.Secretaria, error)
-	GetAllSecretarias() ([]models.Secretaria, error)
-	UpdateSecretaria(secretaria *models.Secretaria) error
-	DeleteSecretaria(id uint) error
+	GetAllSecretarias(consulta dto.ConsultaDTO) ([]models.Secretaria, int64, error)
+	UpdateSecretaria(ctx context.Context, secretaria *models.Secretaria) error
+	DeleteSecretaria(ctx context.Context, id uint) error
 }
 
 // secretariaRepository implementa SecretariaRepository
@@ -26,8 +28,8 @@ func NewSecretariaRepository(db *gorm.DB) SecretariaRepository {
 }
 
 // CreateSecretaria crea una nueva secretaría/ en la base de datos
-func (r *secretariaRepository) CreateSecretaria(secretaria *models.Secretaria) error {
-	return r.db.Create(secretaria).Error
+func (r *secretariaRepository) CreateSecretaria(ctx context.Context, secretaria *models.Secretaria) error {
+	return r.db.WithContext(ctx).Create(secretaria).Error
 }
 
 // GetSecretariaByID busca una secretaría/ por su ID
@@ -40,19 +42,26 @@ func (r *secretariaRepository) GetSecretariaByID(id uint) (*models.Secretaria, e
 	return &secretaria, nil
 }
 
-// GetAllSecretarias retorna todas las secretaríass
-func (r *secretariaRepository) GetAllSecretarias() ([]models.Secretaria, error) {
-	var secretarias []models.Secretaria
-	err := r.db.Find(&secretarias).Error
-	return secretarias, err
+// GetAllSecretarias retorna una página de secretarías según la consulta
+func (r *secretariaRepository) GetAllSecretarias(consulta dto.ConsultaDTO) ([]models.Secretaria, int64, error) {
+	return paginar[models.Secretaria](r.db.Model(&models.Secretaria{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "nombre": "nombre", "created_at": "created_at"},
+		ordenPorDef: "nombre",
+		filtros: map[string]filtroConsulta{
+			"nombre":     filtroTexto("nombre"),
+			"secretario": filtroTexto("secretario"),
+		},
+		fecha: "created_at",
+	})
 }
 
 // UpdateSecretaria actualiza una secretaría existente
-func (r *secretariaRepository) UpdateSecretaria(secretaria *models.Secretaria) error {
-	return r.db.Save(secretaria).Error
+func (r *secretariaRepository) UpdateSecretaria(ctx context.Context, secretaria *models.Secretaria) error {
+	return r.db.WithContext(ctx).Save(secretaria).Error
 }
 
 // DeleteSecretaria elimina una secretaría por su ID
-func (r *secretariaRepository) DeleteSecretaria(id uint) error {
-	return r.db.Delete(&models.Secretaria{}, id).Error
+func (r *secretariaRepository) DeleteSecretaria(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Secretaria{}, id).Error
 }
@@ -1,18 +1,21 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // SoftwareRepository define las operaciones del repositorio para Software
 type SoftwareRepository interface {
-	Create(software *models.Software) error
+	Create(ctx context.Context, software *models.Software) error
 	FindByID(id uint) (*models.Software, error)
-	Update(software *models.Software) error
-	Delete(id uint) error
-	FindAll() ([]models.Software, error)
+	Update(ctx context.Context, software *models.Software) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.Software, int64, error)
+	ExportarSoftware(consulta dto.ConsultaDTO, procesar func(lote []dto.SoftwareExportacionDTO) error) error
 	FindByEquipoID(equipoID uint) ([]models.Software, error)
 }
 
@@ -27,8 +30,8 @@ func NewSoftwareRepository(db *gorm.DB) SoftwareRepository {
 }
 
 // Create crea un nuevo software en la base de datos
-func (r *softwareRepository) Create(software *models.Software) error {
-	return r.db.Create(software).Error
+func (r *softwareRepository) Create(ctx context.Context, software *models.Software) error {
+	return r.db.WithContext(ctx).Create(software).Error
 }
 
 // FindByID busca un software por su ID
@@ -42,20 +45,43 @@ func (r *softwareRepository) FindByID(id uint) (*models.Software, error) {
 }
 
 // Update actualiza un software existente
-func (r *softwareRepository) Update(software *models.Software) error {
-	return r.db.Save(&software).Error
+func (r *softwareRepository) Update(ctx context.Context, software *models.Software) error {
+	return r.db.WithContext(ctx).Save(&software).Error
 }
 
 // Delete elimina un software por su ID
-func (r *softwareRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Software{}, id).Error
+func (r *softwareRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Software{}, id).Error
 }
 
-// FindAll retorna todos los software
-func (r *softwareRepository) FindAll() ([]models.Software, error) {
-	var allSoftware []models.Software
-	err := r.db.Find(&allSoftware).Error
-	return allSoftware, err
+// FindAll retorna una página de software según la consulta
+func (r *softwareRepository) FindAll(consulta dto.ConsultaDTO) ([]models.Software, int64, error) {
+	return paginar[models.Software](r.db.Model(&models.Software{}), consulta, r.opcionesConsulta())
+}
+
+// ExportarSoftware recorre por lotes todo el software que cumple la consulta, con la
+// placa de su equipo
+func (r *softwareRepository) ExportarSoftware(consulta dto.ConsultaDTO, procesar func(lote []dto.SoftwareExportacionDTO) error) error {
+	opciones := r.opcionesConsulta()
+	opciones.columnas = "softwares.*, (SELECT e.placa_inventario FROM equipos e WHERE e.id = softwares.equipo_id) AS equipo_placa"
+	return recorrerConsulta(r.db.Model(&models.Software{}), consulta, opciones, procesar)
+}
+
+// opcionesConsulta define el orden y los filtros de los listados de software
+func (r *softwareRepository) opcionesConsulta() opcionesConsulta {
+	return opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "nombre": "nombre", "version": "version", "categoria": "categoria", "created_at": "created_at"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"equipo":        filtroID("equipo", "equipo_id = ?"),
+			"nombre":        filtroTexto("nombre"),
+			"version":       filtroTexto("version"),
+			"tipo_licencia": filtroTexto("tipo_licencia"),
+			"categoria":     filtroIgual("categoria"),
+		},
+		fecha: "created_at",
+	}
 }
 
 // FindByEquipoID retorna todos los software asociados a un equipo
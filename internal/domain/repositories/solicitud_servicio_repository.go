@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SolicitudServicioRepository define las operaciones de acceso a datos de las solicitudes de servicio
+type SolicitudServicioRepository interface {
+	Create(ctx context.Context, solicitud *models.SolicitudServicio) error
+	FindByID(id uint) (*models.SolicitudServicio, error)
+	Bloquear(ctx context.Context, id uint) (*models.SolicitudServicio, error)
+	FindAll(consulta dto.ConsultaDTO, dependenciaID *uint) ([]models.SolicitudServicio, int64, error)
+	CambiarEstado(ctx context.Context, id uint, desde []string, cambios map[string]interface{}) (bool, error)
+	ReporteVinculado(ctx context.Context, reporteID uint) (bool, error)
+	PrioridadesDeReportes(reporteIDs []uint) (map[uint]string, error)
+	DependenciaDeUsuario(usuarioID uint) (*uint, error)
+	DependenciaDeEquipo(equipoID uint) (*uint, error)
+}
+
+// solicitudServicioRepository implementa SolicitudServicioRepository
+type solicitudServicioRepository struct {
+	db *gorm.DB
+}
+
+// NewSolicitudServicioRepository crea una nueva instancia de SolicitudServicioRepository
+func NewSolicitudServicioRepository(db *gorm.DB) SolicitudServicioRepository {
+	return &solicitudServicioRepository{db: db}
+}
+
+// datosUsuario limita los datos cargados del solicitante y del técnico a los públicos
+func datosUsuario(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "nombre", "apellido", "username", "email")
+}
+
+// conRelaciones carga el equipo, la dependencia, el solicitante y el técnico de las solicitudes
+func (r *solicitudServicioRepository) conRelaciones(db *gorm.DB) *gorm.DB {
+	return db.Preload("Equipo").Preload("Dependencia").
+		Preload("Solicitante", datosUsuario).Preload("Tecnico", datosUsuario)
+}
+
+// Create crea una solicitud
+func (r *solicitudServicioRepository) Create(ctx context.Context, solicitud *models.SolicitudServicio) error {
+	return r.db.WithContext(ctx).Create(solicitud).Error
+}
+
+// FindByID busca una solicitud por su ID con sus relaciones y el reporte con el que se resolvió
+func (r *solicitudServicioRepository) FindByID(id uint) (*models.SolicitudServicio, error) {
+	var solicitud models.SolicitudServicio
+	if err := r.conRelaciones(r.db).Preload("Reporte").First(&solicitud, id).Error; err != nil {
+		return nil, err
+	}
+	return &solicitud, nil
+}
+
+// Bloquear bloquea la solicitud hasta el fin de la transacción del contexto y la retorna con
+// sus relaciones. Dos operaciones sobre la misma solicitud se aplican una después de la otra.
+func (r *solicitudServicioRepository) Bloquear(ctx context.Context, id uint) (*models.SolicitudServicio, error) {
+	tx := conexion(ctx, r.db)
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.SolicitudServicio{}, id).Error; err != nil {
+		return nil, err
+	}
+	var solicitud models.SolicitudServicio
+	if err := r.conRelaciones(tx).First(&solicitud, id).Error; err != nil {
+		return nil, err
+	}
+	return &solicitud, nil
+}
+
+// FindAll retorna una página de solicitudes según la consulta. Si dependenciaID no es nil,
+// solo las de esa dependencia.
+func (r *solicitudServicioRepository) FindAll(consulta dto.ConsultaDTO, dependenciaID *uint) ([]models.SolicitudServicio, int64, error) {
+	base := r.conRelaciones(r.db.Model(&models.SolicitudServicio{}))
+	if dependenciaID != nil {
+		base = base.Where("dependencia_id = ?", *dependenciaID)
+	}
+	return paginar[models.SolicitudServicio](base, consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "fecha": "created_at", "prioridad": "prioridad", "estado": "estado"},
+		ordenPorDef: "created_at",
+		descPorDef:  true,
+		filtros: map[string]filtroConsulta{
+			"estado":      filtroIgual("estado"),
+			"prioridad":   filtroIgual("prioridad"),
+			"equipo":      filtroID("equipo", "equipo_id = ?"),
+			"tecnico":     filtroID("tecnico", "tecnico_id = ?"),
+			"solicitante": filtroID("solicitante", "solicitante_id = ?"),
+			"dependencia": filtroID("dependencia", "dependencia_id = ?"),
+		},
+		fecha: "created_at",
+	})
+}
+
+// CambiarEstado aplica los cambios solo si la solicitud sigue en alguno de los estados
+// indicados; retorna false si otro cambio llegó antes
+func (r *solicitudServicioRepository) CambiarEstado(ctx context.Context, id uint, desde []string, cambios map[string]interface{}) (bool, error) {
+	resultado := conexion(ctx, r.db).Model(&models.SolicitudServicio{}).
+		Where("id = ? AND estado IN ?", id, desde).Updates(cambios)
+	return resultado.RowsAffected > 0, resultado.Error
+}
+
+// ReporteVinculado indica si una solicitud ya quedó resuelta con el reporte. Bloquea el
+// reporte hasta el fin de la transacción del contexto, así dos solicitudes no se resuelven
+// a la vez con el mismo reporte. Retorna gorm.ErrRecordNotFound si el reporte no existe.
+func (r *solicitudServicioRepository) ReporteVinculado(ctx context.Context, reporteID uint) (bool, error) {
+	tx := conexion(ctx, r.db)
+	if _, err := bloquearReporte(tx, reporteID); err != nil {
+		return false, err
+	}
+	var total int64
+	err := tx.Model(&models.SolicitudServicio{}).Where("reporte_id = ?", reporteID).Count(&total).Error
+	return total > 0, err
+}
+
+// PrioridadesDeReportes obtiene la prioridad de la solicitud que resolvió cada reporte; los
+// reportes sin solicitud no aparecen
+func (r *solicitudServicioRepository) PrioridadesDeReportes(reporteIDs []uint) (map[uint]string, error) {
+	prioridades := make(map[uint]string)
+	if len(reporteIDs) == 0 {
+		return prioridades, nil
+	}
+	var solicitudes []models.SolicitudServicio
+	err := r.db.Select("reporte_id", "prioridad").Where("reporte_id IN ?", reporteIDs).Find(&solicitudes).Error
+	for _, solicitud := range solicitudes {
+		prioridades[*solicitud.ReporteID] = solicitud.Prioridad
+	}
+	return prioridades, err
+}
+
+// DependenciaDeUsuario obtiene la dependencia del usuario del sistema. Un usuario pertenece a
+// la dependencia del usuario responsable con su misma cédula; nil si no tiene.
+func (r *solicitudServicioRepository) DependenciaDeUsuario(usuarioID uint) (*uint, error) {
+	var dependencias []uint
+	err := r.db.Raw(`
+		SELECT ur.dependencia_id
+		FROM usuarios u
+		JOIN usuario_responsables ur ON ur.cedula = u.cedula AND ur.deleted_at IS NULL
+		WHERE u.id = ? AND u.deleted_at IS NULL AND u.cedula <> '' AND ur.dependencia_id IS NOT NULL
+		LIMIT 1
+	`, usuarioID).Scan(&dependencias).Error
+	if err != nil || len(dependencias) == 0 {
+		return nil, err
+	}
+	return &dependencias[0], nil
+}
+
+// DependenciaDeEquipo obtiene la dependencia del responsable del equipo; nil si el equipo no
+// tiene responsable o el responsable no tiene dependencia. Retorna gorm.ErrRecordNotFound si
+// el equipo no existe.
+func (r *solicitudServicioRepository) DependenciaDeEquipo(equipoID uint) (*uint, error) {
+	var equipo models.Equipo
+	if err := r.db.Select("id", "usuario_responsable_id").First(&equipo, equipoID).Error; err != nil {
+		return nil, err
+	}
+	if equipo.UsuarioResponsableID == nil {
+		return nil, nil
+	}
+	var responsable models.UsuarioResponsable
+	err := r.db.Select("id", "dependencia_id").Limit(1).Find(&responsable, *equipo.UsuarioResponsableID).Error
+	if err != nil {
+		return nil, err
+	}
+	return responsable.DependenciaID, nil
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dbDePrueba abre una base SQLite vacía en el directorio temporal del test y migra los
+// modelos indicados. SQLite ignora los bloqueos FOR UPDATE, así que estos tests no
+// prueban la concurrencia, solo el resultado de cada operación.
+func dbDePrueba(t *testing.T, modelos ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "prueba.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("abriendo la base de prueba: %v", err)
+	}
+	if err := db.AutoMigrate(modelos...); err != nil {
+		t.Fatalf("migrando la base de prueba: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
@@ -1,18 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // TipoMantenimientoRepository define las operaciones del repositorio para TipoMantenimiento
 type TipoMantenimientoRepository interface {
-	Create(tipo *models.TipoMantenimiento) error
+	Create(ctx context.Context, tipo *models.TipoMantenimiento) error
 	FindByID(id uint) (*models.TipoMantenimiento, error)
-	Update(tipo *models.TipoMantenimiento) error
-	Delete(id uint) error
-	FindAll() ([]models.TipoMantenimiento, error)
+	Update(ctx context.Context, tipo *models.TipoMantenimiento) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.TipoMantenimiento, int64, error)
 	FindByReporteID(reporteID uint) ([]models.TipoMantenimiento, error)
 }
 
@@ -27,8 +29,8 @@ func NewTipoMantenimientoRepository(db *gorm.DB) TipoMantenimientoRepository {
 }
 
 // Create crea un nuevo tipo de mantenimiento en la base de datos
-func (r *tipoMantenimientoRepository) Create(tipo *models.TipoMantenimiento) error {
-	return r.db.Create(tipo).Error
+func (r *tipoMantenimientoRepository) Create(ctx context.Context, tipo *models.TipoMantenimiento) error {
+	return r.db.WithContext(ctx).Create(tipo).Error
 }
 
 // FindByID busca un tipo de mantenimiento por su ID
@@ -42,20 +44,27 @@ func (r *tipoMantenimientoRepository) FindByID(id uint) (*models.TipoMantenimien
 }
 
 // Update actualiza un tipo de mantenimiento existente
-func (r *tipoMantenimientoRepository) Update(tipo *models.TipoMantenimiento) error {
-	return r.db.Save(tipo).Error
+func (r *tipoMantenimientoRepository) Update(ctx context.Context, tipo *models.TipoMantenimiento) error {
+	return r.db.WithContext(ctx).Save(tipo).Error
 }
 
 // Delete elimina un tipo de mantenimiento por su ID
-func (r *tipoMantenimientoRepository) Delete(id uint) error {
-	return r.db.Delete(&models.TipoMantenimiento{}, id).Error
+func (r *tipoMantenimientoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.TipoMantenimiento{}, id).Error
 }
 
-// FindAll retorna todos los tipos de mantenimiento
-func (r *tipoMantenimientoRepository) FindAll() ([]models.TipoMantenimiento, error) {
-	var tipos []models.TipoMantenimiento
-	err := r.db.Find(&tipos).Error
-	return tipos, err
+// FindAll retorna una página de tipos de mantenimiento según la consulta
+func (r *tipoMantenimientoRepository) FindAll(consulta dto.ConsultaDTO) ([]models.TipoMantenimiento, int64, error) {
+	return paginar[models.TipoMantenimiento](r.db.Model(&models.TipoMantenimiento{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "tipo": "tipo", "created_at": "created_at"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"reporte": filtroID("reporte", "reporte_id = ?"),
+			"tipo":    filtroIgual("tipo"),
+		},
+		fecha: "created_at",
+	})
 }
 
 // FindByReporteID retorna todos los tipos de mantenimiento asociados a un reporte
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TrabajoRepository define las operaciones de la cola de trabajos en segundo plano
+type TrabajoRepository interface {
+	Create(ctx context.Context, trabajo *models.Trabajo) error
+	FindByID(id uint) (*models.Trabajo, error)
+	Arrendar(ctx context.Context, trabajador string, duracion time.Duration) (*models.Trabajo, error)
+	Renovar(ctx context.Context, id uint, trabajador string, hasta time.Time, progreso int) (bool, error)
+	Finalizar(ctx context.Context, trabajo *models.Trabajo, trabajador string) (bool, error)
+	FindTerminadosAntes(fecha time.Time) ([]models.Trabajo, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+// trabajoRepository implementa TrabajoRepository
+type trabajoRepository struct {
+	db *gorm.DB
+}
+
+// NewTrabajoRepository crea una nueva instancia de TrabajoRepository
+func NewTrabajoRepository(db *gorm.DB) TrabajoRepository {
+	return &trabajoRepository{db: db}
+}
+
+// Create encola un trabajo
+func (r *trabajoRepository) Create(ctx context.Context, trabajo *models.Trabajo) error {
+	return r.db.WithContext(ctx).Create(trabajo).Error
+}
+
+// FindByID busca un trabajo por su ID
+func (r *trabajoRepository) FindByID(id uint) (*models.Trabajo, error) {
+	var trabajo models.Trabajo
+	if err := r.db.First(&trabajo, id).Error; err != nil {
+		return nil, err
+	}
+	return &trabajo, nil
+}
+
+// Arrendar toma el trabajo disponible más antiguo: uno pendiente cuya espera terminó o uno
+// en proceso cuyo arriendo venció. SKIP LOCKED evita que dos trabajadores tomen el mismo.
+// Retorna gorm.ErrRecordNotFound si no hay trabajos disponibles.
+func (r *trabajoRepository) Arrendar(ctx context.Context, trabajador string, duracion time.Duration) (*models.Trabajo, error) {
+	var trabajo models.Trabajo
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ahora := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(estado = ? AND disponible_en <= ?) OR (estado = ? AND arrendado_hasta < ?)",
+				models.TrabajoPendiente, ahora, models.TrabajoEnProceso, ahora).
+			Order("disponible_en, id").Take(&trabajo).Error
+		if err != nil {
+			return err
+		}
+
+		hasta := ahora.Add(duracion)
+		trabajo.Estado = models.TrabajoEnProceso
+		trabajo.Trabajador = trabajador
+		trabajo.ArrendadoHasta = &hasta
+		trabajo.Intentos++
+		trabajo.IniciadoEn = &ahora
+		return tx.Model(&trabajo).Select("estado", "trabajador", "arrendado_hasta", "intentos", "iniciado_en").Updates(&trabajo).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trabajo, nil
+}
+
+// Renovar extiende el arriendo y guarda el progreso. Retorna false si el trabajador ya no
+// tiene el trabajo, porque venció el arriendo y otro lo tomó.
+func (r *trabajoRepository) Renovar(ctx context.Context, id uint, trabajador string, hasta time.Time, progreso int) (bool, error) {
+	resultado := r.db.WithContext(ctx).Model(&models.Trabajo{}).
+		Where("id = ? AND estado = ? AND trabajador = ?", id, models.TrabajoEnProceso, trabajador).
+		Updates(map[string]interface{}{"arrendado_hasta": hasta, "progreso": progreso})
+	return resultado.RowsAffected > 0, resultado.Error
+}
+
+// Finalizar guarda el resultado de una ejecución (terminado, fallido o a la espera de otro
+// intento) si el trabajador todavía tiene el trabajo. Retorna false si ya no lo tiene.
+func (r *trabajoRepository) Finalizar(ctx context.Context, trabajo *models.Trabajo, trabajador string) (bool, error) {
+	resultado := r.db.WithContext(ctx).Model(trabajo).
+		Where("estado = ? AND trabajador = ?", models.TrabajoEnProceso, trabajador).
+		Select("estado", "progreso", "disponible_en", "arrendado_hasta", "trabajador", "error",
+			"resultado", "resultado_nombre", "resumen", "finalizado_en").
+		Updates(trabajo)
+	return resultado.RowsAffected > 0, resultado.Error
+}
+
+// FindTerminadosAntes retorna los trabajos completados o fallidos antes de la fecha
+func (r *trabajoRepository) FindTerminadosAntes(fecha time.Time) ([]models.Trabajo, error) {
+	var trabajos []models.Trabajo
+	err := r.db.Where("estado IN ? AND finalizado_en < ?", []string{models.TrabajoCompletado, models.TrabajoFallido}, fecha).
+		Order("id").Find(&trabajos).Error
+	return trabajos, err
+}
+
+// Delete elimina definitivamente un trabajo; la cola no necesita conservar su historial
+func (r *trabajoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.Trabajo{}, id).Error
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+func TestArrendar(t *testing.T) {
+	ahora := time.Now()
+	vencido := ahora.Add(-time.Minute)
+	vigente := ahora.Add(time.Minute)
+
+	casos := []struct {
+		nombre       string
+		trabajos     []models.Trabajo
+		wantTipo     string // Tipo del trabajo arrendado; vacío si no hay ninguno disponible
+		wantIntentos int
+	}{
+		{"cola vacía", nil, "", 0},
+		{"pendiente disponible", []models.Trabajo{
+			{Tipo: "a", Estado: models.TrabajoPendiente, DisponibleEn: ahora.Add(-time.Second)},
+		}, "a", 1},
+		{"pendiente esperando reintento", []models.Trabajo{
+			{Tipo: "a", Estado: models.TrabajoPendiente, DisponibleEn: ahora.Add(time.Minute), Intentos: 1},
+		}, "", 0},
+		{"en proceso con arriendo vigente", []models.Trabajo{
+			{Tipo: "a", Estado: models.TrabajoEnProceso, DisponibleEn: ahora.Add(-time.Hour), ArrendadoHasta: &vigente, Trabajador: "otro", Intentos: 1},
+		}, "", 0},
+		{"en proceso con arriendo vencido", []models.Trabajo{
+			{Tipo: "a", Estado: models.TrabajoEnProceso, DisponibleEn: ahora.Add(-time.Hour), ArrendadoHasta: &vencido, Trabajador: "otro", Intentos: 1},
+		}, "a", 2},
+		{"terminados no se toman", []models.Trabajo{
+			{Tipo: "a", Estado: models.TrabajoCompletado, DisponibleEn: ahora.Add(-time.Hour)},
+			{Tipo: "b", Estado: models.TrabajoFallido, DisponibleEn: ahora.Add(-time.Hour)},
+		}, "", 0},
+		{"el más antiguo primero", []models.Trabajo{
+			{Tipo: "nuevo", Estado: models.TrabajoPendiente, DisponibleEn: ahora.Add(-time.Second)},
+			{Tipo: "antiguo", Estado: models.TrabajoPendiente, DisponibleEn: ahora.Add(-time.Hour)},
+		}, "antiguo", 1},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			db := dbDePrueba(t, &models.Trabajo{})
+			repo := NewTrabajoRepository(db)
+			ctx := context.Background()
+			for i := range tc.trabajos {
+				if err := repo.Create(ctx, &tc.trabajos[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			trabajo, err := repo.Arrendar(ctx, "trabajador-1", 5*time.Minute)
+			if tc.wantTipo == "" {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					t.Fatalf("Arrendar = %+v, %v; se esperaba gorm.ErrRecordNotFound", trabajo, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Arrendar: %v", err)
+			}
+
+			guardado, err := repo.FindByID(trabajo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if guardado.Tipo != tc.wantTipo || guardado.Estado != models.TrabajoEnProceso || guardado.Trabajador != "trabajador-1" || guardado.Intentos != tc.wantIntentos {
+				t.Errorf("trabajo arrendado = tipo %q, estado %q, trabajador %q, intentos %d; se esperaba %q en proceso por trabajador-1 con %d intentos",
+					guardado.Tipo, guardado.Estado, guardado.Trabajador, guardado.Intentos, tc.wantTipo, tc.wantIntentos)
+			}
+			if guardado.ArrendadoHasta == nil || guardado.ArrendadoHasta.Before(ahora.Add(4*time.Minute)) {
+				t.Errorf("arrendado hasta = %v, se esperaba unos 5 minutos desde ahora", guardado.ArrendadoHasta)
+			}
+
+			// Mientras el arriendo esté vigente, ningún otro trabajador toma el mismo trabajo
+			if otro, err := repo.Arrendar(ctx, "trabajador-2", 5*time.Minute); err == nil && otro.ID == trabajo.ID {
+				t.Error("otro trabajador tomó un trabajo con arriendo vigente")
+			}
+		})
+	}
+}
+
+func TestRenovarYFinalizar(t *testing.T) {
+	casos := []struct {
+		nombre      string
+		trabajador  string
+		retomado    bool // Otro trabajador tomó el trabajo porque venció el arriendo
+		wantVigente bool
+	}{
+		{"el mismo trabajador", "trabajador-1", false, true},
+		{"otro trabajador", "trabajador-2", false, false},
+		{"arriendo retomado por otro", "trabajador-1", true, false},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			db := dbDePrueba(t, &models.Trabajo{})
+			repo := NewTrabajoRepository(db)
+			ctx := context.Background()
+
+			if err := repo.Create(ctx, &models.Trabajo{Tipo: "a", Estado: models.TrabajoPendiente, DisponibleEn: time.Now().Add(-time.Second)}); err != nil {
+				t.Fatal(err)
+			}
+			trabajo, err := repo.Arrendar(ctx, "trabajador-1", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.retomado {
+				if err := db.Model(&models.Trabajo{}).Where("id = ?", trabajo.ID).Update("arrendado_hasta", time.Now().Add(-time.Second)).Error; err != nil {
+					t.Fatal(err)
+				}
+				if _, err := repo.Arrendar(ctx, "trabajador-3", time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			vigente, err := repo.Renovar(ctx, trabajo.ID, tc.trabajador, time.Now().Add(time.Hour), 40)
+			if err != nil {
+				t.Fatalf("Renovar: %v", err)
+			}
+			if vigente != tc.wantVigente {
+				t.Errorf("Renovar = %v, se esperaba %v", vigente, tc.wantVigente)
+			}
+
+			ahora := time.Now()
+			terminado := *trabajo
+			terminado.Estado = models.TrabajoCompletado
+			terminado.Progreso = 100
+			terminado.Trabajador = ""
+			terminado.ArrendadoHasta = nil
+			terminado.FinalizadoEn = &ahora
+			vigente, err = repo.Finalizar(ctx, &terminado, tc.trabajador)
+			if err != nil {
+				t.Fatalf("Finalizar: %v", err)
+			}
+			if vigente != tc.wantVigente {
+				t.Errorf("Finalizar = %v, se esperaba %v", vigente, tc.wantVigente)
+			}
+
+			guardado, err := repo.FindByID(trabajo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.wantVigente {
+				if guardado.Estado != models.TrabajoCompletado || guardado.Progreso != 100 || guardado.Trabajador != "" || guardado.ArrendadoHasta != nil {
+					t.Errorf("trabajo finalizado = %+v", guardado)
+				}
+				// Un trabajo terminado ya no se puede renovar
+				if vigente, _ := repo.Renovar(ctx, trabajo.ID, tc.trabajador, time.Now().Add(time.Hour), 0); vigente {
+					t.Error("se renovó un trabajo terminado")
+				}
+				return
+			}
+			if guardado.Estado != models.TrabajoEnProceso || guardado.Progreso == 100 {
+				t.Errorf("un trabajador sin el arriendo modificó el trabajo: %+v", guardado)
+			}
+		})
+	}
+}
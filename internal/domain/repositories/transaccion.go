@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// claveTransaccion es la clave del contexto que lleva la transacción en curso
+type claveTransaccion struct{}
+
+// Transacciones agrupa operaciones de varios repositorios en una sola transacción
+type Transacciones interface {
+	// Ejecutar llama a fn con un contexto que lleva la transacción: los repositorios que
+	// reciben ese contexto escriben en ella. Si fn retorna un error se revierte todo.
+	Ejecutar(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// transacciones implementa Transacciones
+type transacciones struct {
+	db *gorm.DB
+}
+
+// NewTransacciones crea una nueva instancia de Transacciones
+func NewTransacciones(db *gorm.DB) Transacciones {
+	return &transacciones{db: db}
+}
+
+// Ejecutar abre la transacción o, si el contexto ya lleva una, un punto de guardado dentro de ella
+func (t *transacciones) Ejecutar(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conexion(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, claveTransaccion{}, tx))
+	})
+}
+
+// conexion retorna la transacción que lleva el contexto o, si no lleva ninguna, la conexión
+// del repositorio con el contexto
+func conexion(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(claveTransaccion{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
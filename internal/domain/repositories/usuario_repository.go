@@ -1,21 +1,23 @@
 package repositories
 
 import (
+	"context"
 	"time"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // UsuarioRepository define las operaciones del repositorio para Usuario
 type UsuarioRepository interface {
-	Create(usuario *models.Usuario) error
+	Create(ctx context.Context, usuario *models.Usuario) error
 	FindByID(id uint) (*models.Usuario, error)
 	FindByUsername(username string) (*models.Usuario, error)
 	FindByEmail(email string) (*models.Usuario, error)
-	Update(usuario *models.Usuario) error
-	Delete(id uint) error
-	FindAll() ([]models.Usuario, error)
+	Update(ctx context.Context, usuario *models.Usuario) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.Usuario, int64, error)
 	UpdateLastLogin(id uint) error
 }
 
@@ -30,8 +32,8 @@ func NewUsuarioRepository(db *gorm.DB) UsuarioRepository {
 }
 
 // Create crea un nuevo usuario en la base de datos
-func (r *usuarioRepository) Create(usuario *models.Usuario) error {
-	return r.db.Create(usuario).Error
+func (r *usuarioRepository) Create(ctx context.Context, usuario *models.Usuario) error {
+	return r.db.WithContext(ctx).Create(usuario).Error
 }
 
 // FindByID busca un usuario por su ID
@@ -65,20 +67,30 @@ func (r *usuarioRepository) FindByEmail(email string) (*models.Usuario, error) {
 }
 
 // Update actualiza un usuario existente
-func (r *usuarioRepository) Update(usuario *models.Usuario) error {
-	return r.db.Save(usuario).Error
+func (r *usuarioRepository) Update(ctx context.Context, usuario *models.Usuario) error {
+	return r.db.WithContext(ctx).Save(usuario).Error
 }
 
 // Delete elimina un usuario por su ID
-func (r *usuarioRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Usuario{}, id).Error
+func (r *usuarioRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Usuario{}, id).Error
 }
 
-// FindAll obtiene todos los usuarios
-func (r *usuarioRepository) FindAll() ([]models.Usuario, error) {
-	var usuarios []models.Usuario
-	err := r.db.Find(&usuarios).Error
-	return usuarios, err
+// FindAll obtiene una página de usuarios según la consulta
+func (r *usuarioRepository) FindAll(consulta dto.ConsultaDTO) ([]models.Usuario, int64, error) {
+	return paginar[models.Usuario](r.db.Model(&models.Usuario{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "username": "username", "nombre": "nombre", "apellido": "apellido", "rol": "rol", "ultimo_login": "ultimo_login", "created_at": "created_at"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"username": filtroTexto("username"),
+			"nombre":   filtroTexto("nombre || ' ' || apellido"),
+			"email":    filtroTexto("email"),
+			"rol":      filtroIgual("rol"),
+			"activo":   filtroBool("activo", "activo"),
+		},
+		fecha: "created_at",
+	})
 }
 
 // UpdateLastLogin actualiza la fecha del último inicio de sesión
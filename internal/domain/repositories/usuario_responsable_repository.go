@@ -1,21 +1,23 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // UsuarioResponsableRepository define las operaciones del repositorio para UsuarioResponsable
 type UsuarioResponsableRepository interface {
-	Create(usuario *models.UsuarioResponsable) error
+	Create(ctx context.Context, usuario *models.UsuarioResponsable) error
 	FindByID(id uint) (*models.UsuarioResponsable, error)
-	Update(usuario *models.UsuarioResponsable) error
-	Delete(id uint) error
-	FindAll() ([]models.UsuarioResponsable, error)
+	Update(ctx context.Context, usuario *models.UsuarioResponsable) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.UsuarioResponsable, int64, error)
 	FindByCedula(cedula string) (*models.UsuarioResponsable, error)
 	FindByDependenciaID(dependenciaID uint) ([]models.UsuarioResponsable, error)
-	AsignarDependencia(usuarioID uint, dependenciaID *uint) error
+	AsignarDependencia(ctx context.Context, usuarioID uint, dependenciaID *uint) error
 }
 
 // usuarioResponsableRepository implementa UsuarioResponsableRepository
@@ -29,8 +31,8 @@ func NewUsuarioResponsableRepository(db *gorm.DB) UsuarioResponsableRepository {
 }
 
 // Create crea un nuevo usuario responsable en la base de datos
-func (r *usuarioResponsableRepository) Create(usuario *models.UsuarioResponsable) error {
-	return r.db.Create(usuario).Error
+func (r *usuarioResponsableRepository) Create(ctx context.Context, usuario *models.UsuarioResponsable) error {
+	return r.db.WithContext(ctx).Create(usuario).Error
 }
 
 // FindByID busca un usuario responsable por su ID
@@ -44,20 +46,32 @@ func (r *usuarioResponsableRepository) FindByID(id uint) (*models.UsuarioRespons
 }
 
 // Update actualiza un usuario responsable existente
-func (r *usuarioResponsableRepository) Update(usuario *models.UsuarioResponsable) error {
-	return r.db.Save(usuario).Error
+func (r *usuarioResponsableRepository) Update(ctx context.Context, usuario *models.UsuarioResponsable) error {
+	return r.db.WithContext(ctx).Save(usuario).Error
 }
 
 // Delete elimina un usuario responsable por su ID
-func (r *usuarioResponsableRepository) Delete(id uint) error {
-	return r.db.Delete(&models.UsuarioResponsable{}, id).Error
+func (r *usuarioResponsableRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.UsuarioResponsable{}, id).Error
 }
 
-// FindAll retorna todos los usuarios responsables
-func (r *usuarioResponsableRepository) FindAll() ([]models.UsuarioResponsable, error) {
-	var usuarios []models.UsuarioResponsable
-	err := r.db.Find(&usuarios).Error
-	return usuarios, err
+// FindAll retorna una página de usuarios responsables según la consulta
+func (r *usuarioResponsableRepository) FindAll(consulta dto.ConsultaDTO) ([]models.UsuarioResponsable, int64, error) {
+	return paginar[models.UsuarioResponsable](r.db.Model(&models.UsuarioResponsable{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "nombres_apellidos": "nombres_apellidos", "cedula": "cedula", "created_at": "created_at"},
+		ordenPorDef: "nombres_apellidos",
+		filtros: map[string]filtroConsulta{
+			"nombre":           filtroTexto("nombres_apellidos"),
+			"cedula":           filtroTexto("cedula"),
+			"tipo_vinculacion": filtroIgual("tipo_vinculacion"),
+			"dependencia":      filtroID("dependencia", "dependencia_id = ?"),
+			"secretaria": filtroPorID("secretaria", func(db *gorm.DB, id uint) *gorm.DB {
+				return db.Where("dependencia_id IN (?)", dependenciasDeSecretaria(r.db, id))
+			}),
+		},
+		fecha: "created_at",
+	})
 }
 
 // FindByCedula busca un usuario responsable por su número de cédula
@@ -78,6 +92,6 @@ func (r *usuarioResponsableRepository) FindByDependenciaID(dependenciaID uint) (
 }
 
 // AsignarDependencia actualiza solo el DependenciaID de un usuario responsable
-func (r *usuarioResponsableRepository) AsignarDependencia(usuarioID uint, dependenciaID *uint) error {
-	return r.db.Model(&models.UsuarioResponsable{}).Where("id = ?", usuarioID).Update("dependencia_id", dependenciaID).Error
+func (r *usuarioResponsableRepository) AsignarDependencia(ctx context.Context, usuarioID uint, dependenciaID *uint) error {
+	return r.db.WithContext(ctx).Model(&models.UsuarioResponsable{}).Where("id = ?", usuarioID).Update("dependencia_id", dependenciaID).Error
 }
@@ -1,18 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 
 	"gorm.io/gorm"
 )
 
 // UsuarioSistemaRepository define las operaciones del repositorio para UsuarioSistema
 type UsuarioSistemaRepository interface {
-	Create(usuario *models.UsuarioSistema) error
+	Create(ctx context.Context, usuario *models.UsuarioSistema) error
 	FindByID(id uint) (*models.UsuarioSistema, error)
-	Update(usuario *models.UsuarioSistema) error
-	Delete(id uint) error
-	FindAll() ([]models.UsuarioSistema, error)
+	Update(ctx context.Context, usuario *models.UsuarioSistema) error
+	Delete(ctx context.Context, id uint) error
+	FindAll(consulta dto.ConsultaDTO) ([]models.UsuarioSistema, int64, error)
 	FindByEquipoID(equipoID uint) ([]models.UsuarioSistema, error)
 	FindByNombreUsuario(nombreUsuario string, equipoID uint) (*models.UsuarioSistema, error)
 }
@@ -28,8 +30,8 @@ func NewUsuarioSistemaRepository(db *gorm.DB) UsuarioSistemaRepository {
 }
 
 // Create crea un nuevo usuario del sistema en la base de datos
-func (r *usuarioSistemaRepository) Create(usuario *models.UsuarioSistema) error {
-	return r.db.Create(usuario).Error
+func (r *usuarioSistemaRepository) Create(ctx context.Context, usuario *models.UsuarioSistema) error {
+	return r.db.WithContext(ctx).Create(usuario).Error
 }
 
 // FindByID busca un usuario del sistema por su ID
@@ -43,20 +45,28 @@ func (r *usuarioSistemaRepository) FindByID(id uint) (*models.UsuarioSistema, er
 }
 
 // Update actualiza un usuario del sistema existente
-func (r *usuarioSistemaRepository) Update(usuario *models.UsuarioSistema) error {
-	return r.db.Save(usuario).Error
+func (r *usuarioSistemaRepository) Update(ctx context.Context, usuario *models.UsuarioSistema) error {
+	return r.db.WithContext(ctx).Save(usuario).Error
 }
 
 // Delete elimina un usuario del sistema por su ID
-func (r *usuarioSistemaRepository) Delete(id uint) error {
-	return r.db.Delete(&models.UsuarioSistema{}, id).Error
+func (r *usuarioSistemaRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.UsuarioSistema{}, id).Error
 }
 
-// FindAll retorna todos los usuarios del sistema
-func (r *usuarioSistemaRepository) FindAll() ([]models.UsuarioSistema, error) {
-	var usuarios []models.UsuarioSistema
-	err := r.db.Find(&usuarios).Error
-	return usuarios, err
+// FindAll retorna una página de usuarios del sistema según la consulta
+func (r *usuarioSistemaRepository) FindAll(consulta dto.ConsultaDTO) ([]models.UsuarioSistema, int64, error) {
+	return paginar[models.UsuarioSistema](r.db.Model(&models.UsuarioSistema{}), consulta, opcionesConsulta{
+		id:          "id",
+		orden:       map[string]string{"id": "id", "nombre_usuario": "nombre_usuario", "created_at": "created_at"},
+		ordenPorDef: "id",
+		filtros: map[string]filtroConsulta{
+			"equipo":           filtroID("equipo", "equipo_id = ?"),
+			"nombre_usuario":   filtroTexto("nombre_usuario"),
+			"es_administrador": filtroBool("es_administrador", "es_administrador"),
+		},
+		fecha: "created_at",
+	})
 }
 
 // FindByEquipoID retorna todos los usuarios del sistema asociados a un equipo
@@ -1,33 +1,39 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
 )
 
 // AccesoRemotoService define las operaciones del servicio para AccesoRemoto
 type AccesoRemotoService interface {
-	CreateAccesoRemoto(acceso *models.AccesoRemoto) error
+	CreateAccesoRemoto(ctx context.Context, acceso *models.AccesoRemoto) error
 	GetAccesoRemotoByID(id uint) (*models.AccesoRemoto, error)
-	UpdateAccesoRemoto(acceso *models.AccesoRemoto) error
-	DeleteAccesoRemoto(id uint) error
-	GetAllAccesosRemotos() ([]models.AccesoRemoto, error)
+	UpdateAccesoRemoto(ctx context.Context, acceso *models.AccesoRemoto) error
+	DeleteAccesoRemoto(ctx context.Context, id uint) error
+	GetAllAccesosRemotos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.AccesoRemoto], error)
 	GetAccesosRemotosByEquipoID(equipoID uint) ([]models.AccesoRemoto, error)
+	RevelarContrasena(ctx context.Context, id uint) (string, error)
 }
 
 // accesoRemotoService implementa AccesoRemotoService
 type accesoRemotoService struct {
-	accesoRepo repositories.AccesoRemotoRepository
+	accesoRepo       repositories.AccesoRemotoRepository
+	auditoriaService AuditoriaService
 }
 
 // NewAccesoRemotoService crea una nueva instancia de AccesoRemotoService
-func NewAccesoRemotoService(accesoRepo repositories.AccesoRemotoRepository) AccesoRemotoService {
-	return &accesoRemotoService{accesoRepo: accesoRepo}
+func NewAccesoRemotoService(accesoRepo repositories.AccesoRemotoRepository, auditoriaService AuditoriaService) AccesoRemotoService {
+	return &accesoRemotoService{accesoRepo: accesoRepo, auditoriaService: auditoriaService}
 }
 
 // CreateAccesoRemoto crea un nuevo acceso remoto
-func (s *accesoRemotoService) CreateAccesoRemoto(acceso *models.AccesoRemoto) error {
+func (s *accesoRemotoService) CreateAccesoRemoto(ctx context.Context, acceso *models.AccesoRemoto) error {
 	if acceso.EquipoID == 0 {
 		return errors.New("el ID del equipo es obligatorio")
 	}
@@ -38,7 +44,7 @@ func (s *accesoRemotoService) CreateAccesoRemoto(acceso *models.AccesoRemoto) er
 		return errors.New("el ID de conexión es obligatorio")
 	}
 
-	return s.accesoRepo.Create(acceso)
+	return s.accesoRepo.Create(ctx, acceso)
 }
 
 // GetAccesoRemotoByID obtiene un acceso remoto por su ID
@@ -47,7 +53,7 @@ func (s *accesoRemotoService) GetAccesoRemotoByID(id uint) (*models.AccesoRemoto
 }
 
 // UpdateAccesoRemoto actualiza un acceso remoto existente
-func (s *accesoRemotoService) UpdateAccesoRemoto(acceso *models.AccesoRemoto) error {
+func (s *accesoRemotoService) UpdateAccesoRemoto(ctx context.Context, acceso *models.AccesoRemoto) error {
 	if acceso.ID == 0 {
 		return errors.New("ID de acceso remoto no válido")
 	}
@@ -67,20 +73,29 @@ func (s *accesoRemotoService) UpdateAccesoRemoto(acceso *models.AccesoRemoto) er
 		return errors.New("acceso remoto no encontrado")
 	}
 
-	return s.accesoRepo.Update(acceso)
+	// Conservar la contraseña almacenada si el cliente reenvía el valor enmascarado
+	if acceso.Contrasena == models.ContrasenaOculta && existente != nil {
+		acceso.Contrasena = existente.Contrasena
+	}
+
+	return s.accesoRepo.Update(ctx, acceso)
 }
 
 // DeleteAccesoRemoto elimina un acceso remoto por su ID
-func (s *accesoRemotoService) DeleteAccesoRemoto(id uint) error {
+func (s *accesoRemotoService) DeleteAccesoRemoto(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de acceso remoto no válido")
 	}
-	return s.accesoRepo.Delete(id)
+	return s.accesoRepo.Delete(ctx, id)
 }
 
 // GetAllAccesosRemotos obtiene todos los accesos remotos
-func (s *accesoRemotoService) GetAllAccesosRemotos() ([]models.AccesoRemoto, error) {
-	return s.accesoRepo.FindAll()
+func (s *accesoRemotoService) GetAllAccesosRemotos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.AccesoRemoto], error) {
+	registros, total, err := s.accesoRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.AccesoRemoto]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetAccesosRemotosByEquipoID obtiene todos los accesos remotos asociados a un equipo
@@ -90,3 +105,20 @@ func (s *accesoRemotoService) GetAccesosRemotosByEquipoID(equipoID uint) ([]mode
 	}
 	return s.accesoRepo.FindByEquipoID(equipoID)
 }
+
+// RevelarContrasena obtiene la contraseña descifrada de un acceso remoto y registra la consulta en la auditoría
+func (s *accesoRemotoService) RevelarContrasena(ctx context.Context, id uint) (string, error) {
+	if id == 0 {
+		return "", errors.New("ID de acceso remoto no válido")
+	}
+	acceso, err := s.accesoRepo.FindByID(id)
+	if err != nil {
+		return "", errors.New("acceso remoto no encontrado")
+	}
+
+	// Toda consulta de la contraseña en claro debe quedar en la auditoría
+	if err := s.auditoriaService.RegistrarEvento(ctx, "acceso_remotos", acceso.ID, &acceso.EquipoID, auditoria.AccionRevelar); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrRevelarSinAuditoria, err)
+	}
+	return acceso.Contrasena, nil
+}
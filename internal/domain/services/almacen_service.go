@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// Errores del almacén de repuestos
+var (
+	ErrArticuloInvalido     = errors.New("artículo inválido")
+	ErrArticuloDuplicado    = errors.New("ya existe un artículo con ese serial o número de parte")
+	ErrArticuloConStock     = repositories.ErrArticuloConStock
+	ErrArticuloEnUso        = repositories.ErrArticuloEnUso
+	ErrMovimientoInvalido   = errors.New("movimiento inválido")
+	ErrStockInsuficiente    = repositories.ErrStockInsuficiente
+	ErrArticuloNoEncontrado = repositories.ErrArticuloNoEncontrado
+)
+
+// AlmacenService administra el catálogo de repuestos, sus existencias y su kardex
+type AlmacenService interface {
+	GetArticulos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.ArticuloRepuesto], error)
+	GetArticulo(id uint) (*models.ArticuloRepuesto, error)
+	GetBajoStock() ([]models.ArticuloRepuesto, error)
+	CreateArticulo(ctx context.Context, articulo *models.ArticuloRepuesto) error
+	UpdateArticulo(ctx context.Context, articulo *models.ArticuloRepuesto) error
+	DeleteArticulo(ctx context.Context, id uint) error
+	RegistrarMovimiento(ctx context.Context, movimiento *models.MovimientoRepuesto) error
+	GetKardex(articuloID uint, consulta dto.ConsultaDTO) (dto.PaginaDTO[models.MovimientoRepuesto], error)
+}
+
+// almacenService implementa AlmacenService
+type almacenService struct {
+	repo repositories.AlmacenRepository
+}
+
+// NewAlmacenService crea una nueva instancia de AlmacenService
+func NewAlmacenService(repo repositories.AlmacenRepository) AlmacenService {
+	return &almacenService{repo: repo}
+}
+
+// GetArticulos obtiene una página del catálogo
+func (s *almacenService) GetArticulos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.ArticuloRepuesto], error) {
+	registros, total, err := s.repo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.ArticuloRepuesto]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
+}
+
+// GetArticulo obtiene un artículo por su ID
+func (s *almacenService) GetArticulo(id uint) (*models.ArticuloRepuesto, error) {
+	articulo, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrArticuloNoEncontrado
+	}
+	return articulo, err
+}
+
+// GetBajoStock obtiene los artículos con el stock en el mínimo o por debajo
+func (s *almacenService) GetBajoStock() ([]models.ArticuloRepuesto, error) {
+	return s.repo.FindBajoStock()
+}
+
+// CreateArticulo crea un artículo. El Stock recibido se registra como una entrada de
+// inventario inicial para que el kardex cuadre desde el principio.
+func (s *almacenService) CreateArticulo(ctx context.Context, articulo *models.ArticuloRepuesto) error {
+	if articulo.Stock < 0 {
+		return fmt.Errorf("%w: el stock inicial no puede ser negativo", ErrArticuloInvalido)
+	}
+	if err := s.validarArticulo(articulo); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, articulo, articulo.Stock)
+}
+
+// UpdateArticulo actualiza los datos y el stock mínimo de un artículo; el stock no cambia
+func (s *almacenService) UpdateArticulo(ctx context.Context, articulo *models.ArticuloRepuesto) error {
+	actual, err := s.GetArticulo(articulo.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validarArticulo(articulo); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, articulo); err != nil {
+		return err
+	}
+	articulo.Stock = actual.Stock
+	return nil
+}
+
+// DeleteArticulo elimina un artículo sin existencias que ningún repuesto de un reporte usa
+func (s *almacenService) DeleteArticulo(ctx context.Context, id uint) error {
+	err := s.repo.Delete(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrArticuloNoEncontrado
+	}
+	return err
+}
+
+// validarArticulo revisa los campos obligatorios y que el serial no esté repetido
+func (s *almacenService) validarArticulo(articulo *models.ArticuloRepuesto) error {
+	articulo.SerialNumeroParte = strings.TrimSpace(articulo.SerialNumeroParte)
+	articulo.Descripcion = strings.TrimSpace(articulo.Descripcion)
+	if articulo.SerialNumeroParte == "" {
+		return fmt.Errorf("%w: el serial o número de parte es obligatorio", ErrArticuloInvalido)
+	}
+	if articulo.Descripcion == "" {
+		return fmt.Errorf("%w: la descripción es obligatoria", ErrArticuloInvalido)
+	}
+	if articulo.StockMinimo < 0 {
+		return fmt.Errorf("%w: el stock mínimo no puede ser negativo", ErrArticuloInvalido)
+	}
+
+	duplicado, err := s.repo.ExisteSerial(articulo.SerialNumeroParte, articulo.ID)
+	if err != nil {
+		return err
+	}
+	if duplicado {
+		return ErrArticuloDuplicado
+	}
+	return nil
+}
+
+// RegistrarMovimiento registra una entrada (compra), una salida sin reporte (por ejemplo, un
+// repuesto dañado) o un ajuste por conteo físico. Las salidas por uso en un reporte se
+// registran solas al crear el repuesto del reporte.
+func (s *almacenService) RegistrarMovimiento(ctx context.Context, movimiento *models.MovimientoRepuesto) error {
+	if _, err := s.GetArticulo(movimiento.ArticuloID); err != nil {
+		return err
+	}
+	switch movimiento.Tipo {
+	case models.MovimientoEntrada, models.MovimientoSalida:
+		if movimiento.Cantidad <= 0 {
+			return fmt.Errorf("%w: la cantidad debe ser mayor que cero", ErrMovimientoInvalido)
+		}
+	case models.MovimientoAjuste:
+		if movimiento.Cantidad == 0 {
+			return fmt.Errorf("%w: el ajuste debe sumar o restar una cantidad", ErrMovimientoInvalido)
+		}
+		if strings.TrimSpace(movimiento.Observaciones) == "" {
+			return fmt.Errorf("%w: indique en las observaciones el motivo del ajuste", ErrMovimientoInvalido)
+		}
+	default:
+		return fmt.Errorf("%w: el tipo debe ser entrada, salida o ajuste", ErrMovimientoInvalido)
+	}
+
+	// Los movimientos de los reportes solo los registra el sistema
+	movimiento.ID = 0
+	movimiento.ReporteID = nil
+	movimiento.RepuestoID = nil
+	movimiento.RealizadoPorID = nil
+	movimiento.RealizadoPorUsername = ""
+	return s.repo.RegistrarMovimiento(ctx, movimiento)
+}
+
+// GetKardex obtiene una página de los movimientos de un artículo
+func (s *almacenService) GetKardex(articuloID uint, consulta dto.ConsultaDTO) (dto.PaginaDTO[models.MovimientoRepuesto], error) {
+	if _, err := s.GetArticulo(articuloID); err != nil {
+		return dto.PaginaDTO[models.MovimientoRepuesto]{}, err
+	}
+	registros, total, err := s.repo.FindMovimientos(articuloID, consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.MovimientoRepuesto]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
+}
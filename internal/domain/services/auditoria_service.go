@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
+)
+
+// ErrRevelarSinAuditoria indica que una contraseña no se entregó porque la consulta no se pudo
+// registrar en la auditoría
+var ErrRevelarSinAuditoria = errors.New("no se pudo registrar la consulta en la auditoría")
+
+// AuditoriaService define las operaciones del servicio para Auditoria
+type AuditoriaService interface {
+	RegistrarEvento(ctx context.Context, entidad string, entidadID uint, equipoID *uint, accion string) error
+	GetAuditoria(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Auditoria], error)
+	GetHistorialEquipo(equipoID uint) ([]models.Auditoria, error)
+}
+
+// auditoriaService implementa AuditoriaService
+type auditoriaService struct {
+	auditoriaRepo repositories.AuditoriaRepository
+}
+
+// NewAuditoriaService crea una nueva instancia de AuditoriaService
+func NewAuditoriaService(auditoriaRepo repositories.AuditoriaRepository) AuditoriaService {
+	return &auditoriaService{auditoriaRepo: auditoriaRepo}
+}
+
+// RegistrarEvento registra una acción que no modifica datos (por ejemplo revelar una contraseña).
+// Los cambios hechos con GORM se registran automáticamente.
+func (s *auditoriaService) RegistrarEvento(ctx context.Context, entidad string, entidadID uint, equipoID *uint, accion string) error {
+	evento := &models.Auditoria{
+		Entidad:   entidad,
+		EntidadID: entidadID,
+		EquipoID:  equipoID,
+		Accion:    accion,
+		Username:  "sistema",
+	}
+	if actor, ok := auditoria.ActorDesde(ctx); ok {
+		evento.UsuarioID = &actor.UsuarioID
+		evento.Username = actor.Username
+	}
+	return s.auditoriaRepo.Create(ctx, evento)
+}
+
+// GetAuditoria obtiene una página de registros de auditoría filtrados
+func (s *auditoriaService) GetAuditoria(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Auditoria], error) {
+	registros, total, err := s.auditoriaRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.Auditoria]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
+}
+
+// GetHistorialEquipo obtiene el historial de cambios de un equipo
+func (s *auditoriaService) GetHistorialEquipo(equipoID uint) ([]models.Auditoria, error) {
+	if equipoID == 0 {
+		return nil, errors.New("ID de equipo no válido")
+	}
+	return s.auditoriaRepo.FindByEquipoID(equipoID)
+}
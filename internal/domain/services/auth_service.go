@@ -1,13 +1,16 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"time"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 	"tum_inv_backend/internal/infrastructure/config"
 
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 // Duración de los tokens
@@ -27,12 +30,13 @@ type JWTClaims struct {
 
 // AuthService define las operaciones del servicio de autenticación
 type AuthService interface {
-	Register(req models.RegisterRequest) (*models.Usuario, error)
+	Register(ctx context.Context, req models.RegisterRequest) (*models.Usuario, error)
 	Login(req models.LoginRequest) (*models.TokenResponse, error)
 	ValidateToken(tokenString string) (*JWTClaims, error)
+	RolVigente(userID uint) (string, error)
 	RefreshToken(refreshToken string) (*models.TokenResponse, error)
 	GetUserByID(id uint) (*models.Usuario, error)
-	GetAllUsers() ([]models.Usuario, error)
+	GetAllUsers(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Usuario], error)
 }
 
 // authService implementa AuthService
@@ -50,7 +54,7 @@ func NewAuthService(usuarioRepo repositories.UsuarioRepository, cfg *config.Conf
 }
 
 // Register registra un nuevo usuario
-func (s *authService) Register(req models.RegisterRequest) (*models.Usuario, error) {
+func (s *authService) Register(ctx context.Context, req models.RegisterRequest) (*models.Usuario, error) {
 	// Verificar si el nombre de usuario ya existe
 	existingUser, err := s.usuarioRepo.FindByUsername(req.Username)
 	if err == nil && existingUser != nil {
@@ -81,7 +85,7 @@ func (s *authService) Register(req models.RegisterRequest) (*models.Usuario, err
 	}
 
 	// Guardar usuario en la base de datos
-	if err := s.usuarioRepo.Create(usuario); err != nil {
+	if err := s.usuarioRepo.Create(ctx, usuario); err != nil {
 		return nil, err
 	}
 
@@ -161,6 +165,27 @@ func (s *authService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	return claims, nil
 }
 
+// ErrCuentaNoVigente indica que el usuario de un token válido se desactivó o se eliminó
+var ErrCuentaNoVigente = errors.New("la cuenta está desactivada o ya no existe")
+
+// RolVigente retorna el rol actual del usuario de un token. El rol y el estado de la cuenta se
+// leen de la base de datos en cada petición, así que desactivar un usuario o cambiar su rol
+// tiene efecto de inmediato y no cuando vence el token. Retorna ErrCuentaNoVigente si la
+// cuenta está desactivada o eliminada.
+func (s *authService) RolVigente(userID uint) (string, error) {
+	usuario, err := s.usuarioRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrCuentaNoVigente
+		}
+		return "", err
+	}
+	if !usuario.Activo {
+		return "", ErrCuentaNoVigente
+	}
+	return usuario.Rol, nil
+}
+
 // RefreshToken genera un nuevo token de acceso a partir de un token de actualización
 func (s *authService) RefreshToken(refreshToken string) (*models.TokenResponse, error) {
 	// Validar refresh token
@@ -208,10 +233,10 @@ func (s *authService) GetUserByID(id uint) (*models.Usuario, error) {
 }
 
 // GetAllUsers obtiene todos los usuarios registrados
-func (s *authService) GetAllUsers() ([]models.Usuario, error) {
-	usuarios, err := s.usuarioRepo.FindAll()
+func (s *authService) GetAllUsers(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Usuario], error) {
+	usuarios, total, err := s.usuarioRepo.FindAll(consulta)
 	if err != nil {
-		return nil, err
+		return dto.PaginaDTO[models.Usuario]{}, err
 	}
 
 	// Ocultar contraseñas en la respuesta
@@ -219,7 +244,7 @@ func (s *authService) GetAllUsers() ([]models.Usuario, error) {
 		usuarios[i].Password = ""
 	}
 
-	return usuarios, nil
+	return dto.NuevaPagina(usuarios, total, consulta), nil
 }
 
 // generateAccessToken genera un token de acceso JWT
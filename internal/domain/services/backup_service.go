@@ -1,18 +1,20 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 )
 
 // BackupService define las operaciones del servicio para Backup
 type BackupService interface {
-	CreateBackup(backup *models.Backup) error
+	CreateBackup(ctx context.Context, backup *models.Backup) error
 	GetBackupByID(id uint) (*models.Backup, error)
-	UpdateBackup(backup *models.Backup) error
-	DeleteBackup(id uint) error
-	GetAllBackups() ([]models.Backup, error)
+	UpdateBackup(ctx context.Context, backup *models.Backup) error
+	DeleteBackup(ctx context.Context, id uint) error
+	GetAllBackups(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Backup], error)
 	GetBackupsByEquipoID(equipoID uint) ([]models.Backup, error)
 }
 
@@ -27,7 +29,7 @@ func NewBackupService(backupRepo repositories.BackupRepository) BackupService {
 }
 
 // CreateBackup crea un nuevo backup
-func (s *backupService) CreateBackup(backup *models.Backup) error {
+func (s *backupService) CreateBackup(ctx context.Context, backup *models.Backup) error {
 	if backup.EquipoID == 0 {
 		return errors.New("el ID del equipo es obligatorio")
 	}
@@ -35,7 +37,7 @@ func (s *backupService) CreateBackup(backup *models.Backup) error {
 		return errors.New("la ruta del backup es obligatoria")
 	}
 
-	return s.backupRepo.Create(backup)
+	return s.backupRepo.Create(ctx, backup)
 }
 
 // GetBackupByID obtiene un backup por su ID
@@ -44,7 +46,7 @@ func (s *backupService) GetBackupByID(id uint) (*models.Backup, error) {
 }
 
 // UpdateBackup actualiza un backup existente
-func (s *backupService) UpdateBackup(backup *models.Backup) error {
+func (s *backupService) UpdateBackup(ctx context.Context, backup *models.Backup) error {
 	if backup.ID == 0 {
 		return errors.New("ID de backup no válido")
 	}
@@ -61,20 +63,24 @@ func (s *backupService) UpdateBackup(backup *models.Backup) error {
 		return errors.New("backup no encontrado")
 	}
 
-	return s.backupRepo.Update(backup)
+	return s.backupRepo.Update(ctx, backup)
 }
 
 // DeleteBackup elimina un backup por su ID
-func (s *backupService) DeleteBackup(id uint) error {
+func (s *backupService) DeleteBackup(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de backup no válido")
 	}
-	return s.backupRepo.Delete(id)
+	return s.backupRepo.Delete(ctx, id)
 }
 
 // GetAllBackups obtiene todos los backups
-func (s *backupService) GetAllBackups() ([]models.Backup, error) {
-	return s.backupRepo.FindAll()
+func (s *backupService) GetAllBackups(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Backup], error) {
+	registros, total, err := s.backupRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.Backup]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetBackupsByEquipoID obtiene todos los backups asociados a un equipo
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+	"unicode/utf8"
+)
+
+// Límites de la búsqueda global
+const (
+	LongitudMinimaBusqueda = 2
+	LimiteBusquedaDefecto  = 20
+	LimiteBusquedaMaximo   = 50
+)
+
+// BusquedaService define la búsqueda global sobre el inventario
+type BusquedaService interface {
+	Buscar(texto string, limite int) ([]dto.ResultadoBusquedaDTO, error)
+}
+
+type busquedaService struct {
+	busquedaRepo repositories.BusquedaRepository
+}
+
+// NewBusquedaService crea una nueva instancia de BusquedaService
+func NewBusquedaService(busquedaRepo repositories.BusquedaRepository) BusquedaService {
+	return &busquedaService{busquedaRepo: busquedaRepo}
+}
+
+// Buscar busca el texto en equipos, periféricos, responsables, configuraciones de red y
+// software. Cada resultado incluye el enlace a su equipo o, si no tiene, al propio registro.
+func (s *busquedaService) Buscar(texto string, limite int) ([]dto.ResultadoBusquedaDTO, error) {
+	texto = strings.TrimSpace(texto)
+	if utf8.RuneCountInString(texto) < LongitudMinimaBusqueda {
+		return nil, fmt.Errorf("%w: la búsqueda debe tener al menos %d caracteres", dto.ErrConsultaInvalida, LongitudMinimaBusqueda)
+	}
+	if limite <= 0 {
+		limite = LimiteBusquedaDefecto
+	}
+	if limite > LimiteBusquedaMaximo {
+		limite = LimiteBusquedaMaximo
+	}
+
+	resultados, err := s.busquedaRepo.Buscar(texto, limite)
+	if err != nil {
+		return nil, err
+	}
+	if resultados == nil {
+		resultados = []dto.ResultadoBusquedaDTO{}
+	}
+	for i := range resultados {
+		resultados[i].Enlace = enlaceResultado(resultados[i])
+	}
+	return resultados, nil
+}
+
+// enlaceResultado devuelve la ruta de la API del equipo del resultado o del propio registro
+func enlaceResultado(r dto.ResultadoBusquedaDTO) string {
+	if r.EquipoID != nil {
+		return fmt.Sprintf("/api/equipos/%d", *r.EquipoID)
+	}
+	switch r.Tipo {
+	case dto.TipoResultadoPeriferico:
+		return fmt.Sprintf("/api/perifericos/%d", r.ID)
+	case dto.TipoResultadoResponsable:
+		return fmt.Sprintf("/api/usuarios-responsables/%d", r.ID)
+	case dto.TipoResultadoConfiguracionRed:
+		return fmt.Sprintf("/api/configuraciones-red/%d", r.ID)
+	case dto.TipoResultadoSoftware:
+		return fmt.Sprintf("/api/software/%d", r.ID)
+	}
+	return fmt.Sprintf("/api/equipos/%d", r.ID)
+}
@@ -1,18 +1,20 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 )
 
 // ConfiguracionRedService define las operaciones del servicio para ConfiguracionRed
 type ConfiguracionRedService interface {
-	CreateConfiguracionRed(configuracion *models.ConfiguracionRed) error
+	CreateConfiguracionRed(ctx context.Context, configuracion *models.ConfiguracionRed) error
 	GetConfiguracionRedByID(id uint) (*models.ConfiguracionRed, error)
-	UpdateConfiguracionRed(configuracion *models.ConfiguracionRed) error
-	DeleteConfiguracionRed(id uint) error
-	GetAllConfiguracionesRed() ([]models.ConfiguracionRed, error)
+	UpdateConfiguracionRed(ctx context.Context, configuracion *models.ConfiguracionRed) error
+	DeleteConfiguracionRed(ctx context.Context, id uint) error
+	GetAllConfiguracionesRed(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.ConfiguracionRed], error)
 	GetConfiguracionRedByEquipoID(equipoID uint) (*models.ConfiguracionRed, error)
 }
 
@@ -27,7 +29,7 @@ func NewConfiguracionRedService(configuracionRepo repositories.ConfiguracionRedR
 }
 
 // CreateConfiguracionRed crea una nueva configuración de red
-func (s *configuracionRedService) CreateConfiguracionRed(configuracion *models.ConfiguracionRed) error {
+func (s *configuracionRedService) CreateConfiguracionRed(ctx context.Context, configuracion *models.ConfiguracionRed) error {
 	if configuracion.EquipoID == 0 {
 		return errors.New("el ID del equipo es obligatorio")
 	}
@@ -44,7 +46,7 @@ func (s *configuracionRedService) CreateConfiguracionRed(configuracion *models.C
 		return errors.New("ya existe una configuración de red para este equipo")
 	}
 	
-	return s.configuracionRepo.Create(configuracion)
+	return s.configuracionRepo.Create(ctx, configuracion)
 }
 
 // GetConfiguracionRedByID obtiene una configuración de red por su ID
@@ -53,7 +55,7 @@ func (s *configuracionRedService) GetConfiguracionRedByID(id uint) (*models.Conf
 }
 
 // UpdateConfiguracionRed actualiza una configuración de red existente
-func (s *configuracionRedService) UpdateConfiguracionRed(configuracion *models.ConfiguracionRed) error {
+func (s *configuracionRedService) UpdateConfiguracionRed(ctx context.Context, configuracion *models.ConfiguracionRed) error {
 	if configuracion.ID == 0 {
 		return errors.New("ID de configuración de red no válido")
 	}
@@ -81,20 +83,24 @@ func (s *configuracionRedService) UpdateConfiguracionRed(configuracion *models.C
 		}
 	}
 	
-	return s.configuracionRepo.Update(configuracion)
+	return s.configuracionRepo.Update(ctx, configuracion)
 }
 
 // DeleteConfiguracionRed elimina una configuración de red por su ID
-func (s *configuracionRedService) DeleteConfiguracionRed(id uint) error {
+func (s *configuracionRedService) DeleteConfiguracionRed(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de configuración de red no válido")
 	}
-	return s.configuracionRepo.Delete(id)
+	return s.configuracionRepo.Delete(ctx, id)
 }
 
 // GetAllConfiguracionesRed obtiene todas las configuraciones de red
-func (s *configuracionRedService) GetAllConfiguracionesRed() ([]models.ConfiguracionRed, error) {
-	return s.configuracionRepo.FindAll()
+func (s *configuracionRedService) GetAllConfiguracionesRed(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.ConfiguracionRed], error) {
+	registros, total, err := s.configuracionRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.ConfiguracionRed]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetConfiguracionRedByEquipoID obtiene la configuración de red asociada a un equipo
@@ -16,6 +16,13 @@ type DashboardStats struct {
 	EquiposPorEstado  []EstadoCount          `json:"equiposPorEstado"`
 	EquiposPorTipo    []TipoCount            `json:"equiposPorTipo"`
 	Secretarias       []SecretariaConEquipos `json:"secretarias"`
+
+	// Mantenimiento preventivo según los planes (ver /api/mantenimientos/pendientes)
+	MantenimientosVencidos int64 `json:"mantenimientosVencidos"`
+	MantenimientosProximos int64 `json:"mantenimientosProximos"` // Vencen en los próximos 30 días
+
+	// Artículos del almacén con el stock en el mínimo o por debajo
+	RepuestosBajoStock int64 `json:"repuestosBajoStock"`
 }
 
 // EstadoCount conteo por estado
@@ -38,6 +45,8 @@ type SecretariaConEquipos struct {
 	Ubicacion    string                  `json:"Ubicacion"`
 	TotalEquipos int                     `json:"totalEquipos"`
 	Dependencias []DependenciaConEquipos `json:"dependencias"`
+
+	MantenimientosVencidos int `json:"mantenimientosVencidos"`
 }
 
 // DependenciaConEquipos dependencia con su conteo de equipos
@@ -45,6 +54,8 @@ type DependenciaConEquipos struct {
 	ID           uint   `json:"ID"`
 	Nombre       string `json:"Nombre"`
 	TotalEquipos int    `json:"totalEquipos"`
+
+	MantenimientosVencidos int `json:"mantenimientosVencidos"`
 }
 
 // EquipoSinSecretaria representa un equipo sin secretaría asignada
@@ -84,12 +95,13 @@ type DashboardService interface {
 }
 
 type dashboardService struct {
-	db *gorm.DB
+	db                   *gorm.DB
+	mantenimientoService MantenimientoService
 }
 
 // NewDashboardService crea una nueva instancia del servicio
-func NewDashboardService(db *gorm.DB) DashboardService {
-	return &dashboardService{db: db}
+func NewDashboardService(db *gorm.DB, mantenimientoService MantenimientoService) DashboardService {
+	return &dashboardService{db: db, mantenimientoService: mantenimientoService}
 }
 
 func (s *dashboardService) GetDashboardStats() (*DashboardStats, error) {
@@ -138,6 +150,27 @@ func (s *dashboardService) GetDashboardStats() (*DashboardStats, error) {
 		ORDER BY cantidad DESC
 	`).Scan(&stats.EquiposPorTipo)
 
+	// Mantenimientos preventivos vencidos y próximos, en total y por dependencia
+	equiposMantenimiento, err := s.mantenimientoService.CalcularEquipos(DiasAnticipacionMantenimiento)
+	if err != nil {
+		return nil, err
+	}
+	depVencidosMap := make(map[uint]int)
+	for _, equipo := range equiposMantenimiento {
+		switch equipo.Estado {
+		case models.MantenimientoVencido:
+			stats.MantenimientosVencidos++
+			if equipo.DependenciaID != nil {
+				depVencidosMap[*equipo.DependenciaID]++
+			}
+		case models.MantenimientoProximo:
+			stats.MantenimientosProximos++
+		}
+	}
+
+	// Artículos del almacén de repuestos que hay que reponer
+	s.db.Model(&models.ArticuloRepuesto{}).Where("stock <= stock_minimo").Count(&stats.RepuestosBajoStock)
+
 	// Cargar secretarías con sus dependencias usando Preload de GORM
 	var secretarias []models.Secretaria
 	s.db.Preload("Dependencias").Find(&secretarias)
@@ -175,11 +208,13 @@ func (s *dashboardService) GetDashboardStats() (*DashboardStats, error) {
 		for _, dep := range sec.Dependencias {
 			totalEquiposDep := depEquiposMap[dep.ID]
 			secConEquipos.Dependencias = append(secConEquipos.Dependencias, DependenciaConEquipos{
-				ID:           dep.ID,
-				Nombre:       dep.Nombre,
-				TotalEquipos: totalEquiposDep,
+				ID:                     dep.ID,
+				Nombre:                 dep.Nombre,
+				TotalEquipos:           totalEquiposDep,
+				MantenimientosVencidos: depVencidosMap[dep.ID],
 			})
 			totalEquiposSec += totalEquiposDep
+			secConEquipos.MantenimientosVencidos += depVencidosMap[dep.ID]
 		}
 
 		secConEquipos.TotalEquipos = totalEquiposSec
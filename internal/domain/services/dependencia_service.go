@@ -1,18 +1,20 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 )
 
 // DependenciaService define la interfaz para operaciones de servicio de Dependencia
 type DependenciaService interface {
-	CreateDependencia(dependencia *models.Dependencia) error
+	CreateDependencia(ctx context.Context, dependencia *models.Dependencia) error
 	GetDependenciaByID(id uint) (*models.Dependencia, error)
-	GetAllDependencias() ([]models.Dependencia, error)
-	UpdateDependencia(dependencia *models.Dependencia) error
-	DeleteDependencia(id uint) error
+	GetAllDependencias(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Dependencia], error)
+	UpdateDependencia(ctx context.Context, dependencia *models.Dependencia) error
+	DeleteDependencia(ctx context.Context, id uint) error
 	GetDependenciasBySecretariaID(secretariaID uint) ([]models.Dependencia, error)
 }
 
@@ -27,7 +29,7 @@ func NewDependenciaService(repo repositories.DependenciaRepository) DependenciaS
 }
 
 // CreateDependencia crea una nueva dependencia
-func (s *dependenciaService) CreateDependencia(dependencia *models.Dependencia) error {
+func (s *dependenciaService) CreateDependencia(ctx context.Context, dependencia *models.Dependencia) error {
 	if dependencia.Nombre == "" {
 		return errors.New("el nombre es obligatorio")
 	}
@@ -48,7 +50,7 @@ func (s *dependenciaService) CreateDependencia(dependencia *models.Dependencia)
 		return errors.New("el correo institucional es obligatorio")
 	}
 
-	return s.repo.CreateDependencia(dependencia)
+	return s.repo.CreateDependencia(ctx, dependencia)
 }
 
 // GetDependenciaByID obtiene una dependencia por su ID
@@ -57,12 +59,16 @@ func (s *dependenciaService) GetDependenciaByID(id uint) (*models.Dependencia, e
 }
 
 // GetAllDependencias obtiene todas las dependencias
-func (s *dependenciaService) GetAllDependencias() ([]models.Dependencia, error) {
-	return s.repo.GetAllDependencias()
+func (s *dependenciaService) GetAllDependencias(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Dependencia], error) {
+	registros, total, err := s.repo.GetAllDependencias(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.Dependencia]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // UpdateDependencia actualiza una dependencia existente
-func (s *dependenciaService) UpdateDependencia(dependencia *models.Dependencia) error {
+func (s *dependenciaService) UpdateDependencia(ctx context.Context, dependencia *models.Dependencia) error {
 	if dependencia.ID == 0 {
 		return errors.New("ID de dependencia no válido")
 	}
@@ -79,21 +85,21 @@ func (s *dependenciaService) UpdateDependencia(dependencia *models.Dependencia)
 		return errors.New("la secretaría es obligatoria")
 	}
 
-	return s.repo.UpdateDependencia(dependencia)
+	return s.repo.UpdateDependencia(ctx, dependencia)
 }
 
 // DeleteDependencia elimina una dependencia por su ID, liberando usuarios responsables
-func (s *dependenciaService) DeleteDependencia(id uint) error {
+func (s *dependenciaService) DeleteDependencia(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de dependencia no válido")
 	}
 
 	// Liberar usuarios responsables antes de eliminar
-	if err := s.repo.LiberarUsuariosDeDependencia(id); err != nil {
+	if err := s.repo.LiberarUsuariosDeDependencia(ctx, id); err != nil {
 		return errors.New("error al liberar usuarios de la dependencia")
 	}
 
-	return s.repo.DeleteDependencia(id)
+	return s.repo.DeleteDependencia(ctx, id)
 }
 
 // GetDependenciasBySecretariaID obtiene todas las dependencias de una secretaría
@@ -1,44 +1,79 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 	"tum_inv_backend/internal/domain/models"
 	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
+	"tum_inv_backend/internal/infrastructure/storage"
 )
 
 // EquipoService define las operaciones del servicio para Equipo
 type EquipoService interface {
-	CreateEquipo(equipo *models.Equipo) error
+	CreateEquipo(ctx context.Context, equipo *models.Equipo) error
 	GetEquipoByID(id uint) (*models.Equipo, error)
-	UpdateEquipo(equipo *models.Equipo) error
-	DeleteEquipo(id uint) error
-	GetAllEquipos() ([]models.Equipo, error)
+	UpdateEquipo(ctx context.Context, equipo *models.Equipo) error
+	DeleteEquipo(ctx context.Context, id uint) error
+	GetAllEquipos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Equipo], error)
 	GetEquiposByDependenciaID(dependenciaID uint) ([]models.Equipo, error)
 	GetEquipoUsuDepByID(equipoID uint) (dto.EquipoConResponsableDTO, error)
-	GetAllEquiposDetalle() ([]dto.EquipoConResponsableDTO, error)
-	AsignarResponsable(equipoID uint, usuarioResponsableID *uint) error
+	GetAllEquiposDetalle(consulta dto.ConsultaDTO) (dto.PaginaDTO[dto.EquipoConResponsableDTO], error)
+	AsignarResponsable(ctx context.Context, equipoID uint, usuarioResponsableID *uint, motivo string) (*models.AsignacionEquipo, error)
+	GetAsignacionesByEquipo(equipoID uint) ([]models.AsignacionEquipo, error)
+	GetAsignacionesByResponsable(responsableID uint) ([]models.AsignacionEquipo, error)
+	SubirActaFirmada(ctx context.Context, asignacionID uint, fileData []byte, contentType string) (*models.AsignacionEquipo, error)
+	ObtenerURLActaFirmada(asignacionID uint) (string, error)
 }
 
 // equipoService implementa EquipoService
 type equipoService struct {
-	equipoRepo repositories.EquipoRepository
+	equipoRepo     repositories.EquipoRepository
+	asignacionRepo repositories.AsignacionEquipoRepository
+	notificacion   NotificacionService
+	storage        storage.Provider
 }
 
-// NewEquipoService crea una nueva instancia de EquipoService
-func NewEquipoService(equipoRepo repositories.EquipoRepository) EquipoService {
-	return &equipoService{equipoRepo: equipoRepo}
+// NewEquipoService crea una nueva instancia de EquipoService. notificacion avisa al nuevo
+// responsable cuando se le asigna un equipo.
+func NewEquipoService(
+	equipoRepo repositories.EquipoRepository,
+	asignacionRepo repositories.AsignacionEquipoRepository,
+	notificacion NotificacionService,
+	storageSvc storage.Provider,
+) EquipoService {
+	return &equipoService{
+		equipoRepo:     equipoRepo,
+		asignacionRepo: asignacionRepo,
+		notificacion:   notificacion,
+		storage:        storageSvc,
+	}
 }
 
 // CreateEquipo crea un nuevo equipo
-func (s *equipoService) CreateEquipo(equipo *models.Equipo) error {
+func (s *equipoService) CreateEquipo(ctx context.Context, equipo *models.Equipo) error {
 	if equipo.Serial == "" {
 		return errors.New("el número de serie es obligatorio")
 	}
 	if equipo.Marca == "" {
 		return errors.New("la marca es obligatoria")
 	}
-	return s.equipoRepo.Create(equipo)
+	if err := s.equipoRepo.Create(ctx, equipo); err != nil {
+		return err
+	}
+
+	// Si el equipo se crea con responsable, se abre su historial de custodia
+	if equipo.UsuarioResponsableID != nil {
+		asignacion := nuevaAsignacion(ctx, equipo.ID, equipo.UsuarioResponsableID, "Asignación inicial")
+		if err := s.asignacionRepo.Create(ctx, asignacion); err != nil {
+			return errors.New("error al registrar la asignación inicial: " + err.Error())
+		}
+		s.notificacion.EquipoAsignado(ctx, asignacion)
+	}
+	return nil
 }
 
 // GetEquipoByID obtiene un equipo por su ID
@@ -47,32 +82,36 @@ func (s *equipoService) GetEquipoByID(id uint) (*models.Equipo, error) {
 }
 
 // UpdateEquipo actualiza un equipo existente
-func (s *equipoService) UpdateEquipo(equipo *models.Equipo) error {
+func (s *equipoService) UpdateEquipo(ctx context.Context, equipo *models.Equipo) error {
 	if equipo.ID == 0 {
 		return errors.New("ID de equipo no válido")
 	}
-	return s.equipoRepo.Update(equipo)
+	return s.equipoRepo.Update(ctx, equipo)
 }
 
 // DeleteEquipo elimina un equipo por su ID, liberando primero sus periféricos
-func (s *equipoService) DeleteEquipo(id uint) error {
+func (s *equipoService) DeleteEquipo(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de equipo no válido")
 	}
 	// Liberar periféricos: poner EquipoID en NULL
-	if err := s.equipoRepo.LiberarPerifericos(id); err != nil {
+	if err := s.equipoRepo.LiberarPerifericos(ctx, id); err != nil {
 		return errors.New("error al liberar periféricos del equipo: " + err.Error())
 	}
 	// Eliminar datos asociados: Software, Hardware, ConfigRed, UsuariosSistema, AccesosRemotos, Backups
-	if err := s.equipoRepo.EliminarDatosAsociados(id); err != nil {
+	if err := s.equipoRepo.EliminarDatosAsociados(ctx, id); err != nil {
 		return errors.New("error al eliminar datos asociados del equipo: " + err.Error())
 	}
-	return s.equipoRepo.Delete(id)
+	return s.equipoRepo.Delete(ctx, id)
 }
 
 // GetAllEquipos obtiene todos los equipos
-func (s *equipoService) GetAllEquipos() ([]models.Equipo, error) {
-	return s.equipoRepo.FindAll()
+func (s *equipoService) GetAllEquipos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Equipo], error) {
+	registros, total, err := s.equipoRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.Equipo]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetEquiposByDependenciaID obtiene todos los equipos de una dependencia
@@ -83,20 +122,118 @@ func (s *equipoService) GetEquiposByDependenciaID(dependenciaID uint) ([]models.
 	return s.equipoRepo.FindByDependenciaID(dependenciaID)
 }
 
-// GetEquiposByDependenciaID obtiene todos los equipos de una dependencia
+// GetEquipoUsuDepByID obtiene la hoja de vida del equipo con su historial de custodia
 func (s *equipoService) GetEquipoUsuDepByID(equipoID uint) (dto.EquipoConResponsableDTO, error) {
+	equipo, err := s.equipoRepo.FindEquiUsuDepByID(equipoID)
+	if err != nil {
+		return equipo, err
+	}
 
-	return s.equipoRepo.FindEquiUsuDepByID(equipoID)
+	equipo.Asignaciones, err = s.asignacionRepo.FindByEquipoID(equipoID)
+	return equipo, err
 }
 
-func (s *equipoService) GetAllEquiposDetalle() ([]dto.EquipoConResponsableDTO, error) {
-	return s.equipoRepo.FindAllEquiposDetalle()
+func (s *equipoService) GetAllEquiposDetalle(consulta dto.ConsultaDTO) (dto.PaginaDTO[dto.EquipoConResponsableDTO], error) {
+	registros, total, err := s.equipoRepo.FindAllEquiposDetalle(consulta)
+	if err != nil {
+		return dto.PaginaDTO[dto.EquipoConResponsableDTO]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
-// AsignarResponsable asigna un usuario responsable a un equipo (solo actualiza el FK)
-func (s *equipoService) AsignarResponsable(equipoID uint, usuarioResponsableID *uint) error {
+// AsignarResponsable asigna un usuario responsable a un equipo y registra la entrega
+// en el historial de custodia. Retorna nil si el equipo ya estaba a cargo del mismo responsable.
+func (s *equipoService) AsignarResponsable(ctx context.Context, equipoID uint, usuarioResponsableID *uint, motivo string) (*models.AsignacionEquipo, error) {
 	if equipoID == 0 {
-		return errors.New("ID de equipo no válido")
+		return nil, errors.New("ID de equipo no válido")
+	}
+	// Registrar el cambio como asignación en la auditoría
+	ctx = auditoria.ConAccion(ctx, auditoria.AccionAsignar)
+	asignacion := nuevaAsignacion(ctx, equipoID, usuarioResponsableID, motivo)
+	if err := s.equipoRepo.AsignarResponsable(ctx, asignacion); err != nil {
+		return nil, err
+	}
+	if asignacion.ID == 0 {
+		return nil, nil
+	}
+	s.notificacion.EquipoAsignado(ctx, asignacion)
+	return asignacion, nil
+}
+
+// GetAsignacionesByEquipo obtiene el historial de custodia de un equipo
+func (s *equipoService) GetAsignacionesByEquipo(equipoID uint) ([]models.AsignacionEquipo, error) {
+	if equipoID == 0 {
+		return nil, errors.New("ID de equipo no válido")
+	}
+	return s.asignacionRepo.FindByEquipoID(equipoID)
+}
+
+// GetAsignacionesByResponsable obtiene los equipos que ha tenido a cargo un usuario responsable
+func (s *equipoService) GetAsignacionesByResponsable(responsableID uint) ([]models.AsignacionEquipo, error) {
+	if responsableID == 0 {
+		return nil, errors.New("ID de usuario responsable no válido")
+	}
+	return s.asignacionRepo.FindByResponsableID(responsableID)
+}
+
+// SubirActaFirmada sube el acta de entrega firmada al almacenamiento
+func (s *equipoService) SubirActaFirmada(ctx context.Context, asignacionID uint, fileData []byte, contentType string) (*models.AsignacionEquipo, error) {
+	if asignacionID == 0 {
+		return nil, errors.New("ID de asignación no válido")
+	}
+
+	if _, err := s.asignacionRepo.FindByID(asignacionID); err != nil {
+		return nil, errors.New("asignación no encontrada")
+	}
+
+	// Subir archivo al almacenamiento (reemplaza una versión anterior si existe)
+	fileName := fmt.Sprintf("acta_entrega_%d_firmada.pdf", asignacionID)
+	objectPath, err := s.storage.Upload(fileName, fileData, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("error al subir archivo: %w", err)
+	}
+
+	if err := s.asignacionRepo.RegistrarActaFirmada(ctx, asignacionID, fileName, objectPath); err != nil {
+		return nil, fmt.Errorf("error al registrar el acta firmada: %w", err)
+	}
+
+	return s.asignacionRepo.FindByID(asignacionID)
+}
+
+// ObtenerURLActaFirmada genera una URL firmada temporal para descargar el acta de entrega firmada
+func (s *equipoService) ObtenerURLActaFirmada(asignacionID uint) (string, error) {
+	if asignacionID == 0 {
+		return "", errors.New("ID de asignación no válido")
+	}
+
+	asignacion, err := s.asignacionRepo.FindByID(asignacionID)
+	if err != nil {
+		return "", errors.New("asignación no encontrada")
+	}
+	if asignacion.ActaFirmadaArchivo == "" {
+		return "", errors.New("esta asignación no tiene un acta firmada")
+	}
+
+	// Generar URL firmada (válida por 1 hora)
+	signedURL, err := s.storage.SignedURL(asignacion.ActaFirmadaArchivo, time.Hour)
+	if err != nil {
+		return "", fmt.Errorf("error al generar URL de descarga: %w", err)
+	}
+
+	return signedURL, nil
+}
+
+// nuevaAsignacion arma el registro de entrega con el técnico autenticado como responsable del trámite
+func nuevaAsignacion(ctx context.Context, equipoID uint, responsableID *uint, motivo string) *models.AsignacionEquipo {
+	asignacion := &models.AsignacionEquipo{
+		EquipoID:           equipoID,
+		ResponsableNuevoID: responsableID,
+		FechaInicio:        time.Now(),
+		Motivo:             motivo,
+	}
+	if actor, ok := auditoria.ActorDesde(ctx); ok {
+		asignacion.RealizadoPorID = &actor.UsuarioID
+		asignacion.RealizadoPorUsername = actor.Username
 	}
-	return s.equipoRepo.AsignarResponsable(equipoID, usuarioResponsableID)
+	return asignacion
 }
@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 )
 
@@ -16,8 +18,12 @@ func NewEstadoEquipoService(repo *repositories.EstadoEquipoRepository) *EstadoEq
 }
 
 // GetAllEstados obtiene todos los estados de equipo
-func (s *EstadoEquipoService) GetAllEstados() ([]models.EstadoEquipo, error) {
-	return s.repo.GetAll()
+func (s *EstadoEquipoService) GetAllEstados(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.EstadoEquipo], error) {
+	registros, total, err := s.repo.GetAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.EstadoEquipo]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetEstadoByID obtiene un estado de equipo por su ID
@@ -34,7 +40,7 @@ func (s *EstadoEquipoService) GetActiveEstados() ([]models.EstadoEquipo, error)
 }
 
 // CreateEstado crea un nuevo estado de equipo con validaciones
-func (s *EstadoEquipoService) CreateEstado(estado *models.EstadoEquipo) error {
+func (s *EstadoEquipoService) CreateEstado(ctx context.Context, estado *models.EstadoEquipo) error {
 	// Validaciones
 	if err := s.validateEstado(estado); err != nil {
 		return err
@@ -53,11 +59,11 @@ func (s *EstadoEquipoService) CreateEstado(estado *models.EstadoEquipo) error {
 	estado.Nombre = strings.TrimSpace(estado.Nombre)
 	estado.Descripcion = strings.TrimSpace(estado.Descripcion)
 
-	return s.repo.Create(estado)
+	return s.repo.Create(ctx, estado)
 }
 
 // UpdateEstado actualiza un estado de equipo existente
-func (s *EstadoEquipoService) UpdateEstado(id uint, estado *models.EstadoEquipo) error {
+func (s *EstadoEquipoService) UpdateEstado(ctx context.Context, id uint, estado *models.EstadoEquipo) error {
 	if id == 0 {
 		return errors.New("ID no válido")
 	}
@@ -87,11 +93,11 @@ func (s *EstadoEquipoService) UpdateEstado(id uint, estado *models.EstadoEquipo)
 	existingEstado.Descripcion = strings.TrimSpace(estado.Descripcion)
 	existingEstado.Activo = estado.Activo
 
-	return s.repo.Update(existingEstado)
+	return s.repo.Update(ctx, existingEstado)
 }
 
 // DeleteEstado elimina un estado de equipo
-func (s *EstadoEquipoService) DeleteEstado(id uint) error {
+func (s *EstadoEquipoService) DeleteEstado(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID no válido")
 	}
@@ -111,11 +117,11 @@ func (s *EstadoEquipoService) DeleteEstado(id uint) error {
 		return errors.New("no se puede eliminar el estado porque hay equipos asociados a él")
 	}
 
-	return s.repo.Delete(id)
+	return s.repo.Delete(ctx, id)
 }
 
 // ToggleActivo cambia el estado activo/inactivo de un estado de equipo
-func (s *EstadoEquipoService) ToggleActivo(id uint) error {
+func (s *EstadoEquipoService) ToggleActivo(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID no válido")
 	}
@@ -137,7 +143,7 @@ func (s *EstadoEquipoService) ToggleActivo(id uint) error {
 	}
 
 	estado.Activo = !estado.Activo
-	return s.repo.Update(estado)
+	return s.repo.Update(ctx, estado)
 }
 
 // GetEquiposByEstado obtiene todos los equipos que tienen un estado específico
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Tipos de contenido de los archivos exportados
+const (
+	TipoContenidoXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	TipoContenidoCSV  = "text/csv; charset=utf-8"
+)
+
+// hojaSinSecretaria agrupa los equipos sin responsable o cuyo responsable no tiene dependencia
+const hojaSinSecretaria = "Sin secretaría"
+
+// encabezadoEquipos son las columnas de cada hoja de la exportación de equipos
+var encabezadoEquipos = []string{
+	"ID", "Placa inventario", "Serial", "Tipo dispositivo", "Marca", "Modelo", "Estado",
+	"Responsable", "Cédula", "Dependencia", "Procesador", "Memoria RAM", "Disco duro",
+	"Dirección IP", "Nombre en red", "Fecha diligenciamiento", "Observaciones",
+}
+
+// anchosEquipos es el ancho de cada columna de la hoja, en caracteres
+var anchosEquipos = []float64{8, 16, 20, 14, 14, 18, 14, 30, 14, 28, 24, 18, 18, 15, 18, 12, 40}
+
+// caracteresNoValidosHoja no se permiten en el nombre de una hoja de Excel
+var caracteresNoValidosHoja = strings.NewReplacer(":", " ", "\\", " ", "/", " ", "?", " ", "*", " ", "[", "(", "]", ")")
+
+// ExportacionService define la exportación del inventario a hojas de cálculo
+type ExportacionService interface {
+	ExportarEquiposXLSX(consulta dto.ConsultaDTO, w io.Writer) error
+	ExportarPerifericosCSV(consulta dto.ConsultaDTO, w io.Writer) error
+	ExportarSoftwareCSV(consulta dto.ConsultaDTO, w io.Writer) error
+	ExportarReportesServicioCSV(consulta dto.ConsultaDTO, w io.Writer) error
+}
+
+// exportacionService implementa ExportacionService
+type exportacionService struct {
+	equipoRepo          repositories.EquipoRepository
+	perifericoRepo      repositories.PerifericoRepository
+	softwareRepo        repositories.SoftwareRepository
+	reporteServicioRepo repositories.ReporteServicioRepository
+}
+
+// NewExportacionService crea una nueva instancia de ExportacionService
+func NewExportacionService(
+	equipoRepo repositories.EquipoRepository,
+	perifericoRepo repositories.PerifericoRepository,
+	softwareRepo repositories.SoftwareRepository,
+	reporteServicioRepo repositories.ReporteServicioRepository,
+) ExportacionService {
+	return &exportacionService{
+		equipoRepo:          equipoRepo,
+		perifericoRepo:      perifericoRepo,
+		softwareRepo:        softwareRepo,
+		reporteServicioRepo: reporteServicioRepo,
+	}
+}
+
+// libroEquipos escribe la exportación de equipos con una hoja por secretaría
+type libroEquipos struct {
+	libro      *excelize.File
+	hoja       *excelize.StreamWriter
+	secretaria *uint // Secretaría de la hoja actual
+	fila       int
+	estilo     int
+	nombres    map[string]bool
+}
+
+// ExportarEquiposXLSX escribe en w un XLSX con los equipos que cumplen la consulta, una
+// hoja por secretaría. Nada se escribe en w hasta que todas las hojas están completas.
+func (s *exportacionService) ExportarEquiposXLSX(consulta dto.ConsultaDTO, w io.Writer) error {
+	libro := excelize.NewFile()
+	defer libro.Close()
+
+	estilo, err := libro.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"1F4E78"}},
+	})
+	if err != nil {
+		return err
+	}
+	l := &libroEquipos{libro: libro, estilo: estilo, nombres: map[string]bool{}}
+
+	err = s.equipoRepo.ExportarEquipos(consulta, func(lote []dto.EquipoExportacionDTO) error {
+		for _, equipo := range lote {
+			if l.hoja == nil || !mismaSecretaria(l.secretaria, equipo.SecretariaID) {
+				nombre := equipo.SecretariaNombre
+				if equipo.SecretariaID == nil {
+					nombre = hojaSinSecretaria
+				}
+				if err := l.nuevaHoja(nombre, equipo.SecretariaID); err != nil {
+					return err
+				}
+			}
+			if err := l.agregarEquipo(equipo); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sin equipos el libro conserva una hoja con el encabezado
+	if l.hoja == nil {
+		if err := l.nuevaHoja("Equipos", nil); err != nil {
+			return err
+		}
+	}
+	if err := l.hoja.Flush(); err != nil {
+		return err
+	}
+	libro.SetActiveSheet(0)
+
+	_, err = libro.WriteTo(w)
+	return err
+}
+
+// nuevaHoja cierra la hoja actual y abre otra con el encabezado de equipos
+func (l *libroEquipos) nuevaHoja(nombre string, secretariaID *uint) error {
+	if l.hoja != nil {
+		if err := l.hoja.Flush(); err != nil {
+			return err
+		}
+	}
+
+	primera := len(l.nombres) == 0
+	nombre = l.nombreHoja(nombre)
+	if primera {
+		// La primera hoja reemplaza la hoja vacía que trae el libro nuevo
+		if err := l.libro.SetSheetName(l.libro.GetSheetName(0), nombre); err != nil {
+			return err
+		}
+	} else if _, err := l.libro.NewSheet(nombre); err != nil {
+		return err
+	}
+
+	hoja, err := l.libro.NewStreamWriter(nombre)
+	if err != nil {
+		return err
+	}
+	for i, ancho := range anchosEquipos {
+		if err := hoja.SetColWidth(i+1, i+1, ancho); err != nil {
+			return err
+		}
+	}
+	if err := hoja.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	encabezado := make([]interface{}, len(encabezadoEquipos))
+	for i, titulo := range encabezadoEquipos {
+		encabezado[i] = excelize.Cell{StyleID: l.estilo, Value: titulo}
+	}
+	if err := hoja.SetRow("A1", encabezado); err != nil {
+		return err
+	}
+
+	l.hoja = hoja
+	l.secretaria = secretariaID
+	l.fila = 2
+	return nil
+}
+
+// nombreHoja ajusta el nombre a las reglas de Excel (máx. 31 caracteres, sin : \ / ? * [ ])
+// y evita nombres repetidos
+func (l *libroEquipos) nombreHoja(nombre string) string {
+	nombre = strings.Join(strings.Fields(caracteresNoValidosHoja.Replace(nombre)), " ")
+	if nombre == "" {
+		nombre = "Secretaría"
+	}
+	base := []rune(nombre)
+	if len(base) > 31 {
+		base = base[:31]
+	}
+	nombre = string(base)
+	for i := 2; l.nombres[strings.ToLower(nombre)]; i++ {
+		sufijo := fmt.Sprintf(" (%d)", i)
+		corte := base
+		if len(corte)+len(sufijo) > 31 {
+			corte = corte[:31-len(sufijo)]
+		}
+		nombre = string(corte) + sufijo
+	}
+	l.nombres[strings.ToLower(nombre)] = true
+	return nombre
+}
+
+// agregarEquipo escribe la fila de un equipo en la hoja actual
+func (l *libroEquipos) agregarEquipo(equipo dto.EquipoExportacionDTO) error {
+	hardware := resumenHardware(equipo.HardwareInterno)
+	celda, err := excelize.CoordinatesToCellName(1, l.fila)
+	if err != nil {
+		return err
+	}
+	l.fila++
+	return l.hoja.SetRow(celda, []interface{}{
+		equipo.ID, equipo.PlacaInventario, equipo.Serial, equipo.TipoDispositivo, equipo.Marca, equipo.Modelo,
+		equipo.Estado, equipo.ResponsableNombre, equipo.ResponsableCedula, equipo.DependenciaNombre,
+		hardware["Procesador"], hardware["Memoria RAM"], hardware["Disco Duro"],
+		equipo.DireccionIP, equipo.NombreDispositivo, fechaExportacion(&equipo.FechaDiligenciamiento),
+		equipo.ObservacionesGenerales,
+	})
+}
+
+// mismaSecretaria compara dos IDs de secretaría opcionales
+func mismaSecretaria(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// resumenHardware agrupa el hardware interno por componente, por ejemplo
+// "Memoria RAM" -> "DDR4 8GB, DDR4 8GB"
+func resumenHardware(hardware []models.HardwareInterno) map[string]string {
+	resumen := map[string]string{}
+	for _, h := range hardware {
+		texto := strings.TrimSpace(h.Tecnologia + " " + h.Capacidad)
+		if resumen[h.Componente] != "" {
+			texto = resumen[h.Componente] + ", " + texto
+		}
+		resumen[h.Componente] = texto
+	}
+	return resumen
+}
+
+// ExportarPerifericosCSV escribe en w los periféricos que cumplen la consulta
+func (s *exportacionService) ExportarPerifericosCSV(consulta dto.ConsultaDTO, w io.Writer) error {
+	return escribirCSV(w,
+		[]string{"ID", "Tipo", "Marca", "Serial", "Placa inventario", "Equipo ID", "Placa equipo", "Fecha registro"},
+		func(escribir func([]string) error) error {
+			return s.perifericoRepo.ExportarPerifericos(consulta, func(lote []dto.PerifericoExportacionDTO) error {
+				for _, p := range lote {
+					if err := escribir([]string{
+						idTexto(p.ID), p.TipoPeriferico, p.Marca, p.Serial, p.PlacaInventario,
+						idOpcionalTexto(p.EquipoID), p.EquipoPlaca, fechaExportacion(&p.CreatedAt),
+					}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+}
+
+// ExportarSoftwareCSV escribe en w el software que cumple la consulta
+func (s *exportacionService) ExportarSoftwareCSV(consulta dto.ConsultaDTO, w io.Writer) error {
+	return escribirCSV(w,
+		[]string{"ID", "Nombre", "Versión", "Tipo licencia", "Categoría", "Equipo ID", "Placa equipo", "Fecha registro"},
+		func(escribir func([]string) error) error {
+			return s.softwareRepo.ExportarSoftware(consulta, func(lote []dto.SoftwareExportacionDTO) error {
+				for _, sw := range lote {
+					if err := escribir([]string{
+						idTexto(sw.ID), sw.Nombre, sw.Version, sw.TipoLicencia, sw.Categoria,
+						idTexto(sw.EquipoID), sw.EquipoPlaca, fechaExportacion(&sw.CreatedAt),
+					}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+}
+
+// ExportarReportesServicioCSV escribe en w los reportes de servicio que cumplen la consulta
+func (s *exportacionService) ExportarReportesServicioCSV(consulta dto.ConsultaDTO, w io.Writer) error {
+	return escribirCSV(w,
+		[]string{
+			"ID", "Fecha inicio", "Fecha finalización", "Tipo mantenimiento", "Estado", "Fecha cierre",
+			"Equipo ID", "Placa equipo", "Serial equipo", "Dependencia", "Ubicación",
+			"Diagnóstico", "Actividad realizada", "Observaciones", "Técnico",
+		},
+		func(escribir func([]string) error) error {
+			return s.reporteServicioRepo.ExportarReportes(consulta, func(lote []models.ReporteServicio) error {
+				for _, r := range lote {
+					estado := "Abierto"
+					if r.FechaCierre != nil {
+						estado = "Cerrado"
+					}
+					tipo := r.TipoMantenimiento.Tipo
+					if tipo == "" {
+						tipo = "OTRO"
+					}
+					tecnico := strings.TrimSpace(r.CreadoPor.Nombre + " " + r.CreadoPor.Apellido)
+					if err := escribir([]string{
+						idTexto(r.ID), fechaExportacion(&r.FechaInicio), fechaExportacion(r.FechaFinalizacion),
+						tipo, estado, fechaExportacion(r.FechaCierre),
+						idTexto(r.EquipoID), r.Equipo.PlacaInventario, r.Equipo.Serial, r.Dependencia, r.Ubicacion,
+						r.DiagnosticoFalla, r.ActividadRealizada, r.Observaciones, tecnico,
+					}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+}
+
+// escribirCSV escribe un CSV separado por punto y coma y con BOM UTF-8, como lo abre
+// Excel en español. Las filas se envían a w por lotes, a medida que se leen.
+func escribirCSV(w io.Writer, encabezado []string, recorrer func(escribir func([]string) error) error) error {
+	escritor := csv.NewWriter(w)
+	escritor.Comma = ';'
+	iniciado := false
+
+	// El BOM y el encabezado se escriben con la primera fila, para que un error en la
+	// consulta todavía se pueda responder como JSON
+	iniciar := func() error {
+		if iniciado {
+			return nil
+		}
+		iniciado = true
+		if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+			return err
+		}
+		return escritor.Write(encabezado)
+	}
+
+	filas := 0
+	err := recorrer(func(fila []string) error {
+		if err := iniciar(); err != nil {
+			return err
+		}
+		if err := escritor.Write(fila); err != nil {
+			return err
+		}
+		if filas++; filas%500 == 0 {
+			escritor.Flush()
+			return escritor.Error()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := iniciar(); err != nil {
+		return err
+	}
+	escritor.Flush()
+	return escritor.Error()
+}
+
+// fechaExportacion formatea una fecha como YYYY-MM-DD; vacío si no tiene
+func fechaExportacion(fecha *time.Time) string {
+	if fecha == nil || fecha.IsZero() {
+		return ""
+	}
+	return fecha.Format("2006-01-02")
+}
+
+func idTexto(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func idOpcionalTexto(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return idTexto(*id)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/firma"
+)
+
+// ErrCertificadoAjeno indica que el certificado enviado no es del usuario que firma
+var ErrCertificadoAjeno = errors.New("el certificado no pertenece al usuario autenticado")
+
+// FirmaDigitalService firma digitalmente los reportes de servicio en el servidor y verifica
+// las firmas de los PDF, como alternativa a imprimir, firmar a mano y subir el escaneo
+type FirmaDigitalService interface {
+	FirmarReporte(ctx context.Context, reporteID, usuarioID uint, tecnico *firma.Firmante) (*models.ReporteServicio, error)
+	VerificarFirmas(datos []byte) ([]firma.ResultadoFirma, error)
+}
+
+// firmaDigitalService implementa FirmaDigitalService
+type firmaDigitalService struct {
+	reporteService ReporteServicioService
+	usuarioRepo    repositories.UsuarioRepository
+	pdfService     *PDFReporteService
+	firmaDigital   *firma.FirmaDigital
+}
+
+// NewFirmaDigitalService crea una nueva instancia de FirmaDigitalService
+func NewFirmaDigitalService(reporteService ReporteServicioService, usuarioRepo repositories.UsuarioRepository, pdfService *PDFReporteService, firmaDigital *firma.FirmaDigital) FirmaDigitalService {
+	return &firmaDigitalService{
+		reporteService: reporteService,
+		usuarioRepo:    usuarioRepo,
+		pdfService:     pdfService,
+		firmaDigital:   firmaDigital,
+	}
+}
+
+// FirmarReporte genera el PDF del reporte, lo firma con el certificado del técnico (si se
+// envía) y después con el de la Oficina de Sistemas, y cierra el reporte con ese documento
+// como nueva versión del PDF firmado. El certificado del técnico debe ser del usuario
+// autenticado (misma cédula o correo).
+func (s *firmaDigitalService) FirmarReporte(ctx context.Context, reporteID, usuarioID uint, tecnico *firma.Firmante) (*models.ReporteServicio, error) {
+	if !s.firmaDigital.Habilitada() {
+		return nil, firma.ErrFirmaNoConfigurada
+	}
+	if tecnico != nil {
+		usuario, err := s.usuarioRepo.FindByID(usuarioID)
+		if err != nil {
+			return nil, errors.New("usuario no encontrado")
+		}
+		if !tecnico.PerteneceA(usuario.Cedula, usuario.Email) {
+			return nil, ErrCertificadoAjeno
+		}
+	}
+
+	// Se revisa antes de generar el PDF; SubirFirmado lo vuelve a comprobar al cerrar
+	reporte, err := s.reporteService.GetReporteServicioByID(reporteID)
+	if err != nil {
+		return nil, errors.New("reporte no encontrado")
+	}
+	if reporte.FechaCierre != nil {
+		return nil, errors.New("el reporte ya está cerrado")
+	}
+
+	pdf, err := s.pdfService.GenerarPDFReporte(reporteID, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tecnico != nil {
+		pdf, err = s.firmaDigital.Firmar(pdf, tecnico, firma.CampoTecnico, "Técnico que realizó el servicio")
+		if err != nil {
+			return nil, fmt.Errorf("error en la firma del técnico: %w", err)
+		}
+	}
+	pdf, err = s.firmaDigital.FirmarOficina(pdf, fmt.Sprintf("Reporte de servicio técnico No. %d", reporteID))
+	if err != nil {
+		return nil, fmt.Errorf("error en la firma de la Oficina de Sistemas: %w", err)
+	}
+
+	return s.reporteService.SubirFirmado(ctx, reporteID, pdf, "application/pdf")
+}
+
+// VerificarFirmas verifica las firmas digitales de un PDF
+func (s *firmaDigitalService) VerificarFirmas(datos []byte) ([]firma.ResultadoFirma, error) {
+	return s.firmaDigital.Verificar(datos)
+}
@@ -1,18 +1,20 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 )
 
 // HardwareInternoService define las operaciones del servicio para HardwareInterno
 type HardwareInternoService interface {
-	CreateHardwareInterno(hardware *models.HardwareInterno) error
+	CreateHardwareInterno(ctx context.Context, hardware *models.HardwareInterno) error
 	GetHardwareInternoByID(id uint) (*models.HardwareInterno, error)
-	UpdateHardwareInterno(hardware *models.HardwareInterno) error
-	DeleteHardwareInterno(id uint) error
-	GetAllHardwareInterno() ([]models.HardwareInterno, error)
+	UpdateHardwareInterno(ctx context.Context, hardware *models.HardwareInterno) error
+	DeleteHardwareInterno(ctx context.Context, id uint) error
+	GetAllHardwareInterno(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.HardwareInterno], error)
 	GetHardwareInternoByEquipoID(equipoID uint) ([]models.HardwareInterno, error)
 }
 
@@ -27,7 +29,7 @@ func NewHardwareInternoService(hardwareRepo repositories.HardwareInternoReposito
 }
 
 // CreateHardwareInterno crea un nuevo componente de hardware interno
-func (s *hardwareInternoService) CreateHardwareInterno(hardware *models.HardwareInterno) error {
+func (s *hardwareInternoService) CreateHardwareInterno(ctx context.Context, hardware *models.HardwareInterno) error {
 	if hardware.EquipoID == 0 {
 		return errors.New("el ID del equipo es obligatorio")
 	}
@@ -40,7 +42,7 @@ func (s *hardwareInternoService) CreateHardwareInterno(hardware *models.Hardware
 	if hardware.Capacidad == "" {
 		return errors.New("la capacidad del componente es obligatoria")
 	}
-	return s.hardwareRepo.Create(hardware)
+	return s.hardwareRepo.Create(ctx, hardware)
 }
 
 // GetHardwareInternoByID obtiene un componente de hardware interno por su ID
@@ -49,7 +51,7 @@ func (s *hardwareInternoService) GetHardwareInternoByID(id uint) (*models.Hardwa
 }
 
 // UpdateHardwareInterno actualiza un componente de hardware interno existente
-func (s *hardwareInternoService) UpdateHardwareInterno(hardware *models.HardwareInterno) error {
+func (s *hardwareInternoService) UpdateHardwareInterno(ctx context.Context, hardware *models.HardwareInterno) error {
 	if hardware.ID == 0 {
 		return errors.New("ID de hardware interno no válido")
 	}
@@ -65,20 +67,24 @@ func (s *hardwareInternoService) UpdateHardwareInterno(hardware *models.Hardware
 	if hardware.Capacidad == "" {
 		return errors.New("la capacidad del componente es obligatoria")
 	}
-	return s.hardwareRepo.Update(hardware)
+	return s.hardwareRepo.Update(ctx, hardware)
 }
 
 // DeleteHardwareInterno elimina un componente de hardware interno por su ID
-func (s *hardwareInternoService) DeleteHardwareInterno(id uint) error {
+func (s *hardwareInternoService) DeleteHardwareInterno(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de hardware interno no válido")
 	}
-	return s.hardwareRepo.Delete(id)
+	return s.hardwareRepo.Delete(ctx, id)
 }
 
 // GetAllHardwareInterno obtiene todos los componentes de hardware interno
-func (s *hardwareInternoService) GetAllHardwareInterno() ([]models.HardwareInterno, error) {
-	return s.hardwareRepo.FindAll()
+func (s *hardwareInternoService) GetAllHardwareInterno(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.HardwareInterno], error) {
+	registros, total, err := s.hardwareRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.HardwareInterno]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetHardwareInternoByEquipoID obtiene todos los componentes de hardware interno asociados a un equipo
@@ -0,0 +1,542 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// MaxFilasImportacion limita el número de equipos por archivo
+const MaxFilasImportacion = 5000
+
+// Columnas del archivo de importación. El encabezado se compara sin mayúsculas ni
+// tildes, con espacios o guiones como _, por ejemplo "Cédula Responsable".
+const (
+	colTipoDispositivo     = "tipo_dispositivo"
+	colPlacaInventario     = "placa_inventario"
+	colMarca               = "marca"
+	colSerial              = "serial"
+	colModelo              = "modelo"
+	colEstado              = "estado"
+	colObservaciones       = "observaciones"
+	colCedulaResponsable   = "cedula_responsable"
+	colNombresResponsable  = "nombres_responsable"
+	colCorreoResponsable   = "correo_responsable"
+	colCelularResponsable  = "celular_responsable"
+	colTipoVinculacion     = "tipo_vinculacion"
+	colDependencia         = "dependencia"
+	colProcesador          = "procesador"
+	colProcesadorCapacidad = "procesador_capacidad"
+	colMemoriaRAM          = "memoria_ram"
+	colMemoriaRAMCapacidad = "memoria_ram_capacidad"
+	colDiscoDuro           = "disco_duro"
+	colDiscoDuroCapacidad  = "disco_duro_capacidad"
+	colSistemaOperativo    = "sistema_operativo"
+	colPaqueteOficina      = "paquete_oficina"
+	colNavegadorWeb        = "navegador_web"
+	colOtroSoftware        = "otro_software"
+	colDireccionIP         = "direccion_ip"
+	colAsignacionIP        = "asignacion_ip"
+	colNombreDispositivo   = "nombre_dispositivo"
+	colConectividad        = "conectividad"
+)
+
+// columnasImportacion son las columnas reconocidas; true indica que debe estar en el encabezado
+var columnasImportacion = map[string]bool{
+	colTipoDispositivo: true, colPlacaInventario: true, colMarca: true, colSerial: true,
+	colModelo: false, colEstado: false, colObservaciones: false,
+	colCedulaResponsable: false, colNombresResponsable: false, colCorreoResponsable: false,
+	colCelularResponsable: false, colTipoVinculacion: false, colDependencia: false,
+	colProcesador: false, colProcesadorCapacidad: false, colMemoriaRAM: false,
+	colMemoriaRAMCapacidad: false, colDiscoDuro: false, colDiscoDuroCapacidad: false,
+	colSistemaOperativo: false, colPaqueteOficina: false, colNavegadorWeb: false, colOtroSoftware: false,
+	colDireccionIP: false, colAsignacionIP: false, colNombreDispositivo: false, colConectividad: false,
+}
+
+// Valores permitidos por los CHECK de los modelos
+var (
+	tiposDispositivo  = []string{"Todo en Uno", "Escritorio", "Portátil", "Impresora", "Escáner", "Otro"}
+	tiposVinculacion  = []string{"Planta", "Contratista", "Otro"}
+	asignacionesIP    = []string{"Manual", "Automatica", "Dinamica"}
+	componentesImport = []struct{ componente, tecnologia, capacidad string }{
+		{"Procesador", colProcesador, colProcesadorCapacidad},
+		{"Memoria RAM", colMemoriaRAM, colMemoriaRAMCapacidad},
+		{"Disco Duro", colDiscoDuro, colDiscoDuroCapacidad},
+	}
+	categoriasSoftware = []struct{ categoria, columna string }{
+		{"Sistema Operativo", colSistemaOperativo},
+		{"Paquete de Oficina", colPaqueteOficina},
+		{"Navegador Web", colNavegadorWeb},
+		{"Otro", colOtroSoftware},
+	}
+)
+
+// estadoPorDefecto se asigna cuando la fila no trae estado
+const estadoPorDefecto = "Activo"
+
+// ImportacionService define la importación masiva de inventario desde XLSX o CSV
+type ImportacionService interface {
+	ImportarInventario(ctx context.Context, nombreArchivo string, datos []byte, dryRun bool) (*dto.ResultadoImportacionDTO, error)
+}
+
+// importacionService implementa ImportacionService
+type importacionService struct {
+	importacionRepo        repositories.ImportacionRepository
+	usuarioResponsableRepo repositories.UsuarioResponsableRepository
+	dependenciaRepo        repositories.DependenciaRepository
+	estadoEquipoRepo       *repositories.EstadoEquipoRepository
+}
+
+// NewImportacionService crea una nueva instancia de ImportacionService
+func NewImportacionService(
+	importacionRepo repositories.ImportacionRepository,
+	usuarioResponsableRepo repositories.UsuarioResponsableRepository,
+	dependenciaRepo repositories.DependenciaRepository,
+	estadoEquipoRepo *repositories.EstadoEquipoRepository,
+) ImportacionService {
+	return &importacionService{
+		importacionRepo:        importacionRepo,
+		usuarioResponsableRepo: usuarioResponsableRepo,
+		dependenciaRepo:        dependenciaRepo,
+		estadoEquipoRepo:       estadoEquipoRepo,
+	}
+}
+
+// filaImportacion es una fila del archivo con sus valores por columna
+type filaImportacion struct {
+	numero  int
+	valores map[string]string
+}
+
+func (f filaImportacion) valor(columna string) string {
+	return f.valores[columna]
+}
+
+// validacionImportacion acumula los errores y los datos ya resueltos durante la validación
+type validacionImportacion struct {
+	resultado    *dto.ResultadoImportacionDTO
+	equipos      []repositories.EquipoImportado
+	nuevos       []*models.UsuarioResponsable
+	responsables map[string]*models.UsuarioResponsable // Por cédula, existentes y nuevos
+	dependencias map[string]*uint
+	estados      map[string]uint
+}
+
+func (v *validacionImportacion) agregarError(fila int, columna, valor, mensaje string) {
+	v.resultado.Errores = append(v.resultado.Errores, dto.ErrorFilaImportacionDTO{
+		Fila:    fila,
+		Columna: columna,
+		Valor:   valor,
+		Mensaje: mensaje,
+	})
+}
+
+// ImportarInventario valida todas las filas del archivo y, si no hay errores y no es
+// dry-run, crea equipos, responsables nuevos, hardware, software y configuración de red
+// en una sola transacción. Con errores de fila no se guarda nada.
+func (s *importacionService) ImportarInventario(ctx context.Context, nombreArchivo string, datos []byte, dryRun bool) (*dto.ResultadoImportacionDTO, error) {
+	filas, err := leerFilasImportacion(nombreArchivo, datos)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validacionImportacion{
+		resultado:    &dto.ResultadoImportacionDTO{DryRun: dryRun, Errores: []dto.ErrorFilaImportacionDTO{}},
+		responsables: map[string]*models.UsuarioResponsable{},
+		dependencias: map[string]*uint{},
+		estados:      map[string]uint{},
+	}
+	v.resultado.TotalFilas = len(filas)
+
+	if err := s.validarDuplicados(v, filas); err != nil {
+		return nil, err
+	}
+	for _, fila := range filas {
+		if err := s.validarFila(ctx, v, fila); err != nil {
+			return nil, err
+		}
+	}
+	v.resultado.ResponsablesNuevos = len(v.nuevos)
+	sort.SliceStable(v.resultado.Errores, func(i, j int) bool {
+		return v.resultado.Errores[i].Fila < v.resultado.Errores[j].Fila
+	})
+
+	if dryRun || len(v.resultado.Errores) > 0 {
+		return v.resultado, nil
+	}
+
+	if err := s.importacionRepo.Importar(ctx, v.nuevos, v.equipos); err != nil {
+		return nil, errors.New("error al guardar la importación: " + err.Error())
+	}
+	v.resultado.EquiposCreados = len(v.equipos)
+	return v.resultado, nil
+}
+
+// validarDuplicados revisa que serial y placa no se repitan en el archivo ni en la base de datos
+func (s *importacionService) validarDuplicados(v *validacionImportacion, filas []filaImportacion) error {
+	for _, columna := range []string{colSerial, colPlacaInventario} {
+		primeraFila := map[string]int{}
+		var valores []string
+		for _, fila := range filas {
+			valor := fila.valor(columna)
+			if valor == "" {
+				continue
+			}
+			if anterior, ok := primeraFila[valor]; ok {
+				v.agregarError(fila.numero, columna, valor, fmt.Sprintf("valor repetido en el archivo (fila %d)", anterior))
+				continue
+			}
+			primeraFila[valor] = fila.numero
+			valores = append(valores, valor)
+		}
+
+		var existentes map[string]bool
+		var err error
+		if columna == colSerial {
+			existentes, err = s.importacionRepo.SerialesExistentes(valores)
+		} else {
+			existentes, err = s.importacionRepo.PlacasExistentes(valores)
+		}
+		if err != nil {
+			return err
+		}
+		for valor, fila := range primeraFila {
+			if existentes[valor] {
+				v.agregarError(fila, columna, valor, "ya existe un equipo con este valor")
+			}
+		}
+	}
+	return nil
+}
+
+// validarFila valida una fila y, si es válida, la agrega a los equipos a importar
+func (s *importacionService) validarFila(ctx context.Context, v *validacionImportacion, fila filaImportacion) error {
+	erroresAntes := len(v.resultado.Errores)
+
+	equipo := &models.Equipo{
+		PlacaInventario:        fila.valor(colPlacaInventario),
+		Marca:                  fila.valor(colMarca),
+		Serial:                 fila.valor(colSerial),
+		Modelo:                 fila.valor(colModelo),
+		ObservacionesGenerales: fila.valor(colObservaciones),
+	}
+	for _, columna := range []string{colPlacaInventario, colMarca, colSerial} {
+		if fila.valor(columna) == "" {
+			v.agregarError(fila.numero, columna, "", "campo obligatorio")
+		}
+	}
+
+	tipo := fila.valor(colTipoDispositivo)
+	if tipo == "" {
+		v.agregarError(fila.numero, colTipoDispositivo, "", "campo obligatorio")
+	} else if equipo.TipoDispositivo = valorPermitido(tipo, tiposDispositivo); equipo.TipoDispositivo == "" {
+		v.agregarError(fila.numero, colTipoDispositivo, tipo, "valor no permitido, use: "+strings.Join(tiposDispositivo, ", "))
+	}
+
+	// Estado
+	estado := fila.valor(colEstado)
+	if estado == "" {
+		estado = estadoPorDefecto
+	}
+	estadoID, ok := v.estados[strings.ToLower(estado)]
+	if !ok {
+		encontrado, err := s.estadoEquipoRepo.GetByNombre(estado)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if encontrado != nil {
+			estadoID = encontrado.ID
+			v.estados[strings.ToLower(estado)] = estadoID
+		}
+	}
+	if estadoID == 0 {
+		v.agregarError(fila.numero, colEstado, estado, "estado de equipo no encontrado")
+	}
+	equipo.EstadoEquipoID = estadoID
+
+	// Responsable
+	responsable, err := s.resolverResponsable(v, fila)
+	if err != nil {
+		return err
+	}
+
+	// Hardware interno: tecnología y capacidad son obligatorias juntas
+	for _, c := range componentesImport {
+		tecnologia, capacidad := fila.valor(c.tecnologia), fila.valor(c.capacidad)
+		if tecnologia == "" && capacidad == "" {
+			continue
+		}
+		if tecnologia == "" {
+			v.agregarError(fila.numero, c.tecnologia, "", "indique la tecnología de "+c.componente)
+			continue
+		}
+		if capacidad == "" {
+			v.agregarError(fila.numero, c.capacidad, "", "indique la capacidad de "+c.componente)
+			continue
+		}
+		equipo.HardwareInterno = append(equipo.HardwareInterno, models.HardwareInterno{
+			Componente: c.componente,
+			Tecnologia: tecnologia,
+			Capacidad:  capacidad,
+		})
+	}
+
+	// Software: varios nombres separados por ;
+	for _, c := range categoriasSoftware {
+		for _, nombre := range strings.Split(fila.valor(c.columna), ";") {
+			if nombre = strings.TrimSpace(nombre); nombre != "" {
+				equipo.Software = append(equipo.Software, models.Software{Nombre: nombre, Categoria: c.categoria})
+			}
+		}
+	}
+
+	// Configuración de red: opcional, pero si viene debe estar completa
+	ip, asignacion, nombreDispositivo := fila.valor(colDireccionIP), fila.valor(colAsignacionIP), fila.valor(colNombreDispositivo)
+	if ip != "" || asignacion != "" || nombreDispositivo != "" || fila.valor(colConectividad) != "" {
+		red := models.ConfiguracionRed{
+			DireccionIP:       ip,
+			NombreDispositivo: nombreDispositivo,
+			Conectividad:      fila.valor(colConectividad),
+		}
+		if ip == "" {
+			v.agregarError(fila.numero, colDireccionIP, "", "campo obligatorio en la configuración de red")
+		} else if net.ParseIP(ip) == nil {
+			v.agregarError(fila.numero, colDireccionIP, ip, "dirección IP inválida")
+		}
+		if nombreDispositivo == "" {
+			v.agregarError(fila.numero, colNombreDispositivo, "", "campo obligatorio en la configuración de red")
+		}
+		if red.AsignacionIP = valorPermitido(asignacion, asignacionesIP); red.AsignacionIP == "" {
+			v.agregarError(fila.numero, colAsignacionIP, asignacion, "valor no permitido, use: "+strings.Join(asignacionesIP, ", "))
+		}
+		equipo.ConfiguracionRed = red
+	}
+
+	if len(v.resultado.Errores) > erroresAntes {
+		return nil
+	}
+	importado := repositories.EquipoImportado{Equipo: equipo, Responsable: responsable}
+	if responsable != nil {
+		importado.Asignacion = nuevaAsignacion(ctx, 0, nil, "Asignación inicial (importación)")
+	}
+	v.equipos = append(v.equipos, importado)
+	return nil
+}
+
+// resolverResponsable busca el responsable por cédula o, si no existe, prepara uno nuevo
+// con los datos de la fila. Un mismo responsable nuevo puede aparecer en varias filas.
+func (s *importacionService) resolverResponsable(v *validacionImportacion, fila filaImportacion) (*models.UsuarioResponsable, error) {
+	cedula := fila.valor(colCedulaResponsable)
+	if cedula == "" {
+		if fila.valor(colNombresResponsable) != "" {
+			v.agregarError(fila.numero, colCedulaResponsable, "", "indique la cédula del responsable")
+		}
+		return nil, nil
+	}
+	if responsable, ok := v.responsables[cedula]; ok {
+		return responsable, nil
+	}
+
+	existente, err := s.usuarioResponsableRepo.FindByCedula(cedula)
+	if err == nil {
+		v.responsables[cedula] = existente
+		return existente, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Responsable nuevo
+	nombres := fila.valor(colNombresResponsable)
+	vinculacion := valorPermitido(fila.valor(colTipoVinculacion), tiposVinculacion)
+	valido := true
+	if nombres == "" {
+		v.agregarError(fila.numero, colNombresResponsable, "", "no existe un responsable con esta cédula; indique sus nombres para crearlo")
+		valido = false
+	}
+	if vinculacion == "" {
+		v.agregarError(fila.numero, colTipoVinculacion, fila.valor(colTipoVinculacion), "valor no permitido, use: "+strings.Join(tiposVinculacion, ", "))
+		valido = false
+	}
+	dependenciaID, err := s.resolverDependencia(v, fila)
+	if err != nil {
+		return nil, err
+	}
+	if !valido || (fila.valor(colDependencia) != "" && dependenciaID == nil) {
+		return nil, nil
+	}
+
+	nuevo := &models.UsuarioResponsable{
+		DependenciaID:    dependenciaID,
+		NombresApellidos: nombres,
+		Cedula:           cedula,
+		CorreoPersonal:   fila.valor(colCorreoResponsable),
+		TipoVinculacion:  vinculacion,
+		Celular:          fila.valor(colCelularResponsable),
+	}
+	v.responsables[cedula] = nuevo
+	v.nuevos = append(v.nuevos, nuevo)
+	return nuevo, nil
+}
+
+// resolverDependencia busca la dependencia por nombre; el nombre debe ser único
+func (s *importacionService) resolverDependencia(v *validacionImportacion, fila filaImportacion) (*uint, error) {
+	nombre := fila.valor(colDependencia)
+	if nombre == "" {
+		return nil, nil
+	}
+	clave := strings.ToLower(nombre)
+	if id, ok := v.dependencias[clave]; ok {
+		if id == nil {
+			v.agregarError(fila.numero, colDependencia, nombre, "dependencia no encontrada o con nombre repetido")
+		}
+		return id, nil
+	}
+
+	dependencias, err := s.dependenciaRepo.GetDependenciasByNombre(nombre)
+	if err != nil {
+		return nil, err
+	}
+	switch len(dependencias) {
+	case 0:
+		v.agregarError(fila.numero, colDependencia, nombre, "dependencia no encontrada")
+		v.dependencias[clave] = nil
+		return nil, nil
+	case 1:
+		id := dependencias[0].ID
+		v.dependencias[clave] = &id
+		return &id, nil
+	default:
+		v.agregarError(fila.numero, colDependencia, nombre, fmt.Sprintf("hay %d dependencias con este nombre", len(dependencias)))
+		v.dependencias[clave] = nil
+		return nil, nil
+	}
+}
+
+// leerFilasImportacion lee la primera hoja de un XLSX o un CSV (separado por , o ;)
+// y devuelve las filas con datos indexadas por columna
+func leerFilasImportacion(nombreArchivo string, datos []byte) ([]filaImportacion, error) {
+	var registros [][]string
+	switch strings.ToLower(filepath.Ext(nombreArchivo)) {
+	case ".xlsx":
+		libro, err := excelize.OpenReader(bytes.NewReader(datos), excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("%w: no se pudo abrir el XLSX: %v", dto.ErrImportacionInvalida, err)
+		}
+		defer libro.Close()
+		hojas := libro.GetSheetList()
+		if len(hojas) == 0 {
+			return nil, fmt.Errorf("%w: el XLSX no tiene hojas", dto.ErrImportacionInvalida)
+		}
+		if registros, err = libro.GetRows(hojas[0]); err != nil {
+			return nil, fmt.Errorf("%w: no se pudo leer la hoja %s: %v", dto.ErrImportacionInvalida, hojas[0], err)
+		}
+	case ".csv":
+		datos = bytes.TrimPrefix(datos, []byte("\xef\xbb\xbf"))
+		lector := csv.NewReader(bytes.NewReader(datos))
+		lector.FieldsPerRecord = -1
+		// Excel en español exporta CSV separado por punto y coma
+		primeraLinea, _, _ := bytes.Cut(datos, []byte("\n"))
+		if bytes.Count(primeraLinea, []byte(";")) > bytes.Count(primeraLinea, []byte(",")) {
+			lector.Comma = ';'
+		}
+		var err error
+		if registros, err = lector.ReadAll(); err != nil {
+			return nil, fmt.Errorf("%w: CSV mal formado: %v", dto.ErrImportacionInvalida, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: el archivo debe ser .xlsx o .csv", dto.ErrImportacionInvalida)
+	}
+
+	if len(registros) == 0 {
+		return nil, fmt.Errorf("%w: el archivo está vacío", dto.ErrImportacionInvalida)
+	}
+
+	encabezado := make([]string, len(registros[0]))
+	presentes := map[string]bool{}
+	for i, nombre := range registros[0] {
+		columna := normalizarColumna(nombre)
+		if columna == "" {
+			continue
+		}
+		if _, ok := columnasImportacion[columna]; !ok {
+			return nil, fmt.Errorf("%w: columna desconocida %q", dto.ErrImportacionInvalida, nombre)
+		}
+		if presentes[columna] {
+			return nil, fmt.Errorf("%w: columna repetida %q", dto.ErrImportacionInvalida, nombre)
+		}
+		presentes[columna] = true
+		encabezado[i] = columna
+	}
+	for columna, obligatoria := range columnasImportacion {
+		if obligatoria && !presentes[columna] {
+			return nil, fmt.Errorf("%w: falta la columna %s", dto.ErrImportacionInvalida, columna)
+		}
+	}
+
+	var filas []filaImportacion
+	for i, registro := range registros[1:] {
+		fila := filaImportacion{numero: i + 2, valores: map[string]string{}}
+		for j, valor := range registro {
+			if j < len(encabezado) && encabezado[j] != "" {
+				if valor = strings.TrimSpace(valor); valor != "" {
+					fila.valores[encabezado[j]] = valor
+				}
+			}
+		}
+		if len(fila.valores) == 0 {
+			continue
+		}
+		filas = append(filas, fila)
+	}
+
+	if len(filas) == 0 {
+		return nil, fmt.Errorf("%w: el archivo no tiene filas con datos", dto.ErrImportacionInvalida)
+	}
+	if len(filas) > MaxFilasImportacion {
+		return nil, fmt.Errorf("%w: el archivo supera el máximo de %d filas", dto.ErrImportacionInvalida, MaxFilasImportacion)
+	}
+	return filas, nil
+}
+
+// normalizarColumna convierte "Cédula Responsable" en "cedula_responsable"
+func normalizarColumna(nombre string) string {
+	nombre = strings.ReplaceAll(sinTildes(strings.ToLower(strings.TrimSpace(nombre))), "-", " ")
+	return strings.Join(strings.Fields(nombre), "_")
+}
+
+// valorPermitido devuelve el valor permitido que coincide sin distinguir mayúsculas ni tildes
+func valorPermitido(valor string, permitidos []string) string {
+	buscado := strings.Join(strings.Fields(sinTildes(strings.ToLower(valor))), " ")
+	for _, permitido := range permitidos {
+		if sinTildes(strings.ToLower(permitido)) == buscado {
+			return permitido
+		}
+	}
+	return ""
+}
+
+// sinTildes quita las tildes y diéresis del texto
+func sinTildes(texto string) string {
+	resultado, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), texto)
+	if err != nil {
+		return texto
+	}
+	return resultado
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// Formatos de la descarga de reportes por lote
+const (
+	FormatoLoteZIP = "zip" // Un PDF por reporte dentro de un ZIP
+	FormatoLotePDF = "pdf" // Un solo PDF con todos los reportes, uno tras otro
+)
+
+var (
+	// ErrFormatoLote indica que el formato pedido no es zip ni pdf
+	ErrFormatoLote = errors.New("formato debe ser zip o pdf")
+	// ErrLoteVacio indica que ningún reporte cumple los filtros
+	ErrLoteVacio = errors.New("ningún reporte de servicio cumple los filtros")
+	// ErrLoteExcedido indica que los filtros abarcan más reportes de los permitidos por lote
+	ErrLoteExcedido = errors.New("el lote supera el máximo de reportes")
+)
+
+// LoteReportesService genera los PDF de varios reportes de servicio en una sola descarga
+type LoteReportesService interface {
+	GenerarLote(ctx context.Context, consulta dto.ConsultaDTO, formato string, usuarioID uint, w io.Writer, progreso func(porcentaje int)) error
+}
+
+// loteReportesService implementa LoteReportesService
+type loteReportesService struct {
+	reporteRepo  repositories.ReporteServicioRepository
+	pdfService   *PDFReporteService
+	trabajadores int // PDF que se generan al mismo tiempo
+	maximo       int // Reportes por lote
+}
+
+// NewLoteReportesService crea una nueva instancia de LoteReportesService
+func NewLoteReportesService(reporteRepo repositories.ReporteServicioRepository, pdfService *PDFReporteService, trabajadores, maximo int) LoteReportesService {
+	return &loteReportesService{
+		reporteRepo:  reporteRepo,
+		pdfService:   pdfService,
+		trabajadores: max(trabajadores, 1),
+		maximo:       maximo,
+	}
+}
+
+// GenerarLote escribe en w los PDF de los reportes que cumplen la consulta, como ZIP o como
+// un único PDF. El ZIP se envía a medida que se generan los PDF; el PDF unido solo se puede
+// escribir cuando están todos. Si progreso no es nil, recibe el porcentaje de PDF generados.
+func (s *loteReportesService) GenerarLote(ctx context.Context, consulta dto.ConsultaDTO, formato string, usuarioID uint, w io.Writer, progreso func(porcentaje int)) error {
+	if formato != FormatoLoteZIP && formato != FormatoLotePDF {
+		return ErrFormatoLote
+	}
+
+	// Sin orden explícito los reportes van del más antiguo al más reciente, como se archivan
+	if consulta.Orden == "" {
+		consulta.Orden = "fecha_inicio"
+	}
+	ids, err := s.reporteRepo.FindIDs(consulta, s.maximo+1)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrLoteVacio
+	}
+	if len(ids) > s.maximo {
+		return fmt.Errorf("%w (%d); acote las fechas o los filtros", ErrLoteExcedido, s.maximo)
+	}
+
+	if progreso == nil {
+		progreso = func(int) {}
+	}
+	if formato == FormatoLoteZIP {
+		return s.escribirZIP(ctx, ids, usuarioID, w, progreso)
+	}
+	return s.escribirPDFUnido(ctx, ids, usuarioID, w, progreso)
+}
+
+// escribirZIP agrega cada PDF al ZIP apenas está listo. Los PDF ya vienen comprimidos,
+// así que se guardan sin volver a comprimir.
+func (s *loteReportesService) escribirZIP(ctx context.Context, ids []uint, usuarioID uint, w io.Writer, progreso func(int)) error {
+	zw := zip.NewWriter(w)
+	generado := time.Now()
+	err := s.generar(ctx, ids, usuarioID, progreso, func(id uint, pdfBytes []byte) error {
+		archivo, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     fmt.Sprintf("reporte_servicio_%d.pdf", id),
+			Method:   zip.Store,
+			Modified: generado,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = archivo.Write(pdfBytes)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// escribirPDFUnido junta los PDF en un solo documento, en el orden de ids
+func (s *loteReportesService) escribirPDFUnido(ctx context.Context, ids []uint, usuarioID uint, w io.Writer, progreso func(int)) error {
+	documentos := make([]io.ReadSeeker, 0, len(ids))
+	err := s.generar(ctx, ids, usuarioID, progreso, func(_ uint, pdfBytes []byte) error {
+		documentos = append(documentos, bytes.NewReader(pdfBytes))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := api.MergeRaw(documentos, w, false, model.NewDefaultConfiguration()); err != nil {
+		return fmt.Errorf("error uniendo los PDF: %w", err)
+	}
+	return nil
+}
+
+// resultadoLote es el PDF generado de un reporte del lote
+type resultadoLote struct {
+	datos []byte
+	err   error
+}
+
+// generar reparte los reportes entre los trabajadores y llama a procesar con cada PDF en
+// el orden de ids. Como mucho hay dos PDF por trabajador generados sin procesar, de modo
+// que un cliente lento frena la generación en vez de acumular el lote en memoria.
+// Se detiene con el primer error o si se cancela ctx.
+func (s *loteReportesService) generar(ctx context.Context, ids []uint, usuarioID uint, progreso func(int), procesar func(id uint, pdfBytes []byte) error) error {
+	ctx, cancelar := context.WithCancel(ctx)
+	defer cancelar()
+
+	resultados := make([]chan resultadoLote, len(ids))
+	for i := range resultados {
+		resultados[i] = make(chan resultadoLote, 1)
+	}
+	cupos := make(chan struct{}, 2*s.trabajadores)
+	trabajos := make(chan int)
+
+	go func() {
+		defer close(trabajos)
+		for i := range ids {
+			select {
+			case cupos <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case trabajos <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for range min(s.trabajadores, len(ids)) {
+		go func() {
+			for i := range trabajos {
+				datos, err := s.pdfService.GenerarPDFReporte(ids[i], usuarioID)
+				resultados[i] <- resultadoLote{datos: datos, err: err}
+			}
+		}()
+	}
+
+	for i, id := range ids {
+		var resultado resultadoLote
+		select {
+		case resultado = <-resultados[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		<-cupos
+		if resultado.err != nil {
+			return fmt.Errorf("error generando el PDF del reporte %d: %w", id, resultado.err)
+		}
+		if err := procesar(id, resultado.datos); err != nil {
+			return err
+		}
+		progreso((i + 1) * 100 / len(ids))
+	}
+	return nil
+}
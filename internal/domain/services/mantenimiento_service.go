@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// Límites de los planes de mantenimiento y de la vista de pendientes
+const (
+	maxIntervaloMeses = 120
+
+	// DiasAnticipacionMantenimiento es la ventana por defecto en la que un mantenimiento
+	// cuenta como próximo
+	DiasAnticipacionMantenimiento = 30
+	maxDiasAnticipacion           = 365
+)
+
+// Errores de los planes de mantenimiento
+var (
+	ErrPlanMantenimientoInvalido     = errors.New("plan de mantenimiento inválido")
+	ErrPlanMantenimientoNoEncontrado = errors.New("plan de mantenimiento no encontrado")
+	ErrPlanMantenimientoDuplicado    = errors.New("ya existe un plan de mantenimiento para ese equipo o tipo de dispositivo")
+	ErrDiasAnticipacionMantenimiento = fmt.Errorf("dias debe estar entre 0 y %d", maxDiasAnticipacion)
+)
+
+// tiposDispositivoPlan son los valores de Equipo.TipoDispositivo que admite un plan por tipo
+var tiposDispositivoPlan = []string{"Todo en Uno", "Escritorio", "Portátil", "Impresora", "Escáner", "Otro"}
+
+// MantenimientoService administra los planes de mantenimiento preventivo y calcula cuándo
+// le toca el siguiente a cada equipo
+type MantenimientoService interface {
+	GetPlanes() ([]models.PlanMantenimiento, error)
+	GetPlan(id uint) (*models.PlanMantenimiento, error)
+	CreatePlan(ctx context.Context, plan *models.PlanMantenimiento) error
+	UpdatePlan(ctx context.Context, plan *models.PlanMantenimiento) error
+	DeletePlan(ctx context.Context, id uint) error
+	CalcularEquipos(dias int) ([]dto.EquipoMantenimientoDTO, error)
+	GetPendientes(dias int, dependenciaID *uint) ([]dto.MantenimientosDependenciaDTO, error)
+}
+
+// mantenimientoService implementa MantenimientoService
+type mantenimientoService struct {
+	repo       repositories.PlanMantenimientoRepository
+	equipoRepo repositories.EquipoRepository
+}
+
+// NewMantenimientoService crea una nueva instancia de MantenimientoService
+func NewMantenimientoService(repo repositories.PlanMantenimientoRepository, equipoRepo repositories.EquipoRepository) MantenimientoService {
+	return &mantenimientoService{repo: repo, equipoRepo: equipoRepo}
+}
+
+// GetPlanes obtiene todos los planes, activos e inactivos
+func (s *mantenimientoService) GetPlanes() ([]models.PlanMantenimiento, error) {
+	return s.repo.FindAll()
+}
+
+// GetPlan obtiene un plan por su ID
+func (s *mantenimientoService) GetPlan(id uint) (*models.PlanMantenimiento, error) {
+	plan, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPlanMantenimientoNoEncontrado
+	}
+	return plan, err
+}
+
+// CreatePlan crea un plan para un tipo de dispositivo o para un equipo
+func (s *mantenimientoService) CreatePlan(ctx context.Context, plan *models.PlanMantenimiento) error {
+	if err := s.validarPlan(plan); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, plan)
+}
+
+// UpdatePlan cambia el alcance, el intervalo o el estado de un plan
+func (s *mantenimientoService) UpdatePlan(ctx context.Context, plan *models.PlanMantenimiento) error {
+	if _, err := s.GetPlan(plan.ID); err != nil {
+		return err
+	}
+	if err := s.validarPlan(plan); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, plan)
+}
+
+// DeletePlan elimina un plan; los equipos que cubría quedan con el plan de su tipo, si lo hay
+func (s *mantenimientoService) DeletePlan(ctx context.Context, id uint) error {
+	if _, err := s.GetPlan(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// validarPlan revisa que el plan sea de un tipo de dispositivo o de un equipo existente, y
+// que no haya otro plan con el mismo alcance
+func (s *mantenimientoService) validarPlan(plan *models.PlanMantenimiento) error {
+	invalido := func(formato string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrPlanMantenimientoInvalido, fmt.Sprintf(formato, args...))
+	}
+
+	if plan.IntervaloMeses < 1 || plan.IntervaloMeses > maxIntervaloMeses {
+		return invalido("el intervalo debe estar entre 1 y %d meses", maxIntervaloMeses)
+	}
+	switch {
+	case plan.EquipoID != nil && plan.TipoDispositivo != "":
+		return invalido("indique el equipo o el tipo de dispositivo, no ambos")
+	case plan.EquipoID != nil:
+		if _, err := s.equipoRepo.FindByID(*plan.EquipoID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return invalido("el equipo %d no existe", *plan.EquipoID)
+			}
+			return err
+		}
+	case plan.TipoDispositivo != "":
+		valido := false
+		for _, tipo := range tiposDispositivoPlan {
+			valido = valido || tipo == plan.TipoDispositivo
+		}
+		if !valido {
+			return invalido("tipo de dispositivo desconocido: %s", plan.TipoDispositivo)
+		}
+	default:
+		return invalido("indique el equipo o el tipo de dispositivo")
+	}
+
+	duplicado, err := s.repo.ExisteDuplicado(plan)
+	if err != nil {
+		return err
+	}
+	if duplicado {
+		return ErrPlanMantenimientoDuplicado
+	}
+	return nil
+}
+
+// CalcularEquipos retorna los equipos con un plan activo y la fecha de su próximo
+// mantenimiento: la del último reporte PREVENTIVO más el intervalo del plan o, si nunca lo
+// han tenido, la de registro del equipo más el intervalo. Los equipos sin plan no aparecen.
+func (s *mantenimientoService) CalcularEquipos(dias int) ([]dto.EquipoMantenimientoDTO, error) {
+	if dias < 0 || dias > maxDiasAnticipacion {
+		return nil, ErrDiasAnticipacionMantenimiento
+	}
+
+	planes, err := s.repo.FindActivos()
+	if err != nil {
+		return nil, err
+	}
+	if len(planes) == 0 {
+		return []dto.EquipoMantenimientoDTO{}, nil
+	}
+	planesEquipo := make(map[uint]models.PlanMantenimiento)
+	planesTipo := make(map[string]models.PlanMantenimiento)
+	for _, plan := range planes {
+		if plan.EquipoID != nil {
+			planesEquipo[*plan.EquipoID] = plan
+		} else {
+			planesTipo[plan.TipoDispositivo] = plan
+		}
+	}
+
+	preventivos, err := s.repo.FindUltimosPreventivos()
+	if err != nil {
+		return nil, err
+	}
+	ultimos := make(map[uint]dto.PreventivoEquipoDTO, len(preventivos))
+	for _, preventivo := range preventivos {
+		ultimos[preventivo.EquipoID] = preventivo
+	}
+
+	equipos, err := s.repo.FindEquipos()
+	if err != nil {
+		return nil, err
+	}
+
+	// Se compara por días calendario para que un mantenimiento que vence hoy no cuente como vencido
+	anio, mes, dia := time.Now().Date()
+	hoy := time.Date(anio, mes, dia, 0, 0, 0, 0, time.Local)
+	resultado := make([]dto.EquipoMantenimientoDTO, 0, len(equipos))
+	for _, equipo := range equipos {
+		plan, ok := planesEquipo[equipo.EquipoID]
+		if !ok {
+			if plan, ok = planesTipo[equipo.TipoDispositivo]; !ok {
+				continue
+			}
+		}
+
+		base := equipo.FechaRegistro
+		if ultimo, ok := ultimos[equipo.EquipoID]; ok {
+			base = ultimo.Fecha
+			equipo.UltimoPreventivo = &ultimo.Fecha
+			equipo.UltimoReporteID = &ultimo.ReporteID
+		}
+		anio, mes, dia := base.In(time.Local).Date()
+		equipo.PlanID = plan.ID
+		equipo.IntervaloMeses = plan.IntervaloMeses
+		equipo.ProximoMantenimiento = time.Date(anio, mes, dia, 0, 0, 0, 0, time.Local).AddDate(0, plan.IntervaloMeses, 0)
+		equipo.DiasParaMantenimiento = int(equipo.ProximoMantenimiento.Sub(hoy).Round(24*time.Hour).Hours() / 24)
+		switch {
+		case equipo.DiasParaMantenimiento < 0:
+			equipo.Estado = models.MantenimientoVencido
+		case equipo.DiasParaMantenimiento <= dias:
+			equipo.Estado = models.MantenimientoProximo
+		default:
+			equipo.Estado = models.MantenimientoAlDia
+		}
+		resultado = append(resultado, equipo)
+	}
+	return resultado, nil
+}
+
+// GetPendientes agrupa por dependencia los equipos con mantenimiento vencido o que vence en
+// los próximos dias. Los equipos de cada grupo van del más atrasado al más holgado; los
+// grupos, de los que tienen más vencidos a los que menos, y al final los equipos sin dependencia.
+func (s *mantenimientoService) GetPendientes(dias int, dependenciaID *uint) ([]dto.MantenimientosDependenciaDTO, error) {
+	equipos, err := s.CalcularEquipos(dias)
+	if err != nil {
+		return nil, err
+	}
+
+	grupos := []dto.MantenimientosDependenciaDTO{}
+	indices := make(map[uint]int) // dependencia → posición en grupos; 0 = sin dependencia
+	for _, equipo := range equipos {
+		if equipo.Estado == models.MantenimientoAlDia {
+			continue
+		}
+		if dependenciaID != nil && (equipo.DependenciaID == nil || *equipo.DependenciaID != *dependenciaID) {
+			continue
+		}
+
+		var clave uint
+		if equipo.DependenciaID != nil {
+			clave = *equipo.DependenciaID
+		}
+		i, ok := indices[clave]
+		if !ok {
+			grupo := dto.MantenimientosDependenciaDTO{DependenciaID: equipo.DependenciaID, Dependencia: "Sin dependencia"}
+			if equipo.Dependencia != nil {
+				grupo.Dependencia = *equipo.Dependencia
+			}
+			grupos = append(grupos, grupo)
+			i = len(grupos) - 1
+			indices[clave] = i
+		}
+		if equipo.Estado == models.MantenimientoVencido {
+			grupos[i].Vencidos++
+		} else {
+			grupos[i].Proximos++
+		}
+		grupos[i].Equipos = append(grupos[i].Equipos, equipo)
+	}
+
+	for _, grupo := range grupos {
+		sort.SliceStable(grupo.Equipos, func(a, b int) bool {
+			return grupo.Equipos[a].DiasParaMantenimiento < grupo.Equipos[b].DiasParaMantenimiento
+		})
+	}
+	sort.SliceStable(grupos, func(a, b int) bool {
+		if (grupos[a].DependenciaID == nil) != (grupos[b].DependenciaID == nil) {
+			return grupos[b].DependenciaID == nil
+		}
+		if grupos[a].Vencidos != grupos[b].Vencidos {
+			return grupos[a].Vencidos > grupos[b].Vencidos
+		}
+		return grupos[a].Dependencia < grupos[b].Dependencia
+	})
+	return grupos, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"tum_inv_backend/internal/domain/models"
+)
+
+// datosCorreoReporte es el contenido de un correo sobre un reporte de servicio
+type datosCorreoReporte struct {
+	Asunto       string
+	Titulo       string
+	Introduccion string
+	Filas        []filaCorreo
+	URLSistema   string
+}
+
+// filaCorreo es un dato del reporte que se muestra en el correo
+type filaCorreo struct {
+	Etiqueta string
+	Valor    string
+}
+
+// plantillaCorreoHTML es el diseño de los correos; los estilos van en línea porque muchos
+// clientes de correo ignoran las hojas de estilo
+var plantillaCorreoHTML = htmltemplate.Must(htmltemplate.New("correo").Parse(`<!DOCTYPE html>
+<html lang="es">
+<head><meta charset="UTF-8"><title>{{.Asunto}}</title></head>
+<body style="margin:0;padding:24px;background:#f3f4f6;font-family:Arial,Helvetica,sans-serif;color:#1f2937;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="max-width:600px;margin:0 auto;background:#ffffff;border-radius:6px;">
+<tr><td style="padding:20px 24px;background:#1e3a8a;color:#ffffff;border-radius:6px 6px 0 0;">
+<div style="font-size:13px;">Alcaldía de Tumaco - Oficina de Sistemas</div>
+<div style="font-size:20px;font-weight:bold;margin-top:4px;">{{.Titulo}}</div>
+</td></tr>
+<tr><td style="padding:24px;">
+<p style="margin:0 0 16px 0;font-size:14px;line-height:1.5;">{{.Introduccion}}</p>
+<table role="presentation" width="100%" cellpadding="6" cellspacing="0" style="border-collapse:collapse;font-size:14px;">
+{{range .Filas}}<tr>
+<td style="border-bottom:1px solid #e5e7eb;font-weight:bold;width:40%;vertical-align:top;">{{.Etiqueta}}</td>
+<td style="border-bottom:1px solid #e5e7eb;vertical-align:top;">{{.Valor}}</td>
+</tr>
+{{end}}</table>
+{{if .URLSistema}}<p style="margin:24px 0 0 0;"><a href="{{.URLSistema}}" style="background:#1e3a8a;color:#ffffff;padding:10px 16px;border-radius:4px;text-decoration:none;font-size:14px;">Ir al sistema de inventario</a></p>
+{{end}}</td></tr>
+<tr><td style="padding:16px 24px;font-size:12px;color:#6b7280;border-top:1px solid #e5e7eb;">
+Este correo fue enviado automáticamente por el sistema de inventario tecnológico. Por favor no lo responda.
+</td></tr>
+</table>
+</body>
+</html>
+`))
+
+// plantillaCorreoTexto es la versión en texto plano, para clientes que no muestran HTML
+var plantillaCorreoTexto = texttemplate.Must(texttemplate.New("correo").Parse(`{{.Titulo}}
+
+{{.Introduccion}}
+
+{{range .Filas}}{{.Etiqueta}}: {{.Valor}}
+{{end}}{{if .URLSistema}}
+Sistema de inventario: {{.URLSistema}}
+{{end}}
+--
+Alcaldía de Tumaco - Oficina de Sistemas
+Este correo fue enviado automáticamente. Por favor no lo responda.
+`))
+
+// renderizarCorreo genera las versiones HTML y de texto del correo
+func renderizarCorreo(datos datosCorreoReporte) (string, string, error) {
+	var html, texto bytes.Buffer
+	if err := plantillaCorreoHTML.Execute(&html, datos); err != nil {
+		return "", "", err
+	}
+	if err := plantillaCorreoTexto.Execute(&texto, datos); err != nil {
+		return "", "", err
+	}
+	return html.String(), texto.String(), nil
+}
+
+// filasReporte son los datos del reporte comunes a todos los correos
+func filasReporte(reporte *models.ReporteServicio) []filaCorreo {
+	equipo := strings.TrimSpace(reporte.Equipo.Marca + " " + reporte.Equipo.Modelo)
+	if reporte.Equipo.PlacaInventario != "" {
+		equipo += " - Placa " + reporte.Equipo.PlacaInventario
+	}
+	if reporte.Equipo.Serial != "" {
+		equipo += " - Serial " + reporte.Equipo.Serial
+	}
+
+	filas := []filaCorreo{
+		{"Reporte N.º", strconv.FormatUint(uint64(reporte.ID), 10)},
+		{"Equipo", equipo},
+		{"Dependencia", reporte.Dependencia},
+		{"Ubicación", reporte.Ubicacion},
+		{"Fecha de inicio", reporte.FechaInicio.Format("02/01/2006")},
+	}
+	if reporte.Equipo.UsuarioResponsable != nil {
+		filas = append(filas, filaCorreo{"Responsable del equipo", reporte.Equipo.UsuarioResponsable.NombresApellidos})
+	}
+	if reporte.ActividadRealizada != "" {
+		filas = append(filas, filaCorreo{"Actividad realizada", reporte.ActividadRealizada})
+	}
+	if nombre := strings.TrimSpace(reporte.CreadoPor.Nombre + " " + reporte.CreadoPor.Apellido); nombre != "" {
+		filas = append(filas, filaCorreo{"Técnico", nombre})
+	}
+	return filas
+}
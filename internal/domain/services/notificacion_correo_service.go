@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
+	"tum_inv_backend/internal/infrastructure/config"
+	"tum_inv_backend/internal/infrastructure/correo"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// arriendoCorreo es el tiempo que un trabajador tiene un correo antes de que otro lo retome
+	arriendoCorreo = 2 * time.Minute
+	// esperaReintentoCorreo es la espera antes del segundo envío; se duplica en cada intento
+	esperaReintentoCorreo = time.Minute
+	// retencionCorreos es el tiempo que se conservan los correos enviados
+	retencionCorreos = 30 * 24 * time.Hour
+)
+
+// OpcionesCorreos son los parámetros de las notificaciones por correo
+type OpcionesCorreos struct {
+	Intentos   int
+	URLSistema string // Enlace al sistema que se incluye en los correos
+}
+
+// OpcionesCorreosDesde toma los parámetros de las notificaciones de la configuración
+func OpcionesCorreosDesde(cfg *config.Config) OpcionesCorreos {
+	return OpcionesCorreos{Intentos: cfg.CorreosIntentos, URLSistema: cfg.FrontendURL}
+}
+
+// NotificacionCorreoService avisa por correo de los cambios en los reportes de servicio. El
+// correo se guarda en la bandeja de salida con el ctx de la operación que lo genera, para que
+// quede en su misma transacción (ver repositories.Transacciones): si el correo no se puede
+// preparar, la operación se revierte y no se pierde el aviso.
+type NotificacionCorreoService interface {
+	ReporteCreado(ctx context.Context, reporteID uint) error
+	ReporteCerrado(ctx context.Context, reporteID uint) error
+	ReporteReabierto(ctx context.Context, reporteID uint, motivo string) error
+	// Iniciar arranca el envío de la bandeja de salida; se detiene al cancelar ctx
+	Iniciar(ctx context.Context)
+}
+
+// notificacionCorreoService implementa NotificacionCorreoService
+type notificacionCorreoService struct {
+	correoRepo repositories.CorreoSalienteRepository
+	remitente  correo.Remitente
+	opciones   OpcionesCorreos
+}
+
+// NewNotificacionCorreoService crea una nueva instancia de NotificacionCorreoService. Con
+// remitente nil (SMTP no configurado) los avisos se descartan.
+func NewNotificacionCorreoService(
+	correoRepo repositories.CorreoSalienteRepository,
+	remitente correo.Remitente,
+	opciones OpcionesCorreos,
+) NotificacionCorreoService {
+	return &notificacionCorreoService{
+		correoRepo: correoRepo,
+		remitente:  remitente,
+		opciones:   opciones,
+	}
+}
+
+// ReporteCreado avisa que se registró un reporte de servicio
+func (s *notificacionCorreoService) ReporteCreado(ctx context.Context, reporteID uint) error {
+	return s.notificar(ctx, models.CorreoReporteCreado, reporteID, func(reporte *models.ReporteServicio) datosCorreoReporte {
+		return datosCorreoReporte{
+			Asunto:       fmt.Sprintf("Reporte de servicio N.º %d registrado", reporte.ID),
+			Titulo:       "Nuevo reporte de servicio",
+			Introduccion: "Se registró un reporte de servicio técnico sobre un equipo a su cargo o de su dependencia.",
+			Filas:        filasReporte(reporte),
+		}
+	})
+}
+
+// ReporteCerrado avisa que se subió el PDF firmado y el reporte quedó cerrado
+func (s *notificacionCorreoService) ReporteCerrado(ctx context.Context, reporteID uint) error {
+	return s.notificar(ctx, models.CorreoReporteCerrado, reporteID, func(reporte *models.ReporteServicio) datosCorreoReporte {
+		filas := filasReporte(reporte)
+		if reporte.FechaCierre != nil {
+			filas = append(filas, filaCorreo{"Fecha de cierre", reporte.FechaCierre.Format("02/01/2006 15:04")})
+		}
+		return datosCorreoReporte{
+			Asunto:       fmt.Sprintf("Reporte de servicio N.º %d cerrado", reporte.ID),
+			Titulo:       "Reporte de servicio cerrado",
+			Introduccion: "Se recibió el documento firmado del reporte de servicio y el reporte quedó cerrado.",
+			Filas:        filas,
+		}
+	})
+}
+
+// ReporteReabierto avisa que un reporte cerrado se reabrió, con el motivo y quién lo reabrió
+func (s *notificacionCorreoService) ReporteReabierto(ctx context.Context, reporteID uint, motivo string) error {
+	return s.notificar(ctx, models.CorreoReporteReabierto, reporteID, func(reporte *models.ReporteServicio) datosCorreoReporte {
+		filas := append(filasReporte(reporte), filaCorreo{"Motivo de la reapertura", motivo})
+		if actor, ok := auditoria.ActorDesde(ctx); ok {
+			filas = append(filas, filaCorreo{"Reabierto por", actor.Username})
+		}
+		return datosCorreoReporte{
+			Asunto:       fmt.Sprintf("Reporte de servicio N.º %d reabierto", reporte.ID),
+			Titulo:       "Reporte de servicio reabierto",
+			Introduccion: "El reporte de servicio se reabrió y deberá firmarse de nuevo. El documento firmado anterior se conserva en el historial.",
+			Filas:        filas,
+		}
+	})
+}
+
+// notificar arma el correo del evento y lo deja en la bandeja de salida
+func (s *notificacionCorreoService) notificar(ctx context.Context, evento string, reporteID uint, armar func(*models.ReporteServicio) datosCorreoReporte) error {
+	if s.remitente == nil {
+		return nil
+	}
+	if err := s.encolar(ctx, evento, reporteID, armar); err != nil {
+		return fmt.Errorf("no se pudo preparar el correo %s del reporte %d: %w", evento, reporteID, err)
+	}
+	return nil
+}
+
+// encolar resuelve los destinatarios del reporte, arma el correo y lo guarda como pendiente
+func (s *notificacionCorreoService) encolar(ctx context.Context, evento string, reporteID uint, armar func(*models.ReporteServicio) datosCorreoReporte) error {
+	reporte, err := s.correoRepo.FindReporte(ctx, reporteID)
+	if err != nil {
+		return err
+	}
+	destinatarios, err := s.destinatarios(reporte)
+	if err != nil {
+		return err
+	}
+	if len(destinatarios) == 0 {
+		log.Printf("El reporte %d no tiene destinatarios para el correo %s", reporteID, evento)
+		return nil
+	}
+
+	datos := armar(reporte)
+	datos.URLSistema = s.opciones.URLSistema
+	html, texto, err := renderizarCorreo(datos)
+	if err != nil {
+		return err
+	}
+	return s.correoRepo.Create(ctx, &models.CorreoSaliente{
+		Evento:        evento,
+		ReporteID:     &reporte.ID,
+		Destinatarios: strings.Join(destinatarios, ","),
+		Asunto:        datos.Asunto,
+		HTML:          html,
+		Texto:         texto,
+		Estado:        models.CorreoPendiente,
+		MaxIntentos:   s.opciones.Intentos,
+		DisponibleEn:  time.Now(),
+	})
+}
+
+// destinatarios reúne el correo institucional de la dependencia, el personal del responsable
+// del equipo y el de quien creó el reporte, sin repetidos ni direcciones inválidas. La
+// dependencia es la del responsable o, si el equipo no tiene, la registrada en el reporte.
+func (s *notificacionCorreoService) destinatarios(reporte *models.ReporteServicio) ([]string, error) {
+	responsable := reporte.Equipo.UsuarioResponsable
+	var dependenciaID *uint
+	if responsable != nil {
+		dependenciaID = responsable.DependenciaID
+	}
+	correoDependencia, err := s.correoRepo.CorreoDependencia(dependenciaID, reporte.Dependencia)
+	if err != nil {
+		return nil, err
+	}
+
+	direcciones := []string{correoDependencia, reporte.CreadoPor.Email}
+	if responsable != nil {
+		direcciones = append(direcciones, responsable.CorreoPersonal)
+	}
+
+	vistas := make(map[string]bool)
+	var destinatarios []string
+	for _, direccion := range direcciones {
+		direccion = strings.TrimSpace(direccion)
+		clave := strings.ToLower(direccion)
+		if direccion == "" || vistas[clave] {
+			continue
+		}
+		vistas[clave] = true
+		if !correo.DireccionValida(direccion) {
+			log.Printf("Se omite la dirección de correo inválida %q del reporte %d", direccion, reporte.ID)
+			continue
+		}
+		destinatarios = append(destinatarios, direccion)
+	}
+	return destinatarios, nil
+}
+
+// Iniciar arranca el trabajador que envía la bandeja de salida y la limpieza de los enviados
+func (s *notificacionCorreoService) Iniciar(ctx context.Context) {
+	if s.remitente == nil {
+		return
+	}
+	host, _ := os.Hostname()
+	go s.enviarPendientes(ctx, fmt.Sprintf("%s-%d-correos", host, os.Getpid()))
+	go s.limpiar(ctx)
+}
+
+// enviarPendientes envía los correos de la bandeja hasta que se cancele ctx
+func (s *notificacionCorreoService) enviarPendientes(ctx context.Context, trabajador string) {
+	for ctx.Err() == nil {
+		saliente, err := s.correoRepo.Arrendar(ctx, trabajador, arriendoCorreo)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) && ctx.Err() == nil {
+				log.Printf("Error tomando correos de la bandeja de salida: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(intervaloCola):
+			}
+			continue
+		}
+		s.enviar(ctx, trabajador, saliente)
+	}
+}
+
+// enviar entrega el correo al servidor SMTP y guarda el resultado. Un error programa otro
+// envío, con una espera que se duplica, salvo que el servidor lo rechace o se agoten los intentos.
+func (s *notificacionCorreoService) enviar(ctx context.Context, trabajador string, saliente *models.CorreoSaliente) {
+	err := s.remitente.Enviar(ctx, correo.Mensaje{
+		Para:   strings.Split(saliente.Destinatarios, ","),
+		Asunto: saliente.Asunto,
+		HTML:   saliente.HTML,
+		Texto:  saliente.Texto,
+	})
+	if ctx.Err() != nil {
+		return
+	}
+
+	ahora := time.Now()
+	saliente.ArrendadoHasta = nil
+	saliente.Trabajador = ""
+	switch {
+	case err == nil:
+		saliente.Estado = models.CorreoEnviado
+		saliente.EnviadoEn = &ahora
+	case correo.EsRechazo(err) || saliente.Intentos >= saliente.MaxIntentos:
+		saliente.Estado = models.CorreoFallido
+		saliente.Error = err.Error()
+		log.Printf("No se pudo enviar el correo %d (%s): %v", saliente.ID, saliente.Evento, err)
+	default:
+		saliente.Estado = models.CorreoPendiente
+		saliente.Error = err.Error()
+		saliente.DisponibleEn = ahora.Add(esperaReintentoCorreo << (saliente.Intentos - 1))
+	}
+
+	vigente, errGuardar := s.correoRepo.Finalizar(context.Background(), saliente, trabajador)
+	if errGuardar != nil || !vigente {
+		log.Printf("No se pudo guardar el resultado del correo %d (vigente: %t): %v", saliente.ID, vigente, errGuardar)
+	}
+}
+
+// limpiar elimina cada hora los correos enviados hace más de retencionCorreos
+func (s *notificacionCorreoService) limpiar(ctx context.Context) {
+	ticker := time.NewTicker(intervaloLimpieza)
+	defer ticker.Stop()
+	for {
+		if _, err := s.correoRepo.DeleteEnviadosAntes(ctx, time.Now().Add(-retencionCorreos)); err != nil && ctx.Err() == nil {
+			log.Printf("Error eliminando correos enviados: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
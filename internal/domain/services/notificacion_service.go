@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/config"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// intervaloRevisionNotificaciones es cada cuánto se buscan reportes sin firmar y
+	// mantenimientos vencidos
+	intervaloRevisionNotificaciones = time.Hour
+	// retencionNotificaciones es el tiempo que se conservan las notificaciones leídas
+	retencionNotificaciones = 90 * 24 * time.Hour
+	// maxNotificacionesNuevas limita las notificaciones que se envían de una vez por el stream
+	maxNotificacionesNuevas = 100
+)
+
+// ErrNotificacionNoEncontrada indica que la notificación no existe o es de otro usuario
+var ErrNotificacionNoEncontrada = errors.New("notificación no encontrada")
+
+// OpcionesNotificaciones son los parámetros de las revisiones periódicas
+type OpcionesNotificaciones struct {
+	DiasSinFirma int
+}
+
+// OpcionesNotificacionesDesde toma los parámetros de las notificaciones de la configuración
+func OpcionesNotificacionesDesde(cfg *config.Config) OpcionesNotificaciones {
+	return OpcionesNotificaciones{DiasSinFirma: cfg.NotificacionesDiasSinFirma}
+}
+
+// NotificacionService administra el centro de notificaciones de los usuarios. Los avisos de
+// una acción (asignaciones) se generan al ejecutarla; los de un plazo (reportes sin firmar,
+// mantenimientos vencidos) los genera una revisión periódica una sola vez.
+type NotificacionService interface {
+	GetAll(usuarioID uint, consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Notificacion], error)
+	ContarNoLeidas(usuarioID uint) (int64, error)
+	MarcarLeida(ctx context.Context, usuarioID, id uint) (*models.Notificacion, error)
+	MarcarTodasLeidas(ctx context.Context, usuarioID uint) (int64, error)
+	UltimoID(usuarioID uint) (uint, error)
+	Nuevas(usuarioID, despuesDeID uint) ([]models.Notificacion, error)
+	// Suscribir avisa por el canal cuando cambian las notificaciones del usuario en esta
+	// instancia; la función retornada cancela la suscripción
+	Suscribir(usuarioID uint) (<-chan struct{}, func())
+	SolicitudAsignada(ctx context.Context, solicitud *models.SolicitudServicio)
+	EquipoAsignado(ctx context.Context, asignacion *models.AsignacionEquipo)
+	// Iniciar arranca las revisiones periódicas; se detienen al cancelar ctx
+	Iniciar(ctx context.Context)
+}
+
+// notificacionService implementa NotificacionService
+type notificacionService struct {
+	repo                 repositories.NotificacionRepository
+	mantenimientoService MantenimientoService
+	opciones             OpcionesNotificaciones
+
+	mu           sync.Mutex
+	suscriptores map[uint]map[chan struct{}]struct{}
+}
+
+// NewNotificacionService crea una nueva instancia de NotificacionService
+func NewNotificacionService(
+	repo repositories.NotificacionRepository,
+	mantenimientoService MantenimientoService,
+	opciones OpcionesNotificaciones,
+) NotificacionService {
+	return &notificacionService{
+		repo:                 repo,
+		mantenimientoService: mantenimientoService,
+		opciones:             opciones,
+		suscriptores:         make(map[uint]map[chan struct{}]struct{}),
+	}
+}
+
+// GetAll obtiene una página de las notificaciones del usuario, las más recientes primero
+func (s *notificacionService) GetAll(usuarioID uint, consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Notificacion], error) {
+	registros, total, err := s.repo.FindByUsuario(usuarioID, consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.Notificacion]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
+}
+
+// ContarNoLeidas cuenta las notificaciones sin leer del usuario
+func (s *notificacionService) ContarNoLeidas(usuarioID uint) (int64, error) {
+	return s.repo.ContarNoLeidas(usuarioID)
+}
+
+// MarcarLeida marca como leída una notificación del usuario
+func (s *notificacionService) MarcarLeida(ctx context.Context, usuarioID, id uint) (*models.Notificacion, error) {
+	notificacion, err := s.repo.MarcarLeida(ctx, usuarioID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotificacionNoEncontrada
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.publicar(usuarioID)
+	return notificacion, nil
+}
+
+// MarcarTodasLeidas marca como leídas todas las notificaciones del usuario
+func (s *notificacionService) MarcarTodasLeidas(ctx context.Context, usuarioID uint) (int64, error) {
+	total, err := s.repo.MarcarTodasLeidas(ctx, usuarioID)
+	if err != nil {
+		return 0, err
+	}
+	s.publicar(usuarioID)
+	return total, nil
+}
+
+// UltimoID obtiene el ID de la notificación más reciente del usuario
+func (s *notificacionService) UltimoID(usuarioID uint) (uint, error) {
+	return s.repo.UltimoID(usuarioID)
+}
+
+// Nuevas obtiene las notificaciones del usuario posteriores al ID indicado
+func (s *notificacionService) Nuevas(usuarioID, despuesDeID uint) ([]models.Notificacion, error) {
+	return s.repo.FindNuevas(usuarioID, despuesDeID, maxNotificacionesNuevas)
+}
+
+// Suscribir registra un canal que recibe un aviso cada vez que se crea o se lee una
+// notificación del usuario. Los avisos no se acumulan: el canal guarda uno solo.
+func (s *notificacionService) Suscribir(usuarioID uint) (<-chan struct{}, func()) {
+	canal := make(chan struct{}, 1)
+	s.mu.Lock()
+	if s.suscriptores[usuarioID] == nil {
+		s.suscriptores[usuarioID] = make(map[chan struct{}]struct{})
+	}
+	s.suscriptores[usuarioID][canal] = struct{}{}
+	s.mu.Unlock()
+
+	return canal, func() {
+		s.mu.Lock()
+		delete(s.suscriptores[usuarioID], canal)
+		if len(s.suscriptores[usuarioID]) == 0 {
+			delete(s.suscriptores, usuarioID)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// publicar avisa a los suscriptores del usuario sin esperar a que lean el aviso
+func (s *notificacionService) publicar(usuarioID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for canal := range s.suscriptores[usuarioID] {
+		select {
+		case canal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// notificar guarda la notificación y avisa a los suscriptores. Un error solo se registra en
+// el log: la notificación no debe interrumpir la operación que la genera.
+func (s *notificacionService) notificar(ctx context.Context, notificacion *models.Notificacion) {
+	creada, err := s.repo.Create(ctx, notificacion)
+	if err != nil {
+		log.Printf("No se pudo crear la notificación %s para el usuario %d: %v", notificacion.Tipo, notificacion.UsuarioID, err)
+		return
+	}
+	if creada {
+		s.publicar(notificacion.UsuarioID)
+	}
+}
+
+// SolicitudAsignada avisa al técnico que se le asignó una solicitud de servicio
+func (s *notificacionService) SolicitudAsignada(ctx context.Context, solicitud *models.SolicitudServicio) {
+	if solicitud.TecnicoID == nil {
+		return
+	}
+	s.notificar(ctx, &models.Notificacion{
+		UsuarioID: *solicitud.TecnicoID,
+		Tipo:      models.NotificacionSolicitudAsignada,
+		Titulo:    fmt.Sprintf("Se le asignó la solicitud N.º %d", solicitud.ID),
+		Mensaje:   fmt.Sprintf("Equipo %s, prioridad %s: %s", identificarEquipo(&solicitud.Equipo), solicitud.Prioridad, solicitud.Descripcion),
+		Recurso:   "solicitudes-servicio",
+		RecursoID: &solicitud.ID,
+	})
+}
+
+// EquipoAsignado avisa al nuevo responsable del equipo si tiene usuario en el sistema
+// (mismo número de cédula)
+func (s *notificacionService) EquipoAsignado(ctx context.Context, asignacion *models.AsignacionEquipo) {
+	if asignacion.ResponsableNuevoID == nil {
+		return
+	}
+	usuarioID, err := s.repo.UsuarioDeResponsable(*asignacion.ResponsableNuevoID)
+	if err != nil {
+		log.Printf("No se pudo buscar el usuario del responsable %d: %v", *asignacion.ResponsableNuevoID, err)
+		return
+	}
+	if usuarioID == nil {
+		return
+	}
+	equipo, err := s.repo.FindEquipo(asignacion.EquipoID)
+	if err != nil {
+		log.Printf("No se pudo cargar el equipo %d para la notificación: %v", asignacion.EquipoID, err)
+		return
+	}
+	s.notificar(ctx, &models.Notificacion{
+		UsuarioID: *usuarioID,
+		Tipo:      models.NotificacionEquipoAsignado,
+		Titulo:    fmt.Sprintf("Se le asignó el equipo %s", identificarEquipo(equipo)),
+		Mensaje:   "Queda a su cargo desde " + asignacion.FechaInicio.Format("02/01/2006") + ". Revise y firme el acta de entrega.",
+		Recurso:   "equipos",
+		RecursoID: &asignacion.EquipoID,
+	})
+}
+
+// Iniciar arranca la revisión periódica de plazos y la limpieza de notificaciones leídas
+func (s *notificacionService) Iniciar(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(intervaloRevisionNotificaciones)
+		defer ticker.Stop()
+		for {
+			s.revisar(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// revisar genera los avisos de plazos vencidos. Cada aviso tiene una clave, de modo que
+// repetir la revisión, o hacerla en varias instancias a la vez, no lo duplica.
+func (s *notificacionService) revisar(ctx context.Context) {
+	if err := s.revisarReportesSinFirma(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("Error revisando reportes sin firmar: %v", err)
+	}
+	if err := s.revisarMantenimientos(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("Error revisando mantenimientos vencidos: %v", err)
+	}
+	if _, err := s.repo.DeleteLeidasAntes(ctx, time.Now().Add(-retencionNotificaciones)); err != nil && ctx.Err() == nil {
+		log.Printf("Error eliminando notificaciones leídas: %v", err)
+	}
+}
+
+// revisarReportesSinFirma avisa al técnico que creó cada reporte abierto hace más de
+// DiasSinFirma días
+func (s *notificacionService) revisarReportesSinFirma(ctx context.Context) error {
+	reportes, err := s.repo.FindReportesSinFirma(time.Now().AddDate(0, 0, -s.opciones.DiasSinFirma))
+	if err != nil {
+		return err
+	}
+	for i := range reportes {
+		reporte := &reportes[i]
+		clave := fmt.Sprintf("%s:%d", models.NotificacionReporteSinFirma, reporte.ID)
+		dias := int(time.Since(reporte.CreatedAt).Hours() / 24)
+		s.notificar(ctx, &models.Notificacion{
+			UsuarioID: reporte.CreadoPorID,
+			Tipo:      models.NotificacionReporteSinFirma,
+			Titulo:    fmt.Sprintf("El reporte N.º %d lleva %d días sin firmar", reporte.ID, dias),
+			Mensaje:   fmt.Sprintf("Equipo %s, dependencia %s. Suba el PDF firmado para cerrarlo.", identificarEquipo(&reporte.Equipo), reporte.Dependencia),
+			Recurso:   "reportes-servicio",
+			RecursoID: &reporte.ID,
+			Clave:     &clave,
+		})
+	}
+	return nil
+}
+
+// revisarMantenimientos avisa a los administradores y técnicos de cada equipo con el
+// mantenimiento preventivo vencido; un aviso por equipo y fecha de vencimiento
+func (s *notificacionService) revisarMantenimientos(ctx context.Context) error {
+	equipos, err := s.mantenimientoService.CalcularEquipos(0)
+	if err != nil {
+		return err
+	}
+	var usuarios []uint
+	for i := range equipos {
+		equipo := &equipos[i]
+		if equipo.Estado != models.MantenimientoVencido {
+			continue
+		}
+		if usuarios == nil {
+			if usuarios, err = s.repo.FindUsuariosGestion(); err != nil {
+				return err
+			}
+		}
+
+		dependencia := "sin dependencia"
+		if equipo.Dependencia != nil {
+			dependencia = *equipo.Dependencia
+		}
+		clave := fmt.Sprintf("%s:%d:%s", models.NotificacionMantenimientoVencido, equipo.EquipoID, equipo.ProximoMantenimiento.Format("2006-01-02"))
+		for _, usuarioID := range usuarios {
+			s.notificar(ctx, &models.Notificacion{
+				UsuarioID: usuarioID,
+				Tipo:      models.NotificacionMantenimientoVencido,
+				Titulo:    fmt.Sprintf("Mantenimiento preventivo vencido: %s", identificarEquipo(&models.Equipo{PlacaInventario: equipo.PlacaInventario, Serial: equipo.Serial})),
+				Mensaje:   fmt.Sprintf("Vencido desde el %s (%s).", equipo.ProximoMantenimiento.Format("02/01/2006"), dependencia),
+				Recurso:   "equipos",
+				RecursoID: &equipo.EquipoID,
+				Clave:     &clave,
+			})
+		}
+	}
+	return nil
+}
+
+// identificarEquipo es la placa del equipo o, si no tiene, el serial
+func identificarEquipo(equipo *models.Equipo) string {
+	if equipo.PlacaInventario != "" {
+		return equipo.PlacaInventario
+	}
+	if equipo.Serial != "" {
+		return "serial " + equipo.Serial
+	}
+	return fmt.Sprintf("N.º %d", equipo.ID)
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"tum_inv_backend/internal/domain/models"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ActaEntregaPDFData contiene los datos necesarios para generar el acta de entrega
+type ActaEntregaPDFData struct {
+	Asignacion         models.AsignacionEquipo
+	Equipo             models.Equipo
+	ResponsableEntrega *models.UsuarioResponsable
+	ResponsableRecibe  *models.UsuarioResponsable
+	DependenciaRecibe  string
+	Tecnico            *models.Usuario
+	Componentes        *models.ComponentesActa
+}
+
+// GenerarPDFActaEntrega genera el acta de entrega de un equipo para una asignación. Usa los
+// componentes fijados en la asignación o, si aún no los tiene, los actuales del equipo.
+func (s *PDFReporteService) GenerarPDFActaEntrega(asignacionID uint) ([]byte, error) {
+	var asignacion models.AsignacionEquipo
+	if err := s.db.Preload("ResponsableAnterior").
+		Preload("ResponsableNuevo").
+		Preload("Equipo").
+		Preload("Equipo.EstadoEquipo").
+		Preload("Equipo.Perifericos").
+		Preload("Equipo.HardwareInterno").
+		Preload("Equipo.Software").
+		First(&asignacion, asignacionID).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo asignación: %w", err)
+	}
+
+	data := ActaEntregaPDFData{
+		Asignacion:         asignacion,
+		Equipo:             asignacion.Equipo,
+		ResponsableEntrega: asignacion.ResponsableAnterior,
+		ResponsableRecibe:  asignacion.ResponsableNuevo,
+		Componentes:        asignacion.Componentes,
+	}
+	if data.Componentes == nil {
+		data.Componentes = models.NuevosComponentesActa(&asignacion.Equipo)
+	}
+
+	if asignacion.ResponsableNuevo != nil && asignacion.ResponsableNuevo.DependenciaID != nil {
+		var dependencia models.Dependencia
+		if err := s.db.First(&dependencia, *asignacion.ResponsableNuevo.DependenciaID).Error; err == nil {
+			data.DependenciaRecibe = dependencia.Nombre
+		}
+	}
+
+	if asignacion.RealizadoPorID != nil {
+		var tecnico models.Usuario
+		if err := s.db.First(&tecnico, *asignacion.RealizadoPorID).Error; err == nil {
+			data.Tecnico = &tecnico
+		}
+	}
+
+	plantilla, err := s.plantillaActiva()
+	if err != nil {
+		return nil, err
+	}
+	return s.crearPDFActaEntrega(data, plantilla)
+}
+
+func (s *PDFReporteService) crearPDFActaEntrega(data ActaEntregaPDFData, plantilla *models.DefinicionPlantilla) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetMargins(marginLeft, 15, marginRight)
+	// Margen inferior amplio para no escribir sobre el pie de página
+	pdf.SetAutoPageBreak(true, 40)
+	if err := s.registrarLogos(pdf, plantilla); err != nil {
+		return nil, err
+	}
+
+	// El acta puede ocupar varias páginas según el número de componentes
+	pdf.SetHeaderFunc(func() {
+		s.agregarMarcaAgua(pdf, plantilla)
+		s.agregarEncabezado(pdf, plantilla, models.DocumentoActaEntrega)
+	})
+	pdf.SetFooterFunc(func() {
+		s.agregarPiePagina(pdf, plantilla)
+	})
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.AddPage()
+	s.agregarTitulo(pdf, "ACTA DE ENTREGA DE EQUIPO TECNOLOGICO")
+	s.agregarIntroduccionActa(pdf, tr, data)
+	s.agregarDatosEquipoActa(pdf, tr, data.Componentes)
+	s.agregarTablaSeccion(pdf, tr, "PERIFERICOS",
+		[]string{"TIPO", "MARCA", "SERIAL", "PLACA INVENTARIO"},
+		[]float64{40, 48, 50, 48},
+		filasPerifericos(data.Componentes.Perifericos))
+	s.agregarTablaSeccion(pdf, tr, "HARDWARE INTERNO",
+		[]string{"COMPONENTE", "TECNOLOGIA", "CAPACIDAD"},
+		[]float64{62, 62, 62},
+		filasHardware(data.Componentes.HardwareInterno))
+	s.agregarTablaSeccion(pdf, tr, "SOFTWARE INSTALADO",
+		[]string{"NOMBRE", "VERSION", "LICENCIA", "CATEGORIA"},
+		[]float64{60, 36, 40, 50},
+		filasSoftware(data.Componentes.Software))
+	s.agregarMotivoActa(pdf, tr, data.Asignacion.Motivo)
+	s.agregarFirmasActa(pdf, tr, data)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("error generando PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *PDFReporteService) agregarIntroduccionActa(pdf *fpdf.Fpdf, tr func(string) string, data ActaEntregaPDFData) {
+	recibe := "sin responsable asignado"
+	if data.ResponsableRecibe != nil {
+		recibe = fmt.Sprintf("%s, identificado(a) con C.C. %s", data.ResponsableRecibe.NombresApellidos, data.ResponsableRecibe.Cedula)
+		if data.DependenciaRecibe != "" {
+			recibe += ", de la dependencia " + data.DependenciaRecibe
+		}
+	}
+
+	texto := fmt.Sprintf(
+		"Acta No. %d. En Tumaco, el %s, la Oficina de Sistemas hace entrega del equipo tecnologico y sus componentes descritos a continuacion a %s, quien a partir de la fecha asume su custodia y buen uso.",
+		data.Asignacion.ID,
+		data.Asignacion.FechaInicio.Format("02/01/2006"),
+		recibe,
+	)
+
+	pdf.SetFont("Arial", "", 9)
+	pdf.MultiCell(contentWidth, 5, tr(texto), "", "J", false)
+	pdf.Ln(4)
+}
+
+func (s *PDFReporteService) agregarDatosEquipoActa(pdf *fpdf.Fpdf, tr func(string) string, equipo *models.ComponentesActa) {
+	colWidth := contentWidth / 2
+	rowHeight := 8.0
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(contentWidth, 7, "DATOS DEL EQUIPO", "1", 1, "C", false, 0, "")
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "EQUIPO:", tr(equipo.TipoDispositivo))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "PLACA:", tr(equipo.PlacaInventario))
+	pdf.Ln(rowHeight)
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "MARCA:", tr(equipo.Marca))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "MODELO:", tr(equipo.Modelo))
+	pdf.Ln(rowHeight)
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "SERIE:", tr(equipo.Serial))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "ESTADO:", tr(equipo.Estado))
+	pdf.Ln(rowHeight + 5)
+}
+
+// agregarTablaSeccion dibuja una tabla con título, encabezados y filas; si no hay filas lo indica
+func (s *PDFReporteService) agregarTablaSeccion(pdf *fpdf.Fpdf, tr func(string) string, titulo string, encabezados []string, anchos []float64, filas [][]string) {
+	// Título, encabezados y primera fila en la misma página
+	s.asegurarEspacio(pdf, 21)
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(contentWidth, 7, titulo, "1", 1, "C", false, 0, "")
+
+	pdf.SetFont("Arial", "B", 8)
+	pdf.SetFillColor(240, 240, 240)
+	for i, encabezado := range encabezados {
+		pdf.CellFormat(anchos[i], 7, encabezado, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 8)
+	if len(filas) == 0 {
+		pdf.CellFormat(contentWidth, 7, "Sin registros", "1", 1, "C", false, 0, "")
+	}
+	for _, fila := range filas {
+		for i, valor := range fila {
+			// Aproximadamente 2mm por caracter en Arial 8
+			pdf.CellFormat(anchos[i], 7, tr(s.truncarTexto(valor, int(anchos[i]/2))), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.Ln(5)
+}
+
+func (s *PDFReporteService) agregarMotivoActa(pdf *fpdf.Fpdf, tr func(string) string, motivo string) {
+	boxHeight := 18.0
+	s.asegurarEspacio(pdf, boxHeight+6)
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(contentWidth, 6, "MOTIVO DE LA ENTREGA:", "LTR", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	x, y := pdf.GetXY()
+	pdf.Rect(x, y, contentWidth, boxHeight, "D")
+	pdf.SetXY(x+2, y+2)
+	pdf.MultiCell(contentWidth-4, 5, tr(motivo), "", "L", false)
+	pdf.SetY(y + boxHeight)
+	pdf.Ln(8)
+}
+
+// agregarFirmasActa dibuja los bloques de firma de quien entrega, quien recibe y el técnico
+func (s *PDFReporteService) agregarFirmasActa(pdf *fpdf.Fpdf, tr func(string) string, data ActaEntregaPDFData) {
+	colWidth := contentWidth / 3
+	rowHeight := 7.0
+	firmaHeight := 20.0
+
+	// Evitar que el bloque de firmas quede partido entre dos páginas
+	s.asegurarEspacio(pdf, rowHeight*4+firmaHeight)
+
+	firmantes := []struct {
+		titulo  string
+		nombre  string
+		vinculo string
+		cedula  string
+	}{
+		{titulo: "ENTREGA"},
+		{titulo: "RECIBE"},
+		{titulo: "OFICINA DE SISTEMAS"},
+	}
+	if r := data.ResponsableEntrega; r != nil {
+		firmantes[0].nombre, firmantes[0].vinculo, firmantes[0].cedula = r.NombresApellidos, r.TipoVinculacion, r.Cedula
+	}
+	if r := data.ResponsableRecibe; r != nil {
+		firmantes[1].nombre, firmantes[1].vinculo, firmantes[1].cedula = r.NombresApellidos, r.TipoVinculacion, r.Cedula
+	}
+	if t := data.Tecnico; t != nil {
+		firmantes[2].nombre = fmt.Sprintf("%s %s", t.Nombre, t.Apellido)
+		firmantes[2].vinculo = strings.ToUpper(t.Rol)
+		firmantes[2].cedula = t.Cedula
+	}
+
+	// Títulos
+	pdf.SetFont("Arial", "B", 9)
+	for i, f := range firmantes {
+		ln := 0
+		if i == len(firmantes)-1 {
+			ln = 1
+		}
+		pdf.CellFormat(colWidth, rowHeight, f.titulo, "1", ln, "C", false, 0, "")
+	}
+
+	// Filas de nombre y vinculación (para el técnico, su rol)
+	for _, fila := range []struct {
+		label string
+		valor func(i int) string
+	}{
+		{"NOMBRE:", func(i int) string { return firmantes[i].nombre }},
+		{"VINCULACION:", func(i int) string { return firmantes[i].vinculo }},
+	} {
+		x, y := pdf.GetXY()
+		for i := range firmantes {
+			pdf.Rect(x+colWidth*float64(i), y, colWidth, rowHeight, "D")
+			pdf.SetXY(x+colWidth*float64(i)+2, y+1)
+			pdf.SetFont("Arial", "B", 7)
+			pdf.Cell(18, 5, fila.label)
+			pdf.SetFont("Arial", "", 7)
+			pdf.Cell(colWidth-20, 5, tr(s.truncarTexto(fila.valor(i), 28)))
+		}
+		pdf.SetXY(x, y+rowHeight)
+	}
+
+	// Espacio para firma
+	x, y := pdf.GetXY()
+	for i := range firmantes {
+		pdf.Rect(x+colWidth*float64(i), y, colWidth, firmaHeight, "D")
+	}
+	pdf.SetXY(x, y+firmaHeight)
+
+	// Fila FIRMA y C.C.
+	x, y = pdf.GetXY()
+	for i, f := range firmantes {
+		pdf.Rect(x+colWidth*float64(i), y, colWidth, rowHeight, "D")
+		pdf.SetXY(x+colWidth*float64(i)+2, y+1)
+		pdf.SetFont("Arial", "B", 7)
+		pdf.Cell(20, 5, "FIRMA")
+		pdf.Cell(8, 5, "C.C:")
+		pdf.SetFont("Arial", "", 7)
+		pdf.Cell(colWidth-30, 5, f.cedula)
+	}
+	pdf.SetXY(x, y+rowHeight)
+}
+
+// asegurarEspacio agrega una página si el bloque (dibujado con Rect, que no
+// dispara el salto automático) no cabe en la página actual
+func (s *PDFReporteService) asegurarEspacio(pdf *fpdf.Fpdf, alto float64) {
+	_, alturaPagina := pdf.GetPageSize()
+	_, _, _, margenInferior := pdf.GetMargins()
+	if pdf.GetY()+alto > alturaPagina-margenInferior {
+		pdf.AddPage()
+	}
+}
+
+func filasPerifericos(perifericos []models.PerifericoActa) [][]string {
+	filas := make([][]string, 0, len(perifericos))
+	for _, p := range perifericos {
+		filas = append(filas, []string{p.TipoPeriferico, p.Marca, p.Serial, p.PlacaInventario})
+	}
+	return filas
+}
+
+func filasHardware(hardware []models.HardwareActa) [][]string {
+	filas := make([][]string, 0, len(hardware))
+	for _, h := range hardware {
+		filas = append(filas, []string{h.Componente, h.Tecnologia, h.Capacidad})
+	}
+	return filas
+}
+
+func filasSoftware(software []models.SoftwareActa) [][]string {
+	filas := make([][]string, 0, len(software))
+	for _, sw := range software {
+		filas = append(filas, []string{sw.Nombre, sw.Version, sw.TipoLicencia, sw.Categoria})
+	}
+	return filas
+}
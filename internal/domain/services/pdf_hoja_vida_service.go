@@ -0,0 +1,393 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+
+	"github.com/go-pdf/fpdf"
+	"gorm.io/gorm"
+)
+
+// HojaVidaPDFData contiene los datos necesarios para generar la hoja de vida de un equipo
+type HojaVidaPDFData struct {
+	Equipo      models.Equipo
+	Dependencia *models.Dependencia
+	Secretaria  *models.Secretaria
+}
+
+// caracteresNoValidosArchivo identifica los caracteres que no se usan en nombres de archivo
+var caracteresNoValidosArchivo = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// GenerarPDFHojaVida genera la hoja de vida completa de un equipo
+func (s *PDFReporteService) GenerarPDFHojaVida(equipoID uint) ([]byte, error) {
+	var equipo models.Equipo
+	if err := s.db.Preload("UsuarioResponsable").
+		Preload("EstadoEquipo").
+		Preload("Perifericos").
+		Preload("HardwareInterno").
+		Preload("Software").
+		Preload("ConfiguracionRed").
+		Preload("Backups", func(db *gorm.DB) *gorm.DB { return db.Order("fecha ASC, id ASC") }).
+		Preload("Reportes", func(db *gorm.DB) *gorm.DB { return db.Order("fecha_inicio ASC, id ASC") }).
+		Preload("Reportes.TipoMantenimiento").
+		Preload("Reportes.Repuestos").
+		First(&equipo, equipoID).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo equipo: %w", err)
+	}
+
+	data := HojaVidaPDFData{Equipo: equipo}
+
+	if equipo.UsuarioResponsable != nil && equipo.UsuarioResponsable.DependenciaID != nil {
+		var dependencia models.Dependencia
+		if err := s.db.First(&dependencia, *equipo.UsuarioResponsable.DependenciaID).Error; err == nil {
+			data.Dependencia = &dependencia
+
+			var secretaria models.Secretaria
+			if err := s.db.First(&secretaria, dependencia.SecretariaID).Error; err == nil {
+				data.Secretaria = &secretaria
+			}
+		}
+	}
+
+	plantilla, err := s.plantillaActiva()
+	if err != nil {
+		return nil, err
+	}
+	return s.crearPDFHojaVida(data, plantilla)
+}
+
+// GenerarZIPHojasVidaDependencia genera un archivo ZIP con la hoja de vida de cada
+// equipo asignado a un responsable de la dependencia
+func (s *PDFReporteService) GenerarZIPHojasVidaDependencia(dependenciaID uint) ([]byte, error) {
+	var dependencia models.Dependencia
+	if err := s.db.First(&dependencia, dependenciaID).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo dependencia: %w", err)
+	}
+
+	var equipos []models.Equipo
+	if err := s.db.Select("equipos.id, equipos.placa_inventario").
+		Joins("JOIN usuario_responsables ur ON ur.id = equipos.usuario_responsable_id AND ur.deleted_at IS NULL").
+		Where("ur.dependencia_id = ?", dependenciaID).
+		Order("equipos.id ASC").
+		Find(&equipos).Error; err != nil {
+		return nil, fmt.Errorf("error obteniendo equipos de la dependencia: %w", err)
+	}
+	if len(equipos) == 0 {
+		return nil, fmt.Errorf("la dependencia %s no tiene equipos asignados", dependencia.Nombre)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	generado := time.Now()
+	for _, equipo := range equipos {
+		pdfBytes, err := s.GenerarPDFHojaVida(equipo.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error generando hoja de vida del equipo %d: %w", equipo.ID, err)
+		}
+
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     nombreArchivoHojaVida(equipo),
+			Method:   zip.Deflate,
+			Modified: generado,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error agregando hoja de vida al ZIP: %w", err)
+		}
+		if _, err := w.Write(pdfBytes); err != nil {
+			return nil, fmt.Errorf("error agregando hoja de vida al ZIP: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("error generando ZIP: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// nombreArchivoHojaVida arma el nombre del PDF dentro del ZIP con el ID y la placa del equipo
+func nombreArchivoHojaVida(equipo models.Equipo) string {
+	placa := strings.Trim(caracteresNoValidosArchivo.ReplaceAllString(equipo.PlacaInventario, "_"), "_")
+	if placa == "" {
+		return fmt.Sprintf("hoja_vida_%d.pdf", equipo.ID)
+	}
+	return fmt.Sprintf("hoja_vida_%d_%s.pdf", equipo.ID, placa)
+}
+
+func (s *PDFReporteService) crearPDFHojaVida(data HojaVidaPDFData, plantilla *models.DefinicionPlantilla) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetMargins(marginLeft, 15, marginRight)
+	// Margen inferior amplio para no escribir sobre el pie de página
+	pdf.SetAutoPageBreak(true, 40)
+	if err := s.registrarLogos(pdf, plantilla); err != nil {
+		return nil, err
+	}
+
+	pdf.SetHeaderFunc(func() {
+		s.agregarMarcaAgua(pdf, plantilla)
+		s.agregarEncabezado(pdf, plantilla, models.DocumentoHojaVida)
+	})
+	pdf.SetFooterFunc(func() {
+		s.agregarPiePagina(pdf, plantilla)
+	})
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	equipo := data.Equipo
+	// Los componentes se listan igual que en el acta de entrega
+	componentes := models.NuevosComponentesActa(&equipo)
+
+	pdf.AddPage()
+	s.agregarTitulo(pdf, "HOJA DE VIDA DE EQUIPO TECNOLOGICO")
+	s.agregarIdentificacionHojaVida(pdf, tr, equipo)
+	s.agregarResponsableHojaVida(pdf, tr, data)
+	s.agregarTablaSeccion(pdf, tr, "HARDWARE INTERNO",
+		[]string{"COMPONENTE", "TECNOLOGIA", "CAPACIDAD"},
+		[]float64{62, 62, 62},
+		filasHardware(componentes.HardwareInterno))
+	s.agregarTablaSeccion(pdf, tr, "SOFTWARE INSTALADO",
+		[]string{"NOMBRE", "VERSION", "LICENCIA", "CATEGORIA"},
+		[]float64{60, 36, 40, 50},
+		filasSoftware(componentes.Software))
+	s.agregarRedHojaVida(pdf, tr, equipo.ConfiguracionRed)
+	s.agregarTablaSeccion(pdf, tr, "PERIFERICOS",
+		[]string{"TIPO", "MARCA", "SERIAL", "PLACA INVENTARIO"},
+		[]float64{40, 48, 50, 48},
+		filasPerifericos(componentes.Perifericos))
+	s.agregarTablaSeccion(pdf, tr, "HISTORIAL DE BACKUPS",
+		[]string{"FECHA", "REALIZADO", "CARPETAS", "PESO", "RUTA"},
+		[]float64{24, 22, 22, 28, 90},
+		filasBackups(equipo.Backups))
+	s.agregarMantenimientosHojaVida(pdf, tr, equipo.Reportes)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("error generando PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *PDFReporteService) agregarIdentificacionHojaVida(pdf *fpdf.Fpdf, tr func(string) string, equipo models.Equipo) {
+	colWidth := contentWidth / 2
+	rowHeight := 8.0
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(contentWidth, 7, "IDENTIFICACION DEL EQUIPO", "1", 1, "C", false, 0, "")
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "EQUIPO:", tr(equipo.TipoDispositivo))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "PLACA:", tr(equipo.PlacaInventario))
+	pdf.Ln(rowHeight)
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "MARCA:", tr(equipo.Marca))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "MODELO:", tr(equipo.Modelo))
+	pdf.Ln(rowHeight)
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "SERIE:", tr(equipo.Serial))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "ESTADO:", tr(equipo.EstadoEquipo.Nombre))
+	pdf.Ln(rowHeight)
+
+	s.dibujarCeldaConBorde(pdf, contentWidth, rowHeight, "DILIGENCIADO:", equipo.FechaDiligenciamiento.Format("02/01/2006"))
+	pdf.Ln(rowHeight)
+
+	if equipo.ObservacionesGenerales != "" {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(contentWidth, 6, "OBSERVACIONES:", "LR", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		pdf.MultiCell(contentWidth, 5, tr(equipo.ObservacionesGenerales), "LRB", "L", false)
+	}
+	pdf.Ln(5)
+}
+
+func (s *PDFReporteService) agregarResponsableHojaVida(pdf *fpdf.Fpdf, tr func(string) string, data HojaVidaPDFData) {
+	colWidth := contentWidth / 2
+	rowHeight := 8.0
+	s.asegurarEspacio(pdf, 7+rowHeight*4)
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(contentWidth, 7, "RESPONSABLE Y UBICACION", "1", 1, "C", false, 0, "")
+
+	responsable := data.Equipo.UsuarioResponsable
+	if responsable == nil {
+		pdf.SetFont("Arial", "", 8)
+		pdf.CellFormat(contentWidth, 7, "Sin responsable asignado", "1", 1, "C", false, 0, "")
+		pdf.Ln(5)
+		return
+	}
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "NOMBRE:", tr(s.truncarTexto(responsable.NombresApellidos, 30)))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "C.C:", tr(responsable.Cedula))
+	pdf.Ln(rowHeight)
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "VINCULACION:", tr(responsable.TipoVinculacion))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "CORREO:", tr(s.truncarTexto(responsable.CorreoPersonal, 30)))
+	pdf.Ln(rowHeight)
+
+	dependencia, secretaria, ubicacion := "", "", ""
+	if data.Dependencia != nil {
+		dependencia = data.Dependencia.Nombre
+		ubicacion = data.Dependencia.UbicacionOficina
+	}
+	if data.Secretaria != nil {
+		secretaria = data.Secretaria.Nombre
+	}
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "DEPENDENCIA:", tr(s.truncarTexto(dependencia, 30)))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "SECRETARIA:", tr(s.truncarTexto(secretaria, 30)))
+	pdf.Ln(rowHeight)
+
+	s.dibujarCeldaConBorde(pdf, contentWidth, rowHeight, "UBICACION:", tr(s.truncarTexto(ubicacion, 75)))
+	pdf.Ln(rowHeight + 5)
+}
+
+func (s *PDFReporteService) agregarRedHojaVida(pdf *fpdf.Fpdf, tr func(string) string, red models.ConfiguracionRed) {
+	colWidth := contentWidth / 2
+	rowHeight := 8.0
+	s.asegurarEspacio(pdf, 7+rowHeight*2)
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(contentWidth, 7, "CONFIGURACION DE RED", "1", 1, "C", false, 0, "")
+
+	if red.ID == 0 {
+		pdf.SetFont("Arial", "", 8)
+		pdf.CellFormat(contentWidth, 7, "Sin registros", "1", 1, "C", false, 0, "")
+		pdf.Ln(5)
+		return
+	}
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "DIRECCION IP:", tr(red.DireccionIP))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "ASIGNACION IP:", tr(red.AsignacionIP))
+	pdf.Ln(rowHeight)
+
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "DISPOSITIVO:", tr(s.truncarTexto(red.NombreDispositivo, 30)))
+	s.dibujarCeldaConBorde(pdf, colWidth, rowHeight, "CONECTIVIDAD:", tr(red.Conectividad))
+	pdf.Ln(rowHeight + 5)
+}
+
+// agregarMantenimientosHojaVida dibuja en orden cronológico los reportes de servicio del equipo
+func (s *PDFReporteService) agregarMantenimientosHojaVida(pdf *fpdf.Fpdf, tr func(string) string, reportes []models.ReporteServicio) {
+	encabezados := []string{"No.", "FECHA", "TIPO", "TRABAJOS", "ACTIVIDAD REALIZADA", "REPUESTOS"}
+	anchos := []float64{12, 20, 24, 34, 56, 40}
+
+	s.asegurarEspacio(pdf, 21)
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(contentWidth, 7, "HISTORIAL DE MANTENIMIENTOS", "1", 1, "C", false, 0, "")
+
+	pdf.SetFont("Arial", "B", 8)
+	pdf.SetFillColor(240, 240, 240)
+	for i, encabezado := range encabezados {
+		pdf.CellFormat(anchos[i], 7, encabezado, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 7)
+	if len(reportes) == 0 {
+		pdf.CellFormat(contentWidth, 7, "Sin registros", "1", 1, "C", false, 0, "")
+	}
+	for _, r := range reportes {
+		fecha := r.FechaInicio.Format("02/01/2006")
+		if r.FechaFinalizacion != nil {
+			fecha += "\n" + r.FechaFinalizacion.Format("02/01/2006")
+		}
+		s.agregarFilaAjustada(pdf, anchos, []string{
+			fmt.Sprintf("%d", r.ID),
+			fecha,
+			tipoMantenimientoTexto(r.TipoMantenimiento),
+			tr(trabajosMantenimientoTexto(r.TipoMantenimiento)),
+			tr(r.ActividadRealizada),
+			tr(repuestosTexto(r.Repuestos)),
+		}, 4)
+	}
+	pdf.Ln(5)
+}
+
+// agregarFilaAjustada dibuja una fila de tabla cuyo alto crece para mostrar todo el texto de sus celdas.
+// Los valores deben venir ya traducidos a la codificación de la fuente.
+func (s *PDFReporteService) agregarFilaAjustada(pdf *fpdf.Fpdf, anchos []float64, valores []string, altoLinea float64) {
+	lineas := make([][][]byte, len(valores))
+	maxLineas := 1
+	for i, valor := range valores {
+		for _, parte := range strings.Split(valor, "\n") {
+			lineas[i] = append(lineas[i], pdf.SplitLines([]byte(parte), anchos[i]-2)...)
+		}
+		if len(lineas[i]) > maxLineas {
+			maxLineas = len(lineas[i])
+		}
+	}
+	alto := float64(maxLineas)*altoLinea + 2
+
+	// Rect no dispara el salto automático de página
+	s.asegurarEspacio(pdf, alto)
+
+	x, y := pdf.GetXY()
+	for i := range valores {
+		pdf.Rect(x, y, anchos[i], alto, "D")
+		for j, linea := range lineas[i] {
+			pdf.SetXY(x+1, y+1+float64(j)*altoLinea)
+			pdf.CellFormat(anchos[i]-2, altoLinea, string(linea), "", 0, "L", false, 0, "")
+		}
+		x += anchos[i]
+	}
+	pdf.SetXY(marginLeft, y+alto)
+}
+
+// tipoMantenimientoTexto devuelve el tipo de mantenimiento; si no es preventivo ni correctivo se muestra como OTRO
+func tipoMantenimientoTexto(tm models.TipoMantenimiento) string {
+	if tm.Tipo == "PREVENTIVO" || tm.Tipo == "CORRECTIVO" {
+		return tm.Tipo
+	}
+	return "OTRO"
+}
+
+// trabajosMantenimientoTexto lista los trabajos marcados en el tipo de mantenimiento
+func trabajosMantenimientoTexto(tm models.TipoMantenimiento) string {
+	var trabajos []string
+	for _, t := range []struct {
+		marcado bool
+		nombre  string
+	}{
+		{tm.Revision, "Revisión"},
+		{tm.Instalacion, "Instalación"},
+		{tm.Configuracion, "Configuración"},
+		{tm.Ingreso, "Ingreso"},
+		{tm.Salida, "Salida"},
+		{tm.ConceptoBaja, "Concepto de baja"},
+	} {
+		if t.marcado {
+			trabajos = append(trabajos, t.nombre)
+		}
+	}
+	if tm.Otro {
+		if tm.DescripcionOtro != "" {
+			trabajos = append(trabajos, "Otro: "+tm.DescripcionOtro)
+		} else {
+			trabajos = append(trabajos, "Otro")
+		}
+	}
+	return strings.Join(trabajos, ", ")
+}
+
+// repuestosTexto resume los repuestos usados en un reporte, uno por línea
+func repuestosTexto(repuestos []models.Repuesto) string {
+	lineas := make([]string, 0, len(repuestos))
+	for _, r := range repuestos {
+		lineas = append(lineas, fmt.Sprintf("%d x %s (%s)", r.Cantidad, r.Descripcion, r.SerialNumeroParte))
+	}
+	return strings.Join(lineas, "\n")
+}
+
+func filasBackups(backups []models.Backup) [][]string {
+	filas := make([][]string, 0, len(backups))
+	for _, b := range backups {
+		realizado := "NO"
+		if b.SeRealizoBackup {
+			realizado = "SI"
+		}
+		filas = append(filas, []string{b.Fecha.Format("02/01/2006"), realizado, fmt.Sprintf("%d", b.NumCarpetas), b.PesoTotalArchivos, b.RutaBackup})
+	}
+	return filas
+}
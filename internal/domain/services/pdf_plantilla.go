@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+
+	"github.com/go-pdf/fpdf"
+	"gorm.io/gorm"
+)
+
+const (
+	// tamanoEncabezadoPorDefecto es el tamaño de letra de una línea del encabezado sin Tamano
+	tamanoEncabezadoPorDefecto = 11.0
+
+	// prefijoLogosIncluidos identifica los logos que vienen con el servidor, relativos al ejecutable
+	prefijoLogosIncluidos = "assets/"
+)
+
+// PlantillaPredeterminada es el diseño de la Alcaldía de Tumaco, que se usa cuando no hay
+// plantilla activa. Sirve también como punto de partida para crear una plantilla.
+func PlantillaPredeterminada() models.DefinicionPlantilla {
+	return models.DefinicionPlantilla{
+		Encabezado: []models.LineaPlantilla{
+			{Texto: "REPUBLICA DE COLOMBIA", Tamano: 11, Negrita: true},
+			{Texto: "ALCALDIA DISTRITAL DE TUMACO", Tamano: 11, Negrita: true},
+			{Texto: "SECRETARIA GENERAL", Tamano: 11, Negrita: true},
+			{Texto: "OFICINA DE SISTEMAS", Tamano: 11, Negrita: true},
+			{Texto: "NIT. 891.200.916-2", Tamano: 9},
+		},
+		Logos: []models.LogoPlantilla{
+			{Archivo: "assets/logos/logo-004.png", X: 16, Y: 7, Ancho: 25, Alto: 20},     // Logo Alcaldía
+			{Archivo: "assets/logos/logo-escudo.png", X: 186, Y: 7, Ancho: 15, Alto: 22}, // Escudo Colombia (con transparencia)
+		},
+		// Marca de agua central, con transparencia
+		MarcaAgua: &models.LogoPlantilla{Archivo: "assets/logos/logo-marca-agua.png", X: 61, Y: 73, Ancho: 95, Alto: 135},
+		PiePagina: []string{
+			"Calle 11 con Carrera 9a esquina - Edificio Municipal, Telefax (2) 727 12 01",
+			"Pagina Web: www.tumaco-narino.gov.co",
+			"Correo electronico: contactenos@tumaco-narino.gov.co",
+			"Tumaco - Narino",
+		},
+	}
+}
+
+// plantillaActiva retorna la definición de la plantilla activa o la predeterminada
+func (s *PDFReporteService) plantillaActiva() (*models.DefinicionPlantilla, error) {
+	plantilla, err := s.plantillas.FindActiva()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		predeterminada := PlantillaPredeterminada()
+		return &predeterminada, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo la plantilla de PDF: %w", err)
+	}
+	return &plantilla.Definicion, nil
+}
+
+// VistaPreviaPlantilla genera un reporte de servicio con datos de ejemplo y la plantilla dada.
+// El reporte de ejemplo tiene el número 0, así que su código QR no se puede verificar.
+func (s *PDFReporteService) VistaPreviaPlantilla(plantilla *models.DefinicionPlantilla) ([]byte, error) {
+	return s.crearPDF(datosEjemploReporte(), plantilla)
+}
+
+// registrarLogos carga en el PDF los logos subidos de la plantilla, que están en el
+// almacenamiento; los incluidos en el servidor los lee fpdf del disco al dibujarlos
+func (s *PDFReporteService) registrarLogos(pdf *fpdf.Fpdf, plantilla *models.DefinicionPlantilla) error {
+	logos := plantilla.Logos
+	if plantilla.MarcaAgua != nil {
+		logos = append(append([]models.LogoPlantilla{}, logos...), *plantilla.MarcaAgua)
+	}
+
+	for _, logo := range logos {
+		if esLogoIncluido(logo.Archivo) || pdf.GetImageInfo(logo.Archivo) != nil {
+			continue
+		}
+		archivo, err := s.almacenamiento.Open(logo.Archivo)
+		if err != nil {
+			return fmt.Errorf("error abriendo el logo %s: %w", logo.Archivo, err)
+		}
+		pdf.RegisterImageOptionsReader(logo.Archivo, fpdf.ImageOptions{ImageType: tipoImagen(logo.Archivo)}, archivo)
+		archivo.Close()
+		if err := pdf.Error(); err != nil {
+			return fmt.Errorf("el logo %s no es una imagen válida: %w", logo.Archivo, err)
+		}
+	}
+	return nil
+}
+
+// dibujarLogo coloca una imagen de la plantilla en la página actual
+func (s *PDFReporteService) dibujarLogo(pdf *fpdf.Fpdf, logo models.LogoPlantilla) {
+	pdf.ImageOptions(logo.Archivo, logo.X, logo.Y, logo.Ancho, logo.Alto, false,
+		fpdf.ImageOptions{ImageType: tipoImagen(logo.Archivo)}, 0, "")
+}
+
+// esLogoIncluido indica si el logo es un archivo del servidor y no uno subido
+func esLogoIncluido(archivo string) bool {
+	return strings.HasPrefix(archivo, prefijoLogosIncluidos)
+}
+
+// tipoImagen deduce el tipo de imagen para fpdf a partir de la extensión
+func tipoImagen(archivo string) string {
+	switch strings.ToLower(path.Ext(archivo)) {
+	case ".jpg", ".jpeg":
+		return "JPG"
+	default:
+		return "PNG"
+	}
+}
+
+// textoCodigoDocumento es la identificación del formato que se imprime en el encabezado
+func textoCodigoDocumento(codigo models.CodigoDocumento) string {
+	partes := make([]string, 0, 2)
+	if codigo.Codigo != "" {
+		partes = append(partes, "Código: "+codigo.Codigo)
+	}
+	if codigo.Version != "" {
+		partes = append(partes, "Versión: "+codigo.Version)
+	}
+	return strings.Join(partes, "   ")
+}
+
+// datosEjemploReporte son los datos con los que se muestra la vista previa de una plantilla
+func datosEjemploReporte() ReportePDFData {
+	inicio := time.Date(2025, time.January, 10, 8, 0, 0, 0, time.Local)
+	fin := inicio.Add(3 * time.Hour)
+	return ReportePDFData{
+		Reporte: models.ReporteServicio{
+			FechaInicio:        inicio,
+			FechaFinalizacion:  &fin,
+			Dependencia:        "Secretaria de Hacienda",
+			Ubicacion:          "Piso 2 - Oficina de Tesoreria",
+			DiagnosticoFalla:   "El equipo se reinicia al abrir varias aplicaciones.",
+			ActividadRealizada: "Limpieza interna, cambio de pasta termica y actualizacion del sistema operativo.",
+			Observaciones:      "Se recomienda ampliar la memoria RAM.",
+		},
+		Equipo: models.Equipo{
+			TipoDispositivo: "Escritorio",
+			PlacaInventario: "ALC-0000",
+			Marca:           "HP",
+			Serial:          "5CG0000000",
+			Modelo:          "ProDesk 400",
+		},
+		UsuarioResponsable: &models.UsuarioResponsable{
+			NombresApellidos: "Nombre del Responsable",
+			Cedula:           "1000000000",
+			TipoVinculacion:  "Planta",
+		},
+		UsuarioSistema: models.Usuario{Nombre: "Nombre", Apellido: "del Tecnico", Cedula: "2000000000", Rol: models.RolTecnico},
+		Repuestos: []models.Repuesto{
+			{Cantidad: 1, SerialNumeroParte: "PN-0000", Descripcion: "Memoria RAM DDR4 8GB"},
+		},
+		TipoMantenimiento: models.TipoMantenimiento{Tipo: "PREVENTIVO", Revision: true, Configuracion: true},
+	}
+}
@@ -5,19 +5,27 @@ import (
 	"fmt"
 	"strings"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/storage"
+	"tum_inv_backend/internal/infrastructure/verificacion"
 
+	"github.com/boombuler/barcode/qr"
 	"github.com/go-pdf/fpdf"
+	"github.com/go-pdf/fpdf/contrib/barcode"
 	"gorm.io/gorm"
 )
 
 // PDFReporteService servicio para generar PDFs de reportes
 type PDFReporteService struct {
-	db *gorm.DB
+	db             *gorm.DB
+	verificador    *verificacion.Verificador
+	plantillas     repositories.PlantillaPDFRepository
+	almacenamiento storage.Provider // Logos subidos de las plantillas
 }
 
 // NewPDFReporteService crea una nueva instancia del servicio
-func NewPDFReporteService(db *gorm.DB) *PDFReporteService {
-	return &PDFReporteService{db: db}
+func NewPDFReporteService(db *gorm.DB, verificador *verificacion.Verificador, plantillas repositories.PlantillaPDFRepository, almacenamiento storage.Provider) *PDFReporteService {
+	return &PDFReporteService{db: db, verificador: verificador, plantillas: plantillas, almacenamiento: almacenamiento}
 }
 
 // ReportePDFData contiene todos los datos necesarios para generar el PDF
@@ -38,10 +46,8 @@ const (
 	marginRight  = 15.0
 	contentWidth = pageWidth - marginLeft - marginRight // 186mm
 
-	// Rutas de logos (relativas al ejecutable)
-	logoIzquierda = "assets/logos/logo-004.png"        // Logo Alcaldía
-	logoDerecha   = "assets/logos/logo-escudo.png"     // Escudo Colombia (con transparencia)
-	logoMarcaAgua = "assets/logos/logo-marca-agua.png" // Marca de agua central (con transparencia)
+	// QR de verificación en el pie de página, a la derecha del texto institucional
+	tamanoQR = 18.0
 )
 
 // GenerarPDFReporte genera el PDF del reporte de servicio técnico
@@ -69,32 +75,48 @@ func (s *PDFReporteService) GenerarPDFReporte(reporteID uint, usuarioSistemaID u
 		TipoMantenimiento:  reporte.TipoMantenimiento,
 	}
 
-	return s.crearPDF(data)
+	plantilla, err := s.plantillaActiva()
+	if err != nil {
+		return nil, err
+	}
+	return s.crearPDF(data, plantilla)
 }
 
-func (s *PDFReporteService) crearPDF(data ReportePDFData) ([]byte, error) {
+func (s *PDFReporteService) crearPDF(data ReportePDFData, plantilla *models.DefinicionPlantilla) ([]byte, error) {
 	pdf := fpdf.New("P", "mm", "Letter", "")
 	pdf.SetMargins(marginLeft, 15, marginRight)
 	pdf.SetAutoPageBreak(true, 15)
+	pdf.SetTitle("Reporte de servicio tecnico", false)
+	pdf.SetKeywords(fmt.Sprintf("reporte-servicio:%d", data.Reporte.ID), false)
+
+	codigo := s.verificador.Codigo(data.Reporte.ID)
+	qrVerificacion := barcode.RegisterQR(pdf, s.verificador.URL(codigo), qr.M, qr.Auto)
+	if err := s.registrarLogos(pdf, plantilla); err != nil {
+		return nil, err
+	}
 
 	// ========== PÁGINA 1 ==========
 	pdf.AddPage()
-	s.agregarMarcaAgua(pdf)
-	s.agregarEncabezado(pdf)
+	s.agregarMarcaAgua(pdf, plantilla)
+	s.agregarEncabezado(pdf, plantilla, models.DocumentoReporteServicio)
+	s.agregarIdentificador(pdf, data.Reporte.ID)
 	s.agregarTitulo(pdf, "REPORTE DE SERVICIO TECNICO")
 	s.agregarDatosReporte(pdf, data)
 	s.agregarTrabajoRealizado(pdf, data.TipoMantenimiento)
 	s.agregarSeccionesTexto(pdf, data.Reporte)
-	s.agregarPiePagina(pdf)
+	s.agregarPiePagina(pdf, plantilla)
+	s.agregarVerificacion(pdf, qrVerificacion, codigo)
 
 	// ========== PÁGINA 2 ==========
 	pdf.AddPage()
-	s.agregarMarcaAgua(pdf)
-	s.agregarEncabezado(pdf)
+	s.agregarMarcaAgua(pdf, plantilla)
+	s.agregarEncabezado(pdf, plantilla, models.DocumentoReporteServicio)
+	s.agregarIdentificador(pdf, data.Reporte.ID)
 	s.agregarTitulo(pdf, "REPUESTOS EMPLEADOS Y/O REEMPLAZADO")
 	s.agregarTablaRepuestos(pdf, data.Repuestos)
 	s.agregarFirmas(pdf, data)
-	s.agregarPiePagina(pdf)
+	s.agregarPiePagina(pdf, plantilla)
+	s.agregarVerificacion(pdf, qrVerificacion, codigo)
 
 	var buf bytes.Buffer
 	if err := pdf.Output(&buf); err != nil {
@@ -104,34 +126,57 @@ func (s *PDFReporteService) crearPDF(data ReportePDFData) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func (s *PDFReporteService) agregarMarcaAgua(pdf *fpdf.Fpdf) {
-	// Marca de agua central - posición: x=61, y=73, tamaño: 95x135mm
-	// Se coloca detrás del contenido con transparencia
-	pdf.ImageOptions(logoMarcaAgua, 61, 73, 95, 135, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+func (s *PDFReporteService) agregarMarcaAgua(pdf *fpdf.Fpdf, plantilla *models.DefinicionPlantilla) {
+	// Se coloca detrás del contenido; la imagen trae su propia transparencia
+	if plantilla.MarcaAgua != nil {
+		s.dibujarLogo(pdf, *plantilla.MarcaAgua)
+	}
 }
 
-func (s *PDFReporteService) agregarEncabezado(pdf *fpdf.Fpdf) {
+func (s *PDFReporteService) agregarEncabezado(pdf *fpdf.Fpdf, plantilla *models.DefinicionPlantilla, documento string) {
 	// Guardar posición Y inicial
 	inicioY := pdf.GetY()
 
-	// Logo izquierda (Alcaldía) - posición: x=16, y=7, tamaño: 25x20mm
-	pdf.ImageOptions(logoIzquierda, 16, 7, 25, 20, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	for _, logo := range plantilla.Logos {
+		s.dibujarLogo(pdf, logo)
+	}
 
-	// Logo derecha (Escudo Colombia) - posición: x=186, y=7, tamaño: 15x22mm
-	pdf.ImageOptions(logoDerecha, 186, 7, 15, 22, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	// Código y versión del formato bajo el logo izquierdo, a la altura del número de reporte
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	if codigo, ok := plantilla.Documentos[documento]; ok && (codigo.Codigo != "" || codigo.Version != "") {
+		pdf.SetFont("Arial", "", 7)
+		pdf.Text(marginLeft, 34, tr(textoCodigoDocumento(codigo)))
+	}
 
 	// Texto del encabezado centrado
 	pdf.SetY(inicioY)
-	pdf.SetFont("Arial", "B", 11)
-	pdf.CellFormat(contentWidth, 5, "REPUBLICA DE COLOMBIA", "", 1, "C", false, 0, "")
-	pdf.CellFormat(contentWidth, 5, "ALCALDIA DISTRITAL DE TUMACO", "", 1, "C", false, 0, "")
-	pdf.CellFormat(contentWidth, 5, "SECRETARIA GENERAL", "", 1, "C", false, 0, "")
-	pdf.CellFormat(contentWidth, 5, "OFICINA DE SISTEMAS", "", 1, "C", false, 0, "")
-	pdf.SetFont("Arial", "", 9)
-	pdf.CellFormat(contentWidth, 5, "NIT. 891.200.916-2", "", 1, "C", false, 0, "")
+	for _, linea := range plantilla.Encabezado {
+		estilo, tamano := "", linea.Tamano
+		if linea.Negrita {
+			estilo = "B"
+		}
+		if tamano == 0 {
+			tamano = tamanoEncabezadoPorDefecto
+		}
+		pdf.SetFont("Arial", estilo, tamano)
+		pdf.CellFormat(contentWidth, 5, tr(linea.Texto), "", 1, "C", false, 0, "")
+	}
 	pdf.Ln(8)
 }
 
+// agregarIdentificador escribe el número del reporte bajo el escudo. Al subir el PDF firmado
+// se busca este texto para comprobar que corresponde al reporte (ver validarPDFFirmado).
+func (s *PDFReporteService) agregarIdentificador(pdf *fpdf.Fpdf, reporteID uint) {
+	texto := identificadorReporte(reporteID)
+	pdf.SetFont("Arial", "", 7)
+	pdf.Text(pageWidth-marginRight-pdf.GetStringWidth(texto), 34, texto)
+}
+
+// identificadorReporte es el texto que identifica el reporte en el PDF
+func identificadorReporte(reporteID uint) string {
+	return fmt.Sprintf("Reporte No. %d", reporteID)
+}
+
 func (s *PDFReporteService) agregarTitulo(pdf *fpdf.Fpdf, titulo string) {
 	pdf.SetFont("Arial", "B", 12)
 	pdf.CellFormat(contentWidth, 8, titulo, "", 1, "C", false, 0, "")
@@ -471,11 +516,22 @@ func (s *PDFReporteService) agregarFirmas(pdf *fpdf.Fpdf, data ReportePDFData) {
 	pdf.Ln(rowHeight)
 }
 
-func (s *PDFReporteService) agregarPiePagina(pdf *fpdf.Fpdf) {
+func (s *PDFReporteService) agregarPiePagina(pdf *fpdf.Fpdf, plantilla *models.DefinicionPlantilla) {
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
 	pdf.SetY(-35)
 	pdf.SetFont("Arial", "", 7)
-	pdf.CellFormat(contentWidth, 4, "Calle 11 con Carrera 9a esquina - Edificio Municipal, Telefax (2) 727 12 01", "", 1, "C", false, 0, "")
-	pdf.CellFormat(contentWidth, 4, "Pagina Web: www.tumaco-narino.gov.co", "", 1, "C", false, 0, "")
-	pdf.CellFormat(contentWidth, 4, "Correo electronico: contactenos@tumaco-narino.gov.co", "", 1, "C", false, 0, "")
-	pdf.CellFormat(contentWidth, 4, "Tumaco - Narino", "", 1, "C", false, 0, "")
+	for _, linea := range plantilla.PiePagina {
+		pdf.CellFormat(contentWidth, 4, tr(linea), "", 1, "C", false, 0, "")
+	}
+}
+
+// agregarVerificacion dibuja el QR con la URL de verificación y, debajo, el código para
+// digitarlo a mano. Con el código, GET /api/verificar/:codigo confirma que el reporte es auténtico.
+func (s *PDFReporteService) agregarVerificacion(pdf *fpdf.Fpdf, qrVerificacion, codigo string) {
+	x := pageWidth - marginRight - tamanoQR
+	y := pageHeight - 36
+	barcode.Barcode(pdf, qrVerificacion, x, y, tamanoQR, tamanoQR, false)
+
+	pdf.SetFont("Arial", "", 6)
+	pdf.Text(x+(tamanoQR-pdf.GetStringWidth(codigo))/2, y+tamanoQR+3, codigo)
 }
@@ -1,21 +1,24 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
 )
 
 // PerifericoService define las operaciones del servicio para Periferico
 type PerifericoService interface {
-	CreatePeriferico(periferico *models.Periferico) error
+	CreatePeriferico(ctx context.Context, periferico *models.Periferico) error
 	GetPerifericoByID(id uint) (*models.Periferico, error)
-	UpdatePeriferico(periferico *models.Periferico) error
-	DeletePeriferico(id uint) error
-	GetAllPerifericos() ([]models.Periferico, error)
+	UpdatePeriferico(ctx context.Context, periferico *models.Periferico) error
+	DeletePeriferico(ctx context.Context, id uint) error
+	GetAllPerifericos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Periferico], error)
 	GetPerifericosByEquipoID(equipoID uint) ([]models.Periferico, error)
 	GetPerifericosSinEquipo() ([]models.Periferico, error)
-	AsignarEquipo(perifericoID uint, equipoID *uint) error
+	AsignarEquipo(ctx context.Context, perifericoID uint, equipoID *uint) error
 }
 
 // perifericoService implementa PerifericoService
@@ -29,14 +32,14 @@ func NewPerifericoService(perifericoRepo repositories.PerifericoRepository) Peri
 }
 
 // CreatePeriferico crea un nuevo periférico
-func (s *perifericoService) CreatePeriferico(periferico *models.Periferico) error {
+func (s *perifericoService) CreatePeriferico(ctx context.Context, periferico *models.Periferico) error {
 	if periferico.EquipoID == nil || *periferico.EquipoID == 0 {
 		return errors.New("el ID del equipo es obligatorio")
 	}
 	if periferico.TipoPeriferico == "" {
 		return errors.New("el tipo de periférico es obligatorio")
 	}
-	return s.perifericoRepo.Create(periferico)
+	return s.perifericoRepo.Create(ctx, periferico)
 }
 
 // GetPerifericoByID obtiene un periférico por su ID
@@ -45,24 +48,28 @@ func (s *perifericoService) GetPerifericoByID(id uint) (*models.Periferico, erro
 }
 
 // UpdatePeriferico actualiza un periférico existente
-func (s *perifericoService) UpdatePeriferico(periferico *models.Periferico) error {
+func (s *perifericoService) UpdatePeriferico(ctx context.Context, periferico *models.Periferico) error {
 	if periferico.ID == 0 {
 		return errors.New("ID de periférico no válido")
 	}
-	return s.perifericoRepo.Update(periferico)
+	return s.perifericoRepo.Update(ctx, periferico)
 }
 
 // DeletePeriferico elimina un periférico por su ID
-func (s *perifericoService) DeletePeriferico(id uint) error {
+func (s *perifericoService) DeletePeriferico(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de periférico no válido")
 	}
-	return s.perifericoRepo.Delete(id)
+	return s.perifericoRepo.Delete(ctx, id)
 }
 
 // GetAllPerifericos obtiene todos los periféricos
-func (s *perifericoService) GetAllPerifericos() ([]models.Periferico, error) {
-	return s.perifericoRepo.FindAll()
+func (s *perifericoService) GetAllPerifericos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Periferico], error) {
+	registros, total, err := s.perifericoRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.Periferico]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetPerifericosByEquipoID obtiene todos los periféricos asociados a un equipo
@@ -79,9 +86,11 @@ func (s *perifericoService) GetPerifericosSinEquipo() ([]models.Periferico, erro
 }
 
 // AsignarEquipo asigna un equipo a un periférico (solo actualiza el FK)
-func (s *perifericoService) AsignarEquipo(perifericoID uint, equipoID *uint) error {
+func (s *perifericoService) AsignarEquipo(ctx context.Context, perifericoID uint, equipoID *uint) error {
 	if perifericoID == 0 {
 		return errors.New("ID de periférico no válido")
 	}
-	return s.perifericoRepo.AsignarEquipo(perifericoID, equipoID)
+	// Registrar el cambio como asignación en la auditoría
+	ctx = auditoria.ConAccion(ctx, auditoria.AccionAsignar)
+	return s.perifericoRepo.AsignarEquipo(ctx, perifericoID, equipoID)
 }
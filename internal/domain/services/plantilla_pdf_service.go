@@ -0,0 +1,222 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Formatos aceptados para los logos
+	_ "image/png"
+	"os"
+	"strings"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/storage"
+
+	"github.com/go-pdf/fpdf"
+	"gorm.io/gorm"
+)
+
+// Límites de una plantilla, para que el encabezado y el pie no invadan el contenido del PDF
+const (
+	maxLineasEncabezado = 6
+	maxLineasPie        = 5
+	maxLogosPlantilla   = 4
+
+	// prefijoLogosSubidos es la carpeta de los logos en el almacenamiento
+	prefijoLogosSubidos = "plantillas/logos/"
+)
+
+// Errores de las plantillas de PDF
+var (
+	ErrPlantillaInvalida     = errors.New("plantilla inválida")
+	ErrPlantillaNoEncontrada = errors.New("plantilla no encontrada")
+)
+
+// PlantillaPDFService administra las plantillas de los PDF institucionales y sus logos
+type PlantillaPDFService interface {
+	GetAll() ([]models.PlantillaPDF, error)
+	GetByID(id uint) (*models.PlantillaPDF, error)
+	Create(ctx context.Context, plantilla *models.PlantillaPDF) error
+	Update(ctx context.Context, plantilla *models.PlantillaPDF) error
+	Delete(ctx context.Context, id uint) error
+	Activar(ctx context.Context, id uint) error
+	Validar(definicion *models.DefinicionPlantilla) error
+	SubirLogo(datos []byte) (*dto.LogoPlantillaDTO, error)
+}
+
+// plantillaPDFService implementa PlantillaPDFService
+type plantillaPDFService struct {
+	repo           repositories.PlantillaPDFRepository
+	almacenamiento storage.Provider
+}
+
+// NewPlantillaPDFService crea una nueva instancia de PlantillaPDFService
+func NewPlantillaPDFService(repo repositories.PlantillaPDFRepository, almacenamiento storage.Provider) PlantillaPDFService {
+	return &plantillaPDFService{repo: repo, almacenamiento: almacenamiento}
+}
+
+// GetAll obtiene todas las plantillas
+func (s *plantillaPDFService) GetAll() ([]models.PlantillaPDF, error) {
+	return s.repo.FindAll()
+}
+
+// GetByID obtiene una plantilla por su ID
+func (s *plantillaPDFService) GetByID(id uint) (*models.PlantillaPDF, error) {
+	plantilla, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPlantillaNoEncontrada
+	}
+	return plantilla, err
+}
+
+// Create crea una plantilla inactiva; se usa en los PDF después de activarla
+func (s *plantillaPDFService) Create(ctx context.Context, plantilla *models.PlantillaPDF) error {
+	plantilla.Nombre = strings.TrimSpace(plantilla.Nombre)
+	if plantilla.Nombre == "" {
+		return fmt.Errorf("%w: el nombre es obligatorio", ErrPlantillaInvalida)
+	}
+	if err := s.Validar(&plantilla.Definicion); err != nil {
+		return err
+	}
+	plantilla.Activa = false
+	return s.repo.Create(ctx, plantilla)
+}
+
+// Update cambia el nombre y la definición de una plantilla; si está activa, los PDF
+// generados desde ese momento usan la nueva definición
+func (s *plantillaPDFService) Update(ctx context.Context, plantilla *models.PlantillaPDF) error {
+	plantilla.Nombre = strings.TrimSpace(plantilla.Nombre)
+	if plantilla.Nombre == "" {
+		return fmt.Errorf("%w: el nombre es obligatorio", ErrPlantillaInvalida)
+	}
+	if err := s.Validar(&plantilla.Definicion); err != nil {
+		return err
+	}
+	actual, err := s.GetByID(plantilla.ID)
+	if err != nil {
+		return err
+	}
+	plantilla.Activa = actual.Activa
+	return s.repo.Update(ctx, plantilla)
+}
+
+// Delete elimina una plantilla; si era la activa, los PDF vuelven al diseño predeterminado
+func (s *plantillaPDFService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Activar hace que la plantilla se use en todos los PDF institucionales
+func (s *plantillaPDFService) Activar(ctx context.Context, id uint) error {
+	err := s.repo.Activar(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPlantillaNoEncontrada
+	}
+	return err
+}
+
+// Validar revisa que la definición quepa en la página y que sus logos existan
+func (s *plantillaPDFService) Validar(definicion *models.DefinicionPlantilla) error {
+	invalida := func(formato string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrPlantillaInvalida, fmt.Sprintf(formato, args...))
+	}
+
+	if len(definicion.Encabezado) > maxLineasEncabezado {
+		return invalida("el encabezado admite máximo %d líneas", maxLineasEncabezado)
+	}
+	for i, linea := range definicion.Encabezado {
+		if linea.Tamano != 0 && (linea.Tamano < 6 || linea.Tamano > 16) {
+			return invalida("la línea %d del encabezado debe tener un tamaño entre 6 y 16", i+1)
+		}
+	}
+	if len(definicion.PiePagina) > maxLineasPie {
+		return invalida("el pie de página admite máximo %d líneas", maxLineasPie)
+	}
+	if len(definicion.Logos) > maxLogosPlantilla {
+		return invalida("la plantilla admite máximo %d logos", maxLogosPlantilla)
+	}
+
+	logos := definicion.Logos
+	if definicion.MarcaAgua != nil {
+		logos = append(append([]models.LogoPlantilla{}, logos...), *definicion.MarcaAgua)
+	}
+	for _, logo := range logos {
+		if logo.Ancho <= 0 || logo.Alto <= 0 || logo.X < 0 || logo.Y < 0 ||
+			logo.X+logo.Ancho > pageWidth || logo.Y+logo.Alto > pageHeight {
+			return invalida("el logo %s no cabe en la página (%.0f x %.0f mm)", logo.Archivo, pageWidth, pageHeight)
+		}
+		if err := s.existeLogo(logo.Archivo); err != nil {
+			return invalida("%v", err)
+		}
+	}
+
+	for documento := range definicion.Documentos {
+		switch documento {
+		case models.DocumentoReporteServicio, models.DocumentoActaEntrega, models.DocumentoHojaVida:
+		default:
+			return invalida("tipo de documento desconocido: %s", documento)
+		}
+	}
+	return nil
+}
+
+// existeLogo comprueba que el archivo de un logo se pueda leer
+func (s *plantillaPDFService) existeLogo(archivo string) error {
+	switch {
+	case esLogoIncluido(archivo):
+		if strings.Contains(archivo, "..") {
+			return fmt.Errorf("ruta de logo no permitida: %s", archivo)
+		}
+		if _, err := os.Stat(archivo); err != nil {
+			return fmt.Errorf("el logo %s no existe en el servidor", archivo)
+		}
+	case strings.HasPrefix(archivo, prefijoLogosSubidos):
+		f, err := s.almacenamiento.Open(archivo)
+		if err != nil {
+			if errors.Is(err, storage.ErrArchivoNoEncontrado) {
+				return fmt.Errorf("el logo %s no se ha subido", archivo)
+			}
+			return fmt.Errorf("error abriendo el logo %s: %w", archivo, err)
+		}
+		f.Close()
+	default:
+		return fmt.Errorf("el logo %s debe estar en %s o en %s", archivo, prefijoLogosIncluidos, prefijoLogosSubidos)
+	}
+	return nil
+}
+
+// SubirLogo guarda una imagen PNG o JPEG para usarla en las plantillas. El nombre es el
+// hash del contenido, así que subir el mismo archivo dos veces no lo duplica.
+func (s *plantillaPDFService) SubirLogo(datos []byte) (*dto.LogoPlantillaDTO, error) {
+	config, formato, err := image.DecodeConfig(bytes.NewReader(datos))
+	if err != nil {
+		return nil, fmt.Errorf("%w: el logo debe ser una imagen PNG o JPEG", ErrPlantillaInvalida)
+	}
+
+	extension, contentType, tipo := ".png", "image/png", "PNG"
+	if formato == "jpeg" {
+		extension, contentType, tipo = ".jpg", "image/jpeg", "JPG"
+	}
+
+	// fpdf no admite todas las variantes de PNG (por ejemplo, entrelazado o de 16 bits)
+	prueba := fpdf.New("P", "mm", "Letter", "")
+	prueba.RegisterImageOptionsReader("logo", fpdf.ImageOptions{ImageType: tipo}, bytes.NewReader(datos))
+	if err := prueba.Error(); err != nil {
+		return nil, fmt.Errorf("%w: la imagen no se puede usar en el PDF: %v", ErrPlantillaInvalida, err)
+	}
+
+	suma := sha256.Sum256(datos)
+	archivo := prefijoLogosSubidos + hex.EncodeToString(suma[:8]) + extension
+	if _, err := s.almacenamiento.Upload(archivo, datos, contentType); err != nil {
+		return nil, fmt.Errorf("error al subir el logo: %w", err)
+	}
+
+	return &dto.LogoPlantillaDTO{Archivo: archivo, AnchoPx: config.Width, AltoPx: config.Height}, nil
+}
@@ -1,51 +1,86 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
 	"tum_inv_backend/internal/domain/models"
 	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
 	"tum_inv_backend/internal/infrastructure/storage"
 )
 
 // ReporteServicioService define las operaciones del servicio para ReporteServicio
 type ReporteServicioService interface {
-	CreateReporteServicio(reporte *models.ReporteServicio) error
+	CreateReporteServicio(ctx context.Context, reporte *models.ReporteServicio) error
 	GetReporteServicioByID(id uint) (*models.ReporteServicio, error)
-	UpdateReporteServicio(reporte *models.ReporteServicio) error
-	DeleteReporteServicio(id uint) error
-	GetAllReportesServicio() ([]models.ReporteServicio, error)
+	UpdateReporteServicio(ctx context.Context, reporte *models.ReporteServicio) error
+	DeleteReporteServicio(ctx context.Context, id uint) error
+	GetAllReportesServicio(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.ReporteServicio], error)
 	GetReportesServicioByEquipoID(equipoID uint) ([]models.ReporteServicio, error)
 	GetReportesResumenByEquipoID(equipoID uint) ([]dto.ReporteResumenDTO, error)
-	CrearReporteConTipo(reporteData *dto.CrearReporteCompletoDTO) (*models.ReporteServicio, error)
-	SubirFirmado(reporteID uint, fileData []byte, contentType string) (*models.ReporteServicio, error)
+	CrearReporteConTipo(ctx context.Context, reporteData *dto.CrearReporteCompletoDTO) (*models.ReporteServicio, error)
+	RegistrarReporteConTipo(ctx context.Context, reporteData *dto.CrearReporteCompletoDTO) (*models.ReporteServicio, error)
+	SubirFirmado(ctx context.Context, reporteID uint, fileData []byte, contentType string) (*models.ReporteServicio, error)
+	PrepararSubidaFirmado(reporteID uint) (*dto.SubidaDirectaDTO, error)
+	ConfirmarSubidaFirmado(ctx context.Context, reporteID uint, archivo string) (*models.ReporteServicio, error)
+	TamanoMaximoFirmado() int64
 	ObtenerURLFirmado(reporteID uint) (string, error)
-	ReabrirReporte(reporteID uint) error
+	GetDocumentosFirmados(reporteID uint) ([]models.DocumentoReporte, error)
+	ObtenerURLDocumentoFirmado(reporteID uint, version int) (string, error)
+	ReabrirReporte(ctx context.Context, reporteID uint, motivo string) error
 }
 
+// Errores de la subida directa del PDF firmado al almacenamiento
+var (
+	ErrSubidaDirectaNoDisponible = errors.New("el almacenamiento configurado no admite la subida directa; use subir-firmado")
+	ErrSubidaDirectaInvalida     = errors.New("el archivo no corresponde a una subida directa de este reporte")
+)
+
+// vigenciaSubidaDirecta es el tiempo que el cliente tiene para subir el PDF con la URL PUT
+const vigenciaSubidaDirecta = 15 * time.Minute
+
 // reporteServicioService implementa ReporteServicioService
 type reporteServicioService struct {
-	reporteRepo repositories.ReporteServicioRepository
-	storage     *storage.SupabaseStorage
+	reporteRepo   repositories.ReporteServicioRepository
+	documentoRepo repositories.DocumentoReporteRepository
+	transacciones repositories.Transacciones
+	validacionPDF OpcionesValidacionPDF
+	notificacion  NotificacionCorreoService
+	storage       storage.Provider
 }
 
-// NewReporteServicioService crea una nueva instancia de ReporteServicioService
+// NewReporteServicioService crea una nueva instancia de ReporteServicioService. notificacion
+// avisa por correo de la creación, el cierre y la reapertura de los reportes; el correo se
+// guarda en la misma transacción que el cambio.
 func NewReporteServicioService(
 	reporteRepo repositories.ReporteServicioRepository,
-	storageSvc ...*storage.SupabaseStorage,
+	documentoRepo repositories.DocumentoReporteRepository,
+	transacciones repositories.Transacciones,
+	validacionPDF OpcionesValidacionPDF,
+	notificacion NotificacionCorreoService,
+	storageSvc storage.Provider,
 ) ReporteServicioService {
-	s := &reporteServicioService{
-		reporteRepo: reporteRepo,
+	return &reporteServicioService{
+		reporteRepo:   reporteRepo,
+		documentoRepo: documentoRepo,
+		transacciones: transacciones,
+		validacionPDF: validacionPDF,
+		notificacion:  notificacion,
+		storage:       storageSvc,
 	}
-	if len(storageSvc) > 0 {
-		s.storage = storageSvc[0]
-	}
-	return s
 }
 
 // CreateReporteServicio crea un nuevo reporte de servicio
-func (s *reporteServicioService) CreateReporteServicio(reporte *models.ReporteServicio) error {
+func (s *reporteServicioService) CreateReporteServicio(ctx context.Context, reporte *models.ReporteServicio) error {
 	if reporte.Dependencia == "" {
 		return errors.New("la dependencia es obligatoria")
 	}
@@ -56,7 +91,12 @@ func (s *reporteServicioService) CreateReporteServicio(reporte *models.ReporteSe
 		return errors.New("la actividad realizada es obligatoria")
 	}
 
-	return s.reporteRepo.Create(reporte)
+	return s.transacciones.Ejecutar(ctx, func(ctx context.Context) error {
+		if err := s.reporteRepo.Create(ctx, reporte); err != nil {
+			return err
+		}
+		return s.notificacion.ReporteCreado(ctx, reporte.ID)
+	})
 }
 
 // GetReporteServicioByID obtiene un reporte de servicio por su ID
@@ -64,8 +104,9 @@ func (s *reporteServicioService) GetReporteServicioByID(id uint) (*models.Report
 	return s.reporteRepo.FindByID(id)
 }
 
-// UpdateReporteServicio actualiza un reporte de servicio existente
-func (s *reporteServicioService) UpdateReporteServicio(reporte *models.ReporteServicio) error {
+// UpdateReporteServicio actualiza los datos de un reporte de servicio existente; el cierre
+// solo cambia al subir el firmado o al reabrir el reporte
+func (s *reporteServicioService) UpdateReporteServicio(ctx context.Context, reporte *models.ReporteServicio) error {
 	if reporte.ID == 0 {
 		return errors.New("ID de reporte no válido")
 	}
@@ -80,25 +121,28 @@ func (s *reporteServicioService) UpdateReporteServicio(reporte *models.ReporteSe
 	}
 
 	// Verificar si existe el reporte
-	existente, err := s.reporteRepo.FindByID(reporte.ID)
-	if err != nil && existente != nil {
+	if _, err := s.reporteRepo.FindByID(reporte.ID); err != nil {
 		return errors.New("reporte no encontrado")
 	}
 
-	return s.reporteRepo.Update(reporte)
+	return s.reporteRepo.Update(ctx, reporte)
 }
 
 // DeleteReporteServicio elimina un reporte de servicio por su ID
-func (s *reporteServicioService) DeleteReporteServicio(id uint) error {
+func (s *reporteServicioService) DeleteReporteServicio(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de reporte no válido")
 	}
-	return s.reporteRepo.Delete(id)
+	return s.reporteRepo.Delete(ctx, id)
 }
 
 // GetAllReportesServicio obtiene todos los reportes de servicio
-func (s *reporteServicioService) GetAllReportesServicio() ([]models.ReporteServicio, error) {
-	return s.reporteRepo.FindAll()
+func (s *reporteServicioService) GetAllReportesServicio(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.ReporteServicio], error) {
+	registros, total, err := s.reporteRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.ReporteServicio]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetReportesServicioByEquipoID obtiene todos los reportes de servicio asociados a un equipo
@@ -122,7 +166,24 @@ func (s *reporteServicioService) GetReportesResumenByEquipoID(equipoID uint) ([]
 }
 
 // CrearReporteConTipo crea un reporte de servicio completo con tipos de mantenimiento y repuestos
-func (s *reporteServicioService) CrearReporteConTipo(reporteData *dto.CrearReporteCompletoDTO) (*models.ReporteServicio, error) {
+func (s *reporteServicioService) CrearReporteConTipo(ctx context.Context, reporteData *dto.CrearReporteCompletoDTO) (*models.ReporteServicio, error) {
+	reporte, err := s.RegistrarReporteConTipo(ctx, reporteData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cargar el reporte completo con todas sus relaciones
+	reporteCompleto, err := s.reporteRepo.FindByID(reporte.ID)
+	if err != nil {
+		return nil, errors.New("error al cargar el reporte completo: " + err.Error())
+	}
+
+	return reporteCompleto, nil
+}
+
+// RegistrarReporteConTipo crea el reporte completo como CrearReporteConTipo pero no lo vuelve
+// a cargar con sus relaciones, así se puede usar dentro de una transacción que aún no termina
+func (s *reporteServicioService) RegistrarReporteConTipo(ctx context.Context, reporteData *dto.CrearReporteCompletoDTO) (*models.ReporteServicio, error) {
 	// Validaciones iniciales
 	if reporteData == nil {
 		return nil, errors.New("los datos del reporte son obligatorios")
@@ -145,28 +206,26 @@ func (s *reporteServicioService) CrearReporteConTipo(reporteData *dto.CrearRepor
 	tipoMantenimiento := reporteData.ToTipoMantenimiento(0) // El ID se asignará en el repositorio
 	repuestos := reporteData.ToRepuestos(0)                 // El ID se asignará en el repositorio
 
-	// Crear el reporte completo usando el repositorio
-	if err := s.reporteRepo.CreateReporteCompleto(reporte, &tipoMantenimiento, repuestos); err != nil {
-		return nil, errors.New("error al crear el reporte completo: " + err.Error())
-	}
-
-	// Cargar el reporte completo con todas sus relaciones
-	reporteCompleto, err := s.reporteRepo.FindByID(reporte.ID)
+	// Crear el reporte completo y su aviso por correo en una sola transacción
+	err := s.transacciones.Ejecutar(ctx, func(ctx context.Context) error {
+		if err := s.reporteRepo.CreateReporteCompleto(ctx, reporte, &tipoMantenimiento, repuestos); err != nil {
+			return fmt.Errorf("error al crear el reporte completo: %w", err)
+		}
+		return s.notificacion.ReporteCreado(ctx, reporte.ID)
+	})
 	if err != nil {
-		return nil, errors.New("error al cargar el reporte completo: " + err.Error())
+		return nil, err
 	}
-
-	return reporteCompleto, nil
+	return reporte, nil
 }
 
-// SubirFirmado sube un PDF firmado a Supabase Storage y cierra el reporte
-func (s *reporteServicioService) SubirFirmado(reporteID uint, fileData []byte, contentType string) (*models.ReporteServicio, error) {
+// SubirFirmado valida el PDF firmado, lo sube al almacenamiento como una nueva versión y cierra
+// el reporte. Las versiones anteriores no se sobrescriben. Si el PDF no pasa la validación
+// retorna *dto.ErrorValidacionPDF y el reporte sigue abierto.
+func (s *reporteServicioService) SubirFirmado(ctx context.Context, reporteID uint, fileData []byte, contentType string) (*models.ReporteServicio, error) {
 	if reporteID == 0 {
 		return nil, errors.New("ID de reporte no válido")
 	}
-	if s.storage == nil {
-		return nil, errors.New("servicio de almacenamiento no configurado")
-	}
 
 	// Verificar que el reporte existe y no está cerrado
 	reporte, err := s.reporteRepo.FindByID(reporteID)
@@ -177,15 +236,48 @@ func (s *reporteServicioService) SubirFirmado(reporteID uint, fileData []byte, c
 		return nil, errors.New("el reporte ya está cerrado")
 	}
 
-	// Subir archivo a Supabase Storage
-	fileName := fmt.Sprintf("reporte_%d_firmado.pdf", reporteID)
+	if err := validarPDFFirmado(fileData, reporteID, s.validacionPDF); err != nil {
+		return nil, err
+	}
+
+	// Cada versión tiene su propio archivo; la fecha y el hash evitan reemplazar una anterior
+	suma := sha256.Sum256(fileData)
+	hash := hex.EncodeToString(suma[:])
+	ahora := time.Now()
+	fileName := fmt.Sprintf("reporte_%d/firmado_%s_%s.pdf", reporteID, ahora.Format("20060102T150405"), hash[:12])
 	objectPath, err := s.storage.Upload(fileName, fileData, contentType)
 	if err != nil {
 		return nil, fmt.Errorf("error al subir archivo: %w", err)
 	}
 
-	// Cerrar el reporte en la BD
-	if err := s.reporteRepo.CerrarReporte(reporteID, objectPath); err != nil {
+	documento := &models.DocumentoReporte{
+		ReporteID:   reporteID,
+		Archivo:     fileName,
+		RutaObjeto:  objectPath,
+		SHA256:      hash,
+		Tamano:      int64(len(fileData)),
+		FechaSubida: ahora,
+	}
+	if actor, ok := auditoria.ActorDesde(ctx); ok {
+		documento.SubidoPorID = &actor.UsuarioID
+		documento.SubidoPorUsername = actor.Username
+	}
+
+	// Registrar la versión, cerrar el reporte y dejar el aviso por correo en una sola transacción
+	err = s.transacciones.Ejecutar(ctx, func(ctx context.Context) error {
+		if err := s.reporteRepo.CerrarReporte(ctx, documento); err != nil {
+			return err
+		}
+		return s.notificacion.ReporteCerrado(ctx, reporteID)
+	})
+	if err != nil {
+		// La versión no quedó registrada; el archivo subido no se usará
+		if errDel := s.storage.Delete(fileName); errDel != nil {
+			log.Printf("No se pudo eliminar el archivo %s sin registrar: %v", fileName, errDel)
+		}
+		if errors.Is(err, repositories.ErrReporteCerrado) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("error al cerrar el reporte: %w", err)
 	}
 
@@ -193,14 +285,101 @@ func (s *reporteServicioService) SubirFirmado(reporteID uint, fileData []byte, c
 	return s.reporteRepo.FindByID(reporteID)
 }
 
-// ObtenerURLFirmado genera una URL firmada temporal para descargar el PDF firmado
+// PrepararSubidaFirmado genera una URL PUT prefirmada para que el cliente suba el PDF firmado
+// directamente al almacenamiento, sin pasar por la API. El archivo queda en un nombre temporal
+// hasta que se confirma con ConfirmarSubidaFirmado. Retorna ErrSubidaDirectaNoDisponible si el
+// almacenamiento no lo admite (solo S3).
+func (s *reporteServicioService) PrepararSubidaFirmado(reporteID uint) (*dto.SubidaDirectaDTO, error) {
+	if reporteID == 0 {
+		return nil, errors.New("ID de reporte no válido")
+	}
+	directa, ok := s.storage.(storage.SubidaDirecta)
+	if !ok {
+		return nil, ErrSubidaDirectaNoDisponible
+	}
+
+	reporte, err := s.reporteRepo.FindByID(reporteID)
+	if err != nil {
+		return nil, errors.New("reporte no encontrado")
+	}
+	if reporte.FechaCierre != nil {
+		return nil, errors.New("el reporte ya está cerrado")
+	}
+
+	aleatorio := make([]byte, 16)
+	if _, err := rand.Read(aleatorio); err != nil {
+		return nil, err
+	}
+	archivo := fmt.Sprintf("reporte_%d/subida_%s.pdf", reporteID, hex.EncodeToString(aleatorio))
+	url, err := directa.PresignedPutURL(archivo, vigenciaSubidaDirecta)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar URL de subida: %w", err)
+	}
+	return &dto.SubidaDirectaDTO{URL: url, Archivo: archivo, Vence: time.Now().Add(vigenciaSubidaDirecta)}, nil
+}
+
+// ConfirmarSubidaFirmado toma el PDF que el cliente subió con la URL de PrepararSubidaFirmado y
+// lo procesa igual que SubirFirmado: lo valida, lo guarda como una nueva versión y cierra el
+// reporte. El archivo temporal se elimina siempre, así que una subida rechazada se repite con
+// una URL nueva.
+func (s *reporteServicioService) ConfirmarSubidaFirmado(ctx context.Context, reporteID uint, archivo string) (*models.ReporteServicio, error) {
+	if _, ok := s.storage.(storage.SubidaDirecta); !ok {
+		return nil, ErrSubidaDirectaNoDisponible
+	}
+	if !archivoSubidaDirecta(archivo, reporteID) {
+		return nil, ErrSubidaDirectaInvalida
+	}
+
+	lector, err := s.storage.Open(archivo)
+	if err != nil {
+		if errors.Is(err, storage.ErrArchivoNoEncontrado) {
+			return nil, fmt.Errorf("%w: el PDF no se ha subido o la URL venció", ErrSubidaDirectaInvalida)
+		}
+		return nil, err
+	}
+	defer func() {
+		if errDel := s.storage.Delete(archivo); errDel != nil {
+			log.Printf("No se pudo eliminar la subida directa %s: %v", archivo, errDel)
+		}
+	}()
+
+	// Igual que en subir-firmado, se lee un byte más del límite para detectar el exceso
+	var origen io.Reader = lector
+	if limite := s.validacionPDF.TamanoMaximo; limite > 0 {
+		origen = io.LimitReader(lector, limite+1)
+	}
+	fileData, err := io.ReadAll(origen)
+	lector.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el PDF subido: %w", err)
+	}
+	return s.SubirFirmado(ctx, reporteID, fileData, "application/pdf")
+}
+
+// archivoSubidaDirecta indica si el nombre es el de una subida directa del reporte
+func archivoSubidaDirecta(archivo string, reporteID uint) bool {
+	aleatorio, ok := strings.CutPrefix(archivo, fmt.Sprintf("reporte_%d/subida_", reporteID))
+	if !ok {
+		return false
+	}
+	aleatorio, ok = strings.CutSuffix(aleatorio, ".pdf")
+	if !ok || len(aleatorio) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(aleatorio)
+	return err == nil
+}
+
+// TamanoMaximoFirmado es el tamaño máximo en bytes del PDF firmado; 0 = sin límite
+func (s *reporteServicioService) TamanoMaximoFirmado() int64 {
+	return s.validacionPDF.TamanoMaximo
+}
+
+// ObtenerURLFirmado genera una URL firmada temporal para descargar la versión vigente del PDF firmado
 func (s *reporteServicioService) ObtenerURLFirmado(reporteID uint) (string, error) {
 	if reporteID == 0 {
 		return "", errors.New("ID de reporte no válido")
 	}
-	if s.storage == nil {
-		return "", errors.New("servicio de almacenamiento no configurado")
-	}
 
 	reporte, err := s.reporteRepo.FindByID(reporteID)
 	if err != nil {
@@ -210,40 +389,74 @@ func (s *reporteServicioService) ObtenerURLFirmado(reporteID uint) (string, erro
 		return "", errors.New("este reporte no tiene un PDF firmado")
 	}
 
-	// Generar URL firmada (válida por 1 hora = 3600 segundos)
-	fileName := fmt.Sprintf("reporte_%d_firmado.pdf", reporteID)
-	signedURL, err := s.storage.GetSignedURL(fileName, 3600)
+	documento, err := s.documentoRepo.FindUltima(reporteID)
 	if err != nil {
-		return "", fmt.Errorf("error al generar URL de descarga: %w", err)
+		return "", errors.New("este reporte no tiene un PDF firmado")
+	}
+	return s.urlDocumento(documento)
+}
+
+// GetDocumentosFirmados obtiene el historial de versiones del PDF firmado de un reporte
+func (s *reporteServicioService) GetDocumentosFirmados(reporteID uint) ([]models.DocumentoReporte, error) {
+	if reporteID == 0 {
+		return nil, errors.New("ID de reporte no válido")
+	}
+	if _, err := s.reporteRepo.FindByID(reporteID); err != nil {
+		return nil, errors.New("reporte no encontrado")
 	}
+	return s.documentoRepo.FindByReporteID(reporteID)
+}
 
+// ObtenerURLDocumentoFirmado genera una URL firmada temporal para descargar una versión del PDF firmado,
+// aunque el reporte se haya reabierto después
+func (s *reporteServicioService) ObtenerURLDocumentoFirmado(reporteID uint, version int) (string, error) {
+	if reporteID == 0 || version < 1 {
+		return "", errors.New("ID de reporte o versión no válidos")
+	}
+
+	documento, err := s.documentoRepo.FindVersion(reporteID, version)
+	if err != nil {
+		return "", errors.New("versión del documento no encontrada")
+	}
+	return s.urlDocumento(documento)
+}
+
+// urlDocumento genera la URL de descarga de una versión (válida por 1 hora)
+func (s *reporteServicioService) urlDocumento(documento *models.DocumentoReporte) (string, error) {
+	signedURL, err := s.storage.SignedURL(documento.Archivo, time.Hour)
+	if err != nil {
+		return "", fmt.Errorf("error al generar URL de descarga: %w", err)
+	}
 	return signedURL, nil
 }
 
-// ReabrirReporte elimina el PDF firmado del storage y reabre el reporte
-func (s *reporteServicioService) ReabrirReporte(reporteID uint) error {
+// ReabrirReporte reabre un reporte cerrado. El PDF firmado no se elimina: queda en el
+// historial de versiones junto con el motivo de la reapertura.
+func (s *reporteServicioService) ReabrirReporte(ctx context.Context, reporteID uint, motivo string) error {
 	if reporteID == 0 {
 		return errors.New("ID de reporte no válido")
 	}
-	if s.storage == nil {
-		return errors.New("servicio de almacenamiento no configurado")
+	motivo = strings.TrimSpace(motivo)
+	if motivo == "" {
+		return errors.New("el motivo de la reapertura es obligatorio")
 	}
 
-	// Verificar que el reporte existe y está cerrado
-	reporte, err := s.reporteRepo.FindByID(reporteID)
-	if err != nil {
+	// Verificar que el reporte existe
+	if _, err := s.reporteRepo.FindByID(reporteID); err != nil {
 		return errors.New("reporte no encontrado")
 	}
-	if reporte.FechaCierre == nil {
-		return errors.New("el reporte no está cerrado")
-	}
 
-	// Eliminar el archivo del storage
-	fileName := fmt.Sprintf("reporte_%d_firmado.pdf", reporteID)
-	if err := s.storage.Delete(fileName); err != nil {
-		return fmt.Errorf("error al eliminar el archivo: %w", err)
+	var reabiertoPorID *uint
+	var reabiertoPorUsername string
+	if actor, ok := auditoria.ActorDesde(ctx); ok {
+		reabiertoPorID = &actor.UsuarioID
+		reabiertoPorUsername = actor.Username
 	}
 
-	// Reabrir el reporte en la BD
-	return s.reporteRepo.ReabrirReporte(reporteID)
+	return s.transacciones.Ejecutar(ctx, func(ctx context.Context) error {
+		if err := s.reporteRepo.ReabrirReporte(ctx, reporteID, motivo, reabiertoPorID, reabiertoPorUsername); err != nil {
+			return err
+		}
+		return s.notificacion.ReporteReabierto(ctx, reporteID, motivo)
+	})
 }
@@ -1,18 +1,20 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 )
 
 // RepuestoService define las operaciones del servicio para Repuesto
 type RepuestoService interface {
-	CreateRepuesto(repuesto *models.Repuesto) error
+	CreateRepuesto(ctx context.Context, repuesto *models.Repuesto) error
 	GetRepuestoByID(id uint) (*models.Repuesto, error)
-	UpdateRepuesto(repuesto *models.Repuesto) error
-	DeleteRepuesto(id uint) error
-	GetAllRepuestos() ([]models.Repuesto, error)
+	UpdateRepuesto(ctx context.Context, repuesto *models.Repuesto) error
+	DeleteRepuesto(ctx context.Context, id uint) error
+	GetAllRepuestos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Repuesto], error)
 	GetRepuestosByReporteID(reporteID uint) ([]models.Repuesto, error)
 }
 
@@ -27,18 +29,19 @@ func NewRepuestoService(repuestoRepo repositories.RepuestoRepository) RepuestoSe
 }
 
 // CreateRepuesto crea un nuevo repuesto
-func (s *repuestoService) CreateRepuesto(repuesto *models.Repuesto) error {
-	if repuesto.SerialNumeroParte == "" {
+func (s *repuestoService) CreateRepuesto(ctx context.Context, repuesto *models.Repuesto) error {
+	// Los repuestos del almacén toman el serial y la descripción del artículo
+	if repuesto.SerialNumeroParte == "" && repuesto.ArticuloID == nil {
 		return errors.New("el serial o número de parte es obligatorio")
 	}
-	if repuesto.Descripcion == "" {
+	if repuesto.Descripcion == "" && repuesto.ArticuloID == nil {
 		return errors.New("la descripción es obligatoria")
 	}
 	if repuesto.Cantidad <= 0 {
 		return errors.New("la cantidad debe ser mayor que cero")
 	}
 
-	return s.repuestoRepo.Create(repuesto)
+	return s.repuestoRepo.Create(ctx, repuesto)
 }
 
 // GetRepuestoByID obtiene un repuesto por su ID
@@ -47,7 +50,7 @@ func (s *repuestoService) GetRepuestoByID(id uint) (*models.Repuesto, error) {
 }
 
 // UpdateRepuesto actualiza un repuesto existente
-func (s *repuestoService) UpdateRepuesto(repuesto *models.Repuesto) error {
+func (s *repuestoService) UpdateRepuesto(ctx context.Context, repuesto *models.Repuesto) error {
 	if repuesto.ID == 0 {
 		return errors.New("ID de repuesto no válido")
 	}
@@ -67,20 +70,33 @@ func (s *repuestoService) UpdateRepuesto(repuesto *models.Repuesto) error {
 		return errors.New("repuesto no encontrado")
 	}
 
-	return s.repuestoRepo.Update(repuesto)
+	// La cantidad de un repuesto del almacén ya está descontada en el kardex
+	if existente != nil && existente.ArticuloID != nil {
+		if repuesto.Cantidad != existente.Cantidad || repuesto.ArticuloID == nil || *repuesto.ArticuloID != *existente.ArticuloID {
+			return errors.New("no se puede cambiar la cantidad ni el artículo de un repuesto del almacén; elimínelo y regístrelo de nuevo")
+		}
+	} else if repuesto.ArticuloID != nil {
+		return errors.New("un repuesto existente no se puede asociar al almacén; elimínelo y regístrelo de nuevo")
+	}
+
+	return s.repuestoRepo.Update(ctx, repuesto)
 }
 
 // DeleteRepuesto elimina un repuesto por su ID
-func (s *repuestoService) DeleteRepuesto(id uint) error {
+func (s *repuestoService) DeleteRepuesto(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de repuesto no válido")
 	}
-	return s.repuestoRepo.Delete(id)
+	return s.repuestoRepo.Delete(ctx, id)
 }
 
 // GetAllRepuestos obtiene todos los repuestos
-func (s *repuestoService) GetAllRepuestos() ([]models.Repuesto, error) {
-	return s.repuestoRepo.FindAll()
+func (s *repuestoService) GetAllRepuestos(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Repuesto], error) {
+	registros, total, err := s.repuestoRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.Repuesto]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetRepuestosByReporteID obtiene todos los repuestos asociados a un reporte
@@ -1,18 +1,20 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 )
 
 // SecretariaService define la interfaz para operaciones de servicio de Secretaria
 type SecretariaService interface {
-	CreateSecretaria(secretaria *models.Secretaria) error
+	CreateSecretaria(ctx context.Context, secretaria *models.Secretaria) error
 	GetSecretariaByID(id uint) (*models.Secretaria, error)
-	GetAllSecretarias() ([]models.Secretaria, error)
-	UpdateSecretaria(secretaria *models.Secretaria) error
-	DeleteSecretaria(id uint) error
+	GetAllSecretarias(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Secretaria], error)
+	UpdateSecretaria(ctx context.Context, secretaria *models.Secretaria) error
+	DeleteSecretaria(ctx context.Context, id uint) error
 }
 
 // secretariaService implementa SecretariaService
@@ -27,7 +29,7 @@ func NewSecretariaService(repo repositories.SecretariaRepository, depRepo reposi
 }
 
 // CreateSecretaria crea una nueva Secretaria
-func (s *secretariaService) CreateSecretaria(secretaria *models.Secretaria) error {
+func (s *secretariaService) CreateSecretaria(ctx context.Context, secretaria *models.Secretaria) error {
 	if secretaria.Nombre == "" {
 		return errors.New("el nombre de la secretaría/ es obligatorio")
 	}
@@ -41,7 +43,7 @@ func (s *secretariaService) CreateSecretaria(secretaria *models.Secretaria) erro
 		return errors.New("el nombre del secretario es obligatorio")
 	}
 
-	return s.repo.CreateSecretaria(secretaria)
+	return s.repo.CreateSecretaria(ctx, secretaria)
 }
 
 // GetSecretariaByID obtiene una Secretaria por su ID
@@ -50,12 +52,16 @@ func (s *secretariaService) GetSecretariaByID(id uint) (*models.Secretaria, erro
 }
 
 // GetAllSecretarias obtiene todas las Secretarias
-func (s *secretariaService) GetAllSecretarias() ([]models.Secretaria, error) {
-	return s.repo.GetAllSecretarias()
+func (s *secretariaService) GetAllSecretarias(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Secretaria], error) {
+	registros, total, err := s.repo.GetAllSecretarias(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.Secretaria]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // UpdateSecretaria actualiza una Secretaria existente
-func (s *secretariaService) UpdateSecretaria(secretaria *models.Secretaria) error {
+func (s *secretariaService) UpdateSecretaria(ctx context.Context, secretaria *models.Secretaria) error {
 	if secretaria.ID == 0 {
 		return errors.New("ID de secretaría/ no válido")
 	}
@@ -72,11 +78,11 @@ func (s *secretariaService) UpdateSecretaria(secretaria *models.Secretaria) erro
 		return errors.New("el nombre del secretario es obligatorio")
 	}
 
-	return s.repo.UpdateSecretaria(secretaria)
+	return s.repo.UpdateSecretaria(ctx, secretaria)
 }
 
 // DeleteSecretaria elimina una Secretaria por su ID, liberando usuarios y eliminando dependencias
-func (s *secretariaService) DeleteSecretaria(id uint) error {
+func (s *secretariaService) DeleteSecretaria(ctx context.Context, id uint) error {
 	// Verificar que la secretaría existe y cargar sus dependencias
 	secretaria, err := s.repo.GetSecretariaByID(id)
 	if err != nil {
@@ -88,13 +94,13 @@ func (s *secretariaService) DeleteSecretaria(id uint) error {
 		// Liberar usuarios responsables (poner dependencia_id en NULL no es posible porque es NOT NULL)
 		// Así que simplemente eliminamos la dependencia — los usuarios quedan intactos
 		// porque GORM usa soft delete y no hay ON DELETE CASCADE en la FK
-		if err := s.depRepo.LiberarUsuariosDeDependencia(dep.ID); err != nil {
+		if err := s.depRepo.LiberarUsuariosDeDependencia(ctx, dep.ID); err != nil {
 			return errors.New("error al liberar usuarios de la dependencia: " + dep.Nombre)
 		}
-		if err := s.depRepo.DeleteDependencia(dep.ID); err != nil {
+		if err := s.depRepo.DeleteDependencia(ctx, dep.ID); err != nil {
 			return errors.New("error al eliminar la dependencia: " + dep.Nombre)
 		}
 	}
 
-	return s.repo.DeleteSecretaria(id)
+	return s.repo.DeleteSecretaria(ctx, id)
 }
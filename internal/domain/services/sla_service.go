@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// DiasPeriodoSLA es el periodo que se mide cuando la consulta no indica desde ni hasta
+const DiasPeriodoSLA = 30
+
+// Errores de las políticas de SLA
+var (
+	ErrPoliticaSLAInvalida     = errors.New("política de SLA inválida")
+	ErrPoliticaSLANoEncontrada = errors.New("política de SLA no encontrada")
+	ErrPoliticaSLADuplicada    = errors.New("ya existe una política de SLA para ese tipo de mantenimiento y prioridad")
+	ErrReporteSLANoEncontrado  = errors.New("reporte de servicio no encontrado")
+)
+
+// tiposMantenimientoSLA son los valores de PoliticaSLA.TipoMantenimiento; vacío = cualquiera
+var tiposMantenimientoSLA = []string{"", "PREVENTIVO", "CORRECTIVO", "OTRO"}
+
+// SLAService administra las políticas de SLA y mide contra ellas los tiempos de resolución
+// y de firma de los reportes de servicio
+type SLAService interface {
+	GetPoliticas() ([]models.PoliticaSLA, error)
+	GetPolitica(id uint) (*models.PoliticaSLA, error)
+	CreatePolitica(ctx context.Context, politica *models.PoliticaSLA) error
+	UpdatePolitica(ctx context.Context, politica *models.PoliticaSLA) error
+	DeletePolitica(ctx context.Context, id uint) error
+	EvaluarReporte(reporteID uint) (*dto.ReporteSLADTO, error)
+	GetCumplimiento(consulta dto.ConsultaDTO) (*dto.ResumenSLADTO, error)
+}
+
+// slaService implementa SLAService
+type slaService struct {
+	repo          repositories.PoliticaSLARepository
+	reporteRepo   repositories.ReporteServicioRepository
+	solicitudRepo repositories.SolicitudServicioRepository
+}
+
+// NewSLAService crea una nueva instancia de SLAService
+func NewSLAService(
+	repo repositories.PoliticaSLARepository,
+	reporteRepo repositories.ReporteServicioRepository,
+	solicitudRepo repositories.SolicitudServicioRepository,
+) SLAService {
+	return &slaService{repo: repo, reporteRepo: reporteRepo, solicitudRepo: solicitudRepo}
+}
+
+// GetPoliticas obtiene todas las políticas, activas e inactivas
+func (s *slaService) GetPoliticas() ([]models.PoliticaSLA, error) {
+	return s.repo.FindAll()
+}
+
+// GetPolitica obtiene una política por su ID
+func (s *slaService) GetPolitica(id uint) (*models.PoliticaSLA, error) {
+	politica, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPoliticaSLANoEncontrada
+	}
+	return politica, err
+}
+
+// CreatePolitica crea una política
+func (s *slaService) CreatePolitica(ctx context.Context, politica *models.PoliticaSLA) error {
+	if err := s.validarPolitica(politica); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, politica)
+}
+
+// UpdatePolitica cambia el alcance, los plazos o el estado de una política
+func (s *slaService) UpdatePolitica(ctx context.Context, politica *models.PoliticaSLA) error {
+	if _, err := s.GetPolitica(politica.ID); err != nil {
+		return err
+	}
+	if err := s.validarPolitica(politica); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, politica)
+}
+
+// DeletePolitica elimina una política; sus reportes pasan a medirse con la siguiente más específica
+func (s *slaService) DeletePolitica(ctx context.Context, id uint) error {
+	if _, err := s.GetPolitica(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// validarPolitica revisa el tipo de mantenimiento, la prioridad, los plazos y que no haya
+// otra política con el mismo alcance
+func (s *slaService) validarPolitica(politica *models.PoliticaSLA) error {
+	politica.TipoMantenimiento = strings.ToUpper(strings.TrimSpace(politica.TipoMantenimiento))
+	politica.Prioridad = strings.ToLower(strings.TrimSpace(politica.Prioridad))
+
+	valido := false
+	for _, tipo := range tiposMantenimientoSLA {
+		valido = valido || tipo == politica.TipoMantenimiento
+	}
+	if !valido {
+		return fmt.Errorf("%w: el tipo de mantenimiento debe ser PREVENTIVO, CORRECTIVO, OTRO o vacío", ErrPoliticaSLAInvalida)
+	}
+	valido = politica.Prioridad == ""
+	for _, prioridad := range prioridadesSolicitud {
+		valido = valido || prioridad == politica.Prioridad
+	}
+	if !valido {
+		return fmt.Errorf("%w: la prioridad debe ser baja, media, alta, critica o vacía", ErrPoliticaSLAInvalida)
+	}
+	if politica.HorasResolucion <= 0 || politica.HorasFirma <= 0 {
+		return fmt.Errorf("%w: las horas de resolución y de firma deben ser mayores que cero", ErrPoliticaSLAInvalida)
+	}
+
+	duplicada, err := s.repo.ExisteDuplicado(politica)
+	if err != nil {
+		return err
+	}
+	if duplicada {
+		return ErrPoliticaSLADuplicada
+	}
+	return nil
+}
+
+// EvaluarReporte mide un reporte contra su política
+func (s *slaService) EvaluarReporte(reporteID uint) (*dto.ReporteSLADTO, error) {
+	reporte, err := s.reporteRepo.FindByID(reporteID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReporteSLANoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	politicas, err := s.repo.FindActivas()
+	if err != nil {
+		return nil, err
+	}
+	prioridades, err := s.solicitudRepo.PrioridadesDeReportes([]uint{reporte.ID})
+	if err != nil {
+		return nil, err
+	}
+	evaluado := evaluarSLA(*reporte, prioridades[reporte.ID], politicas, time.Now())
+	return &evaluado, nil
+}
+
+// GetCumplimiento mide los reportes que cumplen la consulta (los mismos filtros del listado
+// de reportes, con desde/hasta sobre FechaInicio) y resume el cumplimiento general, por
+// técnico y por dependencia. Sin desde ni hasta se miden los últimos DiasPeriodoSLA días.
+func (s *slaService) GetCumplimiento(consulta dto.ConsultaDTO) (*dto.ResumenSLADTO, error) {
+	if consulta.Desde == nil && consulta.Hasta == nil {
+		desde := time.Now().AddDate(0, 0, -DiasPeriodoSLA)
+		consulta.Desde = &desde
+	}
+	consulta.Orden = "fecha_inicio"
+	consulta.Descendente = false
+
+	politicas, err := s.repo.FindActivas()
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now()
+	resumen := &dto.ResumenSLADTO{
+		Desde:          consulta.Desde,
+		Hasta:          consulta.Hasta,
+		PorTecnico:     []dto.CumplimientoSLADTO{},
+		PorDependencia: []dto.CumplimientoSLADTO{},
+		Incumplidos:    []dto.ReporteSLADTO{},
+	}
+	tecnicos := make(map[uint]*dto.CumplimientoSLADTO)
+	dependencias := make(map[string]*dto.CumplimientoSLADTO)
+
+	err = s.reporteRepo.ExportarReportes(consulta, func(lote []models.ReporteServicio) error {
+		ids := make([]uint, len(lote))
+		for i, reporte := range lote {
+			ids[i] = reporte.ID
+		}
+		prioridades, err := s.solicitudRepo.PrioridadesDeReportes(ids)
+		if err != nil {
+			return err
+		}
+
+		for _, reporte := range lote {
+			evaluado := evaluarSLA(reporte, prioridades[reporte.ID], politicas, ahora)
+			if evaluado.Estado == models.SLAIncumplido {
+				resumen.Incumplidos = append(resumen.Incumplidos, evaluado)
+			}
+
+			tecnico, ok := tecnicos[evaluado.TecnicoID]
+			if !ok {
+				tecnicoID := evaluado.TecnicoID
+				tecnico = &dto.CumplimientoSLADTO{TecnicoID: &tecnicoID, Tecnico: evaluado.Tecnico}
+				tecnicos[tecnicoID] = tecnico
+			}
+			dependencia, ok := dependencias[evaluado.Dependencia]
+			if !ok {
+				dependencia = &dto.CumplimientoSLADTO{Dependencia: evaluado.Dependencia}
+				dependencias[evaluado.Dependencia] = dependencia
+			}
+			for _, grupo := range []*dto.CumplimientoSLADTO{&resumen.General, tecnico, dependencia} {
+				contarSLA(grupo, evaluado.Estado)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	calcularPorcentajeSLA(&resumen.General)
+	for _, grupo := range tecnicos {
+		calcularPorcentajeSLA(grupo)
+		resumen.PorTecnico = append(resumen.PorTecnico, *grupo)
+	}
+	for _, grupo := range dependencias {
+		calcularPorcentajeSLA(grupo)
+		resumen.PorDependencia = append(resumen.PorDependencia, *grupo)
+	}
+	sort.Slice(resumen.PorTecnico, func(a, b int) bool {
+		return resumen.PorTecnico[a].Tecnico < resumen.PorTecnico[b].Tecnico
+	})
+	sort.Slice(resumen.PorDependencia, func(a, b int) bool {
+		return resumen.PorDependencia[a].Dependencia < resumen.PorDependencia[b].Dependencia
+	})
+	return resumen, nil
+}
+
+// evaluarSLA mide un reporte contra la política activa más específica para su tipo de
+// mantenimiento y prioridad. La resolución va de FechaInicio a FechaFinalizacion (o a
+// FechaCierre si se cerró sin fecha de finalización) y la firma de ahí a FechaCierre. Un
+// plazo que aún corre se marca incumplido en cuanto se vence.
+func evaluarSLA(reporte models.ReporteServicio, prioridad string, politicas []models.PoliticaSLA, ahora time.Time) dto.ReporteSLADTO {
+	tipo := reporte.TipoMantenimiento.Tipo
+	if tipo == "" {
+		tipo = "OTRO"
+	}
+	tecnico := strings.TrimSpace(reporte.CreadoPor.Nombre + " " + reporte.CreadoPor.Apellido)
+	if tecnico == "" {
+		tecnico = reporte.CreadoPor.Username
+	}
+	evaluado := dto.ReporteSLADTO{
+		ReporteID:         reporte.ID,
+		EquipoID:          reporte.EquipoID,
+		TecnicoID:         reporte.CreadoPorID,
+		Tecnico:           tecnico,
+		Dependencia:       strings.TrimSpace(reporte.Dependencia),
+		TipoMantenimiento: tipo,
+		Prioridad:         prioridad,
+		FechaInicio:       reporte.FechaInicio,
+		FechaFinalizacion: reporte.FechaFinalizacion,
+		FechaCierre:       reporte.FechaCierre,
+		EstadoResolucion:  models.SLASinPolitica,
+		EstadoFirma:       models.SLASinPolitica,
+		Estado:            models.SLASinPolitica,
+	}
+
+	// La más específica: coincide el tipo (2 puntos) y la prioridad (1 punto)
+	var politica *models.PoliticaSLA
+	mejor := -1
+	for i := range politicas {
+		p := &politicas[i]
+		if (p.TipoMantenimiento != "" && p.TipoMantenimiento != tipo) || (p.Prioridad != "" && p.Prioridad != prioridad) {
+			continue
+		}
+		puntos := 0
+		if p.TipoMantenimiento != "" {
+			puntos += 2
+		}
+		if p.Prioridad != "" {
+			puntos++
+		}
+		if puntos > mejor {
+			politica, mejor = p, puntos
+		}
+	}
+	if politica == nil {
+		return evaluado
+	}
+	evaluado.PoliticaID = &politica.ID
+	evaluado.LimiteResolucion = politica.HorasResolucion
+	evaluado.LimiteFirma = politica.HorasFirma
+
+	fin := reporte.FechaFinalizacion
+	if fin == nil {
+		fin = reporte.FechaCierre
+	}
+	evaluado.EstadoResolucion, evaluado.HorasResolucion = medirPlazoSLA(reporte.FechaInicio, fin, politica.HorasResolucion, ahora)
+	if fin == nil {
+		evaluado.EstadoFirma = models.SLAPendiente
+	} else {
+		evaluado.EstadoFirma, evaluado.HorasFirma = medirPlazoSLA(*fin, reporte.FechaCierre, politica.HorasFirma, ahora)
+	}
+
+	switch {
+	case evaluado.EstadoResolucion == models.SLAIncumplido || evaluado.EstadoFirma == models.SLAIncumplido:
+		evaluado.Estado = models.SLAIncumplido
+	case evaluado.EstadoResolucion == models.SLACumplido && evaluado.EstadoFirma == models.SLACumplido:
+		evaluado.Estado = models.SLACumplido
+	default:
+		evaluado.Estado = models.SLAPendiente
+	}
+	return evaluado
+}
+
+// medirPlazoSLA compara el tiempo entre inicio y fin con el límite en horas. Si fin es nil
+// el plazo sigue corriendo: está pendiente hasta que se vence.
+func medirPlazoSLA(inicio time.Time, fin *time.Time, limiteHoras int, ahora time.Time) (string, *float64) {
+	limite := time.Duration(limiteHoras) * time.Hour
+	if fin == nil {
+		if ahora.Sub(inicio) > limite {
+			return models.SLAIncumplido, nil
+		}
+		return models.SLAPendiente, nil
+	}
+	duracion := fin.Sub(inicio)
+	if duracion < 0 {
+		duracion = 0
+	}
+	horas := math.Round(duracion.Hours()*100) / 100
+	if duracion > limite {
+		return models.SLAIncumplido, &horas
+	}
+	return models.SLACumplido, &horas
+}
+
+// contarSLA suma un reporte al grupo según su estado
+func contarSLA(grupo *dto.CumplimientoSLADTO, estado string) {
+	grupo.Total++
+	switch estado {
+	case models.SLACumplido:
+		grupo.Cumplidos++
+	case models.SLAIncumplido:
+		grupo.Incumplidos++
+	case models.SLAPendiente:
+		grupo.Pendientes++
+	default:
+		grupo.SinPolitica++
+	}
+}
+
+// calcularPorcentajeSLA calcula el porcentaje de cumplimiento sobre los reportes ya medidos
+func calcularPorcentajeSLA(grupo *dto.CumplimientoSLADTO) {
+	medidos := grupo.Cumplidos + grupo.Incumplidos
+	if medidos == 0 {
+		return
+	}
+	porcentaje := math.Round(float64(grupo.Cumplidos)*1000/float64(medidos)) / 10
+	grupo.Porcentaje = &porcentaje
+}
@@ -1,18 +1,20 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 )
 
 // SoftwareService define las operaciones del servicio para Software
 type SoftwareService interface {
-	CreateSoftware(software *models.Software) error
+	CreateSoftware(ctx context.Context, software *models.Software) error
 	GetSoftwareByID(id uint) (*models.Software, error)
-	UpdateSoftware(software *models.Software) error
-	DeleteSoftware(id uint) error
-	GetAllSoftware() ([]models.Software, error)
+	UpdateSoftware(ctx context.Context, software *models.Software) error
+	DeleteSoftware(ctx context.Context, id uint) error
+	GetAllSoftware(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Software], error)
 	GetAllSoftwareByEquipoID(equipoID uint) ([]models.Software, error)
 }
 
@@ -27,14 +29,14 @@ func NewSoftwareService(softwareRepo repositories.SoftwareRepository) SoftwareSe
 }
 
 // CreateSoftware crea un nuevo software
-func (s *softwareService) CreateSoftware(software *models.Software) error {
+func (s *softwareService) CreateSoftware(ctx context.Context, software *models.Software) error {
 	if software.EquipoID == 0 {
 		return errors.New("el Id del equipo es obligatorio")
 	}
 	if software.Nombre == "" {
 		return errors.New("el nombre del software es obligatorio")
 	}
-	return s.softwareRepo.Create(software)
+	return s.softwareRepo.Create(ctx, software)
 }
 
 // GetSoftwareByID obtiene un software por ID
@@ -43,24 +45,28 @@ func (s *softwareService) GetSoftwareByID(id uint) (*models.Software, error) {
 }
 
 // UpdateSoftware actualiza un software existente
-func (s *softwareService) UpdateSoftware(software *models.Software) error {
+func (s *softwareService) UpdateSoftware(ctx context.Context, software *models.Software) error {
 	if software.ID == 0 {
 		return errors.New(("ID de software no válido"))
 	}
-	return s.softwareRepo.Update(software)
+	return s.softwareRepo.Update(ctx, software)
 }
 
 // DeleteSoftware elimina un software por su ID
-func (s *softwareService) DeleteSoftware(id uint) error {
+func (s *softwareService) DeleteSoftware(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de software no válido")
 	}
-	return s.softwareRepo.Delete(id)
+	return s.softwareRepo.Delete(ctx, id)
 }
 
 // GetAllSoftware obtiene todos los software
-func (s *softwareService) GetAllSoftware() ([]models.Software, error) {
-	return s.softwareRepo.FindAll()
+func (s *softwareService) GetAllSoftware(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.Software], error) {
+	registros, total, err := s.softwareRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.Software]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetAllSoftwareByEquipoID obtiene todos los software asociados a un equipo
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
+
+	"gorm.io/gorm"
+)
+
+// Errores de las solicitudes de servicio
+var (
+	ErrSolicitudInvalida       = errors.New("solicitud de servicio inválida")
+	ErrSolicitudNoEncontrada   = errors.New("solicitud de servicio no encontrada")
+	ErrSolicitudEstado         = errors.New("la solicitud no está en un estado que permita la operación")
+	ErrSolicitudNoPermitida    = errors.New("no puede realizar esta operación sobre la solicitud")
+	ErrSolicitudSinDependencia = errors.New("su usuario no está vinculado a una dependencia; verifique que su cédula corresponda a un usuario responsable")
+)
+
+// prioridadesSolicitud son los valores válidos de SolicitudServicio.Prioridad
+var prioridadesSolicitud = []string{models.PrioridadBaja, models.PrioridadMedia, models.PrioridadAlta, models.PrioridadCritica}
+
+// SolicitudServicioService administra las solicitudes de servicio: las abre un funcionario
+// sobre un equipo de su dependencia, se asignan a un técnico y se resuelven con un reporte
+// de servicio. usuarioID y rol son los del usuario autenticado.
+type SolicitudServicioService interface {
+	Crear(ctx context.Context, solicitud *models.SolicitudServicio, usuarioID uint, rol string) error
+	GetAll(consulta dto.ConsultaDTO, usuarioID uint, rol string) (dto.PaginaDTO[models.SolicitudServicio], error)
+	GetByID(id, usuarioID uint, rol string) (*models.SolicitudServicio, error)
+	Asignar(ctx context.Context, id, tecnicoID uint) (*models.SolicitudServicio, error)
+	Iniciar(ctx context.Context, id, usuarioID uint, rol string) (*models.SolicitudServicio, error)
+	Resolver(ctx context.Context, id uint, datos *dto.ResolverSolicitudDTO, usuarioID uint, rol string) (*models.SolicitudServicio, error)
+	Cancelar(ctx context.Context, id uint, motivo string, usuarioID uint, rol string) (*models.SolicitudServicio, error)
+}
+
+// solicitudServicioService implementa SolicitudServicioService
+type solicitudServicioService struct {
+	repo           repositories.SolicitudServicioRepository
+	usuarioRepo    repositories.UsuarioRepository
+	reporteService ReporteServicioService
+	notificacion   NotificacionService
+	transacciones  repositories.Transacciones
+}
+
+// NewSolicitudServicioService crea una nueva instancia de SolicitudServicioService.
+// notificacion avisa al técnico cuando se le asigna una solicitud.
+func NewSolicitudServicioService(
+	repo repositories.SolicitudServicioRepository,
+	usuarioRepo repositories.UsuarioRepository,
+	reporteService ReporteServicioService,
+	notificacion NotificacionService,
+	transacciones repositories.Transacciones,
+) SolicitudServicioService {
+	return &solicitudServicioService{repo: repo, usuarioRepo: usuarioRepo, reporteService: reporteService, notificacion: notificacion, transacciones: transacciones}
+}
+
+// Crear abre una solicitud sobre un equipo. El rol usuario solo puede abrirlas sobre los
+// equipos de su dependencia.
+func (s *solicitudServicioService) Crear(ctx context.Context, solicitud *models.SolicitudServicio, usuarioID uint, rol string) error {
+	solicitud.Descripcion = strings.TrimSpace(solicitud.Descripcion)
+	if solicitud.Descripcion == "" {
+		return fmt.Errorf("%w: la descripción es obligatoria", ErrSolicitudInvalida)
+	}
+	if solicitud.Prioridad == "" {
+		solicitud.Prioridad = models.PrioridadMedia
+	}
+	valida := false
+	for _, prioridad := range prioridadesSolicitud {
+		valida = valida || prioridad == solicitud.Prioridad
+	}
+	if !valida {
+		return fmt.Errorf("%w: la prioridad debe ser baja, media, alta o critica", ErrSolicitudInvalida)
+	}
+	if solicitud.EquipoID == 0 {
+		return fmt.Errorf("%w: el equipo es obligatorio", ErrSolicitudInvalida)
+	}
+
+	dependenciaEquipo, err := s.repo.DependenciaDeEquipo(solicitud.EquipoID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: el equipo %d no existe", ErrSolicitudInvalida, solicitud.EquipoID)
+	}
+	if err != nil {
+		return err
+	}
+	if rol == models.RolUsuario {
+		dependenciaUsuario, err := s.dependenciaUsuario(usuarioID)
+		if err != nil {
+			return err
+		}
+		if dependenciaEquipo == nil || *dependenciaEquipo != *dependenciaUsuario {
+			return fmt.Errorf("%w: el equipo no pertenece a su dependencia", ErrSolicitudNoPermitida)
+		}
+	}
+
+	solicitud.ID = 0
+	solicitud.DependenciaID = dependenciaEquipo
+	solicitud.SolicitanteID = usuarioID
+	solicitud.SolicitanteUsername = ""
+	if actor, ok := auditoria.ActorDesde(ctx); ok {
+		solicitud.SolicitanteUsername = actor.Username
+	}
+	solicitud.Estado = models.SolicitudAbierta
+	solicitud.TecnicoID = nil
+	solicitud.ReporteID = nil
+	solicitud.MotivoCancelacion = ""
+	solicitud.FechaAsignacion = nil
+	solicitud.FechaInicio = nil
+	solicitud.FechaResolucion = nil
+	return s.repo.Create(ctx, solicitud)
+}
+
+// GetAll obtiene una página de solicitudes; el rol usuario solo ve las de su dependencia
+func (s *solicitudServicioService) GetAll(consulta dto.ConsultaDTO, usuarioID uint, rol string) (dto.PaginaDTO[models.SolicitudServicio], error) {
+	var dependenciaID *uint
+	if rol == models.RolUsuario {
+		var err error
+		if dependenciaID, err = s.dependenciaUsuario(usuarioID); err != nil {
+			return dto.PaginaDTO[models.SolicitudServicio]{}, err
+		}
+	}
+	registros, total, err := s.repo.FindAll(consulta, dependenciaID)
+	if err != nil {
+		return dto.PaginaDTO[models.SolicitudServicio]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
+}
+
+// GetByID obtiene una solicitud. Para el rol usuario, las de otras dependencias no existen.
+func (s *solicitudServicioService) GetByID(id, usuarioID uint, rol string) (*models.SolicitudServicio, error) {
+	solicitud, err := s.buscar(id)
+	if err != nil {
+		return nil, err
+	}
+	if rol != models.RolUsuario || solicitud.SolicitanteID == usuarioID {
+		return solicitud, nil
+	}
+	dependenciaID, err := s.repo.DependenciaDeUsuario(usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	if dependenciaID == nil || solicitud.DependenciaID == nil || *dependenciaID != *solicitud.DependenciaID {
+		return nil, ErrSolicitudNoEncontrada
+	}
+	return solicitud, nil
+}
+
+// Asignar asigna la solicitud a un técnico (un usuario activo con rol técnico o admin).
+// Una solicitud asignada se puede reasignar mientras no se haya iniciado.
+func (s *solicitudServicioService) Asignar(ctx context.Context, id, tecnicoID uint) (*models.SolicitudServicio, error) {
+	if _, err := s.buscar(id); err != nil {
+		return nil, err
+	}
+	tecnico, err := s.usuarioRepo.FindByID(tecnicoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: el técnico %d no existe", ErrSolicitudInvalida, tecnicoID)
+		}
+		return nil, err
+	}
+	if !tecnico.Activo || (tecnico.Rol != models.RolTecnico && tecnico.Rol != models.RolAdmin) {
+		return nil, fmt.Errorf("%w: el usuario %s no es un técnico activo", ErrSolicitudInvalida, tecnico.Username)
+	}
+
+	solicitud, err := s.cambiarEstado(ctx, id, []string{models.SolicitudAbierta, models.SolicitudAsignada}, map[string]interface{}{
+		"estado":           models.SolicitudAsignada,
+		"tecnico_id":       tecnico.ID,
+		"fecha_asignacion": time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notificacion.SolicitudAsignada(ctx, solicitud)
+	return solicitud, nil
+}
+
+// Iniciar pasa a en progreso una solicitud asignada; solo el técnico asignado o un administrador
+func (s *solicitudServicioService) Iniciar(ctx context.Context, id, usuarioID uint, rol string) (*models.SolicitudServicio, error) {
+	solicitud, err := s.buscar(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := verificarTecnicoAsignado(solicitud, usuarioID, rol); err != nil {
+		return nil, err
+	}
+	return s.cambiarEstado(ctx, id, []string{models.SolicitudAsignada}, map[string]interface{}{
+		"estado":       models.SolicitudEnProgreso,
+		"fecha_inicio": time.Now(),
+	})
+}
+
+// Resolver cierra una solicitud en progreso con su reporte de servicio: vincula el reporte
+// existente datos.ReporteID (del mismo equipo) o crea uno nuevo con datos.Reporte. El
+// reporte nuevo siempre es del equipo de la solicitud y lo firma quien la resuelve. La
+// solicitud queda bloqueada mientras se crea o vincula el reporte y todo se guarda en una
+// sola transacción: si algo falla no queda un reporte huérfano.
+func (s *solicitudServicioService) Resolver(ctx context.Context, id uint, datos *dto.ResolverSolicitudDTO, usuarioID uint, rol string) (*models.SolicitudServicio, error) {
+	err := s.transacciones.Ejecutar(ctx, func(ctx context.Context) error {
+		solicitud, err := s.repo.Bloquear(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSolicitudNoEncontrada
+		}
+		if err != nil {
+			return err
+		}
+		if err := verificarTecnicoAsignado(solicitud, usuarioID, rol); err != nil {
+			return err
+		}
+		if solicitud.Estado != models.SolicitudEnProgreso {
+			return fmt.Errorf("%w: solo se resuelven solicitudes en progreso (estado actual: %s)", ErrSolicitudEstado, solicitud.Estado)
+		}
+		if datos == nil || (datos.ReporteID == nil) == (datos.Reporte == nil) {
+			return fmt.Errorf("%w: indique reporte_id o los datos de un reporte nuevo, uno de los dos", ErrSolicitudInvalida)
+		}
+
+		reporteID, err := s.reporteResolucion(ctx, solicitud, datos, usuarioID)
+		if err != nil {
+			return err
+		}
+		// La solicitud está bloqueada y en progreso, así que el cambio siempre se aplica
+		_, err = s.repo.CambiarEstado(ctx, id, []string{models.SolicitudEnProgreso}, map[string]interface{}{
+			"estado":           models.SolicitudResuelta,
+			"reporte_id":       reporteID,
+			"fecha_resolucion": time.Now(),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.buscar(id)
+}
+
+// reporteResolucion obtiene el reporte con el que se resuelve la solicitud: verifica y
+// bloquea el reporte existente o crea uno nuevo en la transacción del contexto
+func (s *solicitudServicioService) reporteResolucion(ctx context.Context, solicitud *models.SolicitudServicio, datos *dto.ResolverSolicitudDTO, usuarioID uint) (uint, error) {
+	if datos.ReporteID != nil {
+		reporte, err := s.reporteService.GetReporteServicioByID(*datos.ReporteID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, fmt.Errorf("%w: el reporte %d no existe", ErrSolicitudInvalida, *datos.ReporteID)
+			}
+			return 0, err
+		}
+		if reporte.EquipoID != solicitud.EquipoID {
+			return 0, fmt.Errorf("%w: el reporte %d es de otro equipo", ErrSolicitudInvalida, reporte.ID)
+		}
+		vinculado, err := s.repo.ReporteVinculado(ctx, reporte.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("%w: el reporte %d no existe", ErrSolicitudInvalida, reporte.ID)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if vinculado {
+			return 0, fmt.Errorf("%w: el reporte %d ya resolvió otra solicitud", ErrSolicitudInvalida, reporte.ID)
+		}
+		return reporte.ID, nil
+	}
+
+	datos.Reporte.EquipoID = solicitud.EquipoID
+	datos.Reporte.CreadoPorID = usuarioID
+	if datos.Reporte.Dependencia == "" && solicitud.Dependencia != nil {
+		datos.Reporte.Dependencia = solicitud.Dependencia.Nombre
+	}
+	if datos.Reporte.FechaInicio.IsZero() && solicitud.FechaInicio != nil {
+		datos.Reporte.FechaInicio = *solicitud.FechaInicio
+	}
+	if datos.Reporte.DiagnosticoFalla == "" {
+		datos.Reporte.DiagnosticoFalla = solicitud.Descripcion
+	}
+	if datos.Reporte.Ubicacion == "" || datos.Reporte.ActividadRealizada == "" {
+		return 0, fmt.Errorf("%w: la ubicación y la actividad realizada del reporte son obligatorias", ErrSolicitudInvalida)
+	}
+	reporte, err := s.reporteService.RegistrarReporteConTipo(ctx, datos.Reporte)
+	if err != nil {
+		return 0, err
+	}
+	return reporte.ID, nil
+}
+
+// Cancelar cancela una solicitud que no se ha resuelto. El rol usuario solo puede cancelar
+// las que abrió.
+func (s *solicitudServicioService) Cancelar(ctx context.Context, id uint, motivo string, usuarioID uint, rol string) (*models.SolicitudServicio, error) {
+	solicitud, err := s.GetByID(id, usuarioID, rol)
+	if err != nil {
+		return nil, err
+	}
+	if rol == models.RolUsuario && solicitud.SolicitanteID != usuarioID {
+		return nil, fmt.Errorf("%w: solo quien abrió la solicitud puede cancelarla", ErrSolicitudNoPermitida)
+	}
+	motivo = strings.TrimSpace(motivo)
+	if motivo == "" {
+		return nil, fmt.Errorf("%w: el motivo de la cancelación es obligatorio", ErrSolicitudInvalida)
+	}
+	return s.cambiarEstado(ctx, id, []string{models.SolicitudAbierta, models.SolicitudAsignada, models.SolicitudEnProgreso}, map[string]interface{}{
+		"estado":             models.SolicitudCancelada,
+		"motivo_cancelacion": motivo,
+		"fecha_resolucion":   time.Now(),
+	})
+}
+
+// buscar obtiene una solicitud sin verificar quién la pide
+func (s *solicitudServicioService) buscar(id uint) (*models.SolicitudServicio, error) {
+	solicitud, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSolicitudNoEncontrada
+	}
+	return solicitud, err
+}
+
+// dependenciaUsuario obtiene la dependencia de un usuario con rol usuario; sin ella no
+// puede abrir ni consultar solicitudes
+func (s *solicitudServicioService) dependenciaUsuario(usuarioID uint) (*uint, error) {
+	dependenciaID, err := s.repo.DependenciaDeUsuario(usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	if dependenciaID == nil {
+		return nil, ErrSolicitudSinDependencia
+	}
+	return dependenciaID, nil
+}
+
+// cambiarEstado aplica la transición si la solicitud sigue en alguno de los estados de
+// origen y retorna la solicitud actualizada
+func (s *solicitudServicioService) cambiarEstado(ctx context.Context, id uint, desde []string, cambios map[string]interface{}) (*models.SolicitudServicio, error) {
+	ok, err := s.repo.CambiarEstado(ctx, id, desde, cambios)
+	if err != nil {
+		return nil, err
+	}
+	solicitud, err := s.buscar(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w (estado actual: %s)", ErrSolicitudEstado, solicitud.Estado)
+	}
+	return solicitud, nil
+}
+
+// verificarTecnicoAsignado permite trabajar la solicitud solo al técnico asignado o a un administrador
+func verificarTecnicoAsignado(solicitud *models.SolicitudServicio, usuarioID uint, rol string) error {
+	if rol == models.RolAdmin {
+		return nil
+	}
+	if solicitud.TecnicoID == nil || *solicitud.TecnicoID != usuarioID {
+		return fmt.Errorf("%w: la solicitud no está asignada a usted", ErrSolicitudNoPermitida)
+	}
+	return nil
+}
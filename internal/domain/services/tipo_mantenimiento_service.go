@@ -1,18 +1,20 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
 )
 
 // TipoMantenimientoService define las operaciones del servicio para TipoMantenimiento
 type TipoMantenimientoService interface {
-	CreateTipoMantenimiento(tipo *models.TipoMantenimiento) error
+	CreateTipoMantenimiento(ctx context.Context, tipo *models.TipoMantenimiento) error
 	GetTipoMantenimientoByID(id uint) (*models.TipoMantenimiento, error)
-	UpdateTipoMantenimiento(tipo *models.TipoMantenimiento) error
-	DeleteTipoMantenimiento(id uint) error
-	GetAllTiposMantenimiento() ([]models.TipoMantenimiento, error)
+	UpdateTipoMantenimiento(ctx context.Context, tipo *models.TipoMantenimiento) error
+	DeleteTipoMantenimiento(ctx context.Context, id uint) error
+	GetAllTiposMantenimiento(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.TipoMantenimiento], error)
 	GetTiposMantenimientoByReporteID(reporteID uint) ([]models.TipoMantenimiento, error)
 }
 
@@ -27,7 +29,7 @@ func NewTipoMantenimientoService(tipoRepo repositories.TipoMantenimientoReposito
 }
 
 // CreateTipoMantenimiento crea un nuevo tipo de mantenimiento
-func (s *tipoMantenimientoService) CreateTipoMantenimiento(tipo *models.TipoMantenimiento) error {
+func (s *tipoMantenimientoService) CreateTipoMantenimiento(ctx context.Context, tipo *models.TipoMantenimiento) error {
 	if tipo.ReporteID == 0 {
 		return errors.New("el ID del reporte es obligatorio")
 	}
@@ -35,7 +37,7 @@ func (s *tipoMantenimientoService) CreateTipoMantenimiento(tipo *models.TipoMant
 		return errors.New("el tipo de mantenimiento es obligatorio")
 	}
 
-	return s.tipoRepo.Create(tipo)
+	return s.tipoRepo.Create(ctx, tipo)
 }
 
 // GetTipoMantenimientoByID obtiene un tipo de mantenimiento por su ID
@@ -44,7 +46,7 @@ func (s *tipoMantenimientoService) GetTipoMantenimientoByID(id uint) (*models.Ti
 }
 
 // UpdateTipoMantenimiento actualiza un tipo de mantenimiento existente
-func (s *tipoMantenimientoService) UpdateTipoMantenimiento(tipo *models.TipoMantenimiento) error {
+func (s *tipoMantenimientoService) UpdateTipoMantenimiento(ctx context.Context, tipo *models.TipoMantenimiento) error {
 	if tipo.ID == 0 {
 		return errors.New("ID de tipo de mantenimiento no válido")
 	}
@@ -61,20 +63,24 @@ func (s *tipoMantenimientoService) UpdateTipoMantenimiento(tipo *models.TipoMant
 		return errors.New("tipo de mantenimiento no encontrado")
 	}
 
-	return s.tipoRepo.Update(tipo)
+	return s.tipoRepo.Update(ctx, tipo)
 }
 
 // DeleteTipoMantenimiento elimina un tipo de mantenimiento por su ID
-func (s *tipoMantenimientoService) DeleteTipoMantenimiento(id uint) error {
+func (s *tipoMantenimientoService) DeleteTipoMantenimiento(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de tipo de mantenimiento no válido")
 	}
-	return s.tipoRepo.Delete(id)
+	return s.tipoRepo.Delete(ctx, id)
 }
 
 // GetAllTiposMantenimiento obtiene todos los tipos de mantenimiento
-func (s *tipoMantenimientoService) GetAllTiposMantenimiento() ([]models.TipoMantenimiento, error) {
-	return s.tipoRepo.FindAll()
+func (s *tipoMantenimientoService) GetAllTiposMantenimiento(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.TipoMantenimiento], error) {
+	registros, total, err := s.tipoRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.TipoMantenimiento]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetTiposMantenimientoByReporteID obtiene todos los tipos de mantenimiento asociados a un reporte
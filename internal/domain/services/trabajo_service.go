@@ -0,0 +1,455 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
+	"tum_inv_backend/internal/infrastructure/config"
+	"tum_inv_backend/internal/infrastructure/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// intervaloCola es la espera de un trabajador cuando no hay trabajos disponibles
+	intervaloCola = 2 * time.Second
+	// intervaloLimpieza es cada cuánto se eliminan los trabajos vencidos
+	intervaloLimpieza = time.Hour
+	// esperaReintento es la espera antes del segundo intento; se duplica en cada intento
+	esperaReintento = 30 * time.Second
+	// vigenciaURLResultado es la duración de la URL de descarga del resultado
+	vigenciaURLResultado = time.Hour
+)
+
+var (
+	// ErrTrabajoInvalido indica que el tipo o los parámetros del trabajo no son válidos
+	ErrTrabajoInvalido = errors.New("trabajo inválido")
+	// ErrTrabajoNoEncontrado indica que el trabajo no existe
+	ErrTrabajoNoEncontrado = errors.New("trabajo no encontrado")
+	// ErrTrabajoSinResultado indica que el trabajo no ha terminado o no genera archivo
+	ErrTrabajoSinResultado = errors.New("el trabajo no tiene archivo para descargar")
+)
+
+// OpcionesTrabajos son los límites de la cola de trabajos
+type OpcionesTrabajos struct {
+	Trabajadores int
+	Arriendo     time.Duration
+	Intentos     int
+	Retencion    time.Duration
+}
+
+// OpcionesTrabajosDesde toma los límites de la cola de la configuración
+func OpcionesTrabajosDesde(cfg *config.Config) OpcionesTrabajos {
+	return OpcionesTrabajos{
+		Trabajadores: cfg.TrabajosTrabajadores,
+		Arriendo:     cfg.TrabajosArriendo,
+		Intentos:     cfg.TrabajosIntentos,
+		Retencion:    cfg.TrabajosRetencion,
+	}
+}
+
+// TrabajoService encola trabajos pesados y los ejecuta en segundo plano
+type TrabajoService interface {
+	Encolar(ctx context.Context, trabajo *models.Trabajo, entrada []byte) error
+	GetByID(id uint) (*models.Trabajo, error)
+	URLResultado(trabajo *models.Trabajo) (string, error)
+	// Iniciar arranca los trabajadores de esta instancia; se detienen al cancelar ctx
+	Iniciar(ctx context.Context)
+}
+
+// resultadoTrabajo es lo que produce la ejecución de un trabajo
+type resultadoTrabajo struct {
+	datos         []byte // Archivo para descargar; nil si el trabajo no genera archivo
+	archivo       string
+	tipoContenido string
+	resumen       any // Se guarda como JSON en Trabajo.Resumen
+}
+
+// manejadorTrabajo ejecuta un tipo de trabajo; progreso recibe el porcentaje de avance
+type manejadorTrabajo func(ctx context.Context, trabajo *models.Trabajo, progreso func(int)) (*resultadoTrabajo, error)
+
+// trabajoService implementa TrabajoService
+type trabajoService struct {
+	trabajoRepo    repositories.TrabajoRepository
+	almacenamiento storage.Provider
+	opciones       OpcionesTrabajos
+	manejadores    map[string]manejadorTrabajo
+}
+
+// NewTrabajoService crea una nueva instancia de TrabajoService
+func NewTrabajoService(
+	trabajoRepo repositories.TrabajoRepository,
+	almacenamiento storage.Provider,
+	opciones OpcionesTrabajos,
+	exportacionService ExportacionService,
+	importacionService ImportacionService,
+	loteService LoteReportesService,
+) TrabajoService {
+	s := &trabajoService{
+		trabajoRepo:    trabajoRepo,
+		almacenamiento: almacenamiento,
+		opciones:       opciones,
+	}
+	s.manejadores = map[string]manejadorTrabajo{
+		models.TrabajoExportarEquipos:     exportar("inventario_equipos", ".xlsx", TipoContenidoXLSX, exportacionService.ExportarEquiposXLSX),
+		models.TrabajoExportarPerifericos: exportar("perifericos", ".csv", TipoContenidoCSV, exportacionService.ExportarPerifericosCSV),
+		models.TrabajoExportarSoftware:    exportar("software", ".csv", TipoContenidoCSV, exportacionService.ExportarSoftwareCSV),
+		models.TrabajoExportarReportes:    exportar("reportes_servicio", ".csv", TipoContenidoCSV, exportacionService.ExportarReportesServicioCSV),
+		models.TrabajoImportarInventario:  s.importarInventario(importacionService),
+		models.TrabajoPDFLoteReportes:     pdfLoteReportes(loteService),
+	}
+	return s
+}
+
+// Encolar valida el trabajo y lo deja pendiente a nombre del usuario de ctx. entrada es el archivo que procesa el
+// trabajo (el de una importación), que se guarda en el almacenamiento hasta que termine.
+func (s *trabajoService) Encolar(ctx context.Context, trabajo *models.Trabajo, entrada []byte) error {
+	if _, ok := s.manejadores[trabajo.Tipo]; !ok {
+		return fmt.Errorf("%w: tipo desconocido %q", ErrTrabajoInvalido, trabajo.Tipo)
+	}
+	switch trabajo.Tipo {
+	case models.TrabajoImportarInventario:
+		if len(entrada) == 0 {
+			return fmt.Errorf("%w: la importación requiere un archivo", ErrTrabajoInvalido)
+		}
+	case models.TrabajoPDFLoteReportes:
+		if trabajo.Parametros.Formato == "" {
+			trabajo.Parametros.Formato = FormatoLoteZIP
+		}
+		if trabajo.Parametros.Formato != FormatoLoteZIP && trabajo.Parametros.Formato != FormatoLotePDF {
+			return fmt.Errorf("%w: %s", ErrTrabajoInvalido, ErrFormatoLote)
+		}
+	}
+
+	if len(entrada) > 0 {
+		ruta := fmt.Sprintf("trabajos/entradas/%s%s", nombreAleatorio(), path.Ext(trabajo.Parametros.Archivo))
+		if _, err := s.almacenamiento.Upload(ruta, entrada, "application/octet-stream"); err != nil {
+			return fmt.Errorf("error guardando el archivo del trabajo: %w", err)
+		}
+		trabajo.Parametros.Entrada = ruta
+	}
+
+	// El trabajo se ejecuta a nombre de quien lo encola, también para la auditoría
+	if actor, ok := auditoria.ActorDesde(ctx); ok {
+		trabajo.CreadoPorID = actor.UsuarioID
+		trabajo.CreadoPorUsername = actor.Username
+	}
+	trabajo.Estado = models.TrabajoPendiente
+	trabajo.MaxIntentos = s.opciones.Intentos
+	trabajo.DisponibleEn = time.Now()
+	if err := s.trabajoRepo.Create(ctx, trabajo); err != nil {
+		if trabajo.Parametros.Entrada != "" {
+			s.eliminarArchivo(trabajo.Parametros.Entrada)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByID obtiene un trabajo por su ID
+func (s *trabajoService) GetByID(id uint) (*models.Trabajo, error) {
+	trabajo, err := s.trabajoRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTrabajoNoEncontrado
+	}
+	return trabajo, err
+}
+
+// URLResultado genera una URL temporal para descargar el archivo de un trabajo completado
+func (s *trabajoService) URLResultado(trabajo *models.Trabajo) (string, error) {
+	if trabajo.Estado != models.TrabajoCompletado || trabajo.Resultado == "" {
+		return "", ErrTrabajoSinResultado
+	}
+	return s.almacenamiento.SignedURL(trabajo.Resultado, vigenciaURLResultado)
+}
+
+// Iniciar arranca los trabajadores y la limpieza de trabajos vencidos
+func (s *trabajoService) Iniciar(ctx context.Context) {
+	host, _ := os.Hostname()
+	for n := 1; n <= s.opciones.Trabajadores; n++ {
+		go s.trabajar(ctx, fmt.Sprintf("%s-%d-%d", host, os.Getpid(), n))
+	}
+	go s.limpiar(ctx)
+}
+
+// trabajar toma trabajos de la cola hasta que se cancele ctx
+func (s *trabajoService) trabajar(ctx context.Context, trabajador string) {
+	for ctx.Err() == nil {
+		trabajo, err := s.trabajoRepo.Arrendar(ctx, trabajador, s.opciones.Arriendo)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) && ctx.Err() == nil {
+				log.Printf("Error tomando trabajos de la cola: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(intervaloCola):
+			}
+			continue
+		}
+		s.ejecutar(ctx, trabajador, trabajo)
+	}
+}
+
+// ejecutar corre el trabajo arrendado, renovando el arriendo mientras dura, y guarda su
+// resultado. Si la instancia se detiene a mitad, el trabajo queda en proceso hasta que
+// venza el arriendo y otro trabajador lo retome.
+func (s *trabajoService) ejecutar(ctx context.Context, trabajador string, trabajo *models.Trabajo) {
+	// Un arriendo vencido cuenta como intento: el trabajo detuvo a la instancia o la instancia murió
+	if trabajo.Intentos > trabajo.MaxIntentos {
+		s.finalizar(trabajador, trabajo, nil, fmt.Errorf("se interrumpió %d veces sin terminar", trabajo.MaxIntentos))
+		return
+	}
+
+	actor := auditoria.Actor{UsuarioID: trabajo.CreadoPorID, Username: trabajo.CreadoPorUsername}
+	ctxTrabajo, cancelar := context.WithCancel(auditoria.ConActor(ctx, actor))
+	defer cancelar()
+
+	var progreso atomic.Int64
+	var renovacion sync.WaitGroup
+	renovacion.Add(1)
+	go func() {
+		defer renovacion.Done()
+		s.renovarArriendo(ctxTrabajo, cancelar, trabajador, trabajo.ID, &progreso)
+	}()
+
+	resultado, err := func() (resultado *resultadoTrabajo, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("error inesperado: %v", r)
+			}
+		}()
+		return s.manejadores[trabajo.Tipo](ctxTrabajo, trabajo, func(p int) { progreso.Store(int64(p)) })
+	}()
+	cancelar()
+	renovacion.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+	s.finalizar(trabajador, trabajo, resultado, err)
+}
+
+// renovarArriendo extiende el arriendo y guarda el progreso cada tercio del arriendo. Si
+// otro trabajador tomó el trabajo, cancela la ejecución.
+func (s *trabajoService) renovarArriendo(ctx context.Context, cancelar context.CancelFunc, trabajador string, id uint, progreso *atomic.Int64) {
+	ticker := time.NewTicker(s.opciones.Arriendo / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		vigente, err := s.trabajoRepo.Renovar(context.Background(), id, trabajador, time.Now().Add(s.opciones.Arriendo), int(progreso.Load()))
+		if err != nil {
+			log.Printf("Error renovando el trabajo %d: %v", id, err)
+			continue
+		}
+		if !vigente {
+			log.Printf("El trabajo %d pasó a otro trabajador; se detiene la ejecución", id)
+			cancelar()
+			return
+		}
+	}
+}
+
+// finalizar guarda el resultado de la ejecución. Un error programa otro intento, con una
+// espera que se duplica, salvo que sea un error de los datos o se agoten los intentos.
+func (s *trabajoService) finalizar(trabajador string, trabajo *models.Trabajo, resultado *resultadoTrabajo, err error) {
+	ahora := time.Now()
+	trabajo.ArrendadoHasta = nil
+	trabajo.Trabajador = ""
+
+	if err == nil && resultado != nil {
+		err = s.guardarResultado(trabajo, resultado)
+	}
+	switch {
+	case err == nil:
+		trabajo.Estado = models.TrabajoCompletado
+		trabajo.Progreso = 100
+		trabajo.FinalizadoEn = &ahora
+	case errorDefinitivo(err) || trabajo.Intentos >= trabajo.MaxIntentos:
+		trabajo.Estado = models.TrabajoFallido
+		trabajo.Error = err.Error()
+		trabajo.FinalizadoEn = &ahora
+	default:
+		trabajo.Estado = models.TrabajoPendiente
+		trabajo.Error = err.Error()
+		trabajo.Progreso = 0
+		trabajo.DisponibleEn = ahora.Add(esperaReintento << (trabajo.Intentos - 1))
+	}
+
+	vigente, errGuardar := s.trabajoRepo.Finalizar(context.Background(), trabajo, trabajador)
+	if errGuardar != nil || !vigente {
+		log.Printf("No se pudo guardar el resultado del trabajo %d (vigente: %t): %v", trabajo.ID, vigente, errGuardar)
+		if trabajo.Resultado != "" {
+			s.eliminarArchivo(trabajo.Resultado)
+		}
+		return
+	}
+	if trabajo.FinalizadoEn != nil && trabajo.Parametros.Entrada != "" {
+		s.eliminarArchivo(trabajo.Parametros.Entrada)
+	}
+}
+
+// guardarResultado sube el archivo generado y guarda el resumen en el trabajo
+func (s *trabajoService) guardarResultado(trabajo *models.Trabajo, resultado *resultadoTrabajo) error {
+	if resultado.resumen != nil {
+		resumen, err := json.Marshal(resultado.resumen)
+		if err != nil {
+			return err
+		}
+		trabajo.Resumen = resumen
+	}
+	if resultado.datos == nil {
+		return nil
+	}
+	ruta := fmt.Sprintf("trabajos/%d/%s", trabajo.ID, resultado.archivo)
+	if _, err := s.almacenamiento.Upload(ruta, resultado.datos, resultado.tipoContenido); err != nil {
+		return fmt.Errorf("error guardando el resultado: %w", err)
+	}
+	trabajo.Resultado = ruta
+	trabajo.ResultadoNombre = resultado.archivo
+	return nil
+}
+
+// limpiar elimina cada hora los trabajos terminados hace más de la retención, con sus archivos
+func (s *trabajoService) limpiar(ctx context.Context) {
+	ticker := time.NewTicker(intervaloLimpieza)
+	defer ticker.Stop()
+	for {
+		trabajos, err := s.trabajoRepo.FindTerminadosAntes(time.Now().Add(-s.opciones.Retencion))
+		if err != nil {
+			log.Printf("Error buscando trabajos vencidos: %v", err)
+		}
+		for _, trabajo := range trabajos {
+			for _, archivo := range []string{trabajo.Resultado, trabajo.Parametros.Entrada} {
+				if archivo != "" {
+					s.eliminarArchivo(archivo)
+				}
+			}
+			if err := s.trabajoRepo.Delete(ctx, trabajo.ID); err != nil {
+				log.Printf("Error eliminando el trabajo %d: %v", trabajo.ID, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// eliminarArchivo borra un archivo de un trabajo; si falla solo queda en el log
+func (s *trabajoService) eliminarArchivo(ruta string) {
+	if err := s.almacenamiento.Delete(ruta); err != nil && !errors.Is(err, storage.ErrArchivoNoEncontrado) {
+		log.Printf("Error eliminando el archivo %s: %v", ruta, err)
+	}
+}
+
+// errorDefinitivo indica si el error se debe a los datos del trabajo, que no cambian al reintentar
+func errorDefinitivo(err error) bool {
+	for _, definitivo := range []error{ErrTrabajoInvalido, dto.ErrConsultaInvalida, dto.ErrImportacionInvalida, ErrFormatoLote, ErrLoteVacio, ErrLoteExcedido} {
+		if errors.Is(err, definitivo) {
+			return true
+		}
+	}
+	return false
+}
+
+// consultaTrabajo arma la consulta de un listado con los parámetros del trabajo
+func consultaTrabajo(parametros models.ParametrosTrabajo) dto.ConsultaDTO {
+	return dto.ConsultaDTO{
+		Orden:       parametros.Orden,
+		Descendente: parametros.Descendente,
+		Filtros:     parametros.Filtros,
+		Desde:       parametros.Desde,
+		Hasta:       parametros.Hasta,
+	}
+}
+
+// exportar ejecuta una exportación del inventario y entrega el archivo con el mismo nombre
+// que la descarga directa
+func exportar(nombre, extension, tipoContenido string, generar func(dto.ConsultaDTO, io.Writer) error) manejadorTrabajo {
+	return func(_ context.Context, trabajo *models.Trabajo, _ func(int)) (*resultadoTrabajo, error) {
+		var buf bytes.Buffer
+		if err := generar(consultaTrabajo(trabajo.Parametros), &buf); err != nil {
+			return nil, err
+		}
+		return &resultadoTrabajo{
+			datos:         buf.Bytes(),
+			archivo:       fmt.Sprintf("%s_%s%s", nombre, trabajo.CreatedAt.Format("20060102"), extension),
+			tipoContenido: tipoContenido,
+		}, nil
+	}
+}
+
+// importarInventario importa el archivo guardado al encolar; el resultado, con los errores
+// por fila, queda en el resumen
+func (s *trabajoService) importarInventario(importacionService ImportacionService) manejadorTrabajo {
+	return func(ctx context.Context, trabajo *models.Trabajo, _ func(int)) (*resultadoTrabajo, error) {
+		archivo, err := s.almacenamiento.Open(trabajo.Parametros.Entrada)
+		if errors.Is(err, storage.ErrArchivoNoEncontrado) {
+			return nil, fmt.Errorf("%w: el archivo de la importación ya no existe", ErrTrabajoInvalido)
+		}
+		if err != nil {
+			return nil, err
+		}
+		datos, err := io.ReadAll(archivo)
+		archivo.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		resultado, err := importacionService.ImportarInventario(ctx, trabajo.Parametros.Archivo, datos, trabajo.Parametros.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		return &resultadoTrabajo{resumen: resultado}, nil
+	}
+}
+
+// pdfLoteReportes genera los PDF de los reportes filtrados, como ZIP o un solo PDF
+func pdfLoteReportes(loteService LoteReportesService) manejadorTrabajo {
+	return func(ctx context.Context, trabajo *models.Trabajo, progreso func(int)) (*resultadoTrabajo, error) {
+		var buf bytes.Buffer
+		formato := trabajo.Parametros.Formato
+		// El último tramo es unir o subir el archivo; el 100% se marca al terminar
+		avance := func(p int) { progreso(p * 95 / 100) }
+		if err := loteService.GenerarLote(ctx, consultaTrabajo(trabajo.Parametros), formato, trabajo.CreadoPorID, &buf, avance); err != nil {
+			return nil, err
+		}
+		tipoContenido := "application/zip"
+		if formato == FormatoLotePDF {
+			tipoContenido = "application/pdf"
+		}
+		return &resultadoTrabajo{
+			datos:         buf.Bytes(),
+			archivo:       fmt.Sprintf("reportes_servicio_%s.%s", trabajo.CreatedAt.Format("20060102"), formato),
+			tipoContenido: tipoContenido,
+		}, nil
+	}
+}
+
+// nombreAleatorio genera un nombre de archivo que no se repite entre trabajos
+func nombreAleatorio() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
+)
+
+// colaDePrueba guarda lo que el servicio entrega a Finalizar
+type colaDePrueba struct {
+	finalizado *models.Trabajo
+	trabajador string
+}
+
+func (c *colaDePrueba) Create(context.Context, *models.Trabajo) error { return nil }
+func (c *colaDePrueba) FindByID(uint) (*models.Trabajo, error)        { return nil, nil }
+func (c *colaDePrueba) Arrendar(context.Context, string, time.Duration) (*models.Trabajo, error) {
+	return nil, nil
+}
+func (c *colaDePrueba) Renovar(context.Context, uint, string, time.Time, int) (bool, error) {
+	return true, nil
+}
+func (c *colaDePrueba) Finalizar(_ context.Context, trabajo *models.Trabajo, trabajador string) (bool, error) {
+	copia := *trabajo
+	c.finalizado, c.trabajador = &copia, trabajador
+	return true, nil
+}
+func (c *colaDePrueba) FindTerminadosAntes(time.Time) ([]models.Trabajo, error) { return nil, nil }
+func (c *colaDePrueba) Delete(context.Context, uint) error                      { return nil }
+
+func TestFinalizarTrabajo(t *testing.T) {
+	errTemporal := errors.New("conexión reiniciada")
+
+	casos := []struct {
+		nombre      string
+		intentos    int
+		err         error
+		wantEstado  string
+		wantEspera  time.Duration // Espera antes del siguiente intento, si queda pendiente
+		wantErrorBD string
+	}{
+		{"termina bien", 1, nil, models.TrabajoCompletado, 0, ""},
+		{"primer error se reintenta", 1, errTemporal, models.TrabajoPendiente, esperaReintento, errTemporal.Error()},
+		{"la espera se duplica", 2, errTemporal, models.TrabajoPendiente, 2 * esperaReintento, errTemporal.Error()},
+		{"se agotan los intentos", 3, errTemporal, models.TrabajoFallido, 0, errTemporal.Error()},
+		{"error de los datos no se reintenta", 1, fmt.Errorf("%w: sort", dto.ErrConsultaInvalida), models.TrabajoFallido, 0, "consulta inválida: sort"},
+		{"lote vacío no se reintenta", 1, ErrLoteVacio, models.TrabajoFallido, 0, ErrLoteVacio.Error()},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			cola := &colaDePrueba{}
+			s := &trabajoService{trabajoRepo: cola}
+			hasta := time.Now().Add(time.Minute)
+			trabajo := &models.Trabajo{
+				Tipo:           models.TrabajoExportarSoftware,
+				Estado:         models.TrabajoEnProceso,
+				Intentos:       tc.intentos,
+				MaxIntentos:    3,
+				Progreso:       60,
+				Trabajador:     "trabajador-1",
+				ArrendadoHasta: &hasta,
+			}
+
+			antes := time.Now()
+			s.finalizar("trabajador-1", trabajo, nil, tc.err)
+
+			f := cola.finalizado
+			if f == nil || cola.trabajador != "trabajador-1" {
+				t.Fatalf("no se guardó el resultado a nombre del trabajador")
+			}
+			if f.Estado != tc.wantEstado || f.Error != tc.wantErrorBD {
+				t.Errorf("estado = %q, error = %q; se esperaba %q, %q", f.Estado, f.Error, tc.wantEstado, tc.wantErrorBD)
+			}
+			if f.Trabajador != "" || f.ArrendadoHasta != nil {
+				t.Errorf("el trabajo sigue arrendado: %q hasta %v", f.Trabajador, f.ArrendadoHasta)
+			}
+
+			switch tc.wantEstado {
+			case models.TrabajoPendiente:
+				espera := f.DisponibleEn.Sub(antes)
+				if espera < tc.wantEspera || espera > tc.wantEspera+time.Second {
+					t.Errorf("espera antes del reintento = %v, se esperaba %v", espera, tc.wantEspera)
+				}
+				if f.Progreso != 0 || f.FinalizadoEn != nil {
+					t.Errorf("un trabajo a la espera de otro intento no debe quedar finalizado: progreso %d, finalizado %v", f.Progreso, f.FinalizadoEn)
+				}
+			case models.TrabajoCompletado:
+				if f.Progreso != 100 || f.FinalizadoEn == nil {
+					t.Errorf("progreso = %d, finalizado = %v", f.Progreso, f.FinalizadoEn)
+				}
+			case models.TrabajoFallido:
+				if f.FinalizadoEn == nil {
+					t.Error("un trabajo fallido debe quedar finalizado")
+				}
+			}
+		})
+	}
+}
+
+func TestEjecutarTrabajoInterrumpido(t *testing.T) {
+	cola := &colaDePrueba{}
+	s := &trabajoService{trabajoRepo: cola, opciones: OpcionesTrabajos{Arriendo: time.Minute}}
+
+	// Un arriendo vencido más veces que el máximo falla sin volver a ejecutar el manejador
+	trabajo := &models.Trabajo{Tipo: "sin-manejador", Estado: models.TrabajoEnProceso, Intentos: 4, MaxIntentos: 3}
+	s.ejecutar(context.Background(), "trabajador-1", trabajo)
+
+	if cola.finalizado == nil || cola.finalizado.Estado != models.TrabajoFallido {
+		t.Fatalf("trabajo finalizado = %+v, se esperaba fallido", cola.finalizado)
+	}
+}
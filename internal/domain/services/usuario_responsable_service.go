@@ -1,21 +1,24 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
 )
 
 // UsuarioResponsableService define las operaciones del servicio para UsuarioResponsable
 type UsuarioResponsableService interface {
-	CreateUsuarioResponsable(usuario *models.UsuarioResponsable) error
+	CreateUsuarioResponsable(ctx context.Context, usuario *models.UsuarioResponsable) error
 	GetUsuarioResponsableByID(id uint) (*models.UsuarioResponsable, error)
-	UpdateUsuarioResponsable(usuario *models.UsuarioResponsable) error
-	DeleteUsuarioResponsable(id uint) error
-	GetAllUsuariosResponsables() ([]models.UsuarioResponsable, error)
+	UpdateUsuarioResponsable(ctx context.Context, usuario *models.UsuarioResponsable) error
+	DeleteUsuarioResponsable(ctx context.Context, id uint) error
+	GetAllUsuariosResponsables(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.UsuarioResponsable], error)
 	GetUsuarioResponsableByCedula(cedula string) (*models.UsuarioResponsable, error)
 	GetUsuariosByDependenciaID(dependenciaID uint) ([]models.UsuarioResponsable, error)
-	AsignarDependencia(usuarioID uint, dependenciaID *uint) error
+	AsignarDependencia(ctx context.Context, usuarioID uint, dependenciaID *uint) error
 }
 
 // usuarioResponsableService implementa UsuarioResponsableService
@@ -29,7 +32,7 @@ func NewUsuarioResponsableService(usuarioRepo repositories.UsuarioResponsableRep
 }
 
 // CreateUsuarioResponsable crea un nuevo usuario responsable
-func (s *usuarioResponsableService) CreateUsuarioResponsable(usuario *models.UsuarioResponsable) error {
+func (s *usuarioResponsableService) CreateUsuarioResponsable(ctx context.Context, usuario *models.UsuarioResponsable) error {
 	if usuario.NombresApellidos == "" {
 		return errors.New("los nombres y apellidos son obligatorios")
 	}
@@ -43,7 +46,7 @@ func (s *usuarioResponsableService) CreateUsuarioResponsable(usuario *models.Usu
 		return errors.New("ya existe un usuario con esta cédula")
 	}
 
-	return s.usuarioRepo.Create(usuario)
+	return s.usuarioRepo.Create(ctx, usuario)
 }
 
 // GetUsuarioResponsableByID obtiene un usuario responsable por su ID
@@ -52,7 +55,7 @@ func (s *usuarioResponsableService) GetUsuarioResponsableByID(id uint) (*models.
 }
 
 // UpdateUsuarioResponsable actualiza un usuario responsable existente
-func (s *usuarioResponsableService) UpdateUsuarioResponsable(usuario *models.UsuarioResponsable) error {
+func (s *usuarioResponsableService) UpdateUsuarioResponsable(ctx context.Context, usuario *models.UsuarioResponsable) error {
 	if usuario.ID == 0 {
 		return errors.New("ID de usuario no válido")
 	}
@@ -72,11 +75,11 @@ func (s *usuarioResponsableService) UpdateUsuarioResponsable(usuario *models.Usu
 		return errors.New("ya existe otro usuario con esta cédula")
 	}
 
-	return s.usuarioRepo.Update(usuario)
+	return s.usuarioRepo.Update(ctx, usuario)
 }
 
 // DeleteUsuarioResponsable elimina un usuario responsable por su ID
-func (s *usuarioResponsableService) DeleteUsuarioResponsable(id uint) error {
+func (s *usuarioResponsableService) DeleteUsuarioResponsable(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de usuario no válido")
 	}
@@ -91,12 +94,16 @@ func (s *usuarioResponsableService) DeleteUsuarioResponsable(id uint) error {
 	// 	return errors.New("no se puede eliminar el usuario porque tiene equipos asociados")
 	// }
 
-	return s.usuarioRepo.Delete(id)
+	return s.usuarioRepo.Delete(ctx, id)
 }
 
 // GetAllUsuariosResponsables obtiene todos los usuarios responsables
-func (s *usuarioResponsableService) GetAllUsuariosResponsables() ([]models.UsuarioResponsable, error) {
-	return s.usuarioRepo.FindAll()
+func (s *usuarioResponsableService) GetAllUsuariosResponsables(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.UsuarioResponsable], error) {
+	registros, total, err := s.usuarioRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.UsuarioResponsable]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetUsuarioResponsableByCedula obtiene un usuario responsable por su cédula
@@ -116,9 +123,11 @@ func (s *usuarioResponsableService) GetUsuariosByDependenciaID(dependenciaID uin
 }
 
 // AsignarDependencia asigna una dependencia a un usuario responsable (solo actualiza el FK)
-func (s *usuarioResponsableService) AsignarDependencia(usuarioID uint, dependenciaID *uint) error {
+func (s *usuarioResponsableService) AsignarDependencia(ctx context.Context, usuarioID uint, dependenciaID *uint) error {
 	if usuarioID == 0 {
 		return errors.New("ID de usuario no válido")
 	}
-	return s.usuarioRepo.AsignarDependencia(usuarioID, dependenciaID)
+	// Registrar el cambio como asignación en la auditoría
+	ctx = auditoria.ConAccion(ctx, auditoria.AccionAsignar)
+	return s.usuarioRepo.AsignarDependencia(ctx, usuarioID, dependenciaID)
 }
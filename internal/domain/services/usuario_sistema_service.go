@@ -1,48 +1,54 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/domain/models/dto"
 	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/auditoria"
 )
 
 // UsuarioSistemaService define las operaciones del servicio para UsuarioSistema
 type UsuarioSistemaService interface {
-	CreateUsuarioSistema(usuario *models.UsuarioSistema) error
+	CreateUsuarioSistema(ctx context.Context, usuario *models.UsuarioSistema) error
 	GetUsuarioSistemaByID(id uint) (*models.UsuarioSistema, error)
-	UpdateUsuarioSistema(usuario *models.UsuarioSistema) error
-	DeleteUsuarioSistema(id uint) error
-	GetAllUsuariosSistema() ([]models.UsuarioSistema, error)
+	UpdateUsuarioSistema(ctx context.Context, usuario *models.UsuarioSistema) error
+	DeleteUsuarioSistema(ctx context.Context, id uint) error
+	GetAllUsuariosSistema(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.UsuarioSistema], error)
 	GetUsuariosSistemaByEquipoID(equipoID uint) ([]models.UsuarioSistema, error)
 	GetUsuarioSistemaByNombreUsuario(nombreUsuario string, equipoID uint) (*models.UsuarioSistema, error)
+	RevelarContrasena(ctx context.Context, id uint) (string, error)
 }
 
 // usuarioSistemaService implementa UsuarioSistemaService
 type usuarioSistemaService struct {
-	usuarioRepo repositories.UsuarioSistemaRepository
+	usuarioRepo      repositories.UsuarioSistemaRepository
+	auditoriaService AuditoriaService
 }
 
 // NewUsuarioSistemaService crea una nueva instancia de UsuarioSistemaService
-func NewUsuarioSistemaService(usuarioRepo repositories.UsuarioSistemaRepository) UsuarioSistemaService {
-	return &usuarioSistemaService{usuarioRepo: usuarioRepo}
+func NewUsuarioSistemaService(usuarioRepo repositories.UsuarioSistemaRepository, auditoriaService AuditoriaService) UsuarioSistemaService {
+	return &usuarioSistemaService{usuarioRepo: usuarioRepo, auditoriaService: auditoriaService}
 }
 
 // CreateUsuarioSistema crea un nuevo usuario del sistema
-func (s *usuarioSistemaService) CreateUsuarioSistema(usuario *models.UsuarioSistema) error {
+func (s *usuarioSistemaService) CreateUsuarioSistema(ctx context.Context, usuario *models.UsuarioSistema) error {
 	if usuario.EquipoID == 0 {
 		return errors.New("el ID del equipo es obligatorio")
 	}
 	if usuario.NombreUsuario == "" {
 		return errors.New("el nombre de usuario es obligatorio")
 	}
-	
+
 	// Verificar si ya existe un usuario con el mismo nombre en el mismo equipo
 	existente, err := s.usuarioRepo.FindByNombreUsuario(usuario.NombreUsuario, usuario.EquipoID)
 	if err == nil && existente != nil {
 		return errors.New("ya existe un usuario con este nombre en el equipo")
 	}
-	
-	return s.usuarioRepo.Create(usuario)
+
+	return s.usuarioRepo.Create(ctx, usuario)
 }
 
 // GetUsuarioSistemaByID obtiene un usuario del sistema por su ID
@@ -51,7 +57,7 @@ func (s *usuarioSistemaService) GetUsuarioSistemaByID(id uint) (*models.UsuarioS
 }
 
 // UpdateUsuarioSistema actualiza un usuario del sistema existente
-func (s *usuarioSistemaService) UpdateUsuarioSistema(usuario *models.UsuarioSistema) error {
+func (s *usuarioSistemaService) UpdateUsuarioSistema(ctx context.Context, usuario *models.UsuarioSistema) error {
 	if usuario.ID == 0 {
 		return errors.New("ID de usuario no válido")
 	}
@@ -61,13 +67,18 @@ func (s *usuarioSistemaService) UpdateUsuarioSistema(usuario *models.UsuarioSist
 	if usuario.NombreUsuario == "" {
 		return errors.New("el nombre de usuario es obligatorio")
 	}
-	
+
 	// Verificar si existe el usuario
 	existente, err := s.usuarioRepo.FindByID(usuario.ID)
 	if err != nil {
 		return errors.New("usuario no encontrado")
 	}
-	
+
+	// Conservar la contraseña almacenada si el cliente reenvía el valor enmascarado
+	if usuario.Contrasena == models.ContrasenaOculta {
+		usuario.Contrasena = existente.Contrasena
+	}
+
 	// Verificar si al cambiar el nombre de usuario no se genera conflicto con otro usuario en el mismo equipo
 	if existente.NombreUsuario != usuario.NombreUsuario || existente.EquipoID != usuario.EquipoID {
 		otro, err := s.usuarioRepo.FindByNombreUsuario(usuario.NombreUsuario, usuario.EquipoID)
@@ -75,21 +86,25 @@ func (s *usuarioSistemaService) UpdateUsuarioSistema(usuario *models.UsuarioSist
 			return errors.New("ya existe un usuario con este nombre en el equipo")
 		}
 	}
-	
-	return s.usuarioRepo.Update(usuario)
+
+	return s.usuarioRepo.Update(ctx, usuario)
 }
 
 // DeleteUsuarioSistema elimina un usuario del sistema por su ID
-func (s *usuarioSistemaService) DeleteUsuarioSistema(id uint) error {
+func (s *usuarioSistemaService) DeleteUsuarioSistema(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("ID de usuario no válido")
 	}
-	return s.usuarioRepo.Delete(id)
+	return s.usuarioRepo.Delete(ctx, id)
 }
 
 // GetAllUsuariosSistema obtiene todos los usuarios del sistema
-func (s *usuarioSistemaService) GetAllUsuariosSistema() ([]models.UsuarioSistema, error) {
-	return s.usuarioRepo.FindAll()
+func (s *usuarioSistemaService) GetAllUsuariosSistema(consulta dto.ConsultaDTO) (dto.PaginaDTO[models.UsuarioSistema], error) {
+	registros, total, err := s.usuarioRepo.FindAll(consulta)
+	if err != nil {
+		return dto.PaginaDTO[models.UsuarioSistema]{}, err
+	}
+	return dto.NuevaPagina(registros, total, consulta), nil
 }
 
 // GetUsuariosSistemaByEquipoID obtiene todos los usuarios del sistema asociados a un equipo
@@ -109,4 +124,21 @@ func (s *usuarioSistemaService) GetUsuarioSistemaByNombreUsuario(nombreUsuario s
 		return nil, errors.New("ID de equipo no válido")
 	}
 	return s.usuarioRepo.FindByNombreUsuario(nombreUsuario, equipoID)
-}
\ No newline at end of file
+}
+
+// RevelarContrasena obtiene la contraseña descifrada de un usuario del sistema y registra la consulta en la auditoría
+func (s *usuarioSistemaService) RevelarContrasena(ctx context.Context, id uint) (string, error) {
+	if id == 0 {
+		return "", errors.New("ID de usuario no válido")
+	}
+	usuario, err := s.usuarioRepo.FindByID(id)
+	if err != nil {
+		return "", errors.New("usuario no encontrado")
+	}
+
+	// Toda consulta de la contraseña en claro debe quedar en la auditoría
+	if err := s.auditoriaService.RegistrarEvento(ctx, "usuario_sistemas", usuario.ID, &usuario.EquipoID, auditoria.AccionRevelar); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrRevelarSinAuditoria, err)
+	}
+	return usuario.Contrasena, nil
+}
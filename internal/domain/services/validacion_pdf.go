@@ -0,0 +1,136 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/infrastructure/config"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func init() {
+	// pdfcpu no debe leer ni crear su directorio de configuración en el servidor
+	api.DisableConfigDir()
+}
+
+// OpcionesValidacionPDF son las reglas que debe cumplir un PDF firmado antes de cerrar un reporte
+type OpcionesValidacionPDF struct {
+	TamanoMaximo     int64 // Bytes
+	PaginasMinimas   int   // El formato de reporte tiene dos páginas
+	VerificarReporte bool  // Exige que el PDF identifique el reporte; no funciona con PDF escaneados
+}
+
+// OpcionesValidacionPDFDesde toma las reglas de validación de la configuración
+func OpcionesValidacionPDFDesde(cfg *config.Config) OpcionesValidacionPDF {
+	return OpcionesValidacionPDF{
+		TamanoMaximo:     int64(cfg.PDFFirmadoTamanoMaximoMB) * 1024 * 1024,
+		PaginasMinimas:   cfg.PDFFirmadoPaginasMinimas,
+		VerificarReporte: cfg.PDFFirmadoVerificarReporte,
+	}
+}
+
+// patronIdentificadorReporte encuentra en el contenido de una página el texto que escribe
+// identificadorReporte, tal como queda en el content stream del PDF: (Reporte No. 15) Tj
+var patronIdentificadorReporte = regexp.MustCompile(`\(Reporte No\. (\d+)\)`)
+
+// validarPDFFirmado revisa que los datos sean un PDF legible, sin cifrar, con las páginas del
+// formato y, si se pide, que corresponda al reporte. Retorna *dto.ErrorValidacionPDF con los problemas.
+func validarPDFFirmado(datos []byte, reporteID uint, opciones OpcionesValidacionPDF) error {
+	invalido := func(problemas ...dto.ProblemaPDFDTO) error {
+		return &dto.ErrorValidacionPDF{Problemas: problemas}
+	}
+
+	if opciones.TamanoMaximo > 0 && int64(len(datos)) > opciones.TamanoMaximo {
+		return invalido(dto.ProblemaPDFDTO{
+			Codigo:  dto.ProblemaPDFTamano,
+			Mensaje: fmt.Sprintf("el archivo no debe superar los %d MB", opciones.TamanoMaximo/(1024*1024)),
+		})
+	}
+
+	// La firma %PDF- puede venir después de algunos bytes basura, que los lectores toleran
+	cabecera := datos
+	if len(cabecera) > 1024 {
+		cabecera = cabecera[:1024]
+	}
+	if !bytes.Contains(cabecera, []byte("%PDF-")) {
+		return invalido(dto.ProblemaPDFDTO{Codigo: dto.ProblemaPDFFormato, Mensaje: "el archivo no es un PDF"})
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(datos), model.NewDefaultConfiguration())
+	if err != nil {
+		if errors.Is(err, pdfcpu.ErrWrongPassword) || errors.Is(err, pdfcpu.ErrUnknownEncryption) {
+			return invalido(dto.ProblemaPDFDTO{Codigo: dto.ProblemaPDFCifrado, Mensaje: "el PDF está protegido con contraseña"})
+		}
+		return invalido(dto.ProblemaPDFDTO{Codigo: dto.ProblemaPDFCorrupto, Mensaje: "el PDF está dañado o no se puede leer"})
+	}
+	// Un PDF con solo contraseña de propietario se puede leer, pero tampoco se acepta
+	if ctx.Encrypt != nil {
+		return invalido(dto.ProblemaPDFDTO{Codigo: dto.ProblemaPDFCifrado, Mensaje: "el PDF está cifrado"})
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		return invalido(dto.ProblemaPDFDTO{Codigo: dto.ProblemaPDFCorrupto, Mensaje: "la estructura del PDF no es válida"})
+	}
+
+	var problemas []dto.ProblemaPDFDTO
+	if ctx.PageCount < opciones.PaginasMinimas {
+		problemas = append(problemas, dto.ProblemaPDFDTO{
+			Codigo:  dto.ProblemaPDFPaginas,
+			Mensaje: fmt.Sprintf("el PDF tiene %d página(s) y el reporte firmado debe tener al menos %d", ctx.PageCount, opciones.PaginasMinimas),
+		})
+	}
+
+	if opciones.VerificarReporte {
+		if problema := verificarIdentificadorReporte(ctx, reporteID); problema != nil {
+			problemas = append(problemas, *problema)
+		}
+	}
+
+	if len(problemas) > 0 {
+		return invalido(problemas...)
+	}
+	return nil
+}
+
+// verificarIdentificadorReporte busca en el texto de las páginas el número de reporte que
+// escribe el PDF generado; retorna el problema si no aparece o es de otro reporte
+func verificarIdentificadorReporte(ctx *model.Context, reporteID uint) *dto.ProblemaPDFDTO {
+	esperado := strconv.FormatUint(uint64(reporteID), 10)
+	var encontrados []string
+
+	for pagina := 1; pagina <= ctx.PageCount; pagina++ {
+		r, err := pdfcpu.ExtractPageContent(ctx, pagina)
+		if err != nil {
+			continue
+		}
+		contenido, err := io.ReadAll(r)
+		if err != nil {
+			continue
+		}
+		for _, m := range patronIdentificadorReporte.FindAllSubmatch(contenido, -1) {
+			numero := strings.TrimLeft(string(m[1]), "0")
+			if numero == esperado {
+				return nil
+			}
+			encontrados = append(encontrados, numero)
+		}
+	}
+
+	if len(encontrados) > 0 {
+		return &dto.ProblemaPDFDTO{
+			Codigo:  dto.ProblemaPDFDocumento,
+			Mensaje: fmt.Sprintf("el PDF corresponde al reporte %s, no al reporte %s", encontrados[0], esperado),
+		}
+	}
+	return &dto.ProblemaPDFDTO{
+		Codigo:  dto.ProblemaPDFDocumento,
+		Mensaje: fmt.Sprintf("no se encontró en el PDF la identificación del reporte %s", esperado),
+	}
+}
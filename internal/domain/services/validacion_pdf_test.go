@@ -0,0 +1,94 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"tum_inv_backend/internal/domain/models/dto"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// pdfDePrueba genera un PDF con el número de páginas pedido; si reporteID no es cero,
+// escribe en la primera página el identificador del reporte como lo hace PDFReporteService
+func pdfDePrueba(t *testing.T, paginas int, reporteID uint, proteger func(*fpdf.Fpdf)) []byte {
+	t.Helper()
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	if proteger != nil {
+		proteger(pdf)
+	}
+	pdf.SetFont("Arial", "", 10)
+	for i := 0; i < paginas; i++ {
+		pdf.AddPage()
+		if i == 0 && reporteID != 0 {
+			pdf.Text(150, 34, identificadorReporte(reporteID))
+		}
+		pdf.Text(20, 50, "Contenido de prueba")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("generando PDF de prueba: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidarPDFFirmado(t *testing.T) {
+	dosPaginas := pdfDePrueba(t, 2, 15, nil)
+	conBasura := append([]byte("basura\n"), dosPaginas...)
+	conContrasena := pdfDePrueba(t, 2, 15, func(p *fpdf.Fpdf) { p.SetProtection(fpdf.CnProtectPrint, "usuario", "propietario") })
+	soloPropietario := pdfDePrueba(t, 2, 15, func(p *fpdf.Fpdf) { p.SetProtection(fpdf.CnProtectPrint, "", "propietario") })
+
+	base := OpcionesValidacionPDF{TamanoMaximo: 1024 * 1024, PaginasMinimas: 2}
+	conReporte := base
+	conReporte.VerificarReporte = true
+
+	casos := []struct {
+		nombre    string
+		datos     []byte
+		reporteID uint
+		opciones  OpcionesValidacionPDF
+		want      []string // Códigos esperados; vacío si el PDF es válido
+	}{
+		{"válido", dosPaginas, 15, base, nil},
+		{"válido con el reporte correcto", dosPaginas, 15, conReporte, nil},
+		{"bytes previos a la cabecera", conBasura, 15, base, nil},
+		{"sin límite de tamaño", dosPaginas, 15, OpcionesValidacionPDF{PaginasMinimas: 2}, nil},
+		{"supera el tamaño", dosPaginas, 15, OpcionesValidacionPDF{TamanoMaximo: 100, PaginasMinimas: 2}, []string{dto.ProblemaPDFTamano}},
+		{"no es PDF", []byte("PK\x03\x04 esto es un zip"), 15, base, []string{dto.ProblemaPDFFormato}},
+		{"vacío", nil, 15, base, []string{dto.ProblemaPDFFormato}},
+		{"truncado", dosPaginas[:len(dosPaginas)/2], 15, base, []string{dto.ProblemaPDFCorrupto}},
+		{"con contraseña de usuario", conContrasena, 15, base, []string{dto.ProblemaPDFCifrado}},
+		{"con contraseña de propietario", soloPropietario, 15, base, []string{dto.ProblemaPDFCifrado}},
+		{"faltan páginas", pdfDePrueba(t, 1, 15, nil), 15, base, []string{dto.ProblemaPDFPaginas}},
+		{"de otro reporte", dosPaginas, 16, conReporte, []string{dto.ProblemaPDFDocumento}},
+		{"sin identificador", pdfDePrueba(t, 2, 0, nil), 15, conReporte, []string{dto.ProblemaPDFDocumento}},
+		{"sin identificador sin verificar", pdfDePrueba(t, 2, 0, nil), 15, base, nil},
+		{"varios problemas", pdfDePrueba(t, 1, 16, nil), 15, conReporte, []string{dto.ProblemaPDFPaginas, dto.ProblemaPDFDocumento}},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			err := validarPDFFirmado(tc.datos, tc.reporteID, tc.opciones)
+			if len(tc.want) == 0 {
+				if err != nil {
+					t.Fatalf("error inesperado: %v", err)
+				}
+				return
+			}
+
+			var errValidacion *dto.ErrorValidacionPDF
+			if !errors.As(err, &errValidacion) {
+				t.Fatalf("se esperaba *dto.ErrorValidacionPDF, se obtuvo %v", err)
+			}
+			if len(errValidacion.Problemas) != len(tc.want) {
+				t.Fatalf("problemas = %+v, se esperaban los códigos %v", errValidacion.Problemas, tc.want)
+			}
+			for i, codigo := range tc.want {
+				if errValidacion.Problemas[i].Codigo != codigo {
+					t.Errorf("problema %d = %q, se esperaba %q", i, errValidacion.Problemas[i].Codigo, codigo)
+				}
+			}
+		})
+	}
+}
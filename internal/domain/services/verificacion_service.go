@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"tum_inv_backend/internal/domain/models/dto"
+	"tum_inv_backend/internal/domain/repositories"
+	"tum_inv_backend/internal/infrastructure/verificacion"
+
+	"gorm.io/gorm"
+)
+
+// ErrReporteNoVerificable indica que el código es válido pero el reporte ya no existe
+var ErrReporteNoVerificable = errors.New("el reporte del código ya no existe")
+
+// VerificacionService confirma la autenticidad de los reportes impresos a partir de su código
+type VerificacionService interface {
+	VerificarReporte(codigo string) (*dto.VerificacionReporteDTO, error)
+}
+
+// verificacionService implementa VerificacionService
+type verificacionService struct {
+	reporteRepo   repositories.ReporteServicioRepository
+	documentoRepo repositories.DocumentoReporteRepository
+	verificador   *verificacion.Verificador
+}
+
+// NewVerificacionService crea una nueva instancia de VerificacionService
+func NewVerificacionService(
+	reporteRepo repositories.ReporteServicioRepository,
+	documentoRepo repositories.DocumentoReporteRepository,
+	verificador *verificacion.Verificador,
+) VerificacionService {
+	return &verificacionService{
+		reporteRepo:   reporteRepo,
+		documentoRepo: documentoRepo,
+		verificador:   verificador,
+	}
+}
+
+// VerificarReporte comprueba la firma del código y retorna los datos del reporte.
+// Retorna verificacion.ErrCodigoInvalido si el código no fue emitido por el servidor.
+func (s *verificacionService) VerificarReporte(codigo string) (*dto.VerificacionReporteDTO, error) {
+	reporteID, err := s.verificador.ReporteID(codigo)
+	if err != nil {
+		return nil, err
+	}
+
+	reporte, err := s.reporteRepo.FindByID(reporteID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReporteNoVerificable
+		}
+		return nil, err
+	}
+
+	tipo := reporte.TipoMantenimiento.Tipo
+	if tipo == "" {
+		tipo = "OTRO"
+	}
+	resultado := &dto.VerificacionReporteDTO{
+		Codigo:            s.verificador.Codigo(reporte.ID),
+		ReporteID:         reporte.ID,
+		FechaInicio:       reporte.FechaInicio,
+		FechaFinalizacion: reporte.FechaFinalizacion,
+		Dependencia:       reporte.Dependencia,
+		Ubicacion:         reporte.Ubicacion,
+		TipoMantenimiento: tipo,
+		EquipoPlaca:       reporte.Equipo.PlacaInventario,
+		EquipoSerial:      reporte.Equipo.Serial,
+		EquipoMarca:       reporte.Equipo.Marca,
+		EquipoModelo:      reporte.Equipo.Modelo,
+		Cerrado:           reporte.FechaCierre != nil,
+		FechaCierre:       reporte.FechaCierre,
+	}
+
+	if resultado.Cerrado {
+		documento, err := s.documentoRepo.FindUltima(reporte.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if documento != nil {
+			resultado.Firmado = true
+			resultado.VersionFirmada = documento.Version
+			resultado.SHA256Firmado = documento.SHA256
+		}
+	}
+
+	return resultado, nil
+}
@@ -0,0 +1,55 @@
+package auditoria
+
+import "context"
+
+// Acciones registradas en la auditoría
+const (
+	AccionCrear      = "crear"
+	AccionActualizar = "actualizar"
+	AccionEliminar   = "eliminar"
+	AccionAsignar    = "asignar"
+	AccionRevelar    = "revelar"
+)
+
+// Actor identifica al usuario autenticado que ejecuta un cambio
+type Actor struct {
+	UsuarioID uint
+	Username  string
+}
+
+type claveContexto int
+
+const (
+	claveActor claveContexto = iota
+	claveAccion
+)
+
+// ConActor retorna un contexto que identifica al usuario que realiza los cambios
+func ConActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, claveActor, actor)
+}
+
+// ActorDesde obtiene el actor guardado en el contexto
+func ActorDesde(ctx context.Context) (Actor, bool) {
+	if ctx == nil {
+		return Actor{}, false
+	}
+	actor, ok := ctx.Value(claveActor).(Actor)
+	return actor, ok
+}
+
+// ConAccion fuerza la acción con la que se registran los cambios hechos con
+// este contexto (por ejemplo "asignar" en lugar de "actualizar")
+func ConAccion(ctx context.Context, accion string) context.Context {
+	return context.WithValue(ctx, claveAccion, accion)
+}
+
+// accionDesde obtiene la acción forzada en el contexto, o la acción por defecto
+func accionDesde(ctx context.Context, porDefecto string) string {
+	if ctx != nil {
+		if accion, ok := ctx.Value(claveAccion).(string); ok && accion != "" {
+			return accion
+		}
+	}
+	return porDefecto
+}
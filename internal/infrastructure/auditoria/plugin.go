@@ -0,0 +1,328 @@
+package auditoria
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+	"tum_inv_backend/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// claveAntes guarda en el statement los registros leídos antes de actualizar o eliminar
+const claveAntes = "auditoria:antes"
+
+// columnasOcultas nunca se guardan en la auditoría
+var columnasOcultas = map[string]bool{
+	"password":   true,
+	"contrasena": true,
+}
+
+// columnasIgnoradas no cuentan como cambio al comparar antes y después
+var columnasIgnoradas = map[string]bool{
+	"created_at":   true,
+	"updated_at":   true,
+	"ultimo_login": true,
+}
+
+// tablasNoAuditadas son tablas internas cuyos cambios no son del inventario; la cola de
+// trabajos, la bandeja de correos y las notificaciones cambian con cada intento o lectura y
+// llenarían la auditoría
+var tablasNoAuditadas = map[string]bool{
+	models.Auditoria{}.TableName():      true,
+	models.Trabajo{}.TableName():        true,
+	models.CorreoSaliente{}.TableName(): true,
+	models.Notificacion{}.TableName():   true,
+}
+
+// Plugin registra en la tabla de auditoría cada creación, actualización y
+// eliminación hecha con GORM. Los registros se escriben en la misma
+// transacción que el cambio auditado.
+type Plugin struct{}
+
+// Name implementa gorm.Plugin
+func (Plugin) Name() string {
+	return "auditoria"
+}
+
+// Initialize registra los callbacks de auditoría
+func (Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("auditoria:despues_crear", despuesDeCrear); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("auditoria:antes_actualizar", leerAntes); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("auditoria:despues_actualizar", despuesDeActualizar); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("auditoria:antes_eliminar", leerAntes); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("auditoria:despues_eliminar", despuesDeEliminar)
+}
+
+// registro es una fila serializada como columna -> valor
+type registro map[string]interface{}
+
+// auditable indica si el statement corresponde a un modelo que se debe auditar
+func auditable(db *gorm.DB) bool {
+	stmt := db.Statement
+	return db.Error == nil && !db.DryRun && stmt.Schema != nil &&
+		!tablasNoAuditadas[stmt.Schema.Table] &&
+		stmt.Schema.PrioritizedPrimaryField != nil
+}
+
+func despuesDeCrear(db *gorm.DB) {
+	if !auditable(db) {
+		return
+	}
+
+	var entradas []models.Auditoria
+	recorrer(db.Statement.ReflectValue, func(v reflect.Value) {
+		despues := serializar(db, v)
+		entradas = append(entradas, nuevaEntrada(db, accionDesde(db.Statement.Context, AccionCrear), nil, despues))
+	})
+	guardar(db, entradas)
+}
+
+// leerAntes carga las filas que serán afectadas por una actualización o eliminación
+func leerAntes(db *gorm.DB) {
+	if !auditable(db) {
+		return
+	}
+	stmt := db.Statement
+
+	var exprs []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			exprs = append(exprs, where.Exprs...)
+		}
+	}
+	// Save y Delete(&modelo) filtran por la llave primaria del valor recibido
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		for _, campo := range stmt.Schema.PrimaryFields {
+			if valor, esCero := campo.ValueOf(stmt.Context, stmt.ReflectValue); !esCero {
+				exprs = append(exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: campo.DBName}, Value: valor})
+			}
+		}
+	}
+	if len(exprs) == 0 {
+		return
+	}
+
+	filas := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+	err := sesion(db).Model(reflect.New(stmt.Schema.ModelType).Interface()).
+		Clauses(clause.Where{Exprs: exprs}).Find(filas.Interface()).Error
+	if err != nil {
+		log.Printf("auditoria: no se pudo leer %s antes del cambio: %v", stmt.Schema.Table, err)
+		return
+	}
+
+	antes := make(map[interface{}]registro)
+	recorrer(filas.Elem(), func(v reflect.Value) {
+		id, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, v)
+		antes[id] = serializar(db, v)
+	})
+	if len(antes) > 0 {
+		stmt.Settings.Store(claveAntes, antes)
+	}
+}
+
+func despuesDeActualizar(db *gorm.DB) {
+	if !auditable(db) {
+		return
+	}
+	stmt := db.Statement
+	antes, ok := leidosAntes(db)
+	if !ok {
+		return
+	}
+
+	ids := make([]interface{}, 0, len(antes))
+	for id := range antes {
+		ids = append(ids, id)
+	}
+	filas := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+	err := sesion(db).Unscoped().Model(reflect.New(stmt.Schema.ModelType).Interface()).
+		Where(clause.IN{Column: clause.PrimaryColumn, Values: ids}).Find(filas.Interface()).Error
+	if err != nil {
+		log.Printf("auditoria: no se pudo leer %s después del cambio: %v", stmt.Schema.Table, err)
+		return
+	}
+
+	accion := accionDesde(stmt.Context, AccionActualizar)
+	var entradas []models.Auditoria
+	recorrer(filas.Elem(), func(v reflect.Value) {
+		id, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, v)
+		despues := serializar(db, v)
+		// Solo se registran las filas que realmente cambiaron
+		if len(diferencias(antes[id], despues)) == 0 {
+			return
+		}
+		entradas = append(entradas, nuevaEntrada(db, accion, antes[id], despues))
+	})
+	guardar(db, entradas)
+}
+
+func despuesDeEliminar(db *gorm.DB) {
+	if !auditable(db) {
+		return
+	}
+	antes, ok := leidosAntes(db)
+	if !ok {
+		return
+	}
+
+	accion := accionDesde(db.Statement.Context, AccionEliminar)
+	var entradas []models.Auditoria
+	for _, fila := range antes {
+		entradas = append(entradas, nuevaEntrada(db, accion, fila, nil))
+	}
+	guardar(db, entradas)
+}
+
+// nuevaEntrada arma el registro de auditoría para una fila
+func nuevaEntrada(db *gorm.DB, accion string, antes, despues registro) models.Auditoria {
+	stmt := db.Statement
+	fila := despues
+	if fila == nil {
+		fila = antes
+	}
+
+	entrada := models.Auditoria{
+		Entidad:   stmt.Schema.Table,
+		EntidadID: aUint(fila[stmt.Schema.PrioritizedPrimaryField.DBName]),
+		Accion:    accion,
+		Username:  "sistema",
+	}
+	if actor, ok := ActorDesde(stmt.Context); ok {
+		usuarioID := actor.UsuarioID
+		entrada.UsuarioID = &usuarioID
+		entrada.Username = actor.Username
+	}
+
+	// Relacionar el cambio con el equipo para construir su historial. Si el
+	// registro se desvincula del equipo se conserva el equipo anterior.
+	if stmt.Schema.ModelType == reflect.TypeOf(models.Equipo{}) {
+		entrada.EquipoID = &entrada.EntidadID
+	} else if campo := stmt.Schema.LookUpField("EquipoID"); campo != nil {
+		equipoID := aUint(fila[campo.DBName])
+		if equipoID == 0 && antes != nil {
+			equipoID = aUint(antes[campo.DBName])
+		}
+		if equipoID != 0 {
+			entrada.EquipoID = &equipoID
+		}
+	}
+
+	entrada.Antes = aJSON(antes)
+	entrada.Despues = aJSON(despues)
+	if antes != nil && despues != nil {
+		entrada.Cambios = aJSON(diferencias(antes, despues))
+	}
+	return entrada
+}
+
+// guardar inserta los registros de auditoría en la misma conexión (y transacción) del cambio
+func guardar(db *gorm.DB, entradas []models.Auditoria) {
+	if len(entradas) == 0 {
+		return
+	}
+	if err := sesion(db).Create(&entradas).Error; err != nil {
+		db.AddError(err)
+	}
+}
+
+// sesion retorna una sesión limpia que comparte la conexión y el contexto del statement
+func sesion(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true, Context: db.Statement.Context, SkipHooks: true})
+}
+
+// leidosAntes retorna las filas guardadas por leerAntes
+func leidosAntes(db *gorm.DB) (map[interface{}]registro, bool) {
+	valor, ok := db.Statement.Settings.Load(claveAntes)
+	if !ok {
+		return nil, false
+	}
+	antes, ok := valor.(map[interface{}]registro)
+	return antes, ok && len(antes) > 0
+}
+
+// serializar convierte una fila en un mapa columna -> valor sin los campos ocultos
+func serializar(db *gorm.DB, v reflect.Value) registro {
+	stmt := db.Statement
+	fila := make(registro)
+	for _, campo := range stmt.Schema.Fields {
+		if campo.DBName == "" || columnasOcultas[campo.DBName] {
+			continue
+		}
+		valor, _ := campo.ValueOf(stmt.Context, v)
+		fila[campo.DBName] = valor
+	}
+	return fila
+}
+
+// diferencias retorna los campos que cambiaron entre dos versiones de una fila
+func diferencias(antes, despues registro) map[string]map[string]interface{} {
+	cambios := make(map[string]map[string]interface{})
+	for columna, nuevo := range despues {
+		if columnasIgnoradas[columna] {
+			continue
+		}
+		anterior := antes[columna]
+		a, _ := json.Marshal(anterior)
+		b, _ := json.Marshal(nuevo)
+		if string(a) != string(b) {
+			cambios[columna] = map[string]interface{}{"antes": anterior, "despues": nuevo}
+		}
+	}
+	return cambios
+}
+
+// recorrer ejecuta fn por cada elemento de un struct o slice de structs
+func recorrer(v reflect.Value, fn func(reflect.Value)) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			elem := reflect.Indirect(v.Index(i))
+			if elem.Kind() == reflect.Struct {
+				fn(elem)
+			}
+		}
+	case reflect.Struct:
+		fn(v)
+	}
+}
+
+// aJSON serializa un valor para las columnas jsonb; nil se guarda como NULL
+func aJSON(valor interface{}) json.RawMessage {
+	if fila, ok := valor.(registro); ok && fila == nil {
+		return nil
+	}
+	datos, err := json.Marshal(valor)
+	if err != nil {
+		return nil
+	}
+	return datos
+}
+
+// aUint convierte un ID (uint, *uint u otros enteros) a uint
+func aUint(valor interface{}) uint {
+	v := reflect.ValueOf(valor)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint(v.Uint())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint(v.Int())
+	}
+	return 0
+}
@@ -0,0 +1,228 @@
+package cifrado
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"tum_inv_backend/internal/infrastructure/config"
+)
+
+// prefijo identifica los valores cifrados con el formato de sobre (envelope) v1:
+// enc:v1:<id de llave>:<DEK cifrada en base64>:<dato cifrado en base64>
+const prefijo = "enc:v1:"
+
+// Llavero contiene las llaves maestras (KEK) disponibles para cifrar y descifrar.
+// Solo la llave actual se usa para cifrar; las anteriores se conservan para
+// poder descifrar valores existentes hasta que se roten.
+type Llavero struct {
+	actual string
+	llaves map[string][]byte
+}
+
+// NewLlavero crea el llavero a partir de la configuración.
+// CREDENCIALES_LLAVES tiene el formato "id1:base64,id2:base64" (llaves AES-256).
+func NewLlavero(cfg *config.Config) (*Llavero, error) {
+	llaves := make(map[string][]byte)
+
+	if strings.TrimSpace(cfg.CredencialesLlaves) == "" {
+		if cfg.AppEnv == "production" {
+			return nil, errors.New("CREDENCIALES_LLAVES es obligatorio en producción")
+		}
+		// En desarrollo se deriva una llave del secreto JWT para no bloquear el arranque
+		log.Println("CREDENCIALES_LLAVES no configurado, derivando llave de desarrollo desde JWT_SECRET")
+		derivada := sha256.Sum256([]byte("credenciales:" + cfg.JWTSecret))
+		llaves["dev"] = derivada[:]
+		return &Llavero{actual: "dev", llaves: llaves}, nil
+	}
+
+	for _, entrada := range strings.Split(cfg.CredencialesLlaves, ",") {
+		partes := strings.SplitN(strings.TrimSpace(entrada), ":", 2)
+		if len(partes) != 2 || partes[0] == "" {
+			return nil, fmt.Errorf("entrada inválida en CREDENCIALES_LLAVES: %q", entrada)
+		}
+		llave, err := base64.StdEncoding.DecodeString(partes[1])
+		if err != nil {
+			return nil, fmt.Errorf("llave %s no es base64 válido: %w", partes[0], err)
+		}
+		if len(llave) != 32 {
+			return nil, fmt.Errorf("llave %s debe tener 32 bytes (AES-256)", partes[0])
+		}
+		llaves[partes[0]] = llave
+	}
+
+	actual := cfg.CredencialesLlaveActual
+	if actual == "" {
+		// Por defecto se usa la primera llave de la lista
+		actual = strings.SplitN(strings.TrimSpace(cfg.CredencialesLlaves), ":", 2)[0]
+	}
+	if _, ok := llaves[actual]; !ok {
+		return nil, fmt.Errorf("la llave actual %q no está en CREDENCIALES_LLAVES", actual)
+	}
+
+	return &Llavero{actual: actual, llaves: llaves}, nil
+}
+
+// LlaveActual retorna el ID de la llave con la que se cifran los valores nuevos
+func (l *Llavero) LlaveActual() string {
+	return l.actual
+}
+
+// Cifrar cifra un texto con una llave de datos (DEK) aleatoria, que a su vez
+// se cifra con la llave maestra actual
+func (l *Llavero) Cifrar(texto string) (string, error) {
+	if texto == "" {
+		return "", nil
+	}
+
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", fmt.Errorf("error generando llave de datos: %w", err)
+	}
+
+	datoCifrado, err := sellar(dek, []byte(texto))
+	if err != nil {
+		return "", err
+	}
+	dekCifrada, err := sellar(l.llaves[l.actual], dek)
+	if err != nil {
+		return "", err
+	}
+
+	return prefijo + l.actual + ":" +
+		base64.StdEncoding.EncodeToString(dekCifrada) + ":" +
+		base64.StdEncoding.EncodeToString(datoCifrado), nil
+}
+
+// Descifrar descifra un valor producido por Cifrar. Los valores sin el prefijo
+// de cifrado se retornan tal cual (filas anteriores a la migración).
+func (l *Llavero) Descifrar(valor string) (string, error) {
+	if !EstaCifrado(valor) {
+		return valor, nil
+	}
+
+	llaveID, dekCifrada, datoCifrado, err := separar(valor)
+	if err != nil {
+		return "", err
+	}
+	kek, ok := l.llaves[llaveID]
+	if !ok {
+		return "", fmt.Errorf("llave de cifrado %q no disponible", llaveID)
+	}
+
+	dek, err := abrir(kek, dekCifrada)
+	if err != nil {
+		return "", fmt.Errorf("error descifrando llave de datos: %w", err)
+	}
+	texto, err := abrir(dek, datoCifrado)
+	if err != nil {
+		return "", fmt.Errorf("error descifrando valor: %w", err)
+	}
+	return string(texto), nil
+}
+
+// Rotar vuelve a cifrar la llave de datos de un valor con la llave maestra actual.
+// Los valores en texto plano se cifran por primera vez.
+func (l *Llavero) Rotar(valor string) (string, error) {
+	if !EstaCifrado(valor) {
+		return l.Cifrar(valor)
+	}
+
+	llaveID, dekCifrada, datoCifrado, err := separar(valor)
+	if err != nil {
+		return "", err
+	}
+	if llaveID == l.actual {
+		return valor, nil
+	}
+	kek, ok := l.llaves[llaveID]
+	if !ok {
+		return "", fmt.Errorf("llave de cifrado %q no disponible", llaveID)
+	}
+
+	dek, err := abrir(kek, dekCifrada)
+	if err != nil {
+		return "", fmt.Errorf("error descifrando llave de datos: %w", err)
+	}
+	nuevaDEK, err := sellar(l.llaves[l.actual], dek)
+	if err != nil {
+		return "", err
+	}
+
+	return prefijo + l.actual + ":" +
+		base64.StdEncoding.EncodeToString(nuevaDEK) + ":" +
+		base64.StdEncoding.EncodeToString(datoCifrado), nil
+}
+
+// NecesitaRotacion indica si un valor está en texto plano o cifrado con una llave distinta a la actual
+func (l *Llavero) NecesitaRotacion(valor string) bool {
+	if valor == "" {
+		return false
+	}
+	if !EstaCifrado(valor) {
+		return true
+	}
+	llaveID, _, _, err := separar(valor)
+	return err == nil && llaveID != l.actual
+}
+
+// EstaCifrado indica si el valor tiene el formato de cifrado
+func EstaCifrado(valor string) bool {
+	return strings.HasPrefix(valor, prefijo)
+}
+
+// separar extrae el ID de llave, la DEK cifrada y el dato cifrado de un valor
+func separar(valor string) (string, []byte, []byte, error) {
+	partes := strings.Split(strings.TrimPrefix(valor, prefijo), ":")
+	if len(partes) != 3 {
+		return "", nil, nil, errors.New("formato de valor cifrado inválido")
+	}
+	dekCifrada, err := base64.StdEncoding.DecodeString(partes[1])
+	if err != nil {
+		return "", nil, nil, errors.New("llave de datos cifrada inválida")
+	}
+	datoCifrado, err := base64.StdEncoding.DecodeString(partes[2])
+	if err != nil {
+		return "", nil, nil, errors.New("dato cifrado inválido")
+	}
+	return partes[0], dekCifrada, datoCifrado, nil
+}
+
+// sellar cifra con AES-GCM y antepone el nonce al resultado
+func sellar(llave, texto []byte) ([]byte, error) {
+	block, err := aes.NewCipher(llave)
+	if err != nil {
+		return nil, fmt.Errorf("error creando cifrador: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creando cifrador: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generando nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, texto, nil), nil
+}
+
+// abrir descifra un valor producido por sellar
+func abrir(llave, datos []byte) ([]byte, error) {
+	block, err := aes.NewCipher(llave)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(datos) < gcm.NonceSize() {
+		return nil, errors.New("dato cifrado demasiado corto")
+	}
+	nonce, texto := datos[:gcm.NonceSize()], datos[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, texto, nil)
+}
@@ -0,0 +1,264 @@
+package cifrado
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"tum_inv_backend/internal/infrastructure/config"
+)
+
+// llaveDePrueba genera una llave AES-256 determinística en base64
+func llaveDePrueba(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func nuevoLlavero(t *testing.T, llaves, actual string) *Llavero {
+	t.Helper()
+	l, err := NewLlavero(&config.Config{CredencialesLlaves: llaves, CredencialesLlaveActual: actual})
+	if err != nil {
+		t.Fatalf("NewLlavero: %v", err)
+	}
+	return l
+}
+
+func TestNewLlavero(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		cfg        config.Config
+		wantActual string
+		wantErr    bool
+	}{
+		{"primera llave por defecto", config.Config{CredencialesLlaves: "k1:" + llaveDePrueba(1) + ",k2:" + llaveDePrueba(2)}, "k1", false},
+		{"llave actual explícita", config.Config{CredencialesLlaves: "k1:" + llaveDePrueba(1) + ", k2:" + llaveDePrueba(2), CredencialesLlaveActual: "k2"}, "k2", false},
+		{"desarrollo sin llaves", config.Config{AppEnv: "development", JWTSecret: "secreto"}, "dev", false},
+		{"producción sin llaves", config.Config{AppEnv: "production"}, "", true},
+		{"entrada sin id", config.Config{CredencialesLlaves: ":" + llaveDePrueba(1)}, "", true},
+		{"entrada sin separador", config.Config{CredencialesLlaves: "k1"}, "", true},
+		{"base64 inválido", config.Config{CredencialesLlaves: "k1:no-es-base64!"}, "", true},
+		{"llave de 16 bytes", config.Config{CredencialesLlaves: "k1:" + base64.StdEncoding.EncodeToString(make([]byte, 16))}, "", true},
+		{"llave actual inexistente", config.Config{CredencialesLlaves: "k1:" + llaveDePrueba(1), CredencialesLlaveActual: "k9"}, "", true},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			l, err := NewLlavero(&tc.cfg)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("se esperaba un error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if l.LlaveActual() != tc.wantActual {
+				t.Errorf("LlaveActual() = %q, se esperaba %q", l.LlaveActual(), tc.wantActual)
+			}
+		})
+	}
+}
+
+func TestCifrarDescifrar(t *testing.T) {
+	l := nuevoLlavero(t, "k1:"+llaveDePrueba(1), "")
+
+	casos := []struct {
+		nombre string
+		texto  string
+	}{
+		{"contraseña simple", "secreto123"},
+		{"con separadores", "a:b:c"},
+		{"unicode", "contraseña ñandú ☃"},
+		{"larga", strings.Repeat("x", 4096)},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			cifrado, err := l.Cifrar(tc.texto)
+			if err != nil {
+				t.Fatalf("Cifrar: %v", err)
+			}
+			if !strings.HasPrefix(cifrado, prefijo+"k1:") {
+				t.Errorf("el valor cifrado %q no tiene el prefijo de la llave actual", cifrado)
+			}
+			if strings.Contains(cifrado, tc.texto) {
+				t.Error("el valor cifrado contiene el texto en claro")
+			}
+
+			texto, err := l.Descifrar(cifrado)
+			if err != nil {
+				t.Fatalf("Descifrar: %v", err)
+			}
+			if texto != tc.texto {
+				t.Errorf("Descifrar = %q, se esperaba %q", texto, tc.texto)
+			}
+		})
+	}
+}
+
+func TestCifrarUsaNonceAleatorio(t *testing.T) {
+	l := nuevoLlavero(t, "k1:"+llaveDePrueba(1), "")
+
+	a, err := l.Cifrar("igual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := l.Cifrar("igual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("dos cifrados del mismo texto no deben coincidir")
+	}
+}
+
+func TestDescifrar(t *testing.T) {
+	l := nuevoLlavero(t, "k1:"+llaveDePrueba(1), "")
+	otro := nuevoLlavero(t, "k9:"+llaveDePrueba(9), "")
+
+	valido, err := l.Cifrar("secreto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ajeno, err := otro.Cifrar("secreto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partes := strings.Split(strings.TrimPrefix(valido, prefijo), ":")
+	dato, _ := base64.StdEncoding.DecodeString(partes[2])
+	dato[len(dato)-1] ^= 0xff
+	alterado := prefijo + partes[0] + ":" + partes[1] + ":" + base64.StdEncoding.EncodeToString(dato)
+	otraKEK := prefijo + "k1:" + strings.Split(strings.TrimPrefix(ajeno, prefijo), ":")[1] + ":" + partes[2]
+
+	casos := []struct {
+		nombre  string
+		valor   string
+		want    string
+		wantErr bool
+	}{
+		{"vacío", "", "", false},
+		{"texto plano heredado", "sin-cifrar", "sin-cifrar", false},
+		{"válido", valido, "secreto", false},
+		{"llave no disponible", ajeno, "", true},
+		{"dato alterado", alterado, "", true},
+		{"DEK cifrada con otra llave", otraKEK, "", true},
+		{"partes faltantes", prefijo + "k1:abc", "", true},
+		{"DEK no es base64", prefijo + "k1:***:" + partes[2], "", true},
+		{"dato no es base64", prefijo + "k1:" + partes[1] + ":***", "", true},
+		{"dato demasiado corto", prefijo + "k1:" + partes[1] + ":" + base64.StdEncoding.EncodeToString([]byte{1}), "", true},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			got, err := l.Descifrar(tc.valor)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("se esperaba un error, se obtuvo %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Descifrar = %q, se esperaba %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRotacion(t *testing.T) {
+	llaves := "k1:" + llaveDePrueba(1) + ",k2:" + llaveDePrueba(2)
+	anterior := nuevoLlavero(t, llaves, "k1")
+	actual := nuevoLlavero(t, llaves, "k2")
+
+	conAnterior, err := anterior.Cifrar("secreto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conActual, err := actual.Cifrar("secreto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	retirada, err := nuevoLlavero(t, "k0:"+llaveDePrueba(7), "").Cifrar("secreto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	casos := []struct {
+		nombre        string
+		valor         string
+		wantRotacion  bool
+		wantSinCambio bool
+		wantErr       bool
+	}{
+		{"vacío", "", false, true, false},
+		{"texto plano", "secreto", true, false, false},
+		{"llave anterior", conAnterior, true, false, false},
+		{"llave actual", conActual, false, true, false},
+		{"llave retirada", retirada, true, false, true},
+		{"formato inválido", prefijo + "k1", false, false, true},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			if got := actual.NecesitaRotacion(tc.valor); got != tc.wantRotacion {
+				t.Errorf("NecesitaRotacion = %v, se esperaba %v", got, tc.wantRotacion)
+			}
+
+			rotado, err := actual.Rotar(tc.valor)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("se esperaba un error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Rotar: %v", err)
+			}
+			if tc.wantSinCambio && rotado != tc.valor {
+				t.Errorf("Rotar modificó un valor que no lo necesitaba")
+			}
+			if actual.NecesitaRotacion(rotado) {
+				t.Errorf("el valor rotado %q sigue necesitando rotación", rotado)
+			}
+
+			texto, err := actual.Descifrar(rotado)
+			if err != nil {
+				t.Fatalf("Descifrar: %v", err)
+			}
+			want := "secreto"
+			if tc.valor == "" {
+				want = ""
+			}
+			if texto != want {
+				t.Errorf("Descifrar(Rotar(%q)) = %q, se esperaba %q", tc.valor, texto, want)
+			}
+		})
+	}
+}
+
+func TestRotarConservaDatoCifrado(t *testing.T) {
+	llaves := "k1:" + llaveDePrueba(1) + ",k2:" + llaveDePrueba(2)
+	anterior := nuevoLlavero(t, llaves, "k1")
+	actual := nuevoLlavero(t, llaves, "k2")
+
+	valor, err := anterior.Cifrar("secreto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotado, err := actual.Rotar(valor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// La rotación solo vuelve a cifrar la DEK; el dato cifrado no cambia
+	_, _, datoAntes, _ := separar(valor)
+	id, _, datoDespues, _ := separar(rotado)
+	if id != "k2" {
+		t.Errorf("llave del valor rotado = %q, se esperaba k2", id)
+	}
+	if !bytes.Equal(datoAntes, datoDespues) {
+		t.Error("la rotación no debe volver a cifrar el dato")
+	}
+}
@@ -0,0 +1,73 @@
+package cifrado
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// llaveroGlobal es el llavero usado por el serializer de GORM
+var llaveroGlobal *Llavero
+
+// Configurar establece el llavero usado para cifrar los campos marcados con
+// `gorm:"serializer:cifrado"`. Debe llamarse antes de acceder a la base de datos.
+func Configurar(l *Llavero) {
+	llaveroGlobal = l
+}
+
+// Global retorna el llavero configurado
+func Global() *Llavero {
+	return llaveroGlobal
+}
+
+func init() {
+	schema.RegisterSerializer("cifrado", Serializer{})
+}
+
+// Serializer cifra y descifra de forma transparente campos string al guardarlos y leerlos
+type Serializer struct{}
+
+// Scan descifra el valor leído de la base de datos
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var valor string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		valor = v
+	case []byte:
+		valor = string(v)
+	default:
+		return fmt.Errorf("tipo no soportado para campo cifrado %s: %T", field.Name, dbValue)
+	}
+
+	if EstaCifrado(valor) {
+		if llaveroGlobal == nil {
+			return errors.New("llavero de cifrado no configurado")
+		}
+		texto, err := llaveroGlobal.Descifrar(valor)
+		if err != nil {
+			return err
+		}
+		valor = texto
+	}
+
+	return field.Set(ctx, dst, valor)
+}
+
+// Value cifra el valor antes de guardarlo en la base de datos
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	texto, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("el campo cifrado %s debe ser string", field.Name)
+	}
+	if texto == "" {
+		return "", nil
+	}
+	if llaveroGlobal == nil {
+		return nil, errors.New("llavero de cifrado no configurado")
+	}
+	return llaveroGlobal.Cifrar(texto)
+}
@@ -24,10 +24,66 @@ type Config struct {
 	JWTSecret   string
 	FrontendURL string
 
+	// Almacenamiento de documentos: "supabase", "local" o "s3"
+	StorageProvider     string
+	StorageLocalDir     string // Directorio del almacenamiento local
+	StorageURLBase      string // URL pública de la API, base de las URL firmadas locales
+	StorageFirmaSecreto string // Secreto HMAC de las URL firmadas locales
+
+	// Almacenamiento S3 o compatible (MinIO)
+	S3Endpoint  string // Host o URL del servidor; http:// desactiva TLS
+	S3Region    string
+	S3Bucket    string
+	S3Prefix    string // Prefijo de las llaves dentro del bucket
+	S3AccessKey string
+	S3SecretKey string
+
 	// Supabase Storage
 	SupabaseURL        string
 	SupabaseServiceKey string
 	SupabaseBucket     string
+
+	// Validación de los PDF firmados que cierran un reporte
+	PDFFirmadoTamanoMaximoMB   int
+	PDFFirmadoPaginasMinimas   int
+	PDFFirmadoVerificarReporte bool // Exige que el PDF identifique el reporte (falla con PDF escaneados)
+
+	// Generación de PDF de reportes por lote
+	PDFLoteTrabajadores int // PDF que se generan al mismo tiempo
+	PDFLoteMaximo       int // Reportes por lote
+
+	// Cola de trabajos en segundo plano (exportaciones, importaciones, PDF por lote)
+	TrabajosTrabajadores int           // Trabajos que ejecuta al mismo tiempo cada instancia
+	TrabajosArriendo     time.Duration // Tiempo sin avisar tras el cual otro trabajador retoma un trabajo
+	TrabajosIntentos     int           // Ejecuciones antes de marcar un trabajo como fallido
+	TrabajosRetencion    time.Duration // Tiempo que se conservan los trabajos terminados y sus archivos
+
+	// Código de verificación (QR) impreso en los reportes de servicio
+	VerificacionSecreto string // Secreto HMAC de los códigos
+	VerificacionURL     string // URL a la que se agrega el código en el QR
+
+	// Firma digital (PAdES) de los reportes de servicio
+	FirmaOficinaCertificado string // Ruta del certificado .p12 de la Oficina de Sistemas
+	FirmaOficinaClave       string // Contraseña del .p12
+	FirmaCAConfiables       string // Ruta de un PEM con las CA raíz aceptadas al verificar
+	FirmaUbicacion          string // Lugar de firma que se registra en el PDF
+
+	// Notificaciones por correo (SMTP); sin SMTPHost quedan deshabilitadas
+	SMTPHost            string
+	SMTPPuerto          int
+	SMTPSeguridad       string // "starttls", "tls" o "ninguna" (MailHog)
+	SMTPUsuario         string // Vacío = sin autenticación
+	SMTPClave           string
+	SMTPRemitente       string // Dirección de la que salen los correos
+	SMTPNombreRemitente string
+	CorreosIntentos     int // Envíos antes de marcar un correo como fallido
+
+	// Centro de notificaciones
+	NotificacionesDiasSinFirma int // Días que un reporte puede seguir abierto antes de avisar al técnico
+
+	// Cifrado de credenciales (UsuarioSistema, AccesoRemoto)
+	CredencialesLlaves      string // "id1:base64,id2:base64"
+	CredencialesLlaveActual string // ID de la llave usada para cifrar
 }
 
 // LoadConfig carga la configuración desde variables de entorno
@@ -43,6 +99,64 @@ func LoadConfig() *Config {
 		log.Fatalf("Valor inválido para DB_TIMEOUT: %v", err)
 	}
 
+	// Reglas de validación de los PDF firmados
+	tamanoPDF, err := strconv.Atoi(getEnv("PDF_FIRMADO_TAMANO_MAXIMO_MB", "10"))
+	if err != nil || tamanoPDF < 1 {
+		log.Fatalf("Valor inválido para PDF_FIRMADO_TAMANO_MAXIMO_MB: %q", getEnv("PDF_FIRMADO_TAMANO_MAXIMO_MB", ""))
+	}
+	paginasPDF, err := strconv.Atoi(getEnv("PDF_FIRMADO_PAGINAS_MINIMAS", "2"))
+	if err != nil || paginasPDF < 1 {
+		log.Fatalf("Valor inválido para PDF_FIRMADO_PAGINAS_MINIMAS: %q", getEnv("PDF_FIRMADO_PAGINAS_MINIMAS", ""))
+	}
+	verificarPDF, err := strconv.ParseBool(getEnv("PDF_FIRMADO_VERIFICAR_REPORTE", "false"))
+	if err != nil {
+		log.Fatalf("Valor inválido para PDF_FIRMADO_VERIFICAR_REPORTE: %v", err)
+	}
+
+	// Límites de la generación de PDF por lote
+	trabajadoresLote, err := strconv.Atoi(getEnv("PDF_LOTE_TRABAJADORES", "4"))
+	if err != nil || trabajadoresLote < 1 {
+		log.Fatalf("Valor inválido para PDF_LOTE_TRABAJADORES: %q", getEnv("PDF_LOTE_TRABAJADORES", ""))
+	}
+	maximoLote, err := strconv.Atoi(getEnv("PDF_LOTE_MAXIMO", "300"))
+	if err != nil || maximoLote < 1 {
+		log.Fatalf("Valor inválido para PDF_LOTE_MAXIMO: %q", getEnv("PDF_LOTE_MAXIMO", ""))
+	}
+
+	// Cola de trabajos
+	trabajadores, err := strconv.Atoi(getEnv("TRABAJOS_TRABAJADORES", "2"))
+	if err != nil || trabajadores < 1 {
+		log.Fatalf("Valor inválido para TRABAJOS_TRABAJADORES: %q", getEnv("TRABAJOS_TRABAJADORES", ""))
+	}
+	arriendo, err := strconv.Atoi(getEnv("TRABAJOS_ARRIENDO_SEGUNDOS", "120"))
+	if err != nil || arriendo < 10 {
+		log.Fatalf("Valor inválido para TRABAJOS_ARRIENDO_SEGUNDOS: %q (mínimo 10)", getEnv("TRABAJOS_ARRIENDO_SEGUNDOS", ""))
+	}
+	intentos, err := strconv.Atoi(getEnv("TRABAJOS_INTENTOS", "3"))
+	if err != nil || intentos < 1 {
+		log.Fatalf("Valor inválido para TRABAJOS_INTENTOS: %q", getEnv("TRABAJOS_INTENTOS", ""))
+	}
+	retencion, err := strconv.Atoi(getEnv("TRABAJOS_RETENCION_DIAS", "7"))
+	if err != nil || retencion < 1 {
+		log.Fatalf("Valor inválido para TRABAJOS_RETENCION_DIAS: %q", getEnv("TRABAJOS_RETENCION_DIAS", ""))
+	}
+
+	// Notificaciones por correo
+	puertoSMTP, err := strconv.Atoi(getEnv("SMTP_PUERTO", "587"))
+	if err != nil || puertoSMTP < 1 || puertoSMTP > 65535 {
+		log.Fatalf("Valor inválido para SMTP_PUERTO: %q", getEnv("SMTP_PUERTO", ""))
+	}
+	intentosCorreo, err := strconv.Atoi(getEnv("CORREOS_INTENTOS", "5"))
+	if err != nil || intentosCorreo < 1 {
+		log.Fatalf("Valor inválido para CORREOS_INTENTOS: %q", getEnv("CORREOS_INTENTOS", ""))
+	}
+
+	// Centro de notificaciones
+	diasSinFirma, err := strconv.Atoi(getEnv("NOTIFICACIONES_DIAS_SIN_FIRMA", "5"))
+	if err != nil || diasSinFirma < 1 {
+		log.Fatalf("Valor inválido para NOTIFICACIONES_DIAS_SIN_FIRMA: %q", getEnv("NOTIFICACIONES_DIAS_SIN_FIRMA", ""))
+	}
+
 	return &Config{
 		DatabaseURL: getEnv("DATABASE_URL", ""), // Railway provee esta variable
 		DBHost:      getEnv("DB_HOST", "localhost"),
@@ -57,10 +171,66 @@ func LoadConfig() *Config {
 		JWTSecret:   getEnv("JWT_SECRET", "tu_clave_secreta_jwt_super_segura"),
 		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:5173"),
 
+		// Almacenamiento de documentos
+		StorageProvider:     getEnv("STORAGE_PROVIDER", "supabase"),
+		StorageLocalDir:     getEnv("STORAGE_LOCAL_DIR", "./data/archivos"),
+		StorageURLBase:      getEnv("STORAGE_URL_BASE", "http://localhost:"+getEnv("APP_PORT", "8080")),
+		StorageFirmaSecreto: getEnv("STORAGE_FIRMA_SECRETO", ""),
+
+		// Almacenamiento S3 o compatible
+		S3Endpoint:  getEnv("S3_ENDPOINT", "s3.amazonaws.com"),
+		S3Region:    getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:    getEnv("S3_BUCKET", "reportes-firmados"),
+		S3Prefix:    getEnv("S3_PREFIX", ""),
+		S3AccessKey: getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey: getEnv("S3_SECRET_KEY", ""),
+
 		// Supabase Storage
 		SupabaseURL:        getEnv("SUPABASE_URL", "https://jlyuebeokvqmdmiqpdvc.supabase.co"),
 		SupabaseServiceKey: getEnv("SUPABASE_SERVICE_KEY", ""),
 		SupabaseBucket:     getEnv("SUPABASE_BUCKET", "reportes-firmados"),
+
+		// Validación de PDF firmados
+		PDFFirmadoTamanoMaximoMB:   tamanoPDF,
+		PDFFirmadoPaginasMinimas:   paginasPDF,
+		PDFFirmadoVerificarReporte: verificarPDF,
+
+		// Generación de PDF por lote
+		PDFLoteTrabajadores: trabajadoresLote,
+		PDFLoteMaximo:       maximoLote,
+
+		// Cola de trabajos
+		TrabajosTrabajadores: trabajadores,
+		TrabajosArriendo:     time.Duration(arriendo) * time.Second,
+		TrabajosIntentos:     intentos,
+		TrabajosRetencion:    time.Duration(retencion) * 24 * time.Hour,
+
+		// Código de verificación de reportes
+		VerificacionSecreto: getEnv("VERIFICACION_SECRETO", ""),
+		VerificacionURL:     getEnv("VERIFICACION_URL", "http://localhost:"+getEnv("APP_PORT", "8080")+"/api/verificar/"),
+
+		// Firma digital de reportes
+		FirmaOficinaCertificado: getEnv("FIRMA_OFICINA_CERTIFICADO", ""),
+		FirmaOficinaClave:       getEnv("FIRMA_OFICINA_CLAVE", ""),
+		FirmaCAConfiables:       getEnv("FIRMA_CA_CONFIABLES", ""),
+		FirmaUbicacion:          getEnv("FIRMA_UBICACION", "Tumaco, Nariño"),
+
+		// Notificaciones por correo
+		SMTPHost:            getEnv("SMTP_HOST", ""),
+		SMTPPuerto:          puertoSMTP,
+		SMTPSeguridad:       getEnv("SMTP_SEGURIDAD", "starttls"),
+		SMTPUsuario:         getEnv("SMTP_USUARIO", ""),
+		SMTPClave:           getEnv("SMTP_CLAVE", ""),
+		SMTPRemitente:       getEnv("SMTP_REMITENTE", "inventario@localhost"),
+		SMTPNombreRemitente: getEnv("SMTP_NOMBRE_REMITENTE", "Inventario TI - Alcaldía de Tumaco"),
+		CorreosIntentos:     intentosCorreo,
+
+		// Centro de notificaciones
+		NotificacionesDiasSinFirma: diasSinFirma,
+
+		// Cifrado de credenciales
+		CredencialesLlaves:      getEnv("CREDENCIALES_LLAVES", ""),
+		CredencialesLlaveActual: getEnv("CREDENCIALES_LLAVE_ACTUAL", ""),
 	}
 }
 
@@ -0,0 +1,218 @@
+package correo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/infrastructure/config"
+)
+
+// Modos de conexión admitidos en SMTP_SEGURIDAD
+const (
+	SeguridadStartTLS = "starttls" // Conexión en claro que pasa a TLS (puerto 587)
+	SeguridadTLS      = "tls"      // TLS desde el inicio (puerto 465)
+	SeguridadNinguna  = "ninguna"  // Sin cifrado, solo para servidores locales como MailHog
+)
+
+// tiempoEspera limita la conexión y la conversación con el servidor SMTP
+const tiempoEspera = 30 * time.Second
+
+// Mensaje es un correo en HTML con su versión en texto plano
+type Mensaje struct {
+	Para   []string
+	Asunto string
+	HTML   string
+	Texto  string
+}
+
+// Remitente envía correos
+type Remitente interface {
+	Enviar(ctx context.Context, mensaje Mensaje) error
+}
+
+// smtpRemitente envía los correos por un servidor SMTP
+type smtpRemitente struct {
+	host      string
+	puerto    int
+	seguridad string
+	usuario   string
+	clave     string
+	de        mail.Address
+}
+
+// NewRemitente crea el remitente SMTP a partir de la configuración. Sin SMTP_HOST las
+// notificaciones por correo quedan deshabilitadas y retorna nil.
+func NewRemitente(cfg *config.Config) (Remitente, error) {
+	if cfg.SMTPHost == "" {
+		log.Println("SMTP_HOST no configurado, las notificaciones por correo quedan deshabilitadas")
+		return nil, nil
+	}
+
+	seguridad := strings.ToLower(strings.TrimSpace(cfg.SMTPSeguridad))
+	switch seguridad {
+	case SeguridadStartTLS, SeguridadTLS, SeguridadNinguna:
+	default:
+		return nil, fmt.Errorf("SMTP_SEGURIDAD inválido: %q (use %s, %s o %s)", cfg.SMTPSeguridad, SeguridadStartTLS, SeguridadTLS, SeguridadNinguna)
+	}
+	de, err := mail.ParseAddress(cfg.SMTPRemitente)
+	if err != nil {
+		return nil, fmt.Errorf("SMTP_REMITENTE inválido: %w", err)
+	}
+	if cfg.SMTPNombreRemitente != "" {
+		de.Name = cfg.SMTPNombreRemitente
+	}
+
+	return &smtpRemitente{
+		host:      cfg.SMTPHost,
+		puerto:    cfg.SMTPPuerto,
+		seguridad: seguridad,
+		usuario:   cfg.SMTPUsuario,
+		clave:     cfg.SMTPClave,
+		de:        *de,
+	}, nil
+}
+
+// Enviar entrega el mensaje al servidor SMTP. Un rechazo del servidor (código 5xx) se
+// puede reconocer con EsRechazo.
+func (r *smtpRemitente) Enviar(ctx context.Context, mensaje Mensaje) error {
+	if len(mensaje.Para) == 0 {
+		return errors.New("el correo no tiene destinatarios")
+	}
+	datos, err := r.armar(mensaje)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancelar := context.WithTimeout(ctx, tiempoEspera)
+	defer cancelar()
+	direccion := net.JoinHostPort(r.host, strconv.Itoa(r.puerto))
+	conexion, err := (&net.Dialer{}).DialContext(ctx, "tcp", direccion)
+	if err != nil {
+		return fmt.Errorf("error conectando con %s: %w", direccion, err)
+	}
+	plazo, _ := ctx.Deadline()
+	conexion.SetDeadline(plazo)
+	if r.seguridad == SeguridadTLS {
+		conexion = tls.Client(conexion, &tls.Config{ServerName: r.host})
+	}
+
+	cliente, err := smtp.NewClient(conexion, r.host)
+	if err != nil {
+		conexion.Close()
+		return fmt.Errorf("error iniciando la sesión SMTP: %w", err)
+	}
+	defer cliente.Close()
+
+	if r.seguridad == SeguridadStartTLS {
+		if err := cliente.StartTLS(&tls.Config{ServerName: r.host}); err != nil {
+			return fmt.Errorf("error en STARTTLS: %w", err)
+		}
+	}
+	if r.usuario != "" {
+		if err := cliente.Auth(smtp.PlainAuth("", r.usuario, r.clave, r.host)); err != nil {
+			return fmt.Errorf("error autenticando en el servidor SMTP: %w", err)
+		}
+	}
+	if err := cliente.Mail(r.de.Address); err != nil {
+		return err
+	}
+	for _, para := range mensaje.Para {
+		if err := cliente.Rcpt(para); err != nil {
+			return fmt.Errorf("destinatario %s: %w", para, err)
+		}
+	}
+	escritor, err := cliente.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := escritor.Write(datos); err != nil {
+		return err
+	}
+	if err := escritor.Close(); err != nil {
+		return err
+	}
+	return cliente.Quit()
+}
+
+// armar construye el mensaje MIME: multipart/alternative con el texto plano y el HTML en
+// quoted-printable, y el asunto codificado para admitir tildes
+func (r *smtpRemitente) armar(mensaje Mensaje) ([]byte, error) {
+	var cuerpo bytes.Buffer
+	partes := multipart.NewWriter(&cuerpo)
+	for _, parte := range []struct{ tipo, contenido string }{
+		{"text/plain; charset=UTF-8", mensaje.Texto},
+		{"text/html; charset=UTF-8", mensaje.HTML},
+	} {
+		escritor, err := partes.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {parte.tipo},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(escritor)
+		if _, err := qp.Write([]byte(parte.contenido)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := partes.Close(); err != nil {
+		return nil, err
+	}
+
+	var datos bytes.Buffer
+	fmt.Fprintf(&datos, "From: %s\r\n", r.de.String())
+	fmt.Fprintf(&datos, "To: %s\r\n", strings.Join(mensaje.Para, ", "))
+	fmt.Fprintf(&datos, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", mensaje.Asunto))
+	fmt.Fprintf(&datos, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&datos, "Message-ID: <%s@%s>\r\n", identificador(), r.dominio())
+	datos.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&datos, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", partes.Boundary())
+	datos.Write(cuerpo.Bytes())
+	return datos.Bytes(), nil
+}
+
+// dominio es el dominio del remitente, usado en el Message-ID
+func (r *smtpRemitente) dominio() string {
+	if _, dominio, ok := strings.Cut(r.de.Address, "@"); ok {
+		return dominio
+	}
+	return r.host
+}
+
+// identificador genera la parte local aleatoria del Message-ID
+func identificador() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// EsRechazo indica si el servidor SMTP rechazó el correo de forma permanente (código 5xx),
+// por ejemplo por un destinatario inexistente; reintentar no cambiaría el resultado
+func EsRechazo(err error) bool {
+	var errSMTP *textproto.Error
+	return errors.As(err, &errSMTP) && errSMTP.Code >= 500
+}
+
+// DireccionValida indica si el texto es una dirección de correo sin nombre
+func DireccionValida(direccion string) bool {
+	parsed, err := mail.ParseAddress(direccion)
+	return err == nil && parsed.Address == direccion
+}
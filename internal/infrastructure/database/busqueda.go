@@ -0,0 +1,31 @@
+package database
+
+import "gorm.io/gorm"
+
+// indicesBusqueda son los índices que usa la búsqueda global (/api/buscar): trigramas
+// para coincidencias parciales y texto completo para los nombres
+var indicesBusqueda = []string{
+	`CREATE INDEX IF NOT EXISTS idx_equipos_serial_trgm ON equipos USING gin (serial gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_equipos_placa_inventario_trgm ON equipos USING gin (placa_inventario gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_perifericos_serial_trgm ON perifericos USING gin (serial gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_usuario_responsables_nombres_trgm ON usuario_responsables USING gin (nombres_apellidos gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_usuario_responsables_nombres_fts ON usuario_responsables USING gin (to_tsvector('simple', nombres_apellidos))`,
+	`CREATE INDEX IF NOT EXISTS idx_usuario_responsables_cedula_trgm ON usuario_responsables USING gin (cedula gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_configuracion_reds_nombre_trgm ON configuracion_reds USING gin (nombre_dispositivo gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_configuracion_reds_ip_trgm ON configuracion_reds USING gin (direccion_ip gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_softwares_nombre_trgm ON softwares USING gin (nombre gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_softwares_nombre_fts ON softwares USING gin (to_tsvector('simple', nombre))`,
+}
+
+// CrearIndicesBusqueda habilita pg_trgm y crea los índices de la búsqueda global
+func CrearIndicesBusqueda(db *gorm.DB) error {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		return err
+	}
+	for _, indice := range indicesBusqueda {
+		if err := db.Exec(indice).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
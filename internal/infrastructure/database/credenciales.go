@@ -0,0 +1,51 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"tum_inv_backend/internal/infrastructure/cifrado"
+
+	"gorm.io/gorm"
+)
+
+// tablasConCredenciales lista las tablas cuya columna contrasena se cifra en reposo
+var tablasConCredenciales = []string{"usuario_sistemas", "acceso_remotos"}
+
+// MigrarCredenciales cifra las contraseñas guardadas en texto plano y vuelve a
+// cifrar con la llave actual las que usan una llave anterior (rotación).
+// Es idempotente: las filas ya cifradas con la llave actual no se modifican.
+func MigrarCredenciales(db *gorm.DB, llavero *cifrado.Llavero) error {
+	type fila struct {
+		ID         uint
+		Contrasena string
+	}
+
+	for _, tabla := range tablasConCredenciales {
+		// Se lee con Table() para obtener el valor crudo, sin pasar por el serializer
+		var filas []fila
+		if err := db.Table(tabla).Select("id, contrasena").Where("contrasena IS NOT NULL AND contrasena <> ''").Find(&filas).Error; err != nil {
+			return fmt.Errorf("error leyendo %s: %w", tabla, err)
+		}
+
+		actualizadas := 0
+		for _, f := range filas {
+			if !llavero.NecesitaRotacion(f.Contrasena) {
+				continue
+			}
+			nuevo, err := llavero.Rotar(f.Contrasena)
+			if err != nil {
+				return fmt.Errorf("error cifrando %s %d: %w", tabla, f.ID, err)
+			}
+			if err := db.Table(tabla).Where("id = ?", f.ID).UpdateColumn("contrasena", nuevo).Error; err != nil {
+				return fmt.Errorf("error actualizando %s %d: %w", tabla, f.ID, err)
+			}
+			actualizadas++
+		}
+
+		if actualizadas > 0 {
+			log.Printf("Credenciales cifradas con la llave %q en %s: %d", llavero.LlaveActual(), tabla, actualizadas)
+		}
+	}
+
+	return nil
+}
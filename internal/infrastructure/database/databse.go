@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 	"tum_inv_backend/internal/domain/models"
+	"tum_inv_backend/internal/infrastructure/auditoria"
 	"tum_inv_backend/internal/infrastructure/config"
 
 	"gorm.io/driver/postgres"
@@ -62,6 +63,11 @@ func ConnectDB(cfg *config.Config) *gorm.DB {
 
 	log.Println("Conexión a la base de datos establecida")
 
+	// Registrar la auditoría de cambios sobre todos los modelos
+	if err := DB.Use(auditoria.Plugin{}); err != nil {
+		log.Fatalf("Error al registrar la auditoría: %v", err)
+	}
+
 	// Configurar conexión de la base de datos subyacente
 	sqlDB, err := DB.DB()
 	if err != nil {
@@ -92,6 +98,18 @@ func ConnectDB(cfg *config.Config) *gorm.DB {
 		&models.Repuesto{},
 		&models.EstadoEquipo{},
 		&models.Usuario{},
+		&models.Auditoria{},
+		&models.AsignacionEquipo{},
+		&models.DocumentoReporte{},
+		&models.PlantillaPDF{},
+		&models.Trabajo{},
+		&models.PlanMantenimiento{},
+		&models.ArticuloRepuesto{},
+		&models.MovimientoRepuesto{},
+		&models.SolicitudServicio{},
+		&models.PoliticaSLA{},
+		&models.CorreoSaliente{},
+		&models.Notificacion{},
 	)
 
 	if err != nil {
@@ -100,5 +118,10 @@ func ConnectDB(cfg *config.Config) *gorm.DB {
 
 	log.Println("Migraciones completadas con éxito")
 
+	// Si falla (por ejemplo, sin permiso para crear pg_trgm) el servidor arranca, pero /api/buscar no funcionará
+	if err := CrearIndicesBusqueda(DB); err != nil {
+		log.Printf("Advertencia: no se pudieron crear los índices de búsqueda: %v", err)
+	}
+
 	return DB
 }
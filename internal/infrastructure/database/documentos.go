@@ -0,0 +1,47 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// MigrarDocumentosReporte registra como versión 1 del historial el PDF firmado de los
+// reportes que se cerraron antes de existir DocumentoReporte. Esos archivos se guardaban
+// como reporte_<id>_firmado.pdf; su hash y tamaño no se conocen y quedan vacíos.
+// Es idempotente: solo toma reportes cerrados sin ninguna versión registrada.
+func MigrarDocumentosReporte(db *gorm.DB) error {
+	resultado := db.Exec(`
+		INSERT INTO documento_reportes (created_at, updated_at, reporte_id, version, archivo, ruta_objeto, sha256, tamano, fecha_subida)
+		SELECT r.fecha_cierre, r.fecha_cierre, r.id, 1, 'reporte_' || r.id || '_firmado.pdf', r.archivo_firmado_url, '', 0, r.fecha_cierre
+		FROM reporte_servicios r
+		WHERE r.fecha_cierre IS NOT NULL AND r.archivo_firmado_url <> '' AND r.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM documento_reportes d WHERE d.reporte_id = r.id)`)
+	if resultado.Error != nil {
+		return fmt.Errorf("error registrando el historial de documentos firmados: %w", resultado.Error)
+	}
+
+	if resultado.RowsAffected > 0 {
+		log.Printf("Documentos firmados anteriores registrados en el historial: %d", resultado.RowsAffected)
+	}
+	return nil
+}
+
+// MigrarActasFirmadas registra el nombre en el almacenamiento de las actas de entrega
+// firmadas que se subieron antes de guardarlo; esas actas se guardaban como
+// acta_entrega_<id>_firmada.pdf. Es idempotente: solo toma actas sin nombre registrado.
+func MigrarActasFirmadas(db *gorm.DB) error {
+	resultado := db.Exec(`
+		UPDATE asignacion_equipos
+		SET acta_firmada_archivo = 'acta_entrega_' || id || '_firmada.pdf'
+		WHERE acta_firmada_url <> '' AND (acta_firmada_archivo IS NULL OR acta_firmada_archivo = '')`)
+	if resultado.Error != nil {
+		return fmt.Errorf("error registrando las actas de entrega firmadas: %w", resultado.Error)
+	}
+
+	if resultado.RowsAffected > 0 {
+		log.Printf("Actas de entrega firmadas anteriores registradas: %d", resultado.RowsAffected)
+	}
+	return nil
+}
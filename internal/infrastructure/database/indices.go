@@ -0,0 +1,39 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// indicesReemplazados son los índices que se cambiaron por índices únicos parciales con otro
+// nombre. AutoMigrate crea los nuevos, pero no borra los anteriores.
+var indicesReemplazados = []string{
+	"idx_plantillas_pdf_nombre",                  // Único sobre el nombre, incluidas las plantillas eliminadas
+	"idx_plantillas_pdf_activa",                  // Índice simple sobre activa
+	"idx_articulos_repuesto_serial_numero_parte", // Único sobre el serial, incluidos los artículos eliminados
+}
+
+// MigrarIndicesReemplazados elimina los índices reemplazados. Se ejecuta después de
+// AutoMigrate, cuando los índices nuevos ya existen, así que la tabla no se queda sin
+// restricción. Es idempotente: solo toma los índices que siguen en la base de datos y que no
+// son parciales.
+func MigrarIndicesReemplazados(db *gorm.DB) error {
+	for _, indice := range indicesReemplazados {
+		var definiciones []string
+		if err := db.Raw("SELECT indexdef FROM pg_indexes WHERE schemaname = current_schema() AND indexname = ?", indice).
+			Scan(&definiciones).Error; err != nil {
+			return fmt.Errorf("error consultando el índice %s: %w", indice, err)
+		}
+		if len(definiciones) == 0 || strings.Contains(definiciones[0], " WHERE ") {
+			continue
+		}
+		if err := db.Exec("DROP INDEX IF EXISTS " + indice).Error; err != nil {
+			return fmt.Errorf("error eliminando el índice %s: %w", indice, err)
+		}
+		log.Printf("Índice reemplazado eliminado: %s", indice)
+	}
+	return nil
+}
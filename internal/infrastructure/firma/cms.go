@@ -0,0 +1,152 @@
+package firma
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Identificadores de los tipos y algoritmos CMS (RFC 5652) usados en la firma
+var (
+	oidData                  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidAtributoContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAtributoMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAtributoSigningCertV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidSHA256                = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSA                   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidECDSAConSHA256        = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type encapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier // Sin contenido: la firma es separada (detached)
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type signerInfo struct {
+	Version            int
+	Sid                issuerAndSerial
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+// essCertIDv2 y signingCertificateV2 son el atributo ESS (RFC 5035) que liga la firma al
+// certificado del firmante; PAdES lo exige en lugar del atributo signing-time
+type essCertIDv2 struct {
+	CertHash []byte // SHA-256, el algoritmo por defecto, que se omite
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// construirCMS genera la firma CMS separada (SubFilter ETSI.CAdES.detached) sobre el hash de
+// los bytes del PDF cubiertos por el ByteRange
+func construirCMS(firmante *Firmante, resumen []byte) ([]byte, error) {
+	hashCertificado := sha256.Sum256(firmante.Certificado.Raw)
+	atributos, err := codificarAtributos(
+		atributo{Type: oidAtributoContentType, Value: oidData},
+		atributo{Type: oidAtributoMessageDigest, Value: resumen},
+		atributo{Type: oidAtributoSigningCertV2, Value: signingCertificateV2{Certs: []essCertIDv2{{CertHash: hashCertificado[:]}}}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error codificando los atributos firmados: %w", err)
+	}
+
+	// Se firma el SET de atributos con su etiqueta universal, no con la implícita [0] del SignerInfo
+	setAtributos, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: atributos})
+	if err != nil {
+		return nil, err
+	}
+	hashAtributos := sha256.Sum256(setAtributos)
+	firma, err := firmante.llave.Sign(rand.Reader, hashAtributos[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("error firmando: %w", err)
+	}
+
+	algoritmo := oidRSA
+	if _, ok := firmante.llave.(*ecdsa.PrivateKey); ok {
+		algoritmo = oidECDSAConSHA256
+	}
+
+	certificados := append([]byte(nil), firmante.Certificado.Raw...)
+	for _, c := range firmante.Cadena {
+		certificados = append(certificados, c.Raw...)
+	}
+
+	contenido, err := asn1.Marshal(signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: encapsulatedContentInfo{ContentType: oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certificados},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			Sid:                issuerAndSerial{Issuer: asn1.RawValue{FullBytes: firmante.Certificado.RawIssuer}, Serial: firmante.Certificado.SerialNumber},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: atributos},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: algoritmo},
+			Signature:          firma,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error codificando la firma: %w", err)
+	}
+	return asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: contenido},
+	})
+}
+
+// atributo es un atributo firmado con un único valor
+type atributo struct {
+	Type  asn1.ObjectIdentifier
+	Value any
+}
+
+// codificarAtributos retorna el contenido del SET OF Attribute, con los elementos en el
+// orden que exige DER para que el verificador obtenga los mismos bytes firmados
+func codificarAtributos(atributos ...atributo) ([]byte, error) {
+	codificados := make([][]byte, 0, len(atributos))
+	for _, a := range atributos {
+		valor, err := asn1.Marshal(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		der, err := asn1.Marshal(struct {
+			Type   asn1.ObjectIdentifier
+			Values []asn1.RawValue `asn1:"set"`
+		}{Type: a.Type, Values: []asn1.RawValue{{FullBytes: valor}}})
+		if err != nil {
+			return nil, err
+		}
+		codificados = append(codificados, der)
+	}
+	sort.Slice(codificados, func(i, j int) bool { return bytes.Compare(codificados[i], codificados[j]) < 0 })
+	return bytes.Join(codificados, nil), nil
+}
@@ -0,0 +1,104 @@
+package firma
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+	"tum_inv_backend/internal/infrastructure/config"
+)
+
+// Campos de firma de los reportes de servicio
+const (
+	CampoTecnico = "FirmaTecnico"
+	CampoOficina = "FirmaOficina"
+)
+
+// ErrFirmaNoConfigurada indica que no hay certificado de la Oficina de Sistemas para firmar
+var ErrFirmaNoConfigurada = errors.New("la firma digital no está configurada (FIRMA_OFICINA_CERTIFICADO)")
+
+// FirmaDigital firma los PDF con el certificado de la Oficina de Sistemas y verifica
+// firmas contra las entidades de certificación confiables
+type FirmaDigital struct {
+	oficina    *Firmante // nil si no se configuró certificado
+	confiables *x509.CertPool
+	ubicacion  string
+}
+
+// NewFirmaDigital carga el certificado de la Oficina de Sistemas y las CA confiables.
+// Sin FIRMA_OFICINA_CERTIFICADO la firma queda deshabilitada, pero se puede verificar.
+func NewFirmaDigital(cfg *config.Config) (*FirmaDigital, error) {
+	f := &FirmaDigital{ubicacion: cfg.FirmaUbicacion}
+	confiables := x509.NewCertPool()
+	hayConfiables := false
+
+	if cfg.FirmaCAConfiables != "" {
+		pem, err := os.ReadFile(cfg.FirmaCAConfiables)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer FIRMA_CA_CONFIABLES: %w", err)
+		}
+		if !confiables.AppendCertsFromPEM(pem) {
+			return nil, errors.New("FIRMA_CA_CONFIABLES no contiene certificados PEM")
+		}
+		hayConfiables = true
+	}
+
+	if cfg.FirmaOficinaCertificado == "" {
+		log.Println("FIRMA_OFICINA_CERTIFICADO no configurado, la firma digital de reportes está deshabilitada")
+	} else {
+		datos, err := os.ReadFile(cfg.FirmaOficinaCertificado)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer FIRMA_OFICINA_CERTIFICADO: %w", err)
+		}
+		oficina, err := CargarPKCS12(datos, cfg.FirmaOficinaClave)
+		if err != nil {
+			return nil, fmt.Errorf("certificado de la Oficina de Sistemas: %w", err)
+		}
+		if !oficina.VigenteEn(time.Now()) {
+			log.Printf("El certificado de firma de %s no está vigente (vence %s)", oficina.Nombre(), oficina.Certificado.NotAfter.Format("2006-01-02"))
+		}
+		f.oficina = oficina
+
+		// La raíz de la cadena de la Oficina se acepta siempre, para verificar los reportes propios
+		raiz := oficina.Certificado
+		if len(oficina.Cadena) > 0 {
+			raiz = oficina.Cadena[len(oficina.Cadena)-1]
+		}
+		confiables.AddCert(raiz)
+		hayConfiables = true
+	}
+
+	if hayConfiables {
+		f.confiables = confiables
+	}
+	return f, nil
+}
+
+// Habilitada indica si hay certificado de la Oficina para firmar
+func (f *FirmaDigital) Habilitada() bool {
+	return f.oficina != nil
+}
+
+// FirmarOficina agrega la firma de la Oficina de Sistemas al PDF
+func (f *FirmaDigital) FirmarOficina(pdf []byte, razon string) ([]byte, error) {
+	if f.oficina == nil {
+		return nil, ErrFirmaNoConfigurada
+	}
+	return FirmarPDF(pdf, f.oficina, Opciones{Campo: CampoOficina, Razon: razon, Ubicacion: f.ubicacion})
+}
+
+// Firmar agrega al PDF la firma de otro firmante, por ejemplo el técnico
+func (f *FirmaDigital) Firmar(pdf []byte, firmante *Firmante, campo, razon string) ([]byte, error) {
+	return FirmarPDF(pdf, firmante, Opciones{Campo: campo, Razon: razon, Ubicacion: f.ubicacion})
+}
+
+// Verificar verifica las firmas del PDF contra las CA confiables configuradas
+func (f *FirmaDigital) Verificar(pdf []byte) ([]ResultadoFirma, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(pdf), []byte("%PDF-")) {
+		return nil, errors.New("el archivo no es un PDF")
+	}
+	return VerificarPDF(pdf, f.confiables)
+}
@@ -0,0 +1,310 @@
+package firma
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// entidadDePrueba es una CA raíz con la que se emiten los certificados de los tests
+type entidadDePrueba struct {
+	certificado *x509.Certificate
+	llave       crypto.Signer
+	pool        *x509.CertPool
+}
+
+func nuevaEntidad(t *testing.T, nombre string) *entidadDePrueba {
+	t.Helper()
+	llave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plantilla := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: nombre},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, plantilla, plantilla, llave.Public(), llave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificado, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(certificado)
+	return &entidadDePrueba{certificado: certificado, llave: llave, pool: pool}
+}
+
+// emitir crea un firmante con un certificado de la entidad válido entre desde y hasta
+func (e *entidadDePrueba) emitir(t *testing.T, nombre string, llave crypto.Signer, desde, hasta time.Time) *Firmante {
+	t.Helper()
+	plantilla := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: nombre},
+		NotBefore:    desde,
+		NotAfter:     hasta,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, plantilla, e.certificado, llave.Public(), e.llave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificado, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Firmante{Certificado: certificado, Cadena: []*x509.Certificate{e.certificado}, llave: llave}
+}
+
+func llaveRSA(t *testing.T) crypto.Signer {
+	t.Helper()
+	llave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return llave
+}
+
+func llaveECDSA(t *testing.T) crypto.Signer {
+	t.Helper()
+	llave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return llave
+}
+
+// pdfDePrueba genera un PDF de dos páginas sin comprimir, para poder alterar su texto
+func pdfDePrueba(t *testing.T, proteger bool) []byte {
+	t.Helper()
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetCompression(false)
+	if proteger {
+		pdf.SetProtection(fpdf.CnProtectPrint, "", "propietario")
+	}
+	pdf.SetFont("Arial", "", 10)
+	for i := 0; i < 2; i++ {
+		pdf.AddPage()
+		pdf.Text(20, 30, "Reporte de servicio original")
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFirmarYVerificarPDF(t *testing.T) {
+	entidad := nuevaEntidad(t, "CA de prueba")
+	otraEntidad := nuevaEntidad(t, "Otra CA")
+	ahora := time.Now()
+	rsaVigente := entidad.emitir(t, "Oficina de Sistemas", llaveRSA(t), ahora.Add(-time.Hour), ahora.Add(time.Hour))
+	ecdsaVigente := entidad.emitir(t, "Técnico", llaveECDSA(t), ahora.Add(-time.Hour), ahora.Add(time.Hour))
+	vencido := entidad.emitir(t, "Vencido", llaveECDSA(t), ahora.Add(-72*time.Hour), ahora.Add(-24*time.Hour))
+
+	original := pdfDePrueba(t, false)
+	alterar := func(pdf []byte) []byte {
+		return bytes.Replace(pdf, []byte("original"), []byte("alterado"), 1)
+	}
+	agregarAlFinal := func(pdf []byte) []byte {
+		return append(pdf, []byte("% comentario agregado\n")...)
+	}
+
+	casos := []struct {
+		nombre        string
+		firmante      *Firmante
+		fecha         time.Time
+		modificar     func([]byte) []byte
+		confiables    *x509.CertPool
+		wantValida    bool
+		wantIntegra   bool
+		wantCubre     bool
+		wantVigente   bool
+		wantConfiable bool
+	}{
+		{"RSA válida", rsaVigente, time.Time{}, nil, entidad.pool, true, true, true, true, true},
+		{"ECDSA válida", ecdsaVigente, time.Time{}, nil, entidad.pool, true, true, true, true, true},
+		{"sin CA confiables", rsaVigente, time.Time{}, nil, nil, false, true, true, true, false},
+		{"CA no confiable", rsaVigente, time.Time{}, nil, otraEntidad.pool, false, true, true, true, false},
+		{"contenido alterado", ecdsaVigente, time.Time{}, alterar, entidad.pool, false, false, true, true, true},
+		{"bytes agregados después", ecdsaVigente, time.Time{}, agregarAlFinal, entidad.pool, true, true, false, true, true},
+		{"certificado vencido hoy", vencido, ahora.Add(-48 * time.Hour), nil, entidad.pool, false, true, true, false, false},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			firmado, err := FirmarPDF(original, tc.firmante, Opciones{Campo: CampoTecnico, Razon: "Cierre del reporte", Fecha: tc.fecha})
+			if err != nil {
+				t.Fatalf("FirmarPDF: %v", err)
+			}
+			if !bytes.HasPrefix(firmado, original) {
+				t.Fatal("la firma debe agregarse como actualización incremental")
+			}
+			if tc.modificar != nil {
+				firmado = tc.modificar(firmado)
+			}
+
+			resultados, err := VerificarPDF(firmado, tc.confiables)
+			if err != nil {
+				t.Fatalf("VerificarPDF: %v", err)
+			}
+			if len(resultados) != 1 {
+				t.Fatalf("se esperaba una firma, se obtuvieron %d", len(resultados))
+			}
+			r := resultados[0]
+			if r.Campo != CampoTecnico || r.Razon != "Cierre del reporte" || r.Firmante != tc.firmante.Nombre() || r.Emisor != "CA de prueba" {
+				t.Errorf("datos de la firma = %+v", r)
+			}
+			if r.Valida != tc.wantValida || r.Integra != tc.wantIntegra || r.CubreDocumento != tc.wantCubre ||
+				r.CertificadoVigente != tc.wantVigente || r.CadenaConfiable != tc.wantConfiable {
+				t.Errorf("Valida=%v Integra=%v CubreDocumento=%v CertificadoVigente=%v CadenaConfiable=%v, se esperaba %v %v %v %v %v; problemas: %v",
+					r.Valida, r.Integra, r.CubreDocumento, r.CertificadoVigente, r.CadenaConfiable,
+					tc.wantValida, tc.wantIntegra, tc.wantCubre, tc.wantVigente, tc.wantConfiable, r.Problemas)
+			}
+			if r.Valida != (len(r.Problemas) == 0) {
+				t.Errorf("problemas = %v con Valida=%v", r.Problemas, r.Valida)
+			}
+		})
+	}
+}
+
+func TestFirmasSucesivas(t *testing.T) {
+	entidad := nuevaEntidad(t, "CA de prueba")
+	ahora := time.Now()
+	tecnico := entidad.emitir(t, "Técnico", llaveECDSA(t), ahora.Add(-time.Hour), ahora.Add(time.Hour))
+	oficina := entidad.emitir(t, "Oficina de Sistemas", llaveECDSA(t), ahora.Add(-time.Hour), ahora.Add(time.Hour))
+
+	primera, err := FirmarPDF(pdfDePrueba(t, false), tecnico, Opciones{Campo: CampoTecnico})
+	if err != nil {
+		t.Fatal(err)
+	}
+	segunda, err := FirmarPDF(primera, oficina, Opciones{Campo: CampoOficina})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resultados, err := VerificarPDF(segunda, entidad.pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	casos := []struct {
+		campo     string
+		firmante  string
+		wantCubre bool
+	}{
+		{CampoTecnico, "Técnico", false},
+		{CampoOficina, "Oficina de Sistemas", true},
+	}
+	if len(resultados) != len(casos) {
+		t.Fatalf("se esperaban %d firmas, se obtuvieron %d", len(casos), len(resultados))
+	}
+	for i, tc := range casos {
+		r := resultados[i]
+		if r.Campo != tc.campo || r.Firmante != tc.firmante {
+			t.Errorf("firma %d = %s de %s, se esperaba %s de %s", i, r.Campo, r.Firmante, tc.campo, tc.firmante)
+		}
+		if !r.Valida || r.CubreDocumento != tc.wantCubre {
+			t.Errorf("firma %s: Valida=%v CubreDocumento=%v, se esperaba true %v; problemas: %v", tc.campo, r.Valida, r.CubreDocumento, tc.wantCubre, r.Problemas)
+		}
+	}
+}
+
+func TestFirmarPDFErrores(t *testing.T) {
+	entidad := nuevaEntidad(t, "CA de prueba")
+	ahora := time.Now()
+	firmante := entidad.emitir(t, "Técnico", llaveECDSA(t), ahora.Add(-time.Hour), ahora.Add(time.Hour))
+	vencido := entidad.emitir(t, "Vencido", llaveECDSA(t), ahora.Add(-72*time.Hour), ahora.Add(-24*time.Hour))
+
+	firmado, err := FirmarPDF(pdfDePrueba(t, false), firmante, Opciones{Campo: CampoTecnico})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	casos := []struct {
+		nombre   string
+		pdf      []byte
+		firmante *Firmante
+		campo    string
+		wantErr  string
+	}{
+		{"certificado vencido", pdfDePrueba(t, false), vencido, CampoTecnico, "no está vigente"},
+		{"no es PDF", []byte("no es un pdf"), firmante, CampoTecnico, "error leyendo el PDF"},
+		{"PDF cifrado", pdfDePrueba(t, true), firmante, CampoTecnico, "cifrado"},
+		{"campo repetido", firmado, firmante, CampoTecnico, "ya tiene el campo de firma"},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			_, err := FirmarPDF(tc.pdf, tc.firmante, Opciones{Campo: tc.campo})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error = %v, se esperaba uno que contenga %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerificarPDFSinFirmas(t *testing.T) {
+	_, err := VerificarPDF(pdfDePrueba(t, false), nil)
+	if !errors.Is(err, ErrSinFirmas) {
+		t.Errorf("error = %v, se esperaba ErrSinFirmas", err)
+	}
+}
+
+func TestCargarPKCS12(t *testing.T) {
+	entidad := nuevaEntidad(t, "CA de prueba")
+	ahora := time.Now()
+	firmante := entidad.emitir(t, "Oficina de Sistemas", llaveECDSA(t), ahora.Add(-time.Hour), ahora.Add(time.Hour))
+	p12, err := pkcs12.Modern.Encode(firmante.llave, firmante.Certificado, firmante.Cadena, "clave")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	casos := []struct {
+		nombre  string
+		datos   []byte
+		clave   string
+		wantErr string
+	}{
+		{"válido", p12, "clave", ""},
+		{"contraseña incorrecta", p12, "otra", "contraseña del certificado es incorrecta"},
+		{"no es PKCS#12", []byte("no es un certificado"), "clave", "no es un PKCS#12 válido"},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			cargado, err := CargarPKCS12(tc.datos, tc.clave)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error = %v, se esperaba uno que contenga %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CargarPKCS12: %v", err)
+			}
+			if cargado.Nombre() != "Oficina de Sistemas" || len(cargado.Cadena) != 1 {
+				t.Errorf("firmante cargado = %s con %d certificados en la cadena", cargado.Nombre(), len(cargado.Cadena))
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package firma
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// Firmante es un certificado X.509 con su llave privada, listo para firmar PDF
+type Firmante struct {
+	Certificado *x509.Certificate
+	Cadena      []*x509.Certificate // Intermedios y raíz que venían en el archivo, sin el certificado
+	llave       crypto.Signer
+}
+
+// CargarPKCS12 lee un certificado de firma en formato PKCS#12 (.p12 o .pfx), que es como
+// lo entregan las entidades de certificación. Solo se aceptan llaves RSA y ECDSA.
+func CargarPKCS12(datos []byte, clave string) (*Firmante, error) {
+	llave, certificado, cadena, err := pkcs12.DecodeChain(datos, clave)
+	if err != nil {
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+			return nil, errors.New("la contraseña del certificado es incorrecta")
+		}
+		return nil, fmt.Errorf("el certificado no es un PKCS#12 válido: %w", err)
+	}
+
+	var firmante crypto.Signer
+	switch l := llave.(type) {
+	case *rsa.PrivateKey:
+		firmante = l
+	case *ecdsa.PrivateKey:
+		firmante = l
+	default:
+		return nil, fmt.Errorf("tipo de llave no soportado: %T", llave)
+	}
+
+	if publica, ok := firmante.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !publica.Equal(certificado.PublicKey) {
+		return nil, errors.New("la llave privada no corresponde al certificado")
+	}
+	if certificado.KeyUsage != 0 && certificado.KeyUsage&(x509.KeyUsageDigitalSignature|x509.KeyUsageContentCommitment) == 0 {
+		return nil, errors.New("el certificado no está habilitado para firma digital")
+	}
+
+	return &Firmante{Certificado: certificado, Cadena: cadena, llave: firmante}, nil
+}
+
+// Nombre es el nombre común del titular del certificado
+func (f *Firmante) Nombre() string {
+	if f.Certificado.Subject.CommonName != "" {
+		return f.Certificado.Subject.CommonName
+	}
+	return f.Certificado.Subject.String()
+}
+
+// oidCorreoSujeto es el atributo emailAddress del sujeto, que algunas entidades usan en vez
+// del nombre alternativo
+var oidCorreoSujeto = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+
+// PerteneceA indica si el certificado es de la persona con la cédula o el correo dados. Las
+// entidades de certificación registran la cédula en el serialNumber del sujeto (por ejemplo
+// "CC 1087654321") o en el nombre común, y el correo en el nombre alternativo o en el sujeto.
+func (f *Firmante) PerteneceA(cedula, email string) bool {
+	sujeto := f.Certificado.Subject
+	if cedula = soloDigitos(cedula); cedula != "" {
+		for _, texto := range []string{sujeto.SerialNumber, sujeto.CommonName} {
+			for _, parte := range strings.Fields(texto) {
+				if soloDigitos(parte) == cedula {
+					return true
+				}
+			}
+		}
+	}
+
+	if email = strings.TrimSpace(email); email != "" {
+		correos := append([]string{}, f.Certificado.EmailAddresses...)
+		for _, atributo := range sujeto.Names {
+			if valor, ok := atributo.Value.(string); ok && atributo.Type.Equal(oidCorreoSujeto) {
+				correos = append(correos, valor)
+			}
+		}
+		for _, correo := range correos {
+			if strings.EqualFold(strings.TrimSpace(correo), email) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// soloDigitos quita puntos, espacios y demás separadores de un número de documento
+func soloDigitos(texto string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, texto)
+}
+
+// VigenteEn indica si el certificado es válido en la fecha dada
+func (f *Firmante) VigenteEn(fecha time.Time) bool {
+	return !fecha.Before(f.Certificado.NotBefore) && !fecha.After(f.Certificado.NotAfter)
+}
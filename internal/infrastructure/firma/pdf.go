@@ -0,0 +1,212 @@
+package firma
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// tamanoFirma son los bytes reservados en /Contents para el CMS (certificado, cadena y firma)
+const tamanoFirma = 16384
+
+// marcadorByteRange reserva el espacio del ByteRange, que se conoce al terminar de escribir
+var marcadorByteRange = "[" + strings.Repeat(" ", 44) + "]"
+
+// Opciones son los datos que se registran en el diccionario de la firma
+type Opciones struct {
+	Campo     string // Nombre del campo de firma, único en el documento
+	Razon     string
+	Ubicacion string
+	Fecha     time.Time
+}
+
+// FirmarPDF agrega una firma PAdES (ETSI.CAdES.detached) invisible al PDF, como una
+// actualización incremental: los bytes originales no cambian, de modo que las firmas
+// anteriores siguen siendo válidas y la nueva cubre el documento completo.
+func FirmarPDF(pdf []byte, firmante *Firmante, opciones Opciones) ([]byte, error) {
+	if opciones.Fecha.IsZero() {
+		opciones.Fecha = time.Now()
+	}
+	if !firmante.VigenteEn(opciones.Fecha) {
+		return nil, fmt.Errorf("el certificado de %s no está vigente (válido del %s al %s)",
+			firmante.Nombre(), firmante.Certificado.NotBefore.Format("2006-01-02"), firmante.Certificado.NotAfter.Format("2006-01-02"))
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el PDF: %w", err)
+	}
+	if ctx.Encrypt != nil {
+		return nil, errors.New("no se puede firmar un PDF cifrado")
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		return nil, fmt.Errorf("el PDF no es válido: %w", err)
+	}
+	inicioXRef, err := ultimoStartXRef(pdf)
+	if err != nil {
+		return nil, err
+	}
+
+	raiz, ok := ctx.RootDict.Clone().(types.Dict)
+	if !ok || ctx.Root == nil {
+		return nil, errors.New("el PDF no tiene catálogo")
+	}
+	paginaOriginal, refPagina, _, err := ctx.PageDict(1, false)
+	if err != nil || paginaOriginal == nil {
+		return nil, errors.New("el PDF no tiene páginas")
+	}
+	pagina := paginaOriginal.Clone().(types.Dict)
+
+	siguiente := *ctx.Size
+	refFirma := types.NewIndirectRef(siguiente, 0)
+	refCampo := types.NewIndirectRef(siguiente+1, 0)
+
+	// El campo se agrega al formulario del catálogo y, como anotación, a la primera página
+	formulario := types.Dict{}
+	if obj, ok := raiz.Find("AcroForm"); ok {
+		if d, err := ctx.DereferenceDict(obj); err == nil && d != nil {
+			formulario = d.Clone().(types.Dict)
+		}
+	}
+	campos, _ := ctx.DereferenceArray(formulario["Fields"])
+	for _, c := range campos {
+		if d, err := ctx.DereferenceDict(c); err == nil && d != nil {
+			if nombre, err := types.StringOrHexLiteral(d["T"]); err == nil && *nombre == opciones.Campo {
+				return nil, fmt.Errorf("el PDF ya tiene el campo de firma %q", opciones.Campo)
+			}
+		}
+	}
+	formulario["Fields"] = append(append(types.Array{}, campos...), *refCampo)
+	formulario["SigFlags"] = types.Integer(3) // SignaturesExist | AppendOnly
+	raiz["AcroForm"] = formulario
+
+	anotaciones, _ := ctx.DereferenceArray(pagina["Annots"])
+	pagina["Annots"] = append(append(types.Array{}, anotaciones...), *refCampo)
+
+	campo := types.Dict{
+		"Type":    types.Name("Annot"),
+		"Subtype": types.Name("Widget"),
+		"FT":      types.Name("Sig"),
+		"Rect":    types.Array{types.Integer(0), types.Integer(0), types.Integer(0), types.Integer(0)},
+		"F":       types.Integer(132), // Print | Locked
+		"T":       textoPDF(opciones.Campo),
+		"V":       *refFirma,
+		"P":       *refPagina,
+	}
+
+	// Actualización incremental: objetos modificados y nuevos, su tabla xref y el trailer
+	var buf bytes.Buffer
+	buf.Write(pdf)
+	if !bytes.HasSuffix(pdf, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	desplazamientos := map[int]int{}
+	generaciones := map[int]int{}
+	escribir := func(ref types.IndirectRef, cuerpo string) {
+		nr, gen := ref.ObjectNumber.Value(), ref.GenerationNumber.Value()
+		desplazamientos[nr], generaciones[nr] = buf.Len(), gen
+		fmt.Fprintf(&buf, "%d %d obj\n%s\nendobj\n", nr, gen, cuerpo)
+	}
+
+	escribir(*ctx.Root, raiz.PDFString())
+	escribir(*refPagina, pagina.PDFString())
+	escribir(*refCampo, campo.PDFString())
+
+	desplazamientos[siguiente], generaciones[siguiente] = buf.Len(), 0
+	fmt.Fprintf(&buf, "%d 0 obj\n<</Type/Sig/Filter/Adobe.PPKLite/SubFilter/ETSI.CAdES.detached/Name %s/Reason %s/Location %s/M %s/ByteRange ",
+		siguiente, textoPDF(firmante.Nombre()).PDFString(), textoPDF(opciones.Razon).PDFString(),
+		textoPDF(opciones.Ubicacion).PDFString(), textoPDF(types.DateString(opciones.Fecha)).PDFString())
+	inicioByteRange := buf.Len()
+	buf.WriteString(marcadorByteRange)
+	buf.WriteString("/Contents ")
+	inicioContenido := buf.Len()
+	buf.WriteString("<" + strings.Repeat("0", tamanoFirma*2) + ">")
+	finContenido := buf.Len()
+	buf.WriteString(">>\nendobj\n")
+
+	inicioNuevaXRef := buf.Len()
+	numeros := make([]int, 0, len(desplazamientos))
+	for nr := range desplazamientos {
+		numeros = append(numeros, nr)
+	}
+	sort.Ints(numeros)
+	buf.WriteString("xref\n")
+	for _, nr := range numeros {
+		fmt.Fprintf(&buf, "%d 1\n%010d %05d n\r\n", nr, desplazamientos[nr], generaciones[nr])
+	}
+	trailer := types.Dict{
+		"Size": types.Integer(siguiente + 2),
+		"Root": *ctx.Root,
+		"Prev": types.Integer(inicioXRef),
+	}
+	if ctx.Info != nil {
+		trailer["Info"] = *ctx.Info
+	}
+	if len(ctx.ID) > 0 {
+		trailer["ID"] = ctx.ID
+	}
+	fmt.Fprintf(&buf, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer.PDFString(), inicioNuevaXRef)
+
+	salida := buf.Bytes()
+	byteRange := fmt.Sprintf("[0 %d %d %d]", inicioContenido, finContenido, len(salida)-finContenido)
+	copy(salida[inicioByteRange:], byteRange+strings.Repeat(" ", len(marcadorByteRange)-len(byteRange)))
+
+	h := sha256.New()
+	h.Write(salida[:inicioContenido])
+	h.Write(salida[finContenido:])
+	cms, err := construirCMS(firmante, h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	if len(cms) > tamanoFirma {
+		return nil, fmt.Errorf("la firma ocupa %d bytes y el espacio reservado es de %d", len(cms), tamanoFirma)
+	}
+	hex.Encode(salida[inicioContenido+1:], cms)
+
+	return salida, nil
+}
+
+// ultimoStartXRef retorna la posición de la tabla xref más reciente, a la que apunta /Prev
+func ultimoStartXRef(pdf []byte) (int, error) {
+	i := bytes.LastIndex(pdf, []byte("startxref"))
+	if i < 0 {
+		return 0, errors.New("el PDF no tiene startxref")
+	}
+	campos := strings.Fields(string(pdf[i+len("startxref") : min(len(pdf), i+len("startxref")+32)]))
+	if len(campos) == 0 {
+		return 0, errors.New("el PDF tiene un startxref inválido")
+	}
+	return strconv.Atoi(campos[0])
+}
+
+// textoPDF codifica un texto como cadena PDF; si no es ASCII usa UTF-16BE con BOM
+func textoPDF(s string) types.Object {
+	ascii := true
+	for _, r := range s {
+		if r > 126 || r < 32 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		r := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+		return types.StringLiteral(r.Replace(s))
+	}
+
+	codificado := []byte{0xFE, 0xFF}
+	for _, u := range utf16.Encode([]rune(s)) {
+		codificado = append(codificado, byte(u>>8), byte(u))
+	}
+	return types.HexLiteral(hex.EncodeToString(codificado))
+}
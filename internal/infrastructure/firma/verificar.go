@@ -0,0 +1,270 @@
+package firma
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/hhrutter/pkcs7"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// ErrSinFirmas indica que el PDF no tiene campos de firma firmados
+var ErrSinFirmas = errors.New("el PDF no tiene firmas digitales")
+
+// oidSelloTiempo identifica el atributo no firmado con el sello de tiempo RFC 3161 de la firma
+var oidSelloTiempo = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+
+// infoSello es el contenido (TSTInfo) de un sello de tiempo RFC 3161. Los campos
+// opcionales posteriores a la fecha no se usan.
+type infoSello struct {
+	Version         int
+	Politica        asn1.ObjectIdentifier
+	HuellaMensaje   huellaSello
+	Serial          *big.Int
+	FechaGeneracion time.Time `asn1:"generalized"`
+}
+
+// huellaSello es el resumen de la firma que sella la autoridad de tiempo
+type huellaSello struct {
+	Algoritmo pkix.AlgorithmIdentifier
+	Resumen   []byte
+}
+
+// ResultadoFirma es el resultado de verificar una firma del PDF
+type ResultadoFirma struct {
+	Campo              string
+	Firmante           string // Nombre común del certificado
+	Emisor             string // Entidad que emitió el certificado
+	Serial             string
+	Fecha              *time.Time // Fecha declarada por el firmante (/M); solo informativa
+	FechaSello         *time.Time // Fecha de un sello de tiempo RFC 3161 verificado
+	Razon              string
+	Valida             bool // Íntegra, con certificado vigente y cadena confiable
+	Integra            bool // El contenido firmado no cambió y la firma corresponde al certificado
+	CubreDocumento     bool // La firma cubre todo el archivo; es false en las firmas anteriores a otra
+	CertificadoVigente bool
+	CadenaConfiable    bool
+	Problemas          []string
+}
+
+// VerificarPDF verifica todas las firmas del PDF. confiables son las CA raíz aceptadas;
+// si es nil ninguna cadena se considera confiable.
+func VerificarPDF(pdf []byte, confiables *x509.CertPool) ([]ResultadoFirma, error) {
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el PDF: %w", err)
+	}
+
+	obj, ok := ctx.RootDict.Find("AcroForm")
+	if !ok {
+		return nil, ErrSinFirmas
+	}
+	formulario, err := ctx.DereferenceDict(obj)
+	if err != nil || formulario == nil {
+		return nil, ErrSinFirmas
+	}
+	campos, err := ctx.DereferenceArray(formulario["Fields"])
+	if err != nil {
+		return nil, fmt.Errorf("el formulario del PDF es inválido: %w", err)
+	}
+
+	var resultados []ResultadoFirma
+	for _, c := range campos {
+		campo, err := ctx.DereferenceDict(c)
+		if err != nil || campo == nil {
+			continue
+		}
+		if tipo := campo.NameEntry("FT"); tipo == nil || *tipo != "Sig" {
+			continue
+		}
+		firma, err := ctx.DereferenceDict(campo["V"])
+		if err != nil || firma == nil {
+			continue // Campo de firma vacío
+		}
+
+		resultado := ResultadoFirma{}
+		if nombre, err := types.StringOrHexLiteral(campo["T"]); err == nil {
+			resultado.Campo = *nombre
+		}
+		if razon, err := types.StringOrHexLiteral(firma["Reason"]); err == nil {
+			resultado.Razon = *razon
+		}
+		if m, err := types.StringOrHexLiteral(firma["M"]); err == nil {
+			if fecha, ok := types.DateTime(*m, true); ok {
+				resultado.Fecha = &fecha
+			}
+		}
+		verificarFirma(pdf, ctx, firma, confiables, &resultado)
+		resultados = append(resultados, resultado)
+	}
+
+	if len(resultados) == 0 {
+		return nil, ErrSinFirmas
+	}
+	return resultados, nil
+}
+
+// verificarFirma comprueba el ByteRange, el CMS y el certificado de una firma
+func verificarFirma(pdf []byte, ctx *model.Context, firma types.Dict, confiables *x509.CertPool, resultado *ResultadoFirma) {
+	problema := func(formato string, args ...any) {
+		resultado.Problemas = append(resultado.Problemas, fmt.Sprintf(formato, args...))
+	}
+
+	rango, err := ctx.DereferenceArray(firma["ByteRange"])
+	if err != nil || len(rango) != 4 {
+		problema("la firma no tiene un ByteRange válido")
+		return
+	}
+	var r [4]int
+	for i, v := range rango {
+		n, ok := v.(types.Integer)
+		if !ok {
+			problema("la firma no tiene un ByteRange válido")
+			return
+		}
+		r[i] = n.Value()
+	}
+	if r[0] != 0 || r[1] <= 0 || r[2] <= r[1] || r[2]+r[3] > len(pdf) || pdf[r[1]] != '<' || pdf[r[2]-1] != '>' {
+		problema("el ByteRange de la firma no corresponde al archivo")
+		return
+	}
+	resultado.CubreDocumento = r[2]+r[3] == len(pdf)
+
+	contenido, ok := firma["Contents"].(types.HexLiteral)
+	if !ok {
+		problema("la firma no tiene contenido")
+		return
+	}
+	der, err := contenido.Bytes()
+	if err != nil {
+		problema("el contenido de la firma no es válido")
+		return
+	}
+	// El espacio reservado se rellena con ceros después del CMS
+	var cms asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &cms); err == nil {
+		der = cms.FullBytes
+	}
+	p7, err := pkcs7.Parse(der)
+	if err != nil {
+		problema("la firma no es un CMS válido: %v", err)
+		return
+	}
+	certificado := p7.GetOnlySigner()
+	if certificado == nil {
+		problema("la firma no tiene un único firmante")
+		return
+	}
+	resultado.Firmante = certificado.Subject.CommonName
+	resultado.Emisor = certificado.Issuer.CommonName
+	resultado.Serial = certificado.SerialNumber.Text(16)
+
+	p7.Content = append(append([]byte{}, pdf[:r[1]]...), pdf[r[2]:r[2]+r[3]]...)
+	if err := p7.Verify(); err != nil {
+		problema("la firma no corresponde al contenido: %v", err)
+	} else {
+		resultado.Integra = true
+	}
+
+	// La fecha /M la escribe el firmante y no prueba nada: la vigencia se comprueba hoy o, si
+	// la firma tiene un sello de tiempo de una autoridad confiable, en la fecha del sello
+	fecha := time.Now()
+	if sello, err := verificarSello(p7, confiables); err != nil {
+		problema("el sello de tiempo no es válido: %v", err)
+	} else if sello != nil {
+		resultado.FechaSello = sello
+		fecha = *sello
+	}
+	resultado.CertificadoVigente = !fecha.Before(certificado.NotBefore) && !fecha.After(certificado.NotAfter)
+	if !resultado.CertificadoVigente {
+		if resultado.FechaSello != nil {
+			problema("el certificado no estaba vigente en la fecha del sello de tiempo")
+		} else {
+			problema("el certificado no está vigente")
+		}
+	}
+
+	if confiables == nil {
+		problema("no hay entidades de certificación confiables configuradas")
+	} else {
+		intermedios := x509.NewCertPool()
+		for _, c := range p7.Certificates {
+			intermedios.AddCert(c)
+		}
+		_, err := certificado.Verify(x509.VerifyOptions{
+			Roots:         confiables,
+			Intermediates: intermedios,
+			CurrentTime:   fecha,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		if err != nil {
+			problema("el certificado no proviene de una entidad confiable: %v", err)
+		} else {
+			resultado.CadenaConfiable = true
+		}
+	}
+
+	resultado.Valida = resultado.Integra && resultado.CertificadoVigente && resultado.CadenaConfiable
+}
+
+// verificarSello retorna la fecha del sello de tiempo RFC 3161 de la firma, o nil si no
+// tiene. El sello debe estar firmado por una autoridad de sellado con cadena confiable y
+// corresponder al valor de la firma.
+func verificarSello(p7 *pkcs7.PKCS7, confiables *x509.CertPool) (*time.Time, error) {
+	if len(p7.Signers) != 1 {
+		return nil, nil
+	}
+	firmante := p7.Signers[0]
+	var token []byte
+	for _, atributo := range firmante.UnauthenticatedAttributes {
+		if atributo.Type.Equal(oidSelloTiempo) {
+			token = atributo.Value.Bytes
+			break
+		}
+	}
+	if token == nil {
+		return nil, nil
+	}
+	if confiables == nil {
+		return nil, errors.New("no hay entidades de certificación confiables configuradas")
+	}
+
+	sello, err := pkcs7.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := sello.VerifyWithChainAtTime(confiables, time.Now()); err != nil {
+		return nil, err
+	}
+	autoridad := sello.GetOnlySigner()
+	if autoridad == nil || !usoSellado(autoridad) {
+		return nil, errors.New("el certificado del sello no es de una autoridad de sellado de tiempo")
+	}
+
+	var info infoSello
+	if _, err := asn1.Unmarshal(sello.Content, &info); err != nil {
+		return nil, fmt.Errorf("contenido inválido: %w", err)
+	}
+	if err := pkcs7.VerifyMessageDigestTSToken(info.HuellaMensaje.Algoritmo.Algorithm, info.HuellaMensaje.Resumen, firmante.EncryptedDigest); err != nil {
+		return nil, errors.New("el sello no corresponde a la firma")
+	}
+	return &info.FechaGeneracion, nil
+}
+
+// usoSellado indica si el certificado está autorizado para emitir sellos de tiempo
+func usoSellado(certificado *x509.Certificate) bool {
+	for _, uso := range certificado.ExtKeyUsage {
+		if uso == x509.ExtKeyUsageTimeStamping {
+			return true
+		}
+	}
+	return false
+}
@@ -410,7 +410,7 @@ func (s *Seeder) SeedEstadosEquipo() error {
 			Activo:      false,
 		},
 		{
-			Nombre:      "Dado de Baja",
+			Nombre:      models.EstadoEquipoDadoDeBaja,
 			Descripcion: "Equipo retirado definitivamente del inventario",
 			Activo:      false,
 		},
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/infrastructure/config"
+)
+
+// RutaDescargaLocal es el prefijo de las URL firmadas que sirve la propia API
+const RutaDescargaLocal = "/api/archivos/"
+
+var (
+	// ErrFirmaInvalida indica que la firma de la URL no corresponde al archivo o al vencimiento
+	ErrFirmaInvalida = errors.New("firma de descarga inválida")
+	// ErrURLVencida indica que la URL firmada ya expiró
+	ErrURLVencida = errors.New("la URL de descarga expiró")
+)
+
+// LocalStorage guarda los archivos en un directorio del servidor. Las descargas se hacen
+// con URL firmadas con HMAC-SHA256 que vencen, servidas por la API en RutaDescargaLocal.
+type LocalStorage struct {
+	dir     string
+	urlBase string
+	secreto []byte
+}
+
+// NewLocalStorage crea el almacenamiento local y el directorio si no existe.
+// STORAGE_FIRMA_SECRETO es obligatorio en producción; en desarrollo se deriva de JWT_SECRET.
+func NewLocalStorage(cfg *config.Config) (*LocalStorage, error) {
+	if strings.TrimSpace(cfg.StorageLocalDir) == "" {
+		return nil, errors.New("STORAGE_LOCAL_DIR es obligatorio con el almacenamiento local")
+	}
+	if err := os.MkdirAll(cfg.StorageLocalDir, 0o750); err != nil {
+		return nil, fmt.Errorf("no se pudo crear el directorio de almacenamiento: %w", err)
+	}
+
+	secreto := []byte(cfg.StorageFirmaSecreto)
+	if len(secreto) == 0 {
+		if cfg.AppEnv == "production" {
+			return nil, errors.New("STORAGE_FIRMA_SECRETO es obligatorio en producción")
+		}
+		log.Println("STORAGE_FIRMA_SECRETO no configurado, derivando secreto de desarrollo desde JWT_SECRET")
+		derivado := sha256.Sum256([]byte("almacenamiento:" + cfg.JWTSecret))
+		secreto = derivado[:]
+	}
+
+	return &LocalStorage{
+		dir:     cfg.StorageLocalDir,
+		urlBase: strings.TrimRight(cfg.StorageURLBase, "/"),
+		secreto: secreto,
+	}, nil
+}
+
+// Upload escribe el archivo en el directorio; usa un archivo temporal y lo renombra
+// para que una descarga simultánea nunca vea el archivo a medio escribir
+func (s *LocalStorage) Upload(fileName string, fileData []byte, contentType string) (string, error) {
+	ruta, err := s.ruta(fileName)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(ruta), 0o750); err != nil {
+		return "", fmt.Errorf("error creando directorio: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(ruta), ".subida-*")
+	if err != nil {
+		return "", fmt.Errorf("error subiendo archivo: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(fileData); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("error subiendo archivo: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("error subiendo archivo: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ruta); err != nil {
+		return "", fmt.Errorf("error subiendo archivo: %w", err)
+	}
+
+	return fileName, nil
+}
+
+// Delete elimina el archivo del directorio
+func (s *LocalStorage) Delete(fileName string) error {
+	ruta, err := s.ruta(fileName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(ruta); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrArchivoNoEncontrado
+		}
+		return fmt.Errorf("error eliminando archivo: %w", err)
+	}
+	return nil
+}
+
+// SignedURL genera la URL de descarga firmada, que la API acepta hasta que vence
+func (s *LocalStorage) SignedURL(fileName string, expiresIn time.Duration) (string, error) {
+	ruta, err := s.ruta(fileName)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(ruta); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrArchivoNoEncontrado
+		}
+		return "", err
+	}
+
+	vence := time.Now().Add(expiresIn).Unix()
+	parametros := url.Values{}
+	parametros.Set("vence", strconv.FormatInt(vence, 10))
+	parametros.Set("firma", s.firmar(fileName, vence))
+
+	return s.urlBase + RutaDescargaLocal + (&url.URL{Path: fileName}).EscapedPath() + "?" + parametros.Encode(), nil
+}
+
+// Open abre el archivo para leerlo
+func (s *LocalStorage) Open(fileName string) (io.ReadCloser, error) {
+	ruta, err := s.ruta(fileName)
+	if err != nil {
+		return nil, err
+	}
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrArchivoNoEncontrado
+		}
+		return nil, fmt.Errorf("error abriendo archivo: %w", err)
+	}
+	return archivo, nil
+}
+
+// VerificarFirma comprueba los parámetros vence y firma de una URL generada con SignedURL
+func (s *LocalStorage) VerificarFirma(fileName, vence, firma string) error {
+	if err := validarNombre(fileName); err != nil {
+		return ErrFirmaInvalida
+	}
+	segundos, err := strconv.ParseInt(vence, 10, 64)
+	if err != nil {
+		return ErrFirmaInvalida
+	}
+	if !hmac.Equal([]byte(firma), []byte(s.firmar(fileName, segundos))) {
+		return ErrFirmaInvalida
+	}
+	if time.Now().Unix() > segundos {
+		return ErrURLVencida
+	}
+	return nil
+}
+
+// firmar calcula el HMAC del nombre del archivo y el vencimiento
+func (s *LocalStorage) firmar(fileName string, vence int64) string {
+	mac := hmac.New(sha256.New, s.secreto)
+	mac.Write([]byte(fileName + "\n" + strconv.FormatInt(vence, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ruta valida el nombre y lo convierte en la ruta dentro del directorio
+func (s *LocalStorage) ruta(fileName string) (string, error) {
+	if err := validarNombre(fileName); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(fileName)), nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/infrastructure/config"
+)
+
+// Proveedores de almacenamiento admitidos en STORAGE_PROVIDER
+const (
+	ProveedorSupabase = "supabase"
+	ProveedorLocal    = "local"
+	ProveedorS3       = "s3"
+)
+
+// ErrArchivoNoEncontrado indica que el archivo pedido no existe en el almacenamiento
+var ErrArchivoNoEncontrado = errors.New("archivo no encontrado")
+
+// Provider es el almacenamiento de documentos (PDF firmados, actas escaneadas).
+// Los nombres de archivo son relativos al bucket o directorio configurado.
+type Provider interface {
+	// Upload guarda el archivo, reemplazando uno anterior con el mismo nombre, y retorna la ruta del objeto
+	Upload(fileName string, fileData []byte, contentType string) (string, error)
+	// Delete elimina el archivo
+	Delete(fileName string) error
+	// SignedURL genera una URL de descarga válida durante el tiempo indicado
+	SignedURL(fileName string, expiresIn time.Duration) (string, error)
+	// Open abre el archivo para leerlo; retorna ErrArchivoNoEncontrado si no existe
+	Open(fileName string) (io.ReadCloser, error)
+}
+
+// SubidaDirecta la implementan los almacenamientos en los que un cliente puede subir un
+// archivo directamente, sin pasar por la API (S3)
+type SubidaDirecta interface {
+	// PresignedPutURL genera una URL PUT válida durante el tiempo indicado
+	PresignedPutURL(fileName string, expiresIn time.Duration) (string, error)
+}
+
+// NewProvider crea el almacenamiento elegido en STORAGE_PROVIDER
+func NewProvider(cfg *config.Config) (Provider, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.StorageProvider)) {
+	case "", ProveedorSupabase:
+		return NewSupabaseStorage(cfg), nil
+	case ProveedorLocal:
+		return NewLocalStorage(cfg)
+	case ProveedorS3:
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("STORAGE_PROVIDER inválido: %q (use %s, %s o %s)", cfg.StorageProvider, ProveedorSupabase, ProveedorLocal, ProveedorS3)
+	}
+}
+
+// validarNombre rechaza nombres vacíos, absolutos o que intenten salir del bucket
+func validarNombre(fileName string) error {
+	if fileName == "" || strings.HasPrefix(fileName, "/") || strings.Contains(fileName, `\`) {
+		return fmt.Errorf("nombre de archivo inválido: %q", fileName)
+	}
+	for _, parte := range strings.Split(fileName, "/") {
+		if parte == "" || parte == "." || parte == ".." {
+			return fmt.Errorf("nombre de archivo inválido: %q", fileName)
+		}
+	}
+	return nil
+}
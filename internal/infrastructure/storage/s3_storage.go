@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"tum_inv_backend/internal/infrastructure/config"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// tiempoEsperaS3 limita cada operación contra el servidor S3
+const tiempoEsperaS3 = 60 * time.Second
+
+// S3Storage gestiona los archivos en un bucket S3 o compatible (MinIO, etc.).
+// Las descargas se hacen con URL prefirmadas por el propio servidor S3.
+type S3Storage struct {
+	client  *minio.Client
+	bucket  string
+	prefijo string
+}
+
+// NewS3Storage crea el cliente S3. S3_ENDPOINT puede incluir el esquema
+// (http://minio:9000); sin esquema se usa HTTPS.
+func NewS3Storage(cfg *config.Config) (*S3Storage, error) {
+	if cfg.S3Bucket == "" {
+		return nil, errors.New("S3_BUCKET es obligatorio con el almacenamiento S3")
+	}
+
+	endpoint := cfg.S3Endpoint
+	seguro := true
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("S3_ENDPOINT inválido: %q", cfg.S3Endpoint)
+		}
+		endpoint = u.Host
+		seguro = u.Scheme == "https"
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: seguro,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creando cliente S3: %w", err)
+	}
+
+	return &S3Storage{
+		client:  client,
+		bucket:  cfg.S3Bucket,
+		prefijo: strings.Trim(cfg.S3Prefix, "/"),
+	}, nil
+}
+
+// Upload sube el archivo al bucket y retorna la ruta del objeto (bucket/llave)
+func (s *S3Storage) Upload(fileName string, fileData []byte, contentType string) (string, error) {
+	llave, err := s.llave(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tiempoEsperaS3)
+	defer cancel()
+
+	_, err = s.client.PutObject(ctx, s.bucket, llave, bytes.NewReader(fileData), int64(len(fileData)),
+		minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return "", fmt.Errorf("error subiendo archivo: %w", err)
+	}
+
+	return s.bucket + "/" + llave, nil
+}
+
+// Delete elimina el archivo del bucket
+func (s *S3Storage) Delete(fileName string) error {
+	llave, err := s.llave(fileName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tiempoEsperaS3)
+	defer cancel()
+
+	// S3 no falla al borrar un objeto inexistente; se verifica antes, como en los demás proveedores
+	if err := s.existe(ctx, llave); err != nil {
+		return err
+	}
+	if err := s.client.RemoveObject(ctx, s.bucket, llave, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("error eliminando archivo: %w", err)
+	}
+	return nil
+}
+
+// SignedURL genera una URL GET prefirmada (máximo 7 días, límite de S3)
+func (s *S3Storage) SignedURL(fileName string, expiresIn time.Duration) (string, error) {
+	llave, err := s.llave(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tiempoEsperaS3)
+	defer cancel()
+
+	if err := s.existe(ctx, llave); err != nil {
+		return "", err
+	}
+	firmada, err := s.client.PresignedGetObject(ctx, s.bucket, llave, expiresIn, url.Values{})
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo URL firmada: %w", err)
+	}
+	return firmada.String(), nil
+}
+
+// PresignedPutURL genera una URL PUT prefirmada para que un cliente suba el archivo
+// directamente al bucket, sin pasar por la API
+func (s *S3Storage) PresignedPutURL(fileName string, expiresIn time.Duration) (string, error) {
+	llave, err := s.llave(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tiempoEsperaS3)
+	defer cancel()
+
+	firmada, err := s.client.PresignedPutObject(ctx, s.bucket, llave, expiresIn)
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo URL firmada: %w", err)
+	}
+	return firmada.String(), nil
+}
+
+// Open abre el archivo para leerlo desde el bucket
+func (s *S3Storage) Open(fileName string) (io.ReadCloser, error) {
+	llave, err := s.llave(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// La lectura continúa después de retornar, así que no se limita con tiempoEsperaS3
+	objeto, err := s.client.GetObject(context.Background(), s.bucket, llave, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error descargando archivo: %w", err)
+	}
+	// GetObject no consulta el servidor hasta la primera lectura; Stat confirma que existe
+	if _, err := objeto.Stat(); err != nil {
+		objeto.Close()
+		if esNoEncontrado(err) {
+			return nil, ErrArchivoNoEncontrado
+		}
+		return nil, fmt.Errorf("error descargando archivo: %w", err)
+	}
+	return objeto, nil
+}
+
+// existe retorna ErrArchivoNoEncontrado si el objeto no está en el bucket
+func (s *S3Storage) existe(ctx context.Context, llave string) error {
+	if _, err := s.client.StatObject(ctx, s.bucket, llave, minio.StatObjectOptions{}); err != nil {
+		if esNoEncontrado(err) {
+			return ErrArchivoNoEncontrado
+		}
+		return fmt.Errorf("error consultando archivo: %w", err)
+	}
+	return nil
+}
+
+// llave valida el nombre y le antepone el prefijo configurado
+func (s *S3Storage) llave(fileName string) (string, error) {
+	if err := validarNombre(fileName); err != nil {
+		return "", err
+	}
+	if s.prefijo == "" {
+		return fileName, nil
+	}
+	return path.Join(s.prefijo, fileName), nil
+}
+
+// esNoEncontrado indica si el error de S3 corresponde a un objeto inexistente
+func esNoEncontrado(err error) bool {
+	codigo := minio.ToErrorResponse(err).Code
+	return codigo == "NoSuchKey" || codigo == "NotFound"
+}
@@ -60,11 +60,11 @@ func (s *SupabaseStorage) GetPublicURL(fileName string) string {
 	return fmt.Sprintf("%s/storage/v1/object/public/%s/%s", s.baseURL, s.bucket, fileName)
 }
 
-// GetSignedURL genera una URL firmada temporal para descargar un archivo privado
-func (s *SupabaseStorage) GetSignedURL(fileName string, expiresIn int) (string, error) {
+// SignedURL genera una URL firmada temporal para descargar un archivo privado
+func (s *SupabaseStorage) SignedURL(fileName string, expiresIn time.Duration) (string, error) {
 	url := fmt.Sprintf("%s/storage/v1/object/sign/%s/%s", s.baseURL, s.bucket, fileName)
 
-	body := fmt.Sprintf(`{"expiresIn": %d}`, expiresIn)
+	body := fmt.Sprintf(`{"expiresIn": %d}`, int(expiresIn.Seconds()))
 	req, err := http.NewRequest("POST", url, bytes.NewReader([]byte(body)))
 	if err != nil {
 		return "", fmt.Errorf("error creando request: %w", err)
@@ -136,3 +136,32 @@ func (s *SupabaseStorage) Delete(fileName string) error {
 
 	return nil
 }
+
+// Open descarga un archivo privado del bucket usando la llave de servicio
+func (s *SupabaseStorage) Open(fileName string) (io.ReadCloser, error) {
+	url := fmt.Sprintf("%s/storage/v1/object/%s/%s", s.baseURL, s.bucket, fileName)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.serviceKey)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error descargando archivo: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		resp.Body.Close()
+		return nil, ErrArchivoNoEncontrado
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("error de Supabase Storage (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,77 @@
+package verificacion
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"tum_inv_backend/internal/infrastructure/config"
+)
+
+// largoFirma es la cantidad de caracteres base32 de la firma en el código (50 bits)
+const largoFirma = 10
+
+// ErrCodigoInvalido indica que el código no tiene el formato esperado o su firma no corresponde
+var ErrCodigoInvalido = errors.New("código de verificación inválido")
+
+// Verificador genera y comprueba los códigos de verificación impresos en los reportes de servicio.
+// Un código tiene la forma <id del reporte>-<firma>, donde la firma es un HMAC-SHA256 del ID
+// truncado, de modo que solo el servidor puede emitir códigos válidos.
+type Verificador struct {
+	secreto []byte
+	url     string
+}
+
+// NewVerificador crea el verificador a partir de la configuración.
+// VERIFICACION_SECRETO es obligatorio en producción; en desarrollo se deriva de JWT_SECRET.
+func NewVerificador(cfg *config.Config) (*Verificador, error) {
+	secreto := []byte(cfg.VerificacionSecreto)
+	if len(secreto) == 0 {
+		if cfg.AppEnv == "production" {
+			return nil, errors.New("VERIFICACION_SECRETO es obligatorio en producción")
+		}
+		log.Println("VERIFICACION_SECRETO no configurado, derivando secreto de desarrollo desde JWT_SECRET")
+		derivado := sha256.Sum256([]byte("verificacion:" + cfg.JWTSecret))
+		secreto = derivado[:]
+	}
+
+	return &Verificador{secreto: secreto, url: cfg.VerificacionURL}, nil
+}
+
+// Codigo genera el código de verificación de un reporte
+func (v *Verificador) Codigo(reporteID uint) string {
+	id := strconv.FormatUint(uint64(reporteID), 10)
+	return id + "-" + v.firma(id)
+}
+
+// URL es la dirección que se codifica en el QR del reporte
+func (v *Verificador) URL(codigo string) string {
+	return v.url + codigo
+}
+
+// ReporteID comprueba la firma del código y retorna el ID del reporte que identifica.
+// No distingue mayúsculas para que el código se pueda digitar a mano.
+func (v *Verificador) ReporteID(codigo string) (uint, error) {
+	id, firma, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(codigo)), "-")
+	if !ok || len(firma) != largoFirma {
+		return 0, ErrCodigoInvalido
+	}
+	reporteID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil || reporteID == 0 || strconv.FormatUint(reporteID, 10) != id {
+		return 0, ErrCodigoInvalido
+	}
+	if !hmac.Equal([]byte(firma), []byte(v.firma(id))) {
+		return 0, ErrCodigoInvalido
+	}
+	return uint(reporteID), nil
+}
+
+// firma calcula la parte firmada del código para el ID en texto
+func (v *Verificador) firma(id string) string {
+	mac := hmac.New(sha256.New, v.secreto)
+	mac.Write([]byte("reporte-servicio:" + id))
+	return base32.StdEncoding.EncodeToString(mac.Sum(nil))[:largoFirma]
+}
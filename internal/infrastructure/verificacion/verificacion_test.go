@@ -0,0 +1,115 @@
+package verificacion
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"tum_inv_backend/internal/infrastructure/config"
+)
+
+func nuevoVerificador(t *testing.T, secreto string) *Verificador {
+	t.Helper()
+	v, err := NewVerificador(&config.Config{VerificacionSecreto: secreto, VerificacionURL: "https://inventario.test/verificar/"})
+	if err != nil {
+		t.Fatalf("NewVerificador: %v", err)
+	}
+	return v
+}
+
+func TestNewVerificador(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		cfg     config.Config
+		wantErr bool
+	}{
+		{"con secreto", config.Config{AppEnv: "production", VerificacionSecreto: "secreto"}, false},
+		{"desarrollo sin secreto", config.Config{AppEnv: "development", JWTSecret: "jwt"}, false},
+		{"producción sin secreto", config.Config{AppEnv: "production", JWTSecret: "jwt"}, true},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			_, err := NewVerificador(&tc.cfg)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("error = %v, se esperaba error: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCodigoIdaYVuelta(t *testing.T) {
+	v := nuevoVerificador(t, "secreto")
+
+	for _, id := range []uint{1, 15, 4294967295} {
+		codigo := v.Codigo(id)
+		got, err := v.ReporteID(codigo)
+		if err != nil {
+			t.Fatalf("ReporteID(%q): %v", codigo, err)
+		}
+		if got != id {
+			t.Errorf("ReporteID(Codigo(%d)) = %d", id, got)
+		}
+	}
+}
+
+func TestReporteID(t *testing.T) {
+	v := nuevoVerificador(t, "secreto")
+	otro := nuevoVerificador(t, "otro-secreto")
+
+	codigo := v.Codigo(15)
+	_, firma, _ := strings.Cut(codigo, "-")
+	alterada := []byte(firma)
+	if alterada[0] == 'A' {
+		alterada[0] = 'B'
+	} else {
+		alterada[0] = 'A'
+	}
+
+	casos := []struct {
+		nombre string
+		codigo string
+		want   uint
+		valido bool
+	}{
+		{"válido", codigo, 15, true},
+		{"minúsculas", strings.ToLower(codigo), 15, true},
+		{"con espacios", "  " + codigo + "\n", 15, true},
+		{"firma de otro secreto", otro.Codigo(15), 0, false},
+		{"firma de otro reporte", "16-" + firma, 0, false},
+		{"firma alterada", "15-" + string(alterada), 0, false},
+		{"firma corta", "15-" + firma[:largoFirma-1], 0, false},
+		{"firma larga", codigo + "A", 0, false},
+		{"sin separador", "15" + firma, 0, false},
+		{"vacío", "", 0, false},
+		{"ID cero", "0-" + v.firma("0"), 0, false},
+		{"ID con ceros a la izquierda", "015-" + v.firma("015"), 0, false},
+		{"ID negativo", "-15-" + firma, 0, false},
+		{"ID no numérico", "abc-" + v.firma("ABC"), 0, false},
+		{"ID fuera de rango", "4294967296-" + v.firma("4294967296"), 0, false},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nombre, func(t *testing.T) {
+			got, err := v.ReporteID(tc.codigo)
+			if !tc.valido {
+				if !errors.Is(err, ErrCodigoInvalido) {
+					t.Fatalf("ReporteID(%q) = %d, %v; se esperaba ErrCodigoInvalido", tc.codigo, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReporteID(%q): %v", tc.codigo, err)
+			}
+			if got != tc.want {
+				t.Errorf("ReporteID(%q) = %d, se esperaba %d", tc.codigo, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	v := nuevoVerificador(t, "secreto")
+	if got, want := v.URL("15-ABC"), "https://inventario.test/verificar/15-ABC"; got != want {
+		t.Errorf("URL = %q, se esperaba %q", got, want)
+	}
+}
@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 	"tum_inv_backend/internal/api/routes"
+	"tum_inv_backend/internal/infrastructure/cifrado"
 	"tum_inv_backend/internal/infrastructure/config"
+	"tum_inv_backend/internal/infrastructure/correo"
 	"tum_inv_backend/internal/infrastructure/database"
+	"tum_inv_backend/internal/infrastructure/firma"
 	"tum_inv_backend/internal/infrastructure/seed"
+	"tum_inv_backend/internal/infrastructure/storage"
+	"tum_inv_backend/internal/infrastructure/verificacion"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -31,19 +41,76 @@ func main() {
 	e.Use(middleware.Secure())
 	e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
 		Timeout: 30 * time.Second,
+		Skipper: func(c echo.Context) bool {
+			return rutasSinTiempoEspera[c.Path()]
+		},
 	}))
 
+	// Configurar llaves de cifrado de credenciales (antes de acceder a la BD)
+	llavero, err := cifrado.NewLlavero(cfg)
+	if err != nil {
+		e.Logger.Fatal("Error configurando cifrado de credenciales: ", err)
+	}
+	cifrado.Configurar(llavero)
+
+	// Almacenamiento de documentos firmados (Supabase o disco local, según STORAGE_PROVIDER)
+	almacenamiento, err := storage.NewProvider(cfg)
+	if err != nil {
+		e.Logger.Fatal("Error configurando almacenamiento: ", err)
+	}
+
+	// Códigos de verificación impresos en los reportes de servicio
+	verificador, err := verificacion.NewVerificador(cfg)
+	if err != nil {
+		e.Logger.Fatal("Error configurando la verificación de reportes: ", err)
+	}
+
+	// Certificado de firma digital de la Oficina de Sistemas y CA confiables
+	firmaDigital, err := firma.NewFirmaDigital(cfg)
+	if err != nil {
+		e.Logger.Fatal("Error configurando la firma digital: ", err)
+	}
+
+	// Servidor SMTP de las notificaciones por correo; sin SMTP_HOST quedan deshabilitadas
+	remitente, err := correo.NewRemitente(cfg)
+	if err != nil {
+		e.Logger.Fatal("Error configurando el correo: ", err)
+	}
+
 	// Conectar a la base de datos
 	database.ConnectDB(cfg)
 
+	// Eliminar los índices que AutoMigrate reemplazó por índices únicos parciales
+	if err := database.MigrarIndicesReemplazados(database.DB); err != nil {
+		e.Logger.Fatal("Error migrando índices: ", err)
+	}
+
+	// Cifrar credenciales en texto plano y rotar las cifradas con llaves anteriores
+	if err := database.MigrarCredenciales(database.DB, llavero); err != nil {
+		e.Logger.Fatal("Error migrando credenciales cifradas: ", err)
+	}
+
+	// Registrar en el historial los PDF firmados de reportes cerrados antes de existir las versiones
+	if err := database.MigrarDocumentosReporte(database.DB); err != nil {
+		e.Logger.Fatal("Error migrando documentos firmados: ", err)
+	}
+	if err := database.MigrarActasFirmadas(database.DB); err != nil {
+		e.Logger.Fatal("Error migrando actas de entrega firmadas: ", err)
+	}
+
 	// Ejecutar seeds (datos iniciales)
 	seeder := seed.NewSeeder(database.DB)
 	if err := seeder.SeedAll(); err != nil {
 		e.Logger.Fatal("Error ejecutando seeds: ", err)
 	}
 
-	// Configurar rutas usando la variable global DB y la configuración
-	routes.SetupRoutes(e, database.DB, cfg)
+	// Los procesos en segundo plano corren hasta que llegue SIGINT o SIGTERM (Railway envía
+	// SIGTERM al desplegar una nueva versión)
+	ctx, detener := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer detener()
+
+	// Configurar rutas usando la variable global DB, la configuración, el almacenamiento, el verificador, la firma digital y el correo
+	routes.SetupRoutes(ctx, e, database.DB, cfg, almacenamiento, verificador, firmaDigital, remitente)
 
 	// Railway usa la variable PORT, usar AppPort como fallback
 	port := os.Getenv("PORT")
@@ -52,5 +119,41 @@ func main() {
 	}
 
 	// Iniciar servidor
-	e.Logger.Fatal(e.Start(":" + port))
+	go func() {
+		if err := e.Start(":" + port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	// Al recibir la señal se detienen primero los procesos en segundo plano y luego se esperan
+	// las peticiones en curso. Los trabajos y correos que queden a medias los retoma otra
+	// instancia al vencer su arriendo.
+	<-ctx.Done()
+	detener()
+	apagado, cancelar := context.WithTimeout(context.Background(), tiempoApagado)
+	defer cancelar()
+	if err := e.Shutdown(apagado); err != nil {
+		e.Logger.Error("Error deteniendo el servidor: ", err)
+	}
+}
+
+// tiempoApagado es lo que se esperan las peticiones en curso al detener el servidor; los
+// streams de notificaciones no terminan solos y se cortan al cumplirse
+const tiempoApagado = 10 * time.Second
+
+// rutasSinTiempoEspera son las rutas que no pasan por el tiempo de espera de 30 segundos.
+// El middleware usa http.TimeoutHandler, que guarda toda la respuesta en memoria hasta que
+// el handler termina: las descargas que se envían por partes no llegarían al cliente hasta el
+// final y se cortarían a los 30 segundos.
+var rutasSinTiempoEspera = map[string]bool{
+	// El stream de notificaciones queda abierto mientras el usuario tenga la aplicación abierta
+	"/api/notificaciones/stream": true,
+	// PDF por lote: el ZIP se envía a medida que se generan los PDF
+	"/api/reportes-servicio/pdf-lote": true,
+	// Exportaciones: el CSV se envía cada 500 registros y el XLSX, que se arma en disco, puede
+	// tardar más de 30 segundos con todo el inventario
+	"/api/equipos/exportar":           true,
+	"/api/perifericos/exportar":       true,
+	"/api/software/exportar":          true,
+	"/api/reportes-servicio/exportar": true,
 }